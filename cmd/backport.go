@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"git_cli_tool/config"
+	"git_cli_tool/engine"
+	"git_cli_tool/git"
+	"git_cli_tool/log"
+
+	"github.com/spf13/cobra"
+)
+
+// backportCmd represents the backport command
+var backportCmd = &cobra.Command{
+	Use:   "backport <commit-or-branch> --to <release-branch>",
+	Short: "Cherry-pick a commit or branch onto a release branch in all repositories",
+	Long: `Backport changes to a release branch in every repository.
+
+In each repository the release branch is checked out and the changes are
+cherry-picked onto it:
+- for a branch, all of its commits that are not in the base branch
+  (--base, default: the sync fallback_branch or 'main') and not yet on
+  the release branch
+- for a commit, that commit, in the repositories where it exists
+
+Repositories with nothing to backport are left on their current branch.
+Repositories with uncommitted changes are not touched. Conflicts are left
+in place for manual resolution.
+
+Example:
+  git_cli_tool backport hotfix/login-timeout --to release/1.2
+  git_cli_tool backport 3f2a9c1 --to release/1.2 --only api-service --push`,
+	Args: cobra.ExactArgs(1),
+	Run:  runBackportCmd,
+}
+
+var (
+	backportTo     string
+	backportBase   string
+	backportPush   bool
+	backportOrigin bool
+)
+
+// initBackportCmd initializes the backport command with its flags
+func initBackportCmd() {
+	backportCmd.Flags().StringVar(&backportTo, "to", "", "Release branch to backport onto (required)")
+	backportCmd.Flags().StringVar(&backportBase, "base", "", "Branch the backported branch was started from (default: sync fallback_branch or the remote's default branch)")
+	backportCmd.Flags().BoolVar(&backportPush, "push", false, "Push the release branch after backporting")
+	backportCmd.Flags().BoolVarP(&backportOrigin, "record-origin", "x", true, "Append \"(cherry picked from commit ...)\" to the commit messages")
+	backportCmd.MarkFlagRequired("to")
+}
+
+// runBackportCmd is the main function for the backport command
+func runBackportCmd(cmd *cobra.Command, args []string) {
+	source := args[0]
+	configObj, repositories := loadRepositories()
+
+	// Empty stands for the fallback branch of each repository
+	base := backportBase
+	if base == "" {
+		base = configObj.Sync.FallbackBranch
+	}
+
+	log.PrintOperation(fmt.Sprintf("Backporting %s to %s", source, backportTo))
+	log.PrintInfo("")
+
+	results := make([]CherryPickResult, len(repositories))
+	errs := engine.ForEachRepository(repositories, parallelOptions(), func(i int, r config.Repository) error {
+		results[i] = backportRepository(r, source, r.MapBranch(backportTo), fallbackBranchFor(base, r))
+		if !results[i].Success {
+			return errors.New(results[i].Message)
+		}
+		return nil
+	})
+
+	// Print summary
+	log.PrintInfo("=== Backport Summary ===")
+	successCount := 0
+	failCount := 0
+	for i, result := range results {
+		if errs[i] == engine.ErrSkipped {
+			result = CherryPickResult{RepoName: repositories[i].Name(), Message: "skipped after an earlier failure"}
+		}
+		if result.Success {
+			successCount++
+			log.PrintSuccess(fmt.Sprintf("%-30s %s", result.RepoName, result.Message))
+		} else {
+			failCount++
+			log.PrintErrorNoExit(log.ErrOperationFailed, fmt.Sprintf("%-30s %s", result.RepoName, result.Message), nil)
+		}
+	}
+
+	log.PrintInfo("")
+	if failCount == 0 {
+		log.PrintSuccess(fmt.Sprintf("All %d repositories processed successfully!", successCount))
+	} else {
+		log.PrintWarning(fmt.Sprintf("%d succeeded, %d failed", successCount, failCount))
+		os.Exit(1)
+	}
+}
+
+// backportRepository cherry-picks a commit or the commits of a branch onto the
+// release branch of a single repository, optionally pushing the result
+func backportRepository(repo config.Repository, source string, target string, base string) CherryPickResult {
+	result := CherryPickResult{RepoName: repo.Name()}
+
+	status, err := git.GetWorkingTreeStatus(repo.Path)
+	if err != nil {
+		result.Message = err.Error()
+		return result
+	}
+	if status.HasChanges() {
+		result.Message = "uncommitted changes, commit or stash them first"
+		return result
+	}
+
+	targetRef, ok, err := git.ResolveBranch(repo.Path, repo.Remote, target)
+	if err != nil {
+		result.Message = err.Error()
+		return result
+	}
+	if !ok {
+		result.Message = fmt.Sprintf("release branch '%s' not found", target)
+		return result
+	}
+
+	// Work out what to pick before touching the working tree
+	var commits []string
+	if sourceRef, isBranch, err := git.ResolveBranch(repo.Path, repo.Remote, repo.MapBranch(source)); err != nil {
+		result.Message = err.Error()
+		return result
+	} else if isBranch {
+		baseRef, ok, err := git.ResolveBranch(repo.Path, repo.Remote, base)
+		if err != nil {
+			result.Message = err.Error()
+			return result
+		}
+		if !ok {
+			result.Message = fmt.Sprintf("base branch '%s' not found", base)
+			return result
+		}
+		commits, err = git.FindUnpickedCommits(repo.Path, targetRef, sourceRef, baseRef)
+		if err != nil {
+			result.Message = err.Error()
+			return result
+		}
+	} else if git.CommitExists(repo.Path, source) {
+		commits = []string{source}
+	}
+
+	if len(commits) == 0 {
+		result.Success = true
+		result.Message = "nothing to backport"
+		return result
+	}
+
+	switchResult := git.SwitchBranchWithResult(repo.Path, repo.Remote, []string{target})
+	if !switchResult.Success {
+		result.Message = fmt.Sprintf("failed to switch to '%s': %s", target, switchResult.Message)
+		return result
+	}
+
+	if err := git.CherryPick(repo.Path, commits, backportOrigin); err != nil {
+		result.Message = cherryPickFailure(err)
+		return result
+	}
+
+	result.Commits = len(commits)
+	result.Message = fmt.Sprintf("picked %d commits onto %s", len(commits), target)
+
+	if backportPush {
+		pushResult := engine.PushRepository(repo)
+		if !pushResult.Success {
+			result.Message += fmt.Sprintf(", push failed: %s", pushResult.Message)
+			return result
+		}
+		result.Message += ", " + pushResult.Message
+	}
+
+	result.Success = true
+	return result
+}
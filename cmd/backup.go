@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"git_cli_tool/config"
+	"git_cli_tool/engine"
+	"git_cli_tool/git"
+	"git_cli_tool/log"
+
+	"github.com/spf13/cobra"
+)
+
+// backupCmd represents the backup command
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Create or update bare mirror backups of all repositories",
+	Long: `Mirror every repository into a destination directory as <name>.git.
+
+The first run creates the mirrors with "git clone --mirror" from the local
+repositories, so local branches that were never pushed are included. Later
+runs update them with "git remote update --prune". The summary shows the
+size of every mirror and when it was last updated.
+
+Example config:
+  backup:
+    dest: "/mnt/backup/git"
+
+Example:
+  git_cli_tool backup --dest /mnt/backup/git`,
+	Args: cobra.NoArgs,
+	Run:  runBackupCmd,
+}
+
+var backupDest string
+
+// initBackupCmd initializes the backup command with its flags
+func initBackupCmd() {
+	backupCmd.Flags().StringVar(&backupDest, "dest", "", "Directory holding the mirror clones (default from backup.dest)")
+}
+
+// BackupResult holds the result of backing up a single repository
+type BackupResult struct {
+	RepoName    string
+	MirrorPath  string
+	Created     bool // the mirror did not exist before this run
+	Size        int64
+	LastUpdated time.Time // zero if the mirror does not exist
+	Err         error
+}
+
+// runBackupCmd is the main function for the backup command
+func runBackupCmd(cmd *cobra.Command, args []string) {
+	configObj, repositories := loadRepositories()
+
+	dest := backupDest
+	if dest == "" {
+		dest = configObj.Backup.Dest
+	}
+	if dest == "" {
+		log.PrintError(log.ErrInvalidArgument, "No backup destination given (--dest or backup.dest)", nil)
+	}
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		log.PrintError(log.ErrInvalidArgument, "Failed to create the backup destination", err)
+	}
+
+	// Mirrors are named after the repositories, so two repositories with the same name would overwrite each other
+	owners := make(map[string]string)
+	for _, repo := range repositories {
+		if other, ok := owners[repo.Name()]; ok {
+			log.PrintError(log.ErrInvalidArgument, fmt.Sprintf("%s and %s would both be backed up as %s.git", other, repo.Path, repo.Name()), nil)
+		}
+		owners[repo.Name()] = repo.Path
+	}
+
+	log.PrintOperation(fmt.Sprintf("Backing up %d repositories to %s", len(repositories), dest))
+	log.PrintInfo("")
+
+	results := make([]BackupResult, len(repositories))
+	opts, progress := progressOptions("Backing up", repositories)
+	errs := engine.ForEachRepository(repositories, opts, func(i int, r config.Repository) error {
+		results[i] = backupRepository(r, dest)
+		return results[i].Err
+	})
+	progress.Stop()
+
+	var totalSize int64
+	rows := make([][]string, len(results))
+	for i, result := range results {
+		state := "updated"
+		switch {
+		case errs[i] == engine.ErrSkipped:
+			state = "skipped"
+		case result.Err != nil:
+			state = "failed"
+		case result.Created:
+			state = "created"
+		}
+
+		size, lastUpdated := "-", "never"
+		if !result.LastUpdated.IsZero() {
+			size = formatSize(result.Size)
+			lastUpdated = result.LastUpdated.Format("2006-01-02 15:04")
+			totalSize += result.Size
+		}
+		rows[i] = []string{repositories[i].Name(), state, size, lastUpdated}
+	}
+	printTable([]string{"REPOSITORY", "BACKUP", "SIZE", "LAST UPDATED"}, rows)
+
+	log.PrintInfo("")
+	for _, result := range results {
+		if result.Err != nil && result.Err != engine.ErrSkipped {
+			log.PrintErrorNoExit("", fmt.Sprintf("%-30s %v", result.RepoName, result.Err), nil)
+		}
+	}
+	log.PrintInfo(fmt.Sprintf("Total size: %s", formatSize(totalSize)))
+
+	reportFailures("Backup", errs)
+}
+
+// backupRepository creates or updates the mirror of a repository in dest
+func backupRepository(repo config.Repository, dest string) BackupResult {
+	result := BackupResult{
+		RepoName:   repo.Name(),
+		MirrorPath: filepath.Join(dest, repo.Name()+".git"),
+	}
+
+	if _, err := os.Stat(result.MirrorPath); os.IsNotExist(err) {
+		result.Created = true
+		result.Err = git.CloneMirror(repo.Path, result.MirrorPath)
+	} else {
+		result.Err = git.UpdateMirror(result.MirrorPath)
+	}
+
+	// Git only touches the mirror directory when refs change, so record the time of a successful update explicitly
+	if result.Err == nil {
+		now := time.Now()
+		os.Chtimes(result.MirrorPath, now, now)
+	}
+
+	if info, err := os.Stat(result.MirrorPath); err == nil {
+		result.LastUpdated = info.ModTime()
+		result.Size = dirSize(result.MirrorPath)
+	}
+	return result
+}
+
+// dirSize returns the total size of the files below a directory
+func dirSize(dir string) int64 {
+	var size int64
+	filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return nil
+		}
+		if info, err := entry.Info(); err == nil {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+// formatSize formats a size in bytes for humans, e.g. "12.3 MB"
+func formatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	value := float64(bytes) / unit
+	for _, suffix := range []string{"KB", "MB", "GB"} {
+		if value < unit {
+			return fmt.Sprintf("%.1f %s", value, suffix)
+		}
+		value /= unit
+	}
+	return fmt.Sprintf("%.1f TB", value)
+}
@@ -0,0 +1,737 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"git_cli_tool/config"
+	"git_cli_tool/engine"
+	"git_cli_tool/git"
+	"git_cli_tool/log"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultProtectedBranches are never deleted unless branch.protected is configured
+var defaultProtectedBranches = []string{"main", "master", "develop"}
+
+// branchCmd represents the branch command
+var branchCmd = &cobra.Command{
+	Use:   "branch",
+	Short: "Manage branches across all repositories",
+}
+
+// branchCreateCmd represents the branch create command
+var branchCreateCmd = &cobra.Command{
+	Use:   "create [name]",
+	Short: "Create the same branch in all repositories",
+	Long: `Create a branch in every repository from the latest base branch (--from,
+default: the sync fallback_branch or the default branch of the repository's
+remote) and check it out.
+
+Instead of a name, pass --ticket and --slug to generate the name from
+branch_template (default "feature/{ticket}-{slug}"), so coordinated branches
+are named the same way everywhere. The slug is lowercased with spaces and
+other characters replaced by "-". The ticket ID is also available to the
+version commit message template as {{.Ticket}}, stored with the history
+entry, and commented on in Jira if notifications.jira is configured.
+
+Example config:
+  branch_template: "feature/{ticket}-{slug}"
+
+Example:
+  git_cli_tool branch create --ticket JIRA-1234 --slug add-login
+  git_cli_tool branch create feature/shared-auth --from develop --push`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runBranchCreateCmd,
+}
+
+// branchRenameCmd represents the branch rename command
+var branchRenameCmd = &cobra.Command{
+	Use:   "rename <old> <new>",
+	Short: "Rename a branch in all repositories that have it",
+	Long: `Rename a local branch in every repository where it exists. Repositories
+without the branch are skipped. Branch maps apply to both names.
+
+Without --push, the renamed branch tracks <remote>/<new> if that exists
+already, otherwise its upstream is left unchanged. With --push, the new
+name is pushed and set as upstream, and the old branch is deleted on the
+remote.
+
+Example:
+  git_cli_tool branch rename master main --push
+  git_cli_tool branch rename feature/login feature/auth`,
+	Args: cobra.ExactArgs(2),
+	Run:  runBranchRenameCmd,
+
+	ValidArgsFunction: completeSingleBranch,
+}
+
+// branchDeleteRemoteCmd represents the branch delete-remote command
+var branchDeleteRemoteCmd = &cobra.Command{
+	Use:   "delete-remote <name>",
+	Short: "Delete a branch on the remote of all repositories that have it",
+	Long: `Delete the branch <remote>/<name> in every repository whose remote has it,
+after asking for confirmation (or with --yes); repositories can be left out
+in a checklist before confirming. The remote is asked directly,
+so stale remote-tracking branches do not count; they are pruned instead.
+Local branches are left alone. Branch maps apply to the name.
+
+Example:
+  git_cli_tool branch delete-remote feature/login --dry-run
+  git_cli_tool branch delete-remote feature/login --yes`,
+	Args: cobra.ExactArgs(1),
+	Run:  runBranchDeleteRemoteCmd,
+
+	ValidArgsFunction: completeSingleBranch,
+}
+
+// branchPruneCmd represents the branch prune command
+var branchPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete local branches that are merged into the fallback branch",
+	Long: `Delete, in every repository, the local branches that are already merged into
+the fallback branch (--into, default: the sync fallback_branch or the default
+branch of the repository's remote).
+The local branch is used if it exists, otherwise its remote-tracking branch.
+
+Protected branches, the current branch, the fallback branch itself and
+branches that point at the same commit as the fallback branch (e.g. a branch
+just created from it) are never deleted. With --remote, branches that also
+exist locally are deleted on the remote if the remote branch is merged as
+well; other remote branches are only deleted when named with --remote-branch.
+
+The branches that would be deleted are listed first, the remote ones
+separately. Nothing is deleted
+until the deletion is confirmed, either interactively or with --yes.
+Before confirming, repositories can be left out in a checklist.
+
+Example config:
+  branch:
+    protected: ["main", "develop", "release/*"]
+
+Example:
+  git_cli_tool branch prune --dry-run
+  git_cli_tool branch prune --into develop --remote --yes
+  git_cli_tool branch prune --remote-branch feature/old-login`,
+	Args: cobra.NoArgs,
+	Run:  runBranchPruneCmd,
+}
+
+var (
+	branchInto           string
+	branchRemote         bool
+	branchYes            bool
+	branchDryRun         bool
+	branchRemoteBranches []string
+	branchPush           bool
+	branchTicket         string
+	branchSlug           string
+	branchFrom           string
+	branchSwitch         bool
+)
+
+// initBranchCmd initializes the branch command and its subcommands
+func initBranchCmd() {
+	branchPruneCmd.Flags().StringVar(&branchInto, "into", "", "Branch the pruned branches are merged into (default: sync fallback_branch or the remote's default branch)")
+	branchPruneCmd.Flags().BoolVar(&branchRemote, "remote", false, "Also delete merged branches on the remote that exist locally")
+	branchPruneCmd.Flags().StringSliceVar(&branchRemoteBranches, "remote-branch", nil, "Also delete this merged branch on the remote, even without a local branch (repeatable)")
+	branchPruneCmd.Flags().BoolVarP(&branchYes, "yes", "y", false, "Delete without asking for confirmation")
+	branchPruneCmd.Flags().BoolVar(&branchDryRun, "dry-run", false, "Only list the branches that would be deleted")
+
+	branchCreateCmd.Flags().StringVar(&branchTicket, "ticket", "", "Ticket ID the branch name is generated for, e.g. JIRA-1234")
+	branchCreateCmd.Flags().StringVar(&branchSlug, "slug", "", "Short description in the generated branch name, e.g. add-login")
+	branchCreateCmd.Flags().StringVar(&branchFrom, "from", "", "Branch to create the branch from (default: sync fallback_branch or the remote's default branch)")
+	branchCreateCmd.Flags().BoolVar(&branchSwitch, "switch", true, "Check out the new branch")
+	branchCreateCmd.Flags().BoolVar(&branchPush, "push", false, "Push the new branch and set it as upstream")
+
+	branchRenameCmd.Flags().BoolVar(&branchPush, "push", false, "Push the new name and delete the old branch on the remote")
+
+	branchDeleteRemoteCmd.Flags().BoolVarP(&branchYes, "yes", "y", false, "Delete without asking for confirmation")
+	branchDeleteRemoteCmd.Flags().BoolVar(&branchDryRun, "dry-run", false, "Only list the repositories whose remote has the branch")
+
+	branchCmd.AddCommand(branchCreateCmd)
+	branchCmd.AddCommand(branchPruneCmd)
+	branchCmd.AddCommand(branchRenameCmd)
+	branchCmd.AddCommand(branchDeleteRemoteCmd)
+}
+
+// prunePlan lists the branches of a repository that branch prune deletes
+type prunePlan struct {
+	Local  []string
+	Remote []string // branches deleted on the repository's remote, with --remote
+}
+
+// runBranchPruneCmd is the main function for the branch prune command
+func runBranchPruneCmd(cmd *cobra.Command, args []string) {
+	configObj, repositories := loadRepositories()
+
+	into := branchInto
+	if into == "" {
+		into = configObj.Sync.FallbackBranch
+	}
+	protected := configObj.Branch.Protected
+	if len(protected) == 0 {
+		protected = defaultProtectedBranches
+	}
+
+	log.PrintOperation(fmt.Sprintf("Looking for branches merged into %s", describeFallback(into)))
+	log.PrintInfo("")
+
+	plans := make([]prunePlan, len(repositories))
+	errs := engine.ForEachRepository(repositories, engine.ParallelOptions{Jobs: jobs}, func(i int, r config.Repository) error {
+		var err error
+		plans[i], err = planPrune(r, fallbackBranchFor(into, r), protected)
+		return err
+	})
+
+	var toPrune []config.Repository
+	var toPrunePlans []prunePlan
+	localCount, remoteCount := 0, 0
+	for i, repo := range repositories {
+		plan := plans[i]
+		switch {
+		case errs[i] != nil:
+			log.PrintErrorNoExit("", fmt.Sprintf("%-30s %v", repo.Name(), errs[i]), nil)
+		case len(plan.Local)+len(plan.Remote) == 0:
+			log.PrintInfo(fmt.Sprintf("%-30s nothing to prune", repo.Name()))
+		default:
+			log.PrintWarning(fmt.Sprintf("%-30s %s:", repo.Name(), describePrunePlan(repo, plan)))
+			for _, branch := range plan.Local {
+				log.PrintOutput("    " + branch)
+			}
+			if len(plan.Remote) > 0 {
+				log.PrintOutput(fmt.Sprintf("  on the remote %s:", repo.Remote))
+				for _, branch := range plan.Remote {
+					log.PrintOutput("    " + repo.Remote + "/" + branch)
+				}
+			}
+			toPrune = append(toPrune, repo)
+			toPrunePlans = append(toPrunePlans, plan)
+			localCount += len(plan.Local)
+			remoteCount += len(plan.Remote)
+		}
+	}
+	log.PrintInfo("")
+
+	if len(toPrune) == 0 {
+		reportPreviewFailures("Prune preview", errs)
+		log.PrintSuccess("Nothing to prune")
+		return
+	}
+	if branchDryRun {
+		log.PrintInfo(fmt.Sprintf("Would delete %s in %d repositories (dry-run)", describePruneCounts(localCount, remoteCount), len(toPrune)))
+		reportPreviewFailures("Prune preview", errs)
+		return
+	}
+
+	if !branchYes {
+		details := make([]string, len(toPrune))
+		for i, plan := range toPrunePlans {
+			details[i] = describePrunePlan(toPrune[i], plan)
+		}
+		toPrune, toPrunePlans = deselectRepositories("Repositories to prune:", toPrune, details, toPrunePlans)
+		if len(toPrune) == 0 {
+			log.PrintInfo("Nothing was deleted")
+			return
+		}
+		localCount, remoteCount = 0, 0
+		for _, plan := range toPrunePlans {
+			localCount += len(plan.Local)
+			remoteCount += len(plan.Remote)
+		}
+
+		confirmed, err := log.Confirm(fmt.Sprintf("Delete %s in %d repositories?", describePruneCounts(localCount, remoteCount), len(toPrune)))
+		if err != nil {
+			log.PrintError(log.ErrPromptRequired, "Pass --yes to delete without confirmation", err)
+		}
+		if !confirmed {
+			log.PrintInfo("Nothing was deleted")
+			return
+		}
+	}
+
+	pruneErrs := engine.ForEachRepository(toPrune, parallelOptions(), func(i int, r config.Repository) error {
+		return prune(r, toPrunePlans[i])
+	})
+	for i, err := range pruneErrs {
+		if err != nil && err != engine.ErrSkipped {
+			log.PrintErrorNoExit("", fmt.Sprintf("%-30s %v", toPrune[i].Name(), err), nil)
+		}
+	}
+
+	reportFailures("Prune", append(pruneErrs, errs...))
+}
+
+// createResult holds the result of creating a branch in a single repository
+type createResult struct {
+	RepoName string
+	BaseRef  string // ref the branch was created from
+	Switched bool
+	Pushed   bool
+	Err      error
+}
+
+// runBranchCreateCmd is the main function for the branch create command
+func runBranchCreateCmd(cmd *cobra.Command, args []string) {
+	configObj, repositories := loadRepositories()
+
+	var name string
+	switch {
+	case len(args) > 0 && (branchTicket != "" || branchSlug != ""):
+		log.PrintError(log.ErrInvalidArgument, "Pass either a branch name or --ticket/--slug, not both", nil)
+	case len(args) > 0:
+		name = args[0]
+	default:
+		var err error
+		if name, err = configObj.BranchName(branchTicket, branchSlug); err != nil {
+			log.PrintError(log.ErrInvalidArgument, "Pass a branch name or --ticket to generate one", err)
+		}
+	}
+
+	from := branchFrom
+	if from == "" {
+		from = configObj.Sync.FallbackBranch
+	}
+
+	ticket := branchTicket
+	if ticket == "" {
+		ticket = configObj.TicketFromBranch(name)
+	}
+	if branchSwitch {
+		historyTicket = ticket
+		recordCurrentState(configObj, repositories, "before branch create "+name)
+	}
+
+	log.PrintOperation(fmt.Sprintf("Creating branch %s from %s", name, describeFallback(from)))
+	log.PrintInfo("")
+
+	results := make([]createResult, len(repositories))
+	opts, progress := progressOptions("Creating", repositories)
+	errs := engine.ForEachRepository(repositories, opts, func(i int, r config.Repository) error {
+		results[i] = createBranch(r, r.MapBranch(name), fallbackBranchFor(from, r))
+		return results[i].Err
+	})
+	progress.Stop()
+
+	for i, result := range results {
+		switch {
+		case errs[i] == engine.ErrSkipped:
+			log.PrintWarning(fmt.Sprintf("%-30s [SKIPPED: earlier failure]", repositories[i].Name()))
+		case result.Err != nil:
+			log.PrintErrorNoExit("", fmt.Sprintf("%-30s %v", result.RepoName, strings.TrimSpace(result.Err.Error())), nil)
+		default:
+			line := fmt.Sprintf("%-30s %s from %s", result.RepoName, repositories[i].MapBranch(name), result.BaseRef)
+			if result.Switched {
+				line += " [CHECKED OUT]"
+			}
+			if result.Pushed {
+				line += " [PUSHED]"
+			}
+			log.PrintSuccess(line)
+		}
+	}
+
+	notifyTicket(configObj, ticket, ticketSummary("branch create", name, repositories, errs))
+
+	log.PrintInfo("")
+	reportFailures("Create", errs)
+}
+
+// createBranch creates a branch from the latest base branch of a repository,
+// checking it out and pushing it if requested
+func createBranch(repo config.Repository, name string, from string) createResult {
+	result := createResult{RepoName: repo.Name()}
+
+	if result.Err = git.FetchRepository(repo); result.Err != nil {
+		return result
+	}
+	if _, exists, err := git.ResolveBranch(repo.Path, repo.Remote, name); err != nil {
+		result.Err = err
+		return result
+	} else if exists {
+		result.Err = fmt.Errorf("branch '%s' already exists", name)
+		return result
+	}
+
+	if result.BaseRef, result.Err = baseRefFor(repo, from); result.Err != nil {
+		return result
+	}
+	if result.Err = git.CreateBranch(repo.Path, name, result.BaseRef); result.Err != nil {
+		return result
+	}
+
+	if branchSwitch {
+		if result.Err = git.SwitchToBranch(repo.Path, repo.Remote, name); result.Err != nil {
+			return result
+		}
+		result.Switched = true
+	}
+	if branchPush {
+		if result.Err = git.PushBranch(repo.Path, repo.Remote, name); result.Err != nil {
+			return result
+		}
+		result.Pushed = true
+	}
+	return result
+}
+
+// baseRefFor returns the ref to branch off base from: the remote-tracking branch,
+// so new branches start at the latest published state, or else the local branch
+func baseRefFor(repo config.Repository, base string) (string, error) {
+	if remoteExists, _ := git.CheckRemoteBranchExists(repo.Path, repo.Remote, base); remoteExists {
+		return repo.Remote + "/" + base, nil
+	}
+	if localExists, _ := git.CheckBranchExists(repo.Path, base); localExists {
+		return base, nil
+	}
+	return "", fmt.Errorf("base branch '%s' not found", base)
+}
+
+// renameResult holds the result of renaming a branch in a single repository
+type renameResult struct {
+	RepoName      string
+	Skipped       bool   // the repository has no branch with the old name
+	Upstream      string // upstream of the renamed branch
+	Pushed        bool
+	RemoteDeleted bool // the old branch was deleted on the remote
+	Err           error
+}
+
+// runBranchRenameCmd is the main function for the branch rename command
+func runBranchRenameCmd(cmd *cobra.Command, args []string) {
+	oldName, newName := args[0], args[1]
+	_, repositories := loadRepositories()
+
+	log.PrintOperation(fmt.Sprintf("Renaming branch %s to %s", oldName, newName))
+	log.PrintInfo("")
+
+	results := make([]renameResult, len(repositories))
+	opts, progress := progressOptions("Renaming", repositories)
+	errs := engine.ForEachRepository(repositories, opts, func(i int, r config.Repository) error {
+		results[i] = renameBranch(r, r.MapBranch(oldName), r.MapBranch(newName))
+		return results[i].Err
+	})
+	progress.Stop()
+
+	renamed := 0
+	for i, result := range results {
+		switch {
+		case errs[i] == engine.ErrSkipped:
+			log.PrintWarning(fmt.Sprintf("%-30s [SKIPPED: earlier failure]", repositories[i].Name()))
+		case result.Err != nil:
+			log.PrintErrorNoExit("", fmt.Sprintf("%-30s %v", result.RepoName, strings.TrimSpace(result.Err.Error())), nil)
+		case result.Skipped:
+			log.PrintInfo(fmt.Sprintf("%-30s no branch %s", result.RepoName, repositories[i].MapBranch(oldName)))
+		default:
+			renamed++
+			line := fmt.Sprintf("%-30s %s → %s", result.RepoName, repositories[i].MapBranch(oldName), repositories[i].MapBranch(newName))
+			if result.Upstream != "" {
+				line += fmt.Sprintf(" (tracking %s)", result.Upstream)
+			}
+			if result.Pushed {
+				line += " [PUSHED]"
+			}
+			if result.RemoteDeleted {
+				line += " [OLD REMOTE BRANCH DELETED]"
+			}
+			log.PrintSuccess(line)
+		}
+	}
+
+	log.PrintInfo("")
+	log.PrintInfo(fmt.Sprintf("Renamed in %d repositories", renamed))
+	reportFailures("Rename", errs)
+}
+
+// renameBranch renames a branch in a repository and updates its upstream,
+// pushing the new name and deleting the old one on the remote with --push
+func renameBranch(repo config.Repository, oldName string, newName string) renameResult {
+	result := renameResult{RepoName: repo.Name()}
+
+	exists, err := git.CheckBranchExists(repo.Path, oldName)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if !exists {
+		result.Skipped = true
+		return result
+	}
+
+	if result.Err = git.RenameBranch(repo.Path, oldName, newName); result.Err != nil {
+		return result
+	}
+	result.Upstream = git.GetBranchUpstream(repo.Path, newName)
+
+	if !branchPush {
+		// Track the new name on the remote if someone already pushed it
+		if result.Upstream != "" {
+			if exists, err := git.CheckRemoteBranchExists(repo.Path, repo.Remote, newName); err == nil && exists {
+				result.Err = git.SetBranchUpstream(repo.Path, newName, repo.Remote+"/"+newName)
+				result.Upstream = repo.Remote + "/" + newName
+			}
+		}
+		return result
+	}
+
+	if result.Err = git.PushBranch(repo.Path, repo.Remote, newName); result.Err != nil {
+		return result
+	}
+	result.Pushed = true
+	result.Upstream = repo.Remote + "/" + newName
+
+	exists, err = git.CheckRemoteBranchExists(repo.Path, repo.Remote, oldName)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if exists {
+		result.Err = git.DeleteRemoteBranch(repo.Path, repo.Remote, oldName)
+		result.RemoteDeleted = result.Err == nil
+	}
+	return result
+}
+
+// remoteBranchState tells where a branch to delete on the remote still exists
+type remoteBranchState struct {
+	OnRemote bool // the remote has the branch
+	Tracking bool // the repository has a remote-tracking branch for it
+}
+
+// runBranchDeleteRemoteCmd is the main function for the branch delete-remote command
+func runBranchDeleteRemoteCmd(cmd *cobra.Command, args []string) {
+	name := args[0]
+	_, repositories := loadRepositories()
+
+	log.PrintOperation(fmt.Sprintf("Looking for remote branch %s", name))
+	log.PrintInfo("")
+
+	states := make([]remoteBranchState, len(repositories))
+	errs := engine.ForEachRepository(repositories, engine.ParallelOptions{Jobs: jobs}, func(i int, r config.Repository) error {
+		branch := r.MapBranch(name)
+		var err error
+		if states[i].OnRemote, err = git.RemoteHasBranch(r.Path, r.Remote, branch); err != nil {
+			return err
+		}
+		states[i].Tracking, err = git.CheckRemoteBranchExists(r.Path, r.Remote, branch)
+		return err
+	})
+
+	var toDelete []config.Repository
+	var toDeleteStates []remoteBranchState
+	remoteCount := 0
+	for i, repo := range repositories {
+		ref := repo.Remote + "/" + repo.MapBranch(name)
+		switch {
+		case errs[i] != nil:
+			log.PrintErrorNoExit("", fmt.Sprintf("%-30s %v", repo.Name(), strings.TrimSpace(errs[i].Error())), nil)
+		case states[i].OnRemote:
+			log.PrintWarning(fmt.Sprintf("%-30s %s will be deleted", repo.Name(), ref))
+			remoteCount++
+		case states[i].Tracking:
+			log.PrintInfo(fmt.Sprintf("%-30s %s is gone on the remote, the stale remote-tracking branch will be pruned", repo.Name(), ref))
+		default:
+			log.PrintInfo(fmt.Sprintf("%-30s no branch %s", repo.Name(), ref))
+			continue
+		}
+		if errs[i] == nil {
+			toDelete = append(toDelete, repo)
+			toDeleteStates = append(toDeleteStates, states[i])
+		}
+	}
+	log.PrintInfo("")
+
+	if len(toDelete) == 0 {
+		reportPreviewFailures("Remote branch lookup", errs)
+		log.PrintSuccess(fmt.Sprintf("No remote has branch %s", name))
+		return
+	}
+	if branchDryRun {
+		log.PrintInfo(fmt.Sprintf("Would delete %s on %d remotes (dry-run)", name, remoteCount))
+		reportPreviewFailures("Remote branch lookup", errs)
+		return
+	}
+
+	if !branchYes {
+		details := make([]string, len(toDelete))
+		for i, repo := range toDelete {
+			ref := repo.Remote + "/" + repo.MapBranch(name)
+			details[i] = "delete " + ref
+			if !toDeleteStates[i].OnRemote {
+				details[i] = "prune stale " + ref
+			}
+		}
+		toDelete, toDeleteStates = deselectRepositories("Repositories to delete the branch in:", toDelete, details, toDeleteStates)
+		if len(toDelete) == 0 {
+			log.PrintInfo("Nothing was deleted")
+			return
+		}
+		remoteCount = 0
+		for _, state := range toDeleteStates {
+			if state.OnRemote {
+				remoteCount++
+			}
+		}
+	}
+	if !branchYes && remoteCount > 0 {
+		confirmed, err := log.Confirm(fmt.Sprintf("Delete %s on %d remotes?", name, remoteCount))
+		if err != nil {
+			log.PrintError(log.ErrPromptRequired, "Pass --yes to delete without confirmation", err)
+		}
+		if !confirmed {
+			log.PrintInfo("Nothing was deleted")
+			return
+		}
+	}
+
+	deleteErrs := engine.ForEachRepository(toDelete, parallelOptions(), func(i int, r config.Repository) error {
+		branch := r.MapBranch(name)
+		if toDeleteStates[i].OnRemote {
+			return git.DeleteRemoteBranch(r.Path, r.Remote, branch)
+		}
+		return git.DeleteRemoteTrackingBranch(r.Path, r.Remote, branch)
+	})
+	for i, err := range deleteErrs {
+		ref := toDelete[i].Remote + "/" + toDelete[i].MapBranch(name)
+		switch {
+		case err == engine.ErrSkipped:
+			log.PrintWarning(fmt.Sprintf("%-30s [SKIPPED: earlier failure]", toDelete[i].Name()))
+		case err != nil:
+			log.PrintErrorNoExit("", fmt.Sprintf("%-30s %v", toDelete[i].Name(), strings.TrimSpace(err.Error())), nil)
+		case toDeleteStates[i].OnRemote:
+			log.PrintSuccess(fmt.Sprintf("%-30s deleted %s", toDelete[i].Name(), ref))
+		default:
+			log.PrintSuccess(fmt.Sprintf("%-30s pruned %s", toDelete[i].Name(), ref))
+		}
+	}
+
+	log.PrintInfo("")
+	reportFailures("Delete remote branch", append(deleteErrs, errs...))
+}
+
+// describePrunePlan summarizes the branches planned for a repository, keeping
+// the deletions on the remote apart
+func describePrunePlan(repo config.Repository, plan prunePlan) string {
+	if len(plan.Remote) == 0 {
+		return fmt.Sprintf("%d local to delete", len(plan.Local))
+	}
+	return fmt.Sprintf("%d local and %d on %s to delete", len(plan.Local), len(plan.Remote), repo.Remote)
+}
+
+// describePruneCounts describes how many local and remote branches are deleted
+func describePruneCounts(local, remote int) string {
+	if remote == 0 {
+		return fmt.Sprintf("%d local branches", local)
+	}
+	return fmt.Sprintf("%d local branches and %d branches on the remote", local, remote)
+}
+
+// planPrune finds the branches of a repository that are merged into the given
+// branch and not protected. Branches pointing at the same commit as the given
+// branch have no commits of their own yet and are kept. Remote branches are only
+// deleted if the branch also exists locally or was named with --remote-branch.
+func planPrune(repo config.Repository, into string, protected []string) (prunePlan, error) {
+	var plan prunePlan
+
+	ref, ok, err := git.ResolveBranch(repo.Path, repo.Remote, into)
+	if err != nil {
+		return plan, err
+	}
+	if !ok {
+		return plan, fmt.Errorf("branch %s not found", into)
+	}
+
+	current, err := git.GetCurrentBranch(repo.Path)
+	if err != nil {
+		return plan, err
+	}
+
+	remote := ""
+	if branchRemote {
+		remote = repo.Remote
+	}
+	if len(branchRemoteBranches) > 0 {
+		remote = repo.Remote
+	}
+	local, remoteBranches, err := git.ListMergedBranches(repo.Path, ref, remote)
+	if err != nil {
+		return plan, err
+	}
+	localAtBase, remoteAtBase, err := git.ListBranchesAt(repo.Path, ref, remote)
+	if err != nil {
+		return plan, err
+	}
+	localAtBaseSet := make(map[string]bool)
+	for _, branch := range localAtBase {
+		localAtBaseSet[branch] = true
+	}
+	remoteAtBaseSet := make(map[string]bool)
+	for _, branch := range remoteAtBase {
+		remoteAtBaseSet[branch] = true
+	}
+
+	// A remote branch is shared with everyone else, so it is only deleted if
+	// the user has it locally or asked for it by name
+	deletable := make(map[string]bool)
+	if branchRemote {
+		allLocal, _, err := git.ListBranches(repo.Path, repo.Remote)
+		if err != nil {
+			return plan, err
+		}
+		for _, branch := range allLocal {
+			deletable[branch] = true
+		}
+	}
+	for _, branch := range branchRemoteBranches {
+		deletable[repo.MapBranch(branch)] = true
+	}
+
+	keep := func(branch string) bool {
+		return branch == into || branch == current || isProtectedBranch(branch, protected)
+	}
+	for _, branch := range local {
+		if !keep(branch) && !localAtBaseSet[branch] {
+			plan.Local = append(plan.Local, branch)
+		}
+	}
+	for _, branch := range remoteBranches {
+		if !keep(branch) && !remoteAtBaseSet[branch] && deletable[branch] {
+			plan.Remote = append(plan.Remote, branch)
+		}
+	}
+	return plan, nil
+}
+
+// prune deletes the branches planned for a repository
+func prune(repo config.Repository, plan prunePlan) error {
+	var failed []string
+	for _, branch := range plan.Local {
+		// The branch was verified to be merged into the fallback branch, which
+		// may not be its upstream or HEAD, so git branch -d could refuse it
+		if err := git.DeleteBranch(repo.Path, branch, true); err != nil {
+			failed = append(failed, strings.TrimSpace(err.Error()))
+		}
+	}
+	for _, branch := range plan.Remote {
+		if err := git.DeleteRemoteBranch(repo.Path, repo.Remote, branch); err != nil {
+			failed = append(failed, strings.TrimSpace(err.Error()))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%s", strings.Join(failed, "\n"))
+	}
+	return nil
+}
+
+// isProtectedBranch reports whether a branch matches one of the protected
+// patterns, e.g. "main" or "release/*"
+func isProtectedBranch(branch string, protected []string) bool {
+	for _, pattern := range protected {
+		if matched, _ := path.Match(pattern, branch); matched {
+			return true
+		}
+	}
+	return false
+}
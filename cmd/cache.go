@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"git_cli_tool/config"
+	"git_cli_tool/git"
+)
+
+// defaultCacheTTL is how long cached branch lists are reused unless cache.ttl is set
+const defaultCacheTTL = 2 * time.Minute
+
+var (
+	noCache  bool              // --no-cache or cache.disabled: run every query
+	cacheTTL = defaultCacheTTL // how long branch lists are reused
+)
+
+// repoCache holds the results of read-only queries of one repository, valid
+// while HEAD is at the recorded commit
+type repoCache struct {
+	Head        string    `json:"head"`
+	Local       []string  `json:"local,omitempty"`       // local branch names
+	Remote      []string  `json:"remote,omitempty"`      // remote-tracking branch names, without the remote
+	BranchesAt  time.Time `json:"branches_at,omitempty"` // when Local and Remote were collected
+	UpstreamSHA string    `json:"upstream_sha,omitempty"`
+	Ahead       int       `json:"ahead"` // commits of HEAD not on the upstream at UpstreamSHA
+	Behind      int       `json:"behind"`
+}
+
+// configureCache applies the cache section of the configuration
+func configureCache(configObj *config.Configuration) {
+	if configObj.Cache.Disabled {
+		noCache = true
+	}
+	if configObj.Cache.TTL > 0 {
+		cacheTTL = configObj.Cache.TTL
+	}
+}
+
+// repoCachePath returns the cache file of a repository, or an empty string
+// when there is no user cache directory or the cache is bypassed
+func repoCachePath(repoPath string) string {
+	if noCache {
+		return ""
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	absPath, err := filepath.Abs(repoPath)
+	if err != nil {
+		return ""
+	}
+	sum := sha1.Sum([]byte(absPath))
+	return filepath.Join(cacheDir, "git_cli_tool", "repos", hex.EncodeToString(sum[:8])+".json")
+}
+
+// readRepoCache returns the cached queries of a repository if they were
+// collected at the given HEAD
+func readRepoCache(repoPath string, head string) (repoCache, bool) {
+	cachePath := repoCachePath(repoPath)
+	if cachePath == "" || head == "" {
+		return repoCache{}, false
+	}
+	data, err := os.ReadFile(cachePath)
+	if err != nil {
+		return repoCache{}, false
+	}
+	var cache repoCache
+	if json.Unmarshal(data, &cache) != nil || cache.Head != head {
+		return repoCache{}, false
+	}
+	return cache, true
+}
+
+// writeRepoCache stores the cached queries of a repository; failures only
+// mean the queries run again next time
+func writeRepoCache(repoPath string, cache repoCache) {
+	cachePath := repoCachePath(repoPath)
+	if cachePath == "" || cache.Head == "" {
+		return
+	}
+	if data, err := json.Marshal(cache); err == nil {
+		os.MkdirAll(filepath.Dir(cachePath), 0755)
+		os.WriteFile(cachePath, data, 0644)
+	}
+}
+
+// workingTreeStatus is git.GetWorkingTreeStatus with the ahead/behind counts
+// taken from the cache while HEAD and the upstream have not moved
+func workingTreeStatus(repoPath string) (git.WorkingTreeStatus, error) {
+	if noCache {
+		return git.GetWorkingTreeStatus(repoPath)
+	}
+	status, err := git.GetWorkingTreeStatusWithoutCounts(repoPath)
+	if err != nil || !status.CountsUnknown {
+		return status, err
+	}
+
+	upstreamSHA, err := git.ResolveCommit(repoPath, status.Upstream)
+	if err != nil {
+		return git.GetWorkingTreeStatus(repoPath)
+	}
+	cache, _ := readRepoCache(repoPath, status.Head)
+	if cache.Head == status.Head && cache.UpstreamSHA == upstreamSHA {
+		status.Ahead, status.Behind, status.CountsUnknown = cache.Ahead, cache.Behind, false
+		return status, nil
+	}
+
+	status.Ahead, status.Behind, err = git.CountAheadBehind(repoPath, upstreamSHA, status.Head)
+	if err != nil {
+		return git.GetWorkingTreeStatus(repoPath)
+	}
+	status.CountsUnknown = false
+	cache.Head, cache.UpstreamSHA, cache.Ahead, cache.Behind = status.Head, upstreamSHA, status.Ahead, status.Behind
+	writeRepoCache(repoPath, cache)
+	return status, nil
+}
+
+// listBranches is git.ListBranches, reusing the branch lists of the cache for
+// cacheTTL while HEAD has not moved
+func listBranches(repo config.Repository) ([]string, []string, error) {
+	if noCache {
+		return git.ListBranches(repo.Path, repo.Remote)
+	}
+	head, err := git.GetHeadCommit(repo.Path)
+	if err != nil {
+		return git.ListBranches(repo.Path, repo.Remote)
+	}
+	cache, ok := readRepoCache(repo.Path, head)
+	if ok && time.Since(cache.BranchesAt) < cacheTTL {
+		return cache.Local, cache.Remote, nil
+	}
+
+	local, remote, err := git.ListBranches(repo.Path, repo.Remote)
+	if err != nil {
+		return local, remote, err
+	}
+	cache.Head, cache.Local, cache.Remote, cache.BranchesAt = head, local, remote, time.Now()
+	writeRepoCache(repo.Path, cache)
+	return local, remote, nil
+}
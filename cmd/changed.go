@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"git_cli_tool/config"
+	"git_cli_tool/engine"
+	"git_cli_tool/git"
+	"git_cli_tool/log"
+
+	"github.com/spf13/cobra"
+)
+
+// changedCmd represents the changed command
+var changedCmd = &cobra.Command{
+	Use:   "changed",
+	Short: "List repositories with commits or uncommitted changes since a ref",
+	Long: `List the repositories that have commits on HEAD which are not in the
+given ref, or uncommitted changes. CI pipelines can use this to build and
+test only the affected repositories.
+
+With --output json, csv or markdown, the changed repositories are printed as a
+report, followed by those that could not be checked with their error. --json
+is a deprecated alias for --output json.
+
+Example:
+  git_cli_tool changed --since origin/main
+  git_cli_tool changed --since v1.4.0 --names-only
+  git_cli_tool changed --since origin/main --output json`,
+	Args: cobra.NoArgs,
+	Run:  runChangedCmd,
+}
+
+var (
+	changedSince     string
+	changedNamesOnly bool
+	changedJSON      bool
+)
+
+// initChangedCmd initializes the changed command with its flags
+func initChangedCmd() {
+	changedCmd.Flags().StringVar(&changedSince, "since", "", "Ref to compare against, e.g. origin/main or a tag (required)")
+	changedCmd.Flags().BoolVar(&changedNamesOnly, "names-only", false, "Only print the names of changed repositories, one per line")
+	changedCmd.Flags().BoolVar(&changedJSON, "json", false, "Print the changed repositories as JSON")
+	changedCmd.Flags().MarkDeprecated("json", "use --output json instead")
+	addOutputFlag(changedCmd)
+	changedCmd.MarkFlagRequired("since")
+}
+
+// ChangedRepo describes how a repository changed since the ref
+type ChangedRepo struct {
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	Commits   int    `json:"commits"`              // commits on HEAD not in the ref
+	Dirty     bool   `json:"dirty"`                // uncommitted changes in the working tree
+	Error     string `json:"error,omitempty"`      // the repository could not be checked
+	ErrorCode string `json:"error_code,omitempty"` // code from log/errors.go, set with Error
+}
+
+// runChangedCmd is the main function for the changed command
+func runChangedCmd(cmd *cobra.Command, args []string) {
+	if changedJSON {
+		if cmd.Flags().Changed("output") && outputFormat != outputJSON {
+			log.PrintError(log.ErrInvalidArgument, "--json and --output "+outputFormat+" cannot be combined", nil)
+		}
+		outputFormat = outputJSON
+	}
+	report := beginReport()
+	if changedNamesOnly && report {
+		log.PrintError(log.ErrInvalidArgument, "--names-only and --output "+outputFormat+" cannot be combined", nil)
+	}
+
+	_, repositories := loadRepositories()
+
+	// Inspect repositories in parallel; results stay in configuration order
+	results := make([]ChangedRepo, len(repositories))
+	errs := engine.ForEachRepository(repositories, parallelOptions(), func(i int, r config.Repository) error {
+		result := ChangedRepo{Name: r.Name(), Path: r.Path}
+
+		commits, err := git.CountCommitsSince(r.Path, changedSince)
+		if err != nil {
+			return err
+		}
+		result.Commits = commits
+
+		status, err := git.GetWorkingTreeStatus(r.Path)
+		if err != nil {
+			return err
+		}
+		result.Dirty = status.HasChanges()
+
+		results[i] = result
+		return nil
+	})
+
+	changed := []ChangedRepo{}
+	var failed []ChangedRepo
+	for i, repo := range repositories {
+		if errs[i] != nil {
+			if errs[i] != engine.ErrSkipped {
+				log.PrintErrorNoExit(log.ErrOperationFailed, fmt.Sprintf("Error checking %s", repo.Name()), errs[i])
+				failed = append(failed, ChangedRepo{Name: repo.Name(), Path: repo.Path, Error: strings.TrimSpace(errs[i].Error()), ErrorCode: errorCode(errs[i])})
+			}
+			continue
+		}
+		if results[i].Commits > 0 || results[i].Dirty {
+			changed = append(changed, results[i])
+		}
+	}
+
+	switch {
+	case report:
+		// Repositories that could not be checked are listed too, with their error
+		records := append(changed, failed...)
+		headers, rows := changedTable(records)
+		printReport(headers, rows, records)
+	case changedNamesOnly:
+		for _, repo := range changed {
+			log.PrintOutput(repo.Name)
+		}
+	default:
+		log.PrintOperation(fmt.Sprintf("Repositories changed since %s", changedSince))
+		log.PrintInfo("")
+		for _, repo := range changed {
+			details := fmt.Sprintf("%d commits", repo.Commits)
+			if repo.Dirty {
+				details += ", uncommitted changes"
+			}
+			log.PrintWarning(fmt.Sprintf("%-30s %s", repo.Name, details))
+		}
+		log.PrintInfo("")
+		log.PrintInfo(fmt.Sprintf("%d of %d repositories changed", len(changed), len(repositories)))
+	}
+
+	// Errors must not go unnoticed in CI, where an incomplete list would skip builds
+	for _, err := range errs {
+		if err != nil {
+			reportFailures("Change detection", errs)
+			break
+		}
+	}
+}
+
+// changedTable returns the headers and rows of the changed report
+func changedTable(repos []ChangedRepo) ([]string, [][]string) {
+	headers := []string{"REPOSITORY", "PATH", "COMMITS", "UNCOMMITTED", "ERROR"}
+	var rows [][]string
+	for _, repo := range repos {
+		if repo.Error != "" {
+			rows = append(rows, []string{repo.Name, repo.Path, "", "", strings.TrimSpace(repo.ErrorCode + " " + repo.Error)})
+			continue
+		}
+		rows = append(rows, []string{repo.Name, repo.Path, strconv.Itoa(repo.Commits), strconv.FormatBool(repo.Dirty), ""})
+	}
+	return headers, rows
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"git_cli_tool/config"
+	"git_cli_tool/engine"
+	"git_cli_tool/git"
+	"git_cli_tool/log"
+
+	"github.com/spf13/cobra"
+)
+
+// checkoutTagCmd represents the checkout-tag command
+var checkoutTagCmd = &cobra.Command{
+	Use:   "checkout-tag <tag>",
+	Short: "Check out a tag in all repositories to reproduce a release",
+	Long: `Check out a tag with a detached HEAD in every repository that has it.
+Tags missing locally are fetched from the remote first. Repositories
+without the tag are reported and make the command fail.
+
+With --nearest, repositories without the tag check out the nearest earlier
+tag instead: the highest tag with the same prefix (e.g. "v") that sorts
+before it in version order.
+
+The previous branches are recorded in the branch history (with
+record_history), so 'git_cli_tool revert' switches back.
+
+Example:
+  git_cli_tool checkout-tag v2.3.0
+  git_cli_tool checkout-tag v2.3.0 --nearest`,
+	Args: cobra.ExactArgs(1),
+	Run:  runCheckoutTagCmd,
+}
+
+var checkoutTagNearest bool
+
+// initCheckoutTagCmd initializes the checkout-tag command with its flags
+func initCheckoutTagCmd() {
+	checkoutTagCmd.Flags().BoolVar(&checkoutTagNearest, "nearest", false, "Check out the nearest earlier tag in repositories without the tag")
+}
+
+// CheckoutTagResult holds the result of checking out a tag in a single repository
+type CheckoutTagResult struct {
+	RepoName string
+	Tag      string // tag that was checked out
+	Nearest  bool   // Tag is the nearest earlier tag, the requested one is missing
+	Missing  bool   // neither the tag nor an earlier one exists
+	Switch   git.SwitchResult
+	Err      error
+}
+
+// runCheckoutTagCmd is the main function for the checkout-tag command
+func runCheckoutTagCmd(cmd *cobra.Command, args []string) {
+	tag := args[0]
+	configObj, repositories := loadRepositories()
+
+	if configObj.RecordHistory {
+		if _, history, err := config.ReadHistory(); err == nil || os.IsNotExist(err) {
+			if state, err := collectCurrentState(repositories); err == nil {
+				state.Description = "before checkout-tag " + tag
+				if err := config.SaveStateToHistory(state, history); err == nil {
+					log.PrintSuccess("Current branch state saved to history")
+				}
+			}
+		}
+	}
+
+	log.PrintOperation("Checking out tag " + tag)
+	log.PrintInfo("")
+
+	results := make([]CheckoutTagResult, len(repositories))
+	opts, progress := progressOptions("Checking out", repositories)
+	errs := engine.ForEachRepository(repositories, opts, func(i int, r config.Repository) error {
+		results[i] = checkoutTag(r, tag)
+		return results[i].Err
+	})
+	progress.Stop()
+
+	atTag, atNearest, failCount := 0, 0, 0
+	for i, result := range results {
+		switch {
+		case errs[i] == engine.ErrSkipped:
+			failCount++
+			log.PrintWarning(fmt.Sprintf("%-30s [SKIPPED: earlier failure]", repositories[i].Name()))
+		case result.Missing:
+			failCount++
+			log.PrintWarning(fmt.Sprintf("%-30s [MISSING TAG %s]", result.RepoName, tag))
+		case result.Err != nil:
+			failCount++
+			log.PrintErrorNoExit("", fmt.Sprintf("%-30s %v", result.RepoName, strings.TrimSpace(result.Err.Error())), nil)
+		case result.Nearest:
+			atNearest++
+			log.PrintWarning(fmt.Sprintf("%-30s %s → %s (nearest to %s)", result.RepoName, result.Switch.FromBranch, result.Tag, tag))
+		default:
+			atTag++
+			log.PrintSuccess(fmt.Sprintf("%-30s %s → %s", result.RepoName, result.Switch.FromBranch, result.Tag))
+		}
+	}
+
+	log.PrintInfo("")
+	if failCount == 0 && atNearest == 0 {
+		log.PrintSuccess(fmt.Sprintf("All %d repositories at %s!", atTag, tag))
+		return
+	}
+	log.PrintWarning(fmt.Sprintf("%d at %s, %d at an earlier tag, %d missing or failed", atTag, tag, atNearest, failCount))
+	if failCount > 0 {
+		os.Exit(1)
+	}
+}
+
+// checkoutTag checks out a tag, or with --nearest the nearest earlier tag, in a repository
+func checkoutTag(repo config.Repository, tag string) CheckoutTagResult {
+	result := CheckoutTagResult{RepoName: repo.Name(), Tag: tag}
+
+	if !git.TagExists(repo.Path, tag) {
+		if result.Err = git.FetchTags(repo.Path, repo.Remote); result.Err != nil {
+			return result
+		}
+	}
+	if !git.TagExists(repo.Path, tag) {
+		if !checkoutTagNearest {
+			result.Missing = true
+			result.Err = fmt.Errorf("tag %s not found", tag)
+			return result
+		}
+		nearest, err := git.FindNearestEarlierTag(repo.Path, tag)
+		if err != nil {
+			result.Err = err
+			return result
+		}
+		if nearest == "" {
+			result.Missing = true
+			result.Err = fmt.Errorf("neither tag %s nor an earlier one found", tag)
+			return result
+		}
+		result.Tag = nearest
+		result.Nearest = true
+	}
+
+	result.Switch = engine.DetachRepository(repo, result.Tag, "", git.StashOptions{})
+	if !result.Switch.Success {
+		result.Err = result.Switch.Err
+		if result.Err == nil {
+			result.Err = fmt.Errorf("%s", result.Switch.Message)
+		}
+	} else if result.Switch.HookErr != nil {
+		result.Err = result.Switch.HookErr
+	}
+	return result
+}
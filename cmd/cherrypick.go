@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"git_cli_tool/config"
+	"git_cli_tool/engine"
+	"git_cli_tool/git"
+	"git_cli_tool/log"
+
+	"github.com/spf13/cobra"
+)
+
+// cherryPickCmd represents the cherry-pick command
+var cherryPickCmd = &cobra.Command{
+	Use:   "cherry-pick [<repo>:<sha>...]",
+	Short: "Cherry-pick commits onto the current branch across repositories",
+	Long: `Cherry-pick commits onto the current branch of repositories.
+
+Commits can be given explicitly as <repo>:<sha>, where <repo> is a repository
+name or path pattern as accepted by --only. Alternatively, use --grep with
+--from to pick every commit of a branch whose message matches a pattern, in
+each repository where such commits exist. Commits already present on the
+current branch are left out.
+
+Conflicts are left in place for manual resolution.
+
+Example:
+  git_cli_tool cherry-pick api-service:3f2a9c1 web-client:91bd0e4
+  git_cli_tool cherry-pick --grep "PROJ-123" --from develop -x`,
+	Run: runCherryPickCmd,
+}
+
+var (
+	cherryPickGrep   string
+	cherryPickFrom   string
+	cherryPickOrigin bool
+)
+
+// initCherryPickCmd initializes the cherry-pick command with its flags
+func initCherryPickCmd() {
+	cherryPickCmd.Flags().StringVar(&cherryPickGrep, "grep", "", "Pick commits whose message matches this regular expression (requires --from)")
+	cherryPickCmd.Flags().StringVar(&cherryPickFrom, "from", "", "Branch to pick matching commits from")
+	cherryPickCmd.Flags().BoolVarP(&cherryPickOrigin, "record-origin", "x", false, "Append \"(cherry picked from commit ...)\" to the commit messages")
+}
+
+// CherryPickResult holds the result of cherry-picking in a single repository
+type CherryPickResult struct {
+	RepoName string
+	Commits  int
+	Success  bool
+	Message  string
+}
+
+// runCherryPickCmd is the main function for the cherry-pick command
+func runCherryPickCmd(cmd *cobra.Command, args []string) {
+	byMessage := cherryPickGrep != "" || cherryPickFrom != ""
+	if byMessage && len(args) > 0 {
+		log.PrintError(log.ErrInvalidArgument, "Give either <repo>:<sha> arguments or --grep/--from, not both", nil)
+	}
+	if byMessage && (cherryPickGrep == "" || cherryPickFrom == "") {
+		log.PrintError(log.ErrInvalidArgument, "--grep and --from must be used together", nil)
+	}
+	if !byMessage && len(args) == 0 {
+		log.PrintError(log.ErrInvalidArgument, "Nothing to cherry-pick: give <repo>:<sha> arguments or --grep with --from", nil)
+	}
+
+	_, repositories := loadRepositories()
+
+	// Explicit commits are only applied to the repositories they were given for
+	var commitsFor map[string][]string
+	if !byMessage {
+		commitsFor = parseRepoCommits(repositories, args)
+		var targets []config.Repository
+		for _, repo := range repositories {
+			if len(commitsFor[repo.Path]) > 0 {
+				targets = append(targets, repo)
+			}
+		}
+		repositories = targets
+		log.PrintOperation("Cherry-picking commits onto the current branches")
+	} else {
+		log.PrintOperation(fmt.Sprintf("Cherry-picking commits matching '%s' from %s", cherryPickGrep, cherryPickFrom))
+	}
+	log.PrintInfo("")
+
+	results := make([]CherryPickResult, len(repositories))
+	errs := engine.ForEachRepository(repositories, parallelOptions(), func(i int, r config.Repository) error {
+		if byMessage {
+			results[i] = cherryPickMatching(r, r.MapBranch(cherryPickFrom), cherryPickGrep)
+		} else {
+			results[i] = cherryPickCommits(r, commitsFor[r.Path])
+		}
+		if !results[i].Success {
+			return errors.New(results[i].Message)
+		}
+		return nil
+	})
+
+	// Print summary
+	log.PrintInfo("=== Cherry-pick Summary ===")
+	successCount := 0
+	failCount := 0
+	for i, result := range results {
+		if errs[i] == engine.ErrSkipped {
+			result = CherryPickResult{RepoName: repositories[i].Name(), Message: "skipped after an earlier failure"}
+		}
+		if result.Success {
+			successCount++
+			log.PrintSuccess(fmt.Sprintf("%-30s %s", result.RepoName, result.Message))
+		} else {
+			failCount++
+			log.PrintErrorNoExit(log.ErrOperationFailed, fmt.Sprintf("%-30s %s", result.RepoName, result.Message), nil)
+		}
+	}
+
+	log.PrintInfo("")
+	if failCount == 0 {
+		log.PrintSuccess(fmt.Sprintf("All %d repositories processed successfully!", successCount))
+	} else {
+		log.PrintWarning(fmt.Sprintf("%d succeeded, %d failed", successCount, failCount))
+		os.Exit(1)
+	}
+}
+
+// parseRepoCommits maps each repository to the commits given for it as <repo>:<sha>.
+// Exits if an argument is malformed or does not match any repository.
+func parseRepoCommits(repositories []config.Repository, args []string) map[string][]string {
+	commitsFor := make(map[string][]string)
+	for _, arg := range args {
+		separator := strings.LastIndex(arg, ":")
+		if separator <= 0 || separator == len(arg)-1 {
+			log.PrintError(log.ErrInvalidArgument, fmt.Sprintf("Invalid argument '%s', expected <repo>:<sha>", arg), nil)
+		}
+		pattern, sha := arg[:separator], arg[separator+1:]
+
+		found := false
+		for _, repo := range repositories {
+			if repo.Matches(pattern) {
+				commitsFor[repo.Path] = append(commitsFor[repo.Path], sha)
+				found = true
+			}
+		}
+		if !found {
+			log.PrintError(log.ErrRepoNotFound, fmt.Sprintf("No selected repository matches '%s'", pattern), nil)
+		}
+	}
+	return commitsFor
+}
+
+// cherryPickCommits applies the given commits onto the current branch of a repository
+func cherryPickCommits(repo config.Repository, commits []string) CherryPickResult {
+	result := CherryPickResult{RepoName: repo.Name(), Commits: len(commits)}
+
+	if err := git.CherryPick(repo.Path, commits, cherryPickOrigin); err != nil {
+		result.Message = cherryPickFailure(err)
+		return result
+	}
+
+	result.Success = true
+	result.Message = fmt.Sprintf("picked %d commits", len(commits))
+	return result
+}
+
+// cherryPickMatching applies the commits of a branch whose message matches a pattern
+// onto the current branch. Repositories without the branch or matching commits are left alone.
+func cherryPickMatching(repo config.Repository, branch string, pattern string) CherryPickResult {
+	result := CherryPickResult{RepoName: repo.Name()}
+
+	source, ok, err := git.ResolveBranch(repo.Path, repo.Remote, branch)
+	if err != nil {
+		result.Message = err.Error()
+		return result
+	}
+	if !ok {
+		result.Success = true
+		result.Message = fmt.Sprintf("branch '%s' not found, nothing to pick", branch)
+		return result
+	}
+
+	commits, err := git.FindCommitsToPick(repo.Path, "HEAD", source, pattern)
+	if err != nil {
+		result.Message = err.Error()
+		return result
+	}
+	if len(commits) == 0 {
+		result.Success = true
+		result.Message = "no matching commits"
+		return result
+	}
+
+	return cherryPickCommits(repo, commits)
+}
+
+// cherryPickFailure describes why a cherry-pick failed
+func cherryPickFailure(err error) string {
+	if errors.Is(err, git.ErrCherryPickConflict) {
+		return "CONFLICT - resolve manually, then run git cherry-pick --continue"
+	}
+	return strings.TrimSpace(err.Error())
+}
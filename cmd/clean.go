@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"git_cli_tool/config"
+	"git_cli_tool/engine"
+	"git_cli_tool/git"
+	"git_cli_tool/log"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultCleanFlags removes untracked directories and ignored files, like git clean -fdx
+var defaultCleanFlags = []string{"-d", "-x"}
+
+// cleanCmd represents the clean command
+var cleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Remove untracked files, such as build artifacts, from all repositories",
+	Long: `Run "git clean" in every repository, by default with -d -x, which removes
+untracked files and directories including ignored ones (git clean -fdx).
+
+First every file and directory that would be deleted is listed per
+repository. Nothing is deleted until the deletion is confirmed, either
+interactively or with --yes. Use --dry-run to only show the preview.
+Before confirming, repositories can be left out in a checklist.
+
+Example config:
+  clean:
+    flags: ["-d", "-X"]   # only remove ignored files
+
+Example:
+  git_cli_tool clean
+  git_cli_tool clean --yes
+  git_cli_tool clean --flags=-d,-e,.env --dry-run`,
+	Args: cobra.NoArgs,
+	Run:  runCleanCmd,
+}
+
+var (
+	cleanYes    bool
+	cleanDryRun bool
+	cleanFlags  []string
+)
+
+// initCleanCmd initializes the clean command with its flags
+func initCleanCmd() {
+	cleanCmd.Flags().BoolVarP(&cleanYes, "yes", "y", false, "Delete without asking for confirmation")
+	cleanCmd.Flags().BoolVar(&cleanDryRun, "dry-run", false, "Only show what would be deleted")
+	cleanCmd.Flags().StringSliceVar(&cleanFlags, "flags", nil, "git clean flags (default from clean.flags, or -d,-x)")
+}
+
+// runCleanCmd is the main function for the clean command
+func runCleanCmd(cmd *cobra.Command, args []string) {
+	configObj, repositories := loadRepositories()
+
+	flags := cleanFlags
+	if len(flags) == 0 {
+		flags = configObj.Clean.Flags
+	}
+	if len(flags) == 0 {
+		flags = defaultCleanFlags
+	}
+	for _, flag := range flags {
+		switch flag {
+		case "-n", "--dry-run", "-i", "--interactive":
+			log.PrintError(log.ErrInvalidArgument, fmt.Sprintf("git clean flag %s is not supported, use --dry-run or the confirmation prompt", flag), nil)
+		}
+	}
+
+	log.PrintOperation(fmt.Sprintf("Looking for files to clean (git clean %s)", strings.Join(flags, " ")))
+	log.PrintInfo("")
+
+	// Preview every repository; nothing is deleted before the whole preview is shown
+	previews := make([][]string, len(repositories))
+	errs := engine.ForEachRepository(repositories, engine.ParallelOptions{Jobs: jobs}, func(i int, r config.Repository) error {
+		var err error
+		previews[i], err = git.CleanPreview(r.Path, flags)
+		return err
+	})
+
+	var toClean []config.Repository
+	var toCleanPaths [][]string
+	pathCount := 0
+	for i, repo := range repositories {
+		switch {
+		case errs[i] != nil:
+			log.PrintErrorNoExit("", fmt.Sprintf("%-30s %v", repo.Name(), errs[i]), nil)
+		case len(previews[i]) == 0:
+			log.PrintInfo(fmt.Sprintf("%-30s nothing to clean", repo.Name()))
+		default:
+			log.PrintWarning(fmt.Sprintf("%-30s %d to delete:", repo.Name(), len(previews[i])))
+			for _, path := range previews[i] {
+				log.PrintOutput("    " + path)
+			}
+			toClean = append(toClean, repo)
+			toCleanPaths = append(toCleanPaths, previews[i])
+			pathCount += len(previews[i])
+		}
+	}
+	log.PrintInfo("")
+
+	if len(toClean) == 0 {
+		reportPreviewFailures("Clean preview", errs)
+		log.PrintSuccess("Nothing to clean")
+		return
+	}
+	if cleanDryRun {
+		log.PrintInfo(fmt.Sprintf("Would delete %d files and directories in %d repositories (dry-run)", pathCount, len(toClean)))
+		reportPreviewFailures("Clean preview", errs)
+		return
+	}
+
+	if !cleanYes {
+		details := make([]string, len(toClean))
+		for i, paths := range toCleanPaths {
+			details[i] = fmt.Sprintf("%d to delete", len(paths))
+		}
+		toClean, toCleanPaths = deselectRepositories("Repositories to clean:", toClean, details, toCleanPaths)
+		if len(toClean) == 0 {
+			log.PrintInfo("Nothing was deleted")
+			return
+		}
+		pathCount = 0
+		for _, paths := range toCleanPaths {
+			pathCount += len(paths)
+		}
+
+		confirmed, err := log.Confirm(fmt.Sprintf("Delete %d files and directories in %d repositories?", pathCount, len(toClean)))
+		if err != nil {
+			log.PrintError(log.ErrPromptRequired, "Pass --yes to delete without confirmation", err)
+		}
+		if !confirmed {
+			log.PrintInfo("Nothing was deleted")
+			return
+		}
+	}
+
+	// Untracked files that are not ignored are kept in the snapshot; ignored ones are not
+	autoSnapshot(configObj, toClean, "clean", "before clean", engine.CaptureUntracked, nil)
+
+	cleanErrs := engine.ForEachRepository(toClean, parallelOptions(), func(i int, r config.Repository) error {
+		return git.Clean(r.Path, flags)
+	})
+	for i, err := range cleanErrs {
+		if err != nil && err != engine.ErrSkipped {
+			log.PrintErrorNoExit("", fmt.Sprintf("%-30s %v", toClean[i].Name(), err), nil)
+		}
+	}
+
+	reportFailures("Clean", append(cleanErrs, errs...))
+}
@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"git_cli_tool/config"
+	"git_cli_tool/engine"
+	"git_cli_tool/git"
+	"git_cli_tool/log"
+
+	"github.com/spf13/cobra"
+)
+
+// conventionalCommitPattern matches Conventional Commits subjects such as
+// "feat(api): add login" or "fix!: drop the legacy token"
+const conventionalCommitPattern = `^(build|chore|ci|docs|feat|fix|perf|refactor|revert|style|test)(\([^)]+\))?!?: \S`
+
+// defaultCommitTemplate uses the message as given
+const defaultCommitTemplate = "{{.Message}}"
+
+// commitCmd represents the commit command
+var commitCmd = &cobra.Command{
+	Use:   "commit",
+	Short: "Commit staged changes in all repositories with the same message",
+	Long: `Commit the staged changes of every repository that has any, using the
+same message. With --all, changes to tracked files are staged first.
+Repositories without changes are skipped.
+
+The message is rendered with commit.template, a Go template with the fields
+.Message, .Ticket (the ticket ID in the branch name, following
+branch_template), .Branch and .Repo. With --verify (or commit.verify), every
+rendered message must match commit.pattern, by default the Conventional
+Commits format; if any does not, nothing is committed.
+
+Example config:
+  commit:
+    template: "{{.Message}}{{if .Ticket}}\n\nRefs: {{.Ticket}}{{end}}"
+    verify: true
+
+Example:
+  git_cli_tool commit -m "feat(auth): add login"
+  git_cli_tool commit -a -m "fix: handle empty tokens" --verify
+  git_cli_tool commit -m "chore: release 1.4" --sign`,
+	Args: cobra.NoArgs,
+	Run:  runCommitCmd,
+}
+
+var (
+	commitMessage string
+	commitAll     bool
+	commitVerify  bool
+	commitSign    bool
+)
+
+// initCommitCmd initializes the commit command with its flags
+func initCommitCmd() {
+	commitCmd.Flags().StringVarP(&commitMessage, "message", "m", "", "Commit message (required)")
+	commitCmd.Flags().BoolVarP(&commitAll, "all", "a", false, "Stage changes to tracked files before committing")
+	commitCmd.Flags().BoolVar(&commitVerify, "verify", false, "Reject messages not matching commit.pattern (default: Conventional Commits)")
+	commitCmd.Flags().BoolVarP(&commitSign, "sign", "S", false, "Sign the commits with the repositories' GPG or SSH signing key")
+	commitCmd.MarkFlagRequired("message")
+}
+
+// commitTemplateData is the data available to the commit message template
+type commitTemplateData struct {
+	Message string
+	Ticket  string // ticket ID in the branch name, empty if there is none
+	Branch  string
+	Repo    string
+}
+
+// runCommitCmd is the main function for the commit command
+func runCommitCmd(cmd *cobra.Command, args []string) {
+	configObj, repositories := loadRepositories()
+
+	text := configObj.Commit.Template
+	if text == "" {
+		text = defaultCommitTemplate
+	}
+	tmpl, err := template.New("commit").Parse(text)
+	if err != nil {
+		log.PrintError(log.ErrConfigParseFailed, "Invalid commit.template", err)
+	}
+
+	var pattern *regexp.Regexp
+	if commitVerify || (configObj.Commit.Verify && !cmd.Flags().Changed("verify")) {
+		expr := configObj.Commit.Pattern
+		if expr == "" {
+			expr = conventionalCommitPattern
+		}
+		if pattern, err = regexp.Compile(expr); err != nil {
+			log.PrintError(log.ErrConfigParseFailed, "Invalid commit.pattern", err)
+		}
+	}
+
+	// Render and verify every message before committing anything
+	messages := make([]string, len(repositories))
+	errs := engine.ForEachRepository(repositories, engine.ParallelOptions{Jobs: jobs}, func(i int, r config.Repository) error {
+		var err error
+		messages[i], err = commitMessageFor(r, configObj, tmpl, pattern)
+		return err
+	})
+
+	invalid := 0
+	for i, err := range errs {
+		if err != nil {
+			invalid++
+			log.PrintErrorNoExit("", fmt.Sprintf("%-30s %v", repositories[i].Name(), err), nil)
+		}
+	}
+	if invalid > 0 {
+		log.PrintInfo("")
+		log.PrintError(log.ErrInvalidArgument, fmt.Sprintf("%d repositories cannot be committed, nothing was committed", invalid), nil)
+	}
+
+	log.PrintOperation("Committing changes")
+	log.PrintInfo("")
+
+	opts, progress := progressOptions("Committing", repositories)
+	errs = engine.ForEachRepository(repositories, opts, func(i int, r config.Repository) error {
+		if messages[i] == "" {
+			return nil
+		}
+		return git.CommitChanges(r.Path, messages[i], commitAll, commitSign)
+	})
+	progress.Stop()
+
+	committed := 0
+	for i, repo := range repositories {
+		switch {
+		case errs[i] == engine.ErrSkipped:
+			log.PrintWarning(fmt.Sprintf("%-30s [SKIPPED: earlier failure]", repo.Name()))
+		case errs[i] != nil:
+			log.PrintErrorNoExit("", fmt.Sprintf("%-30s %v", repo.Name(), strings.TrimSpace(errs[i].Error())), nil)
+		case messages[i] == "":
+			log.PrintInfo(fmt.Sprintf("%-30s nothing to commit", repo.Name()))
+		default:
+			committed++
+			subject, _, _ := strings.Cut(messages[i], "\n")
+			log.PrintSuccess(fmt.Sprintf("%-30s %s", repo.Name(), subject))
+		}
+	}
+
+	log.PrintInfo("")
+	log.PrintInfo(fmt.Sprintf("Committed in %d repositories", committed))
+	reportFailures("Commit", errs)
+}
+
+// commitMessageFor renders the commit message of a repository and checks it
+// against pattern (if not nil). It is empty if there is nothing to commit.
+func commitMessageFor(repo config.Repository, configObj *config.Configuration, tmpl *template.Template, pattern *regexp.Regexp) (string, error) {
+	status, err := git.GetWorkingTreeStatus(repo.Path)
+	if err != nil {
+		return "", err
+	}
+	if status.Conflicts > 0 {
+		return "", fmt.Errorf("unresolved conflicts")
+	}
+	changes := status.StagedChanges
+	if commitAll {
+		changes += status.UnstagedChanges
+	}
+	if changes == 0 {
+		return "", nil
+	}
+
+	data := commitTemplateData{
+		Message: commitMessage,
+		Branch:  status.Branch,
+		Repo:    repo.Name(),
+	}
+	if status.Branch != "" {
+		data.Ticket = configObj.TicketFromBranch(repo.UnmapBranch(status.Branch))
+	}
+	var message strings.Builder
+	if err := tmpl.Execute(&message, data); err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(message.String()) == "" {
+		return "", fmt.Errorf("commit message is empty")
+	}
+	if err := verifyCommitMessage(message.String(), pattern); err != nil {
+		return "", err
+	}
+	return message.String(), nil
+}
+
+// verifyCommitMessage checks that a commit message matches pattern. A nil pattern accepts every message.
+func verifyCommitMessage(message string, pattern *regexp.Regexp) error {
+	if pattern == nil || pattern.MatchString(message) {
+		return nil
+	}
+	subject, _, _ := strings.Cut(message, "\n")
+	return fmt.Errorf("commit message %q does not match %s", subject, pattern)
+}
@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"git_cli_tool/config"
+	"git_cli_tool/engine"
+	"git_cli_tool/git"
+	"git_cli_tool/log"
+
+	"github.com/spf13/cobra"
+)
+
+// compareCmd represents the compare command
+var compareCmd = &cobra.Command{
+	Use:   "compare [branchA] [branchB]",
+	Short: "Show how far a branch is ahead/behind another in all repositories",
+	Long: `Show, per repository, how many commits branchA is ahead of and behind
+branchB, and highlight the repositories where the branches diverged. This
+shows which repositories actually need a sync.
+
+branchA defaults to the current branch of each repository and branchB to
+the fallback branch (sync.fallback_branch, or the default branch of the
+repository's remote). Local branches are
+used when they exist, otherwise the remote-tracking branches.
+
+With --output json, csv or markdown, the table is printed as a report instead.
+
+Example:
+  git_cli_tool compare
+  git_cli_tool compare feature/login
+  git_cli_tool compare feature/login develop --fetch
+  git_cli_tool compare feature/login --output markdown`,
+	Args: cobra.MaximumNArgs(2),
+	Run:  runCompareCmd,
+}
+
+var compareFetch bool
+
+// initCompareCmd initializes the compare command with its flags
+func initCompareCmd() {
+	compareCmd.Flags().BoolVar(&compareFetch, "fetch", false, "Fetch all repositories first so remote-tracking branches are current")
+	addOutputFlag(compareCmd)
+}
+
+// CompareResult holds the comparison of two branches in a single repository
+type CompareResult struct {
+	RepoName string
+	RefA     string // empty if branchA does not exist in the repository
+	RefB     string // empty if branchB does not exist in the repository
+	Ahead    int    // commits on RefA that are not on RefB
+	Behind   int    // commits on RefB that are not on RefA
+	Err      error
+}
+
+// Diverged reports whether both branches have commits the other one lacks
+func (r CompareResult) Diverged() bool {
+	return r.Ahead > 0 && r.Behind > 0
+}
+
+// compareRecord is a repository in the JSON report of the compare command
+type compareRecord struct {
+	Repository string `json:"repository"`
+	BranchA    string `json:"branch_a,omitempty"` // empty if branchA does not exist
+	BranchB    string `json:"branch_b,omitempty"` // empty if branchB does not exist
+	Ahead      int    `json:"ahead"`
+	Behind     int    `json:"behind"`
+	State      string `json:"state"`
+	Error      string `json:"error,omitempty"`
+	ErrorCode  string `json:"error_code,omitempty"` // code from log/errors.go, also set for a missing branch
+}
+
+// runCompareCmd is the main function for the compare command
+func runCompareCmd(cmd *cobra.Command, args []string) {
+	report := beginReport()
+	configObj, repositories := loadRepositories()
+
+	branchA := ""
+	if len(args) > 0 {
+		branchA = args[0]
+	}
+	// Without branchB, each repository is compared with its fallback branch
+	branchB := ""
+	if len(args) > 1 {
+		branchB = args[1]
+	}
+	describeB := branchB
+	if describeB == "" {
+		describeB = describeFallback(configObj.Sync.FallbackBranch)
+	}
+
+	if compareFetch {
+		log.PrintOperation("Fetching from remotes...")
+		errs := engine.FetchRepositories(repositories, parallelOptions())
+		for i, err := range errs {
+			if err != nil && err != engine.ErrSkipped {
+				log.PrintWarning(fmt.Sprintf("%-30s fetch failed, comparison may be stale: %v", repositories[i].Name(), err))
+			}
+		}
+	}
+
+	if branchA == "" {
+		log.PrintOperation("Comparing current branches with " + describeB)
+	} else {
+		log.PrintOperation(fmt.Sprintf("Comparing %s with %s", branchA, describeB))
+	}
+	log.PrintInfo("")
+
+	results := make([]CompareResult, len(repositories))
+	engine.ForEachRepository(repositories, engine.ParallelOptions{Jobs: jobs}, func(i int, r config.Repository) error {
+		b := branchB
+		if b == "" {
+			b = fallbackBranchFor(configObj.Sync.FallbackBranch, r)
+		}
+		results[i] = compareBranches(r, branchA, b)
+		return results[i].Err
+	})
+
+	var diverged []string
+	failCount := 0
+	rows := make([][]string, len(results))
+	records := make([]compareRecord, len(results))
+	for i, result := range results {
+		ahead, behind := "-", "-"
+		state := ""
+		switch {
+		case result.Err != nil:
+			failCount++
+			state = "error"
+		case result.RefA == "" || result.RefB == "":
+			state = "missing branch"
+		default:
+			ahead, behind = strconv.Itoa(result.Ahead), strconv.Itoa(result.Behind)
+			switch {
+			case result.Diverged():
+				diverged = append(diverged, result.RepoName)
+				state = "DIVERGED"
+			case result.Ahead > 0:
+				state = "ahead"
+			case result.Behind > 0:
+				state = "behind"
+			default:
+				state = "in sync"
+			}
+		}
+		rows[i] = []string{result.RepoName, orDash(result.RefA), orDash(result.RefB), ahead, behind, state}
+		records[i] = compareRecord{Repository: result.RepoName, BranchA: result.RefA, BranchB: result.RefB, Ahead: result.Ahead, Behind: result.Behind, State: state}
+		switch {
+		case result.Err != nil:
+			records[i].Error = result.Err.Error()
+			records[i].ErrorCode = errorCode(result.Err)
+		case result.RefA == "" || result.RefB == "":
+			records[i].ErrorCode = log.ErrGitBranchNotFound
+		}
+	}
+	headers := []string{"REPOSITORY", "BRANCH A", "BRANCH B", "AHEAD", "BEHIND", "STATE"}
+	if report {
+		printReport(headers, rows, records)
+		if failCount > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+	printTable(headers, rows)
+
+	log.PrintInfo("")
+	for _, result := range results {
+		if result.Err != nil {
+			log.PrintErrorNoExit("", fmt.Sprintf("%-30s %v", result.RepoName, result.Err), nil)
+		}
+	}
+	if len(diverged) == 0 {
+		log.PrintSuccess(fmt.Sprintf("No diverged branches in %d repositories", len(results)-failCount))
+	} else {
+		log.PrintWarning(fmt.Sprintf("%d of %d repositories diverged:", len(diverged), len(results)-failCount))
+		for _, name := range diverged {
+			log.PrintWarning("  " + name)
+		}
+	}
+	if failCount > 0 {
+		os.Exit(1)
+	}
+}
+
+// compareBranches counts the commits branchA is ahead of and behind branchB in a
+// repository. An empty branchA stands for the current branch.
+func compareBranches(repo config.Repository, branchA string, branchB string) CompareResult {
+	result := CompareResult{RepoName: repo.Name()}
+
+	if branchA == "" {
+		current, err := git.GetCurrentBranch(repo.Path)
+		if err != nil {
+			result.Err = err
+			return result
+		}
+		if current == "HEAD" {
+			result.RefA = "HEAD"
+		} else {
+			branchA = current
+		}
+	}
+	if branchA != "" {
+		ref, ok, err := git.ResolveBranch(repo.Path, repo.Remote, branchA)
+		if err != nil {
+			result.Err = err
+			return result
+		}
+		if ok {
+			result.RefA = ref
+		}
+	}
+
+	ref, ok, err := git.ResolveBranch(repo.Path, repo.Remote, branchB)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if ok {
+		result.RefB = ref
+	}
+
+	if result.RefA == "" || result.RefB == "" {
+		return result
+	}
+	result.Ahead, result.Behind, result.Err = git.CountAheadBehind(repo.Path, result.RefB, result.RefA)
+	return result
+}
+
+// orDash returns s, or "-" if s is empty
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
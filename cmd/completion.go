@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"git_cli_tool/config"
+	"git_cli_tool/engine"
+
+	"github.com/spf13/cobra"
+)
+
+// branchCache is the on-disk cache of branch names offered by completion
+type branchCache struct {
+	CreatedAt time.Time `json:"created_at"`
+	Branches  []string  `json:"branches"`
+}
+
+// initCompletion registers the dynamic completions of the global flags
+func initCompletion() {
+	rootCmd.RegisterFlagCompletionFunc("only", completeRepositories)
+	rootCmd.RegisterFlagCompletionFunc("exclude", completeRepositories)
+	rootCmd.RegisterFlagCompletionFunc("label", completeLabels)
+	rootCmd.RegisterFlagCompletionFunc("repo", completeRepositories)
+}
+
+// completeBranches offers the branch names of the configured repositories
+func completeBranches(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	configObj, err := config.ReadConfig(configFile)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	configureCache(configObj)
+
+	var completions []string
+	for _, branch := range cachedBranches(configObj) {
+		if strings.HasPrefix(branch, toComplete) {
+			completions = append(completions, branch)
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeSingleBranch is completeBranches for commands that take a single branch
+func completeSingleBranch(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeBranches(cmd, args, toComplete)
+}
+
+// completeRepositories offers the repository names for --only, --exclude and --repo.
+// The flags take comma-separated lists, so names already typed are kept as prefix.
+func completeRepositories(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	configObj, err := config.ReadConfig(configFile)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	typed := ""
+	current := toComplete
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		typed, current = toComplete[:i+1], toComplete[i+1:]
+	}
+
+	var completions []string
+	for _, repo := range configObj.AllRepositories() {
+		if name := repo.Name(); strings.HasPrefix(name, current) {
+			completions = append(completions, typed+name)
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeLabels offers the labels of the configured repositories for --label
+func completeLabels(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	configObj, err := config.ReadConfig(configFile)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	typed := ""
+	current := toComplete
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		typed, current = toComplete[:i+1], toComplete[i+1:]
+	}
+
+	seen := make(map[string]bool)
+	var completions []string
+	for _, repo := range configObj.AllRepositories() {
+		for _, label := range repo.Labels {
+			if !seen[label] && strings.HasPrefix(label, current) {
+				seen[label] = true
+				completions = append(completions, typed+label)
+			}
+		}
+	}
+	sort.Strings(completions)
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// cachedBranches returns the branch names of all selected repositories, reading
+// them from the cache when it is recent enough so completion stays fast
+func cachedBranches(configObj *config.Configuration) []string {
+	cachePath := branchCachePath()
+	if cachePath != "" {
+		if data, err := os.ReadFile(cachePath); err == nil {
+			var cache branchCache
+			if json.Unmarshal(data, &cache) == nil && time.Since(cache.CreatedAt) < cacheTTL {
+				return cache.Branches
+			}
+		}
+	}
+
+	branches := collectBranchNames(filterRepositories(configObj.FlattenRepositories()))
+
+	if cachePath != "" {
+		if data, err := json.Marshal(branchCache{CreatedAt: time.Now(), Branches: branches}); err == nil {
+			os.MkdirAll(filepath.Dir(cachePath), 0755)
+			os.WriteFile(cachePath, data, 0644)
+		}
+	}
+	return branches
+}
+
+// collectBranchNames lists the local and remote branches of the repositories in
+// parallel and returns their sorted union. Names are translated back through each
+// repository's branch map, so they can be passed to commands as-is.
+func collectBranchNames(repositories []config.Repository) []string {
+	perRepo := make([][]string, len(repositories))
+	engine.ForEachRepository(repositories, parallelOptions(), func(i int, repo config.Repository) error {
+		local, remote, err := listBranches(repo)
+		for _, branch := range append(local, remote...) {
+			perRepo[i] = append(perRepo[i], repo.UnmapBranch(branch))
+		}
+		return err
+	})
+
+	seen := make(map[string]bool)
+	var branches []string
+	for _, names := range perRepo {
+		for _, name := range names {
+			if !seen[name] {
+				seen[name] = true
+				branches = append(branches, name)
+			}
+		}
+	}
+	sort.Strings(branches)
+	return branches
+}
+
+// branchCachePath returns the cache file for the current configuration file,
+// or an empty string when there is no user cache directory or the cache is bypassed
+func branchCachePath() string {
+	if noCache {
+		return ""
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	absConfig, err := filepath.Abs(configFile)
+	if err != nil {
+		return ""
+	}
+
+	// Each configuration has its own repositories and therefore its own cache
+	sum := sha1.Sum([]byte(absConfig + "\x00" + strings.Join(onlyRepos, ",") + "\x00" + strings.Join(excludeRepos, ",") + "\x00" + repoTarget))
+	return filepath.Join(cacheDir, "git_cli_tool", "branches-"+hex.EncodeToString(sum[:8])+".json")
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"git_cli_tool/config"
+	"git_cli_tool/engine"
+	"git_cli_tool/git"
+	"git_cli_tool/log"
+
+	"github.com/spf13/cobra"
+)
+
+// conflictsCmd represents the conflicts command
+var conflictsCmd = &cobra.Command{
+	Use:   "conflicts",
+	Short: "List in-progress merges and rebases and unresolved conflicts",
+	Long: `Scan all repositories for merges, rebases, cherry-picks and reverts that
+were stopped midway and for files with unresolved conflicts, and print them
+grouped by repository, e.g. to see what is left to resolve after a sync.
+
+Repositories without anything to resolve are only counted. The command exits
+with status 1 if any repository needs attention.
+
+Example:
+  git_cli_tool conflicts`,
+	Args: cobra.NoArgs,
+	Run:  runConflictsCmd,
+}
+
+// initConflictsCmd initializes the conflicts command with its flags
+func initConflictsCmd() {
+	// No specific flags needed for conflicts command
+}
+
+// conflictReport holds what is left to resolve in a single repository
+type conflictReport struct {
+	Branch     string
+	Operation  string // merge, rebase, cherry-pick, revert or am in progress, if any
+	Conflicted []git.ConflictedFile
+}
+
+// runConflictsCmd is the main function for the conflicts command
+func runConflictsCmd(cmd *cobra.Command, args []string) {
+	_, repositories := loadRepositories()
+
+	reports := make([]conflictReport, len(repositories))
+	opts, progress := progressOptions("Scanning", repositories)
+	errs := engine.ForEachRepository(repositories, opts, func(i int, r config.Repository) error {
+		var err error
+		if reports[i].Operation, err = git.GetOperationInProgress(r.Path); err != nil {
+			return err
+		}
+		reports[i].Branch, _ = git.GetCurrentBranch(r.Path)
+		reports[i].Conflicted, err = git.ListConflictedFiles(r.Path)
+		return err
+	})
+	progress.Stop()
+
+	attention := 0
+	for i, report := range reports {
+		switch {
+		case errs[i] == engine.ErrSkipped:
+			log.PrintWarning(fmt.Sprintf("%-30s [SKIPPED: earlier failure]", repositories[i].Name()))
+			continue
+		case errs[i] != nil:
+			log.PrintErrorNoExit(errorCode(errs[i]), fmt.Sprintf("%-30s [ERROR: %v]", repositories[i].Name(), errs[i]), nil)
+			continue
+		case report.Operation == "" && len(report.Conflicted) == 0:
+			continue
+		}
+
+		attention++
+		var state []string
+		if report.Operation != "" {
+			state = append(state, strings.ToUpper(report.Operation)+" IN PROGRESS")
+		}
+		if len(report.Conflicted) > 0 {
+			state = append(state, fmt.Sprintf("%d conflicted files", len(report.Conflicted)))
+		}
+		log.PrintWarning(fmt.Sprintf("%-30s on %s: %s", repositories[i].Name(), report.Branch, strings.Join(state, ", ")))
+		for _, file := range report.Conflicted {
+			log.PrintOutput(fmt.Sprintf("    %-16s %s", file.State, file.Path))
+		}
+		if hint := conflictHint(report); hint != "" {
+			log.PrintInfo("    " + hint)
+		}
+	}
+
+	log.PrintInfo("")
+	failCount := 0
+	for _, err := range errs {
+		if err != nil {
+			failCount++
+		}
+	}
+	if attention == 0 && failCount == 0 {
+		log.PrintSuccess(fmt.Sprintf("Nothing to resolve in %d repositories", len(repositories)))
+		return
+	}
+	if attention > 0 {
+		log.PrintWarning(fmt.Sprintf("%d of %d repositories have something to resolve", attention, len(repositories)))
+	}
+	if failCount > 0 {
+		log.PrintWarning(fmt.Sprintf("%d repositories could not be scanned", failCount))
+	}
+	os.Exit(1)
+}
+
+// conflictHint tells how to finish or abort the operation in progress in a repository
+func conflictHint(report conflictReport) string {
+	switch report.Operation {
+	case git.OperationMerge:
+		if len(report.Conflicted) > 0 {
+			return "resolve, stage and commit the files, or run git merge --abort"
+		}
+		return "commit to conclude the merge, or run git merge --abort"
+	case git.OperationRebase, git.OperationCherryPick, git.OperationRevert, git.OperationAm:
+		return fmt.Sprintf("resolve and stage the files, then run git %s --continue, or git %s --abort", report.Operation, report.Operation)
+	}
+	return ""
+}
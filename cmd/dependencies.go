@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"git_cli_tool/config"
+	"git_cli_tool/engine"
+	"git_cli_tool/git"
+	"git_cli_tool/log"
+)
+
+// defaultDependencyManifests are checked for changes unless dependencies.manifests is configured
+var defaultDependencyManifests = []config.DependencyManifest{
+	{Pattern: "package-lock.json", Install: "npm ci"},
+	{Pattern: "go.sum", Install: "go mod download"},
+	{Pattern: "requirements.txt", Install: "pip install -r requirements.txt"},
+}
+
+// dependencyInstall is a changed dependency manifest and how installing its dependencies went
+type dependencyInstall struct {
+	Change engine.DependencyChange
+	Ran    bool
+	Err    error
+}
+
+// recordHeads returns the commit each repository is at, in the order of the
+// repositories, so checkDependencies can tell what a switch or pull changed.
+// Repositories without commits get an empty string.
+func recordHeads(repositories []config.Repository) []string {
+	heads := make([]string, len(repositories))
+	engine.ForEachRepository(repositories, parallelOptions(), func(i int, r config.Repository) error {
+		heads[i], _ = git.GetHeadCommit(r.Path)
+		return nil
+	})
+	return heads
+}
+
+// checkDependencies looks for dependency manifests that changed between the
+// recorded heads and the commits the repositories are at now. For each one, a
+// reminder to install the dependencies is printed, or with install, its install
+// command is run. Returns the first failed install of each repository.
+func checkDependencies(configObj *config.Configuration, repositories []config.Repository, heads []string, install bool) []error {
+	manifests := configObj.Dependencies.Manifests
+	if len(manifests) == 0 {
+		manifests = defaultDependencyManifests
+	}
+
+	installs := make([][]dependencyInstall, len(repositories))
+	errs := make([]error, len(repositories))
+	engine.ForEachRepository(repositories, parallelOptions(), func(i int, r config.Repository) error {
+		head, err := git.GetHeadCommit(r.Path)
+		if heads[i] == "" || err != nil || head == heads[i] {
+			return nil
+		}
+		changes, err := engine.DetectDependencyChanges(r, heads[i], head, manifests)
+		if err != nil {
+			return nil
+		}
+		for _, change := range changes {
+			result := dependencyInstall{Change: change}
+			if install && change.Install != "" {
+				result.Ran = true
+				result.Err = engine.InstallDependencies(r, change)
+				if errs[i] == nil {
+					errs[i] = result.Err
+				}
+			}
+			installs[i] = append(installs[i], result)
+		}
+		return nil
+	})
+
+	total := 0
+	for _, results := range installs {
+		total += len(results)
+	}
+	if total == 0 {
+		return errs
+	}
+
+	log.PrintInfo("")
+	reminders := 0
+	for i, repo := range repositories {
+		for _, result := range installs[i] {
+			change := result.Change
+			files := strings.Join(change.Files, ", ")
+			dir := path.Join(repo.Name(), change.Dir) + "/"
+			switch {
+			case change.Install == "":
+				reminders++
+				log.PrintWarning(fmt.Sprintf("%-30s %s changed, install the dependencies in %s", repo.Name(), files, dir))
+			case !result.Ran:
+				reminders++
+				log.PrintWarning(fmt.Sprintf("%-30s %s changed, run %s in %s", repo.Name(), files, change.Install, dir))
+			case result.Err != nil:
+				log.PrintErrorNoExit(log.ErrHookFailed, fmt.Sprintf("%-30s %s changed, but %s in %s failed", repo.Name(), files, change.Install, dir), result.Err)
+			default:
+				log.PrintSuccess(fmt.Sprintf("%-30s %s changed, ran %s in %s", repo.Name(), files, change.Install, dir))
+			}
+		}
+	}
+	if reminders > 0 && !install {
+		log.PrintInfo("Pass --auto-install to run the install commands right away")
+	}
+	return errs
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+
+	"git_cli_tool/config"
+	"git_cli_tool/engine"
+	"git_cli_tool/git"
+	"git_cli_tool/log"
+
+	"github.com/spf13/cobra"
+)
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff [<ref>]",
+	Short: "Show uncommitted changes, or changes against a ref, in all repositories",
+	Long: `Show per repository what a change touches. Without arguments the
+uncommitted changes (staged and unstaged) are shown; with a ref or branch
+the working tree is compared against it. Branch names go through each
+repository's branch_map.
+
+By default a diffstat is printed; use --name-only for just the file names or
+--patch for the full diff. Repositories without differences are not shown.
+
+Example:
+  git_cli_tool diff
+  git_cli_tool diff main --name-only
+  git_cli_tool diff origin/develop --patch`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runDiffCmd,
+}
+
+var (
+	diffNameOnly bool
+	diffPatch    bool
+)
+
+// initDiffCmd initializes the diff command with its flags
+func initDiffCmd() {
+	diffCmd.Flags().BoolVar(&diffNameOnly, "name-only", false, "Only show the names of changed files")
+	diffCmd.Flags().BoolVarP(&diffPatch, "patch", "p", false, "Show the full patch instead of a diffstat")
+}
+
+// runDiffCmd is the main function for the diff command
+func runDiffCmd(cmd *cobra.Command, args []string) {
+	if diffNameOnly && diffPatch {
+		log.PrintError(log.ErrInvalidArgument, "--name-only and --patch cannot be combined", nil)
+	}
+
+	_, repositories := loadRepositories()
+
+	ref := ""
+	if len(args) > 0 {
+		ref = args[0]
+	}
+
+	mode := git.DiffStat
+	if diffNameOnly {
+		mode = git.DiffNameOnly
+	} else if diffPatch {
+		mode = git.DiffPatch
+	}
+
+	// Collect diffs in parallel; they are printed in configuration order
+	diffs := make([]string, len(repositories))
+	errs := engine.ForEachRepository(repositories, parallelOptions(), func(i int, r config.Repository) error {
+		var err error
+		diffs[i], err = git.GetDiff(r.Path, r.MapBranch(ref), mode)
+		return err
+	})
+
+	changedCount := 0
+	for i, repo := range repositories {
+		repoName := repo.Name()
+
+		if errs[i] != nil {
+			if errs[i] != engine.ErrSkipped {
+				log.PrintErrorNoExit(log.ErrOperationFailed, fmt.Sprintf("Error diffing %s", repoName), errs[i])
+			}
+			continue
+		}
+		if diffs[i] == "" {
+			continue
+		}
+
+		changedCount++
+		log.PrintOperation(fmt.Sprintf("=== %s ===", repoName))
+		log.PrintOutput(diffs[i])
+		log.PrintInfo("")
+	}
+
+	against := "uncommitted changes"
+	if ref != "" {
+		against = fmt.Sprintf("changes against %s", ref)
+	}
+	log.PrintInfo(fmt.Sprintf("%d of %d repositories have %s", changedCount, len(repositories), against))
+
+	// Only report the overall result when something went wrong
+	for _, err := range errs {
+		if err != nil {
+			reportFailures("Diff", errs)
+			break
+		}
+	}
+}
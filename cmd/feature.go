@@ -0,0 +1,360 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"git_cli_tool/config"
+	"git_cli_tool/engine"
+	"git_cli_tool/git"
+	"git_cli_tool/log"
+
+	"github.com/spf13/cobra"
+)
+
+// featureCmd represents the feature command
+var featureCmd = &cobra.Command{
+	Use:   "feature",
+	Short: "Manage the lifecycle of feature branches across all repositories",
+}
+
+// featureStartCmd represents the feature start command
+var featureStartCmd = &cobra.Command{
+	Use:   "start <branch>",
+	Short: "Start a feature branch in all repositories and register its parent",
+	Long: `Start working on a feature branch. The state of the repositories is recorded
+in the branch history, then every repository is switched to the branch: an
+existing local or remote branch is checked out, otherwise the branch is created
+from the latest base branch (the sync fallback_branch or the default branch of
+the repository's remote). Uncommitted
+changes are carried over to the branch.
+
+The base branch is registered as the parent of the branch under
+sync.branch_dependencies in the configuration file, so 'git_cli_tool sync'
+merges it without further setup; the default branch of each repository needs
+no registration. Only the lines of that entry are changed;
+they are shown and written after confirmation (or with --yes). Without
+confirmation, the entry is printed to add by hand. With --push, branches without an upstream
+are pushed and track the remote branch.
+
+Example:
+  git_cli_tool feature start feature/login
+  git_cli_tool feature start feature/login --base develop --push
+  git_cli_tool feature start feature/login --yes`,
+	Args: cobra.ExactArgs(1),
+	Run:  runFeatureStartCmd,
+}
+
+// featureDoneCmd represents the feature done command
+var featureDoneCmd = &cobra.Command{
+	Use:   "done <branch>",
+	Short: "Tear down a merged feature branch in all repositories",
+	Long: `Close out a feature branch after it was merged. In every repository that has
+the branch, locally or on the remote, this switches to the base branch (the
+sync fallback_branch or the default branch of the repository's remote), pulls it, deletes the feature branch locally
+and on the remote, and drops the GitSwitch stashes of the branch.
+
+A branch that is not merged into the pulled base branch is kept with its
+stashes; pass --force to delete it anyway, e.g. after a squash merge. The state
+of the repositories is recorded in the branch history first, with the commit
+of the branch and its stashes, so 'git_cli_tool revert' switches them back,
+recreates the deleted branches and puts the dropped stashes back.
+
+Example:
+  git_cli_tool feature done feature/login
+  git_cli_tool feature done feature/login --base develop --force`,
+	Args: cobra.ExactArgs(1),
+	Run:  runFeatureDoneCmd,
+}
+
+var (
+	featureBase  string
+	featureForce bool
+	featurePush  bool
+	featureYes   bool
+)
+
+// initFeatureCmd initializes the feature command and its subcommands
+func initFeatureCmd() {
+	featureStartCmd.Flags().StringVar(&featureBase, "base", "", "Branch to create the feature branch from (default from sync.fallback_branch)")
+	featureStartCmd.Flags().BoolVar(&featurePush, "push", false, "Push branches without an upstream and set it")
+	featureStartCmd.Flags().BoolVarP(&featureYes, "yes", "y", false, "Register the parent in the configuration file without asking for confirmation")
+	featureDoneCmd.Flags().StringVar(&featureBase, "base", "", "Branch to switch to and pull (default from sync.fallback_branch)")
+	featureDoneCmd.Flags().BoolVar(&featureForce, "force", false, "Delete the branch even if it is not merged into the base branch")
+
+	featureCmd.AddCommand(featureStartCmd)
+	featureCmd.AddCommand(featureDoneCmd)
+}
+
+// featureStartResult holds the result of starting a feature branch in a single repository
+type featureStartResult struct {
+	RepoName string
+	BaseRef  string // ref the branch was created from, empty if it existed
+	Existed  bool
+	Pushed   bool
+	Err      error
+}
+
+// runFeatureStartCmd is the main function for the feature start command
+func runFeatureStartCmd(cmd *cobra.Command, args []string) {
+	branch := args[0]
+	configObj, repositories := loadRepositories()
+
+	base := featureBaseBranch(configObj)
+	for _, repo := range repositories {
+		if repo.MapBranch(branch) == fallbackBranchFor(base, repo) {
+			log.PrintError(log.ErrInvalidArgument, fmt.Sprintf("%s is the base branch of %s", repo.MapBranch(branch), repo.Name()), nil)
+		}
+	}
+
+	if _, err := captureState(repositories, "feature start", "before feature start "+branch, engine.CaptureTracked, nil); err != nil {
+		log.PrintError(log.ErrHistoryStateFailed, "Failed to record the current state, nothing was changed", err)
+	}
+	log.PrintSuccess("Current state saved to history")
+	log.PrintInfo("")
+
+	log.PrintOperation(fmt.Sprintf("Starting %s from %s", branch, describeFallback(base)))
+	log.PrintInfo("")
+
+	results := make([]featureStartResult, len(repositories))
+	opts, progress := progressOptions("Starting", repositories)
+	errs := engine.ForEachRepository(repositories, opts, func(i int, r config.Repository) error {
+		results[i] = startFeature(r, r.MapBranch(branch), fallbackBranchFor(base, r))
+		return results[i].Err
+	})
+	progress.Stop()
+
+	startedCount := 0
+	for i, result := range results {
+		switch {
+		case errs[i] == engine.ErrSkipped:
+			log.PrintWarning(fmt.Sprintf("%-30s [SKIPPED: earlier failure]", repositories[i].Name()))
+		case result.Err != nil:
+			log.PrintErrorNoExit(errorCode(result.Err), fmt.Sprintf("%-30s %v", result.RepoName, strings.TrimSpace(result.Err.Error())), nil)
+		default:
+			startedCount++
+			line := fmt.Sprintf("%-30s %s from %s", result.RepoName, repositories[i].MapBranch(branch), result.BaseRef)
+			if result.Existed {
+				line = fmt.Sprintf("%-30s %s (existing branch)", result.RepoName, repositories[i].MapBranch(branch))
+			}
+			if result.Pushed {
+				line += " [PUSHED]"
+			}
+			log.PrintSuccess(line)
+		}
+	}
+
+	// Without a parent, sync merges the default branch of each repository anyway
+	if startedCount > 0 && base != "" {
+		log.PrintInfo("")
+		registerFeatureParent(configObj, branch, base)
+	}
+
+	log.PrintInfo("")
+	reportFailures("Feature start", errs)
+}
+
+// startFeature switches a repository to a feature branch, creating it from
+// the latest base branch if it does not exist locally or on the remote, and
+// pushes it if requested
+func startFeature(repo config.Repository, name string, base string) featureStartResult {
+	result := featureStartResult{RepoName: repo.Name()}
+
+	if result.Err = git.FetchRepository(repo); result.Err != nil {
+		return result
+	}
+	_, exists, err := git.ResolveBranch(repo.Path, repo.Remote, name)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	if exists {
+		result.Existed = true
+	} else {
+		if result.BaseRef, result.Err = baseRefFor(repo, base); result.Err != nil {
+			return result
+		}
+		if result.Err = git.CreateBranch(repo.Path, name, result.BaseRef); result.Err != nil {
+			return result
+		}
+	}
+	if result.Err = git.SwitchToBranch(repo.Path, repo.Remote, name); result.Err != nil {
+		return result
+	}
+
+	if featurePush && git.GetBranchUpstream(repo.Path, name) == "" {
+		if result.Err = git.PushBranch(repo.Path, repo.Remote, name); result.Err != nil {
+			return result
+		}
+		result.Pushed = true
+	}
+	return result
+}
+
+// registerFeatureParent records base as the parent of a feature branch in the
+// configuration file. Only the lines of the entry change; they are shown and
+// written after confirmation, otherwise the entry is printed to add by hand.
+func registerFeatureParent(configObj *config.Configuration, branch string, base string) {
+	if parents := configObj.Sync.BranchDependencies[branch].Parents; len(parents) == 1 && parents[0] == base {
+		log.PrintInfo(fmt.Sprintf("%s is already registered as the parent of %s", base, branch))
+		return
+	}
+
+	edit, err := config.PlanBranchDependency(configFile, branch, base)
+	if err != nil {
+		log.PrintWarning(fmt.Sprintf("Could not register %s as the parent of %s: %v", base, branch, err))
+		printFeatureParentSnippet(branch, base)
+		return
+	}
+
+	log.PrintInfo(fmt.Sprintf("Registering %s as the parent of %s changes %s:", base, branch, configFile))
+	for _, line := range edit.Removed {
+		log.PrintOutput(strings.TrimRight("  - "+line, " "))
+	}
+	for _, line := range edit.Added {
+		log.PrintOutput(strings.TrimRight("  + "+line, " "))
+	}
+	if !featureYes {
+		confirmed, err := log.Confirm("Write the change to the configuration file?")
+		if err != nil || !confirmed {
+			log.PrintInfo("The configuration file was left unchanged")
+			printFeatureParentSnippet(branch, base)
+			return
+		}
+	}
+
+	if err := edit.Write(); err != nil {
+		log.PrintWarning(fmt.Sprintf("Could not register %s as the parent of %s: %v", base, branch, err))
+		printFeatureParentSnippet(branch, base)
+		return
+	}
+	if edit.Previous != "" {
+		log.PrintWarning(fmt.Sprintf("Registered %s as the parent of %s in %s, replacing %s", base, branch, configFile, edit.Previous))
+	} else {
+		log.PrintSuccess(fmt.Sprintf("Registered %s as the parent of %s in %s", base, branch, configFile))
+	}
+}
+
+// printFeatureParentSnippet prints the configuration that registers base as the
+// parent of a feature branch, for the user to add to the configuration file
+func printFeatureParentSnippet(branch string, base string) {
+	log.PrintInfo(fmt.Sprintf("Add this to %s to let sync merge %s into %s:", configFile, base, branch))
+	for _, line := range strings.Split(strings.TrimSuffix(config.BranchDependencySnippet(branch, base), "\n"), "\n") {
+		log.PrintOutput("  " + line)
+	}
+}
+
+// runFeatureDoneCmd is the main function for the feature done command
+func runFeatureDoneCmd(cmd *cobra.Command, args []string) {
+	branch := args[0]
+	configObj, repositories := loadRepositories()
+	base := featureBaseBranch(configObj)
+
+	// Leave the repositories that never had the branch alone
+	var involved []config.Repository
+	for _, repo := range repositories {
+		name := repo.MapBranch(branch)
+		localExists, _ := git.CheckBranchExists(repo.Path, name)
+		remoteExists, _ := git.CheckRemoteBranchExists(repo.Path, repo.Remote, name)
+		if localExists || remoteExists {
+			involved = append(involved, repo)
+		} else {
+			log.PrintInfo(fmt.Sprintf("%-30s no branch %s", repo.Name(), name))
+		}
+	}
+	if len(involved) == 0 {
+		log.PrintInfo(fmt.Sprintf("No repository has a branch %s", branch))
+		return
+	}
+
+	// The tip of the branch and its stashes are recorded too, so that revert can restore what is deleted
+	record := func(r config.Repository, state *config.RepositoryState) error {
+		engine.CaptureBranchCommit(r, state, r.MapBranch(branch))
+		return engine.CaptureBranchStashes(r, state, r.MapBranch(branch))
+	}
+	if _, err := captureState(involved, "feature done", "before feature done "+branch, engine.CaptureTracked, record); err != nil {
+		log.PrintError(log.ErrHistoryStateFailed, "Failed to record the current state, nothing was changed", err)
+	}
+	log.PrintSuccess("Current state saved to history")
+	log.PrintInfo("")
+
+	log.PrintOperation(fmt.Sprintf("Finishing %s on %s", branch, describeFallback(base)))
+	log.PrintInfo("")
+
+	results := make([]engine.FeatureDoneResult, len(involved))
+	opts, progress := progressOptions("Finishing", involved)
+	errs := engine.ForEachRepository(involved, opts, func(i int, r config.Repository) error {
+		results[i] = engine.FinishFeature(r, r.MapBranch(branch), fallbackBranchFor(base, r), featureForce)
+		return results[i].Err
+	})
+	progress.Stop()
+
+	keptCount := 0
+	for i, result := range results {
+		switch {
+		case errs[i] == engine.ErrSkipped:
+			log.PrintWarning(fmt.Sprintf("%-30s [SKIPPED: earlier failure]", involved[i].Name()))
+		case result.Err != nil:
+			log.PrintErrorNoExit(errorCode(result.Err), fmt.Sprintf("%-30s %s", result.RepoName, featureDoneSummary(result)), result.Err)
+		case result.KeptLocal || result.KeptRemote:
+			keptCount++
+			log.PrintWarning(fmt.Sprintf("%-30s %s", result.RepoName, featureDoneSummary(result)))
+		default:
+			log.PrintSuccess(fmt.Sprintf("%-30s %s", result.RepoName, featureDoneSummary(result)))
+		}
+		if result.HookErr != nil {
+			log.PrintErrorNoExit(log.ErrOperationFailed, fmt.Sprintf("%-30s post_pull hook failed", result.RepoName), result.HookErr)
+		}
+	}
+
+	log.PrintInfo("")
+	if keptCount > 0 {
+		log.PrintWarning(fmt.Sprintf("%d repositories kept unmerged branches; pass --force to delete them", keptCount))
+	}
+	reportFailures("Feature done", errs)
+}
+
+// featureBaseBranch returns the base branch of feature branches: --base or the
+// sync fallback_branch, empty for the default branch of each repository (see
+// fallbackBranchFor)
+func featureBaseBranch(configObj *config.Configuration) string {
+	if featureBase != "" {
+		return featureBase
+	}
+	return configObj.Sync.FallbackBranch
+}
+
+// featureDoneSummary describes what closing out a feature branch did in a
+// single repository, up to the step that failed
+func featureDoneSummary(result engine.FeatureDoneResult) string {
+	var steps []string
+	if result.Switched {
+		steps = append(steps, "switched to "+result.Base)
+	}
+	if result.Pulled {
+		steps = append(steps, "pulled")
+	}
+	if result.DeletedLocal != "" {
+		steps = append(steps, fmt.Sprintf("deleted %s (was %s)", result.Branch, shortSHA(result.DeletedLocal)))
+	}
+	if result.KeptLocal {
+		steps = append(steps, fmt.Sprintf("kept %s (not merged into %s)", result.Branch, result.Base))
+	}
+	if result.DeletedRemote {
+		steps = append(steps, "deleted remote branch")
+	}
+	if result.KeptRemote {
+		steps = append(steps, fmt.Sprintf("kept remote branch (not merged into %s)", result.Base))
+	}
+	if result.DroppedStashes > 0 {
+		steps = append(steps, fmt.Sprintf("dropped %d stashes", result.DroppedStashes))
+	}
+	if result.Err != nil {
+		steps = append(steps, "failed")
+	}
+	if len(steps) == 0 {
+		return "nothing to do"
+	}
+	return strings.Join(steps, ", ")
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"git_cli_tool/config"
+	"git_cli_tool/engine"
+	"git_cli_tool/git"
+	"git_cli_tool/log"
+
+	"github.com/spf13/cobra"
+)
+
+// fsckCmd represents the fsck command
+var fsckCmd = &cobra.Command{
+	Use:   "fsck",
+	Short: "Check the object stores of all repositories for corruption and missing objects",
+	Long: `Run "git fsck --no-dangling" in every repository, in parallel, and
+summarize the problems found: corruption (objects that cannot be read) and
+connectivity problems (objects that are referenced but missing). Useful after
+a disk or network drive holding the repositories had an outage.
+
+A repository whose check takes longer than --timeout is reported as timed
+out. The command exits with status 1 if any repository has problems.
+
+Example:
+  git_cli_tool fsck
+  git_cli_tool fsck --timeout 30m`,
+	Args: cobra.NoArgs,
+	Run:  runFsckCmd,
+}
+
+// maxFsckProblems is the number of problems listed per repository
+const maxFsckProblems = 10
+
+var fsckTimeout time.Duration
+
+// initFsckCmd initializes the fsck command with its flags
+func initFsckCmd() {
+	fsckCmd.Flags().DurationVar(&fsckTimeout, "timeout", 10*time.Minute, "Give up on a repository whose check takes longer (0 for no limit)")
+}
+
+// runFsckCmd is the main function for the fsck command
+func runFsckCmd(cmd *cobra.Command, args []string) {
+	_, repositories := loadRepositories()
+
+	log.PrintOperation(fmt.Sprintf("Checking %d repositories", len(repositories)))
+	log.PrintInfo("")
+
+	reports := make([]git.FsckReport, len(repositories))
+	opts, progress := progressOptions("Checking", repositories)
+	errs := engine.ForEachRepository(repositories, opts, func(i int, r config.Repository) error {
+		var err error
+		reports[i], err = git.Fsck(r.Path, fsckTimeout)
+		if err == nil && !reports[i].Healthy() {
+			err = fmt.Errorf("%d problems found", len(reports[i].Corruption)+len(reports[i].Connectivity))
+		}
+		return err
+	})
+	progress.Stop()
+
+	rows := make([][]string, len(repositories))
+	for i, report := range reports {
+		state := "ok"
+		switch {
+		case errs[i] == engine.ErrSkipped:
+			state = "skipped"
+		case errors.Is(errs[i], git.ErrFsckTimeout):
+			state = "timed out"
+		case errs[i] != nil && report.Healthy():
+			state = "failed"
+		case errs[i] != nil:
+			state = "problems"
+		}
+		rows[i] = []string{repositories[i].Name(), state, strconv.Itoa(len(report.Corruption)), strconv.Itoa(len(report.Connectivity))}
+	}
+	printTable([]string{"REPOSITORY", "FSCK", "CORRUPTION", "CONNECTIVITY"}, rows)
+
+	for i, report := range reports {
+		if report.Healthy() {
+			if errs[i] != nil && errs[i] != engine.ErrSkipped {
+				log.PrintInfo("")
+				log.PrintErrorNoExit("", fmt.Sprintf("%-30s %v", repositories[i].Name(), errs[i]), nil)
+			}
+			continue
+		}
+
+		log.PrintInfo("")
+		log.PrintWarning(repositories[i].Name() + ":")
+		problems := append(append([]string{}, report.Corruption...), report.Connectivity...)
+		for j, problem := range problems {
+			if j == maxFsckProblems {
+				log.PrintOutput(fmt.Sprintf("    ... and %d more", len(problems)-maxFsckProblems))
+				break
+			}
+			log.PrintOutput("    " + problem)
+		}
+	}
+	log.PrintInfo("")
+
+	reportFailures("Fsck", errs)
+}
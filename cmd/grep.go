@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+
+	"git_cli_tool/config"
+	"git_cli_tool/engine"
+	"git_cli_tool/git"
+	"git_cli_tool/log"
+
+	"github.com/spf13/cobra"
+)
+
+// grepCmd represents the grep command
+var grepCmd = &cobra.Command{
+	Use:   "grep <pattern> [<pathspec>...]",
+	Short: "Search tracked files in all repositories with git grep",
+	Long: `Run 'git grep' in every repository and print the matches prefixed with
+the repository name. Repositories are searched in parallel; results are
+printed in configuration order.
+
+Example:
+  git_cli_tool grep PaymentClient
+  git_cli_tool grep -i "todo" -- '*.go'
+  git_cli_tool grep -l --only api,web NewServer`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  runGrepCmd,
+}
+
+var grepOptions git.GrepOptions
+
+// initGrepCmd initializes the grep command with its flags
+func initGrepCmd() {
+	grepCmd.Flags().BoolVarP(&grepOptions.IgnoreCase, "ignore-case", "i", false, "Ignore case differences between the pattern and the files")
+	grepCmd.Flags().BoolVarP(&grepOptions.WordRegexp, "word-regexp", "w", false, "Match the pattern only at word boundaries")
+	grepCmd.Flags().BoolVarP(&grepOptions.FixedStrings, "fixed-strings", "F", false, "Treat the pattern as a literal string instead of a regular expression")
+	grepCmd.Flags().BoolVarP(&grepOptions.FilesOnly, "files-with-matches", "l", false, "Only print the names of matching files")
+}
+
+// runGrepCmd is the main function for the grep command
+func runGrepCmd(cmd *cobra.Command, args []string) {
+	_, repositories := loadRepositories()
+
+	pattern := args[0]
+	opts := grepOptions
+	opts.Pathspecs = args[1:]
+
+	// Search in parallel; matches are kept per repository in configuration order
+	matches := make([][]string, len(repositories))
+	errs := engine.ForEachRepository(repositories, parallelOptions(), func(i int, r config.Repository) error {
+		var err error
+		matches[i], err = git.GrepRepository(r.Path, pattern, opts)
+		return err
+	})
+
+	matchCount := 0
+	repoCount := 0
+	for i, repo := range repositories {
+		repoName := repo.Name()
+
+		if errs[i] != nil {
+			if errs[i] != engine.ErrSkipped {
+				log.PrintErrorNoExit(log.ErrOperationFailed, fmt.Sprintf("Error searching %s", repoName), errs[i])
+			}
+			continue
+		}
+
+		if len(matches[i]) > 0 {
+			repoCount++
+		}
+		for _, match := range matches[i] {
+			matchCount++
+			log.PrintOutput(fmt.Sprintf("%s:%s", repoName, match))
+		}
+	}
+
+	log.PrintInfo("")
+	what := "matches"
+	if opts.FilesOnly {
+		what = "matching files"
+	}
+	log.PrintInfo(fmt.Sprintf("%d %s in %d of %d repositories", matchCount, what, repoCount, len(repositories)))
+
+	// Only report the overall result when something went wrong, to keep the output greppable
+	for _, err := range errs {
+		if err != nil {
+			reportFailures("Search", errs)
+			break
+		}
+	}
+}
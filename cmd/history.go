@@ -4,8 +4,13 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"git_cli_tool/config"
+	"git_cli_tool/engine"
 	"git_cli_tool/log"
 
 	"github.com/spf13/cobra"
@@ -18,9 +23,55 @@ var historyCmd = &cobra.Command{
 	Run:   runHistoryCmd,
 }
 
-// initHistoryCmd initializes the history command with its flags
+// historyDiffCmd represents the history diff command
+var historyDiffCmd = &cobra.Command{
+	Use:   "diff [index|name]",
+	Short: "Show which repositories drifted from a recorded state",
+	Long: `Compare the current branch and HEAD of every repository with a state in
+the branch history (defaults to the most recent) and show which repositories
+have drifted: another branch is checked out, or HEAD moved away from the
+recorded commit. The commit counts are relative to the recorded commit;
+states recorded without a commit only compare the branch. For recorded
+stashes, it shows whether the stash still exists.
+
+Example:
+  git_cli_tool history diff
+  git_cli_tool history diff 3
+  git_cli_tool history diff release/1.4`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runHistoryDiffCmd,
+}
+
+// historyVerifyCmd represents the history verify command
+var historyVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Report history entries that refer to stashes or branches that no longer exist",
+	Long: `Check every entry of the branch history for what it refers to that no
+longer exists: branches deleted locally and on the remote, recorded commits,
+stashes whose commit was garbage collected, saved uncommitted changes whose
+patch file was removed, and repositories that are gone. A dropped or popped
+stash can still be restored as long as its commit exists; older entries that
+recorded only the stash name need it in the stash list. Revert cannot fully restore such
+entries. Remote branches are checked as of the last fetch.
+
+Entries that can be restored are only counted. The command exits with status 1
+if any entry cannot be fully restored.
+
+Example:
+  git_cli_tool history verify
+  git_cli_tool history verify --only api`,
+	Args: cobra.NoArgs,
+	Run:  runHistoryVerifyCmd,
+}
+
+var historyTicketFilter string
+
+// initHistoryCmd initializes the history command and its subcommands
 func initHistoryCmd() {
-	// No specific flags for history command
+	historyCmd.Flags().StringVar(&historyTicketFilter, "ticket", "", "Only list entries recorded for this ticket ID")
+
+	historyCmd.AddCommand(historyDiffCmd)
+	historyCmd.AddCommand(historyVerifyCmd)
 }
 
 // runHistoryCmd is the main function for the history command
@@ -40,27 +91,32 @@ func runHistoryCmd(cmd *cobra.Command, args []string) {
 	log.PrintInfo("--------------")
 
 	// Display history entries from newest to oldest
+	shown := 0
 	for i := len(history.States) - 1; i >= 0; i-- {
 		state := history.States[i]
 		historyIndex := len(history.States) - 1 - i // Reverse index for display
-
-		message := fmt.Sprintf("[%d] %s", historyIndex, state.Timestamp)
-		if state.Description != "" {
-			message += fmt.Sprintf(" - %s", state.Description)
+		if historyTicketFilter != "" && !strings.EqualFold(state.Ticket, historyTicketFilter) {
+			continue
 		}
-		log.PrintInfo(message)
+		shown++
+
+		log.PrintInfo(historyEntryTitle(historyIndex, state))
 
 		// Display a summary of branches in this state
 		repoCount := len(state.Repositories)
 		if repoCount > 0 {
 			summaryMsg := fmt.Sprintf("    %d repositories", repoCount)
 
-			// Count repositories with stashes
+			// Count repositories with stashes and saved uncommitted changes
 			stashCount := 0
+			patchCount := 0
 			for _, repoState := range state.Repositories {
 				if repoState.StashName != "" {
 					stashCount++
 				}
+				if repoState.Patch != "" {
+					patchCount++
+				}
 			}
 
 			if stashCount > 0 {
@@ -68,6 +124,9 @@ func runHistoryCmd(cmd *cobra.Command, args []string) {
 			} else {
 				summaryMsg += ", no stashes"
 			}
+			if patchCount > 0 {
+				summaryMsg += fmt.Sprintf(", %d with saved changes", patchCount)
+			}
 
 			log.PrintInfo(summaryMsg)
 		} else {
@@ -75,5 +134,269 @@ func runHistoryCmd(cmd *cobra.Command, args []string) {
 		}
 	}
 
-	log.PrintInfo("\nUse 'git_cli_tool revert <index>' to revert to a specific state")
+	if shown == 0 {
+		log.PrintInfo(fmt.Sprintf("No history entries for ticket %s.", historyTicketFilter))
+		return
+	}
+
+	log.PrintInfo("\nUse 'git_cli_tool revert <index>' (or the name of a named state) to revert to a specific state")
+}
+
+// historyEntryTitle describes a history entry on one line, e.g.
+// "[0] 2026-03-02T10:15:00Z <sync> - before sync feature/extension"
+func historyEntryTitle(index int, state config.BranchState) string {
+	message := fmt.Sprintf("[%d] %s", index, state.Timestamp)
+	if state.Name != "" {
+		message += fmt.Sprintf(" (%s)", state.Name)
+	}
+	if state.Ticket != "" {
+		message += fmt.Sprintf(" [%s]", state.Ticket)
+	}
+	if state.Command != "" {
+		message += fmt.Sprintf(" <%s>", state.Command)
+	}
+	if state.Description != "" {
+		message += fmt.Sprintf(" - %s", state.Description)
+	}
+	return message
+}
+
+// runHistoryDiffCmd is the main function for the history diff command
+func runHistoryDiffCmd(cmd *cobra.Command, args []string) {
+	history, err := config.LoadBranchHistory()
+	if err != nil {
+		log.PrintError(log.ErrHistoryReadFailed, "Error loading branch history", err)
+	}
+	if len(history.States) == 0 {
+		log.PrintInfo("No branch history found.")
+		return
+	}
+
+	arg := ""
+	if len(args) > 0 {
+		arg = args[0]
+	}
+	index, actualIndex := findHistoryState(history, arg)
+	state := history.States[actualIndex]
+
+	// Only compare the repositories selected by --only/--exclude/--label/--repo;
+	// the configuration provides their aliases, labels and remotes
+	repositories := engine.StateRepositories(state, loadConfig().AllRepositories(), isSelected)
+	if len(repositories) == 0 {
+		log.PrintError(log.ErrRepoNotFound, fmt.Sprintf("No repository recorded in state [%d] is selected", index), nil)
+	}
+
+	message := fmt.Sprintf("Comparing with state [%d] from %s", index, state.Timestamp)
+	if state.Name != "" {
+		message += fmt.Sprintf(" (%s)", state.Name)
+	}
+	log.PrintOperation(message)
+	log.PrintInfo("")
+
+	results := make([]engine.DriftResult, len(repositories))
+	engine.ForEachRepository(repositories, engine.ParallelOptions{Jobs: jobs}, func(i int, r config.Repository) error {
+		results[i] = engine.CompareToState(r, state.Repositories[r.Path])
+		return results[i].Err
+	})
+
+	driftCount := 0
+	failCount := 0
+	rows := make([][]string, len(results))
+	for i, result := range results {
+		recorded := result.Branch
+		if result.Commit != "" {
+			recorded += " " + shortSHA(result.Commit)
+		}
+		current := result.CurrentBranch + " " + shortSHA(result.CurrentCommit)
+
+		drift := "unchanged"
+		switch {
+		case result.Err != nil:
+			failCount++
+			current = "-"
+			drift = "error"
+		case result.Drifted():
+			driftCount++
+			drift = describeDrift(result)
+		}
+
+		stash := "-"
+		if (result.StashName != "" || result.StashCommit != "") && result.Err == nil {
+			stash = "missing"
+			if result.StashExists {
+				stash = "exists"
+			}
+		}
+		rows[i] = []string{result.RepoName, recorded, current, drift, stash}
+	}
+	printTable([]string{"REPOSITORY", "RECORDED", "CURRENT", "DRIFT", "STASH"}, rows)
+
+	log.PrintInfo("")
+	for _, result := range results {
+		if result.Err != nil {
+			log.PrintErrorNoExit("", fmt.Sprintf("%-30s %v", result.RepoName, result.Err), nil)
+		}
+	}
+	if driftCount == 0 {
+		log.PrintSuccess(fmt.Sprintf("No drift in %d repositories", len(results)-failCount))
+	} else {
+		log.PrintWarning(fmt.Sprintf("%d of %d repositories drifted", driftCount, len(results)-failCount))
+	}
+	if failCount > 0 {
+		os.Exit(1)
+	}
+}
+
+// runHistoryVerifyCmd is the main function for the history verify command
+func runHistoryVerifyCmd(cmd *cobra.Command, args []string) {
+	history, err := config.LoadBranchHistory()
+	if err != nil {
+		log.PrintError(log.ErrHistoryReadFailed, "Error loading branch history", err)
+	}
+	if len(history.States) == 0 {
+		log.PrintInfo("No branch history found.")
+		return
+	}
+
+	// Repositories recorded in any state, with the remote from the configuration
+	// if they are still configured, and selected by --only/--exclude
+	configured := make(map[string]config.Repository)
+	for _, repo := range loadConfig().AllRepositories() {
+		configured[repo.Path] = repo
+	}
+	seen := make(map[string]bool)
+	var repositories []config.Repository
+	for _, state := range history.States {
+		for repoPath := range state.Repositories {
+			if seen[repoPath] {
+				continue
+			}
+			seen[repoPath] = true
+			repo, ok := configured[repoPath]
+			if !ok {
+				repo = config.Repository{Path: repoPath}
+			}
+			if isSelected(repo) {
+				repositories = append(repositories, repo)
+			}
+		}
+	}
+	sort.Slice(repositories, func(i, j int) bool { return repositories[i].Path < repositories[j].Path })
+
+	problems := make([][][]string, len(repositories))
+	opts, progress := progressOptions("Verifying", repositories)
+	errs := engine.ForEachRepository(repositories, opts, func(i int, r config.Repository) error {
+		var err error
+		problems[i], err = engine.VerifyHistory(r, history.States)
+		return err
+	})
+	progress.Stop()
+
+	for i, err := range errs {
+		if err != nil && err != engine.ErrSkipped {
+			log.PrintErrorNoExit(errorCode(err), fmt.Sprintf("%-30s [ERROR: %v]", repositories[i].Name(), err), nil)
+		}
+	}
+
+	// Entries newest first, like the history listing
+	broken := 0
+	for actualIndex := len(history.States) - 1; actualIndex >= 0; actualIndex-- {
+		var lines []string
+		for i, repo := range repositories {
+			if errs[i] != nil {
+				continue
+			}
+			for _, problem := range problems[i][actualIndex] {
+				lines = append(lines, fmt.Sprintf("    %-30s %s", repo.Name(), problem))
+			}
+		}
+		if len(lines) == 0 {
+			continue
+		}
+
+		broken++
+		log.PrintWarning(historyEntryTitle(len(history.States)-1-actualIndex, history.States[actualIndex]))
+		for _, line := range lines {
+			log.PrintOutput(line)
+		}
+	}
+
+	log.PrintInfo("")
+	failCount := 0
+	for _, err := range errs {
+		if err != nil {
+			failCount++
+		}
+	}
+	if broken == 0 && failCount == 0 {
+		log.PrintSuccess(fmt.Sprintf("All %d history entries can be restored", len(history.States)))
+		return
+	}
+	if broken > 0 {
+		log.PrintWarning(fmt.Sprintf("%d of %d history entries cannot be fully restored", broken, len(history.States)))
+	}
+	if failCount > 0 {
+		log.PrintWarning(fmt.Sprintf("%d repositories could not be verified", failCount))
+	}
+	os.Exit(1)
+}
+
+// describeDrift summarizes how a repository moved away from its recorded state,
+// e.g. "branch changed, 3 ahead, 1 behind"
+func describeDrift(result engine.DriftResult) string {
+	var parts []string
+	if result.BranchChanged() {
+		parts = append(parts, "branch changed")
+	}
+	if result.Ahead > 0 {
+		parts = append(parts, fmt.Sprintf("%d ahead", result.Ahead))
+	}
+	if result.Behind > 0 {
+		parts = append(parts, fmt.Sprintf("%d behind", result.Behind))
+	}
+	if len(parts) == 0 {
+		return "commit changed"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// findHistoryState resolves a history index (newest first, default 0) or the name of a
+// named state, returning the displayed index and the index into history.States.
+// Exits if there is no such state.
+func findHistoryState(history *config.BranchHistory, arg string) (int, int) {
+	if arg == "" {
+		return 0, len(history.States) - 1
+	}
+
+	index, err := strconv.Atoi(arg)
+	if err != nil {
+		actualIndex := history.FindNamedState(arg)
+		if actualIndex < 0 {
+			log.PrintError(log.ErrHistoryIndexInvalid, fmt.Sprintf("No history entry with index or name '%s'", arg), nil)
+		}
+		return len(history.States) - 1 - actualIndex, actualIndex
+	}
+
+	// User sees newest first (index 0), but the array stores oldest first
+	actualIndex := len(history.States) - 1 - index
+	if actualIndex < 0 || actualIndex >= len(history.States) {
+		log.PrintErrorNoExit(log.ErrHistoryIndexInvalid, "Invalid index", nil)
+		log.PrintInfo("Valid range: 0-" + strconv.Itoa(len(history.States)-1))
+		os.Exit(1)
+	}
+	return index, actualIndex
+}
+
+// findHistoryStateAt resolves the newest history state recorded at or before a
+// point in time (see BranchHistory.StateAt), returning the displayed index and
+// the index into history.States. Exits if there is no such state.
+func findHistoryStateAt(history *config.BranchHistory, at time.Time) (int, int) {
+	if actualIndex := history.StateAt(at); actualIndex >= 0 {
+		return len(history.States) - 1 - actualIndex, actualIndex
+	}
+
+	log.PrintErrorNoExit(log.ErrHistoryIndexInvalid, fmt.Sprintf("No history entry recorded at or before %s", at.Format("2006-01-02 15:04:05")), nil)
+	log.PrintInfo("The oldest entry is from " + history.States[0].Timestamp)
+	os.Exit(1)
+	return 0, 0
 }
@@ -0,0 +1,253 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"git_cli_tool/config"
+	"git_cli_tool/engine"
+	"git_cli_tool/git"
+	"git_cli_tool/log"
+
+	"github.com/spf13/cobra"
+)
+
+// hooksCmd represents the hooks command
+var hooksCmd = &cobra.Command{
+	Use:   "hooks",
+	Short: "Keep shared git hooks consistent across repositories",
+	Long: `Install a shared set of git hook scripts (such as pre-commit, commit-msg
+and pre-push) into every repository and show where they have drifted.
+
+The scripts are read from the directory configured under git_hooks.dir.
+These are git's own hooks; commands run by git_cli_tool itself around
+switch, pull and sync are configured under hooks.
+
+Example config:
+  git_hooks:
+    dir: "H:/code_base/shared-hooks"
+    link: false`,
+}
+
+// hooksInstallCmd represents the hooks install command
+var hooksInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install the shared git hooks into every repository",
+	Long: `Copy (or, with --link, symlink) every script of the shared hooks directory
+into the hooks directory of each repository. Hooks that exist but differ from
+the shared script are left alone unless --force is given.
+
+Example:
+  git_cli_tool hooks install
+  git_cli_tool hooks install --force --link`,
+	Args: cobra.NoArgs,
+	Run:  runHooksInstallCmd,
+}
+
+// hooksStatusCmd represents the hooks status command
+var hooksStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show which repositories have missing or modified git hooks",
+	Long: `Compare the installed git hooks of every repository with the shared scripts.
+
+Example:
+  git_cli_tool hooks status`,
+	Args: cobra.NoArgs,
+	Run:  runHooksStatusCmd,
+}
+
+var (
+	hooksForce bool
+	hooksLink  bool
+)
+
+// initHooksCmd initializes the hooks command and its subcommands
+func initHooksCmd() {
+	hooksInstallCmd.Flags().BoolVar(&hooksForce, "force", false, "Replace installed hooks that differ from the shared scripts")
+	hooksInstallCmd.Flags().BoolVar(&hooksLink, "link", false, "Create symbolic links instead of copies (default from git_hooks.link)")
+
+	hooksCmd.AddCommand(hooksInstallCmd)
+	hooksCmd.AddCommand(hooksStatusCmd)
+}
+
+// HooksResult holds the hook states of a single repository
+type HooksResult struct {
+	RepoName  string
+	States    map[string]string // hook name -> state
+	Installed []string
+	Kept      []string // differing hooks not replaced without --force
+	Err       error
+}
+
+// loadSharedHooks returns the absolute shared hooks directory and its scripts, exiting on failure
+func loadSharedHooks(configObj *config.Configuration) (string, []string) {
+	if configObj.GitHooks.Dir == "" {
+		log.PrintError(log.ErrInvalidArgument, "No shared hooks directory configured (git_hooks.dir)", nil)
+	}
+
+	dir, err := filepath.Abs(configObj.GitHooks.Dir)
+	if err != nil {
+		log.PrintError(log.ErrInvalidArgument, "Invalid git_hooks.dir", err)
+	}
+
+	scripts, err := git.ListHookScripts(dir)
+	if err != nil {
+		log.PrintError(log.ErrInvalidArgument, "Failed to read shared hooks", err)
+	}
+	if len(scripts) == 0 {
+		log.PrintError(log.ErrInvalidArgument, fmt.Sprintf("No hook scripts found in %s", dir), nil)
+	}
+	return dir, scripts
+}
+
+// runHooksInstallCmd is the main function for the hooks install command
+func runHooksInstallCmd(cmd *cobra.Command, args []string) {
+	configObj, repositories := loadRepositories()
+	dir, scripts := loadSharedHooks(configObj)
+
+	link := configObj.GitHooks.Link
+	if cmd.Flags().Changed("link") {
+		link = hooksLink
+	}
+
+	log.PrintOperation(fmt.Sprintf("Installing %s from %s", strings.Join(scripts, ", "), dir))
+	log.PrintInfo("")
+
+	results := make([]HooksResult, len(repositories))
+	errs := engine.ForEachRepository(repositories, parallelOptions(), func(i int, r config.Repository) error {
+		results[i] = installHooks(r, dir, scripts, link)
+		return results[i].Err
+	})
+
+	successCount := 0
+	failCount := 0
+	for i, result := range results {
+		switch {
+		case errs[i] == engine.ErrSkipped:
+			failCount++
+			log.PrintWarning(fmt.Sprintf("%-30s [SKIPPED: earlier failure]", repositories[i].Name()))
+		case result.Err != nil:
+			failCount++
+			log.PrintErrorNoExit("", fmt.Sprintf("%-30s %v", result.RepoName, result.Err), nil)
+		case len(result.Kept) > 0:
+			failCount++
+			message := fmt.Sprintf("modified: %s (use --force to replace)", strings.Join(result.Kept, ", "))
+			if len(result.Installed) > 0 {
+				message = fmt.Sprintf("installed %s, %s", strings.Join(result.Installed, ", "), message)
+			}
+			log.PrintWarning(fmt.Sprintf("%-30s %s", result.RepoName, message))
+		case len(result.Installed) > 0:
+			successCount++
+			log.PrintSuccess(fmt.Sprintf("%-30s installed %s", result.RepoName, strings.Join(result.Installed, ", ")))
+		default:
+			successCount++
+			log.PrintSuccess(fmt.Sprintf("%-30s up to date", result.RepoName))
+		}
+	}
+
+	log.PrintInfo("")
+	if failCount == 0 {
+		log.PrintSuccess(fmt.Sprintf("Hooks installed in all %d repositories!", successCount))
+	} else {
+		log.PrintWarning(fmt.Sprintf("%d succeeded, %d failed", successCount, failCount))
+		os.Exit(1)
+	}
+}
+
+// installHooks installs the missing shared hooks of a repository, and the differing ones with --force
+func installHooks(repo config.Repository, dir string, scripts []string, link bool) HooksResult {
+	result := checkHooks(repo, dir, scripts)
+	if result.Err != nil {
+		return result
+	}
+
+	hooksDir, _ := git.GetHooksDir(repo.Path)
+	for _, script := range scripts {
+		state := result.States[script]
+		if state == git.HookInstalled {
+			continue
+		}
+		if state == git.HookDiffers && !hooksForce {
+			result.Kept = append(result.Kept, script)
+			continue
+		}
+		if err := git.InstallHook(hooksDir, filepath.Join(dir, script), link); err != nil {
+			result.Err = err
+			return result
+		}
+		result.Installed = append(result.Installed, script)
+	}
+	return result
+}
+
+// checkHooks compares the installed hooks of a repository with the shared scripts
+func checkHooks(repo config.Repository, dir string, scripts []string) HooksResult {
+	result := HooksResult{RepoName: repo.Name(), States: make(map[string]string)}
+
+	hooksDir, err := git.GetHooksDir(repo.Path)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	for _, script := range scripts {
+		state, err := git.HookState(hooksDir, filepath.Join(dir, script))
+		if err != nil {
+			result.Err = err
+			return result
+		}
+		result.States[script] = state
+	}
+	return result
+}
+
+// runHooksStatusCmd is the main function for the hooks status command
+func runHooksStatusCmd(cmd *cobra.Command, args []string) {
+	configObj, repositories := loadRepositories()
+	dir, scripts := loadSharedHooks(configObj)
+
+	results := make([]HooksResult, len(repositories))
+	errs := engine.ForEachRepository(repositories, parallelOptions(), func(i int, r config.Repository) error {
+		results[i] = checkHooks(r, dir, scripts)
+		return results[i].Err
+	})
+
+	headers := append([]string{"REPOSITORY"}, scripts...)
+	var rows [][]string
+	driftCount := 0
+	for i, result := range results {
+		row := []string{repositories[i].Name()}
+		if errs[i] != nil {
+			rows = append(rows, append(row, fmt.Sprintf("error: %v", errs[i])))
+			continue
+		}
+
+		drifted := false
+		for _, script := range scripts {
+			state := result.States[script]
+			drifted = drifted || state != git.HookInstalled
+			row = append(row, state)
+		}
+		if drifted {
+			driftCount++
+		}
+		rows = append(rows, row)
+	}
+	printTable(headers, rows)
+
+	log.PrintInfo("")
+	if driftCount == 0 {
+		log.PrintSuccess(fmt.Sprintf("Hooks are up to date in all %d repositories", len(repositories)))
+	} else {
+		log.PrintWarning(fmt.Sprintf("%d of %d repositories have missing or modified hooks (run 'hooks install')", driftCount, len(repositories)))
+	}
+
+	// Only report the overall result when something went wrong
+	for _, err := range errs {
+		if err != nil {
+			reportFailures("Hooks status", errs)
+		}
+	}
+}
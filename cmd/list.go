@@ -4,11 +4,11 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 	"strings"
+	"text/template"
 
 	"git_cli_tool/config"
-	"git_cli_tool/git"
+	"git_cli_tool/engine"
 	"git_cli_tool/log"
 
 	"github.com/spf13/cobra"
@@ -18,24 +18,50 @@ import (
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List repositories and their current branches",
-	Run:   runListCmd,
+	Long: `List repositories with their current branch, working tree state and upstream.
+
+The output can be customized with --format, a Go template executed once per
+repository. Available fields:
+  .Name .Path .Labels .Branch .Target .Upstream .Ahead .Behind
+  .Dirty .OnTarget .Detached .Skipped .Error
+
+Example:
+  git_cli_tool list
+  git_cli_tool list --format '{{.Name}}\t{{.Branch}}{{if .Dirty}}*{{end}}'`,
+	Run: runListCmd,
 }
 
+var listFormat string
+
 // initListCmd initializes the list command with its flags
 func initListCmd() {
-	// The list command already has access to the global configFile flag
+	listCmd.Flags().StringVar(&listFormat, "format", "", "Go template used to print each repository (see --help for fields)")
+}
+
+// ListEntry holds the information shown for a repository by the list command.
+// Its fields are available to --format templates.
+type ListEntry struct {
+	Name     string
+	Path     string
+	Labels   []string
+	Branch   string // "HEAD" when detached
+	Target   string // preferred branch from the configured fallback order
+	Upstream string
+	Ahead    int
+	Behind   int
+	Dirty    bool
+	OnTarget bool
+	Detached bool
+	Skipped  bool // disabled in configuration
+	Error    string
 }
 
 // runListCmd is the main function for the list command
 func runListCmd(cmd *cobra.Command, args []string) {
-	configObj, err := config.ReadConfig(configFile)
-	if err != nil {
-		log.PrintError(log.ErrConfigReadFailed, "Error reading config", err)
-		os.Exit(1)
-	}
+	configObj := loadConfig()
 
-	// Get the flattened repositories
-	repositories := configObj.FlattenRepositories()
+	// Get the selected repositories, including disabled ones so they can be shown as skipped
+	repositories := filterRepositories(configObj.AllRepositories())
 
 	// Determine configured branches (same logic as switch command)
 	configBranches := configObj.SwitchBranchesFallback
@@ -43,10 +69,31 @@ func runListCmd(cmd *cobra.Command, args []string) {
 		configBranches = configObj.Branches // backwards compatibility
 	}
 
-	// First branch in the list is the preferred one
-	preferredBranch := ""
-	if len(configBranches) > 0 {
-		preferredBranch = configBranches[0]
+	// Parse the template up front so a typo fails before any git call
+	var tmpl *template.Template
+	if listFormat != "" {
+		var err error
+		tmpl, err = template.New("list").Parse(unescapeFormat(listFormat))
+		if err != nil {
+			log.PrintError(log.ErrInvalidArgument, "Invalid --format template", err)
+		}
+	}
+
+	// Collect branch information in parallel; entries stay in configuration order
+	entries := make([]ListEntry, len(repositories))
+	engine.ForEachRepository(repositories, engine.ParallelOptions{Jobs: jobs}, func(i int, r config.Repository) error {
+		entries[i] = getListEntry(r, configBranches)
+		return nil
+	})
+
+	if tmpl != nil {
+		for _, entry := range entries {
+			if err := tmpl.Execute(os.Stdout, entry); err != nil {
+				log.PrintError(log.ErrInvalidArgument, "Failed to execute --format template", err)
+			}
+			fmt.Println()
+		}
+		return
 	}
 
 	log.PrintOperation("Repository Status")
@@ -55,37 +102,51 @@ func runListCmd(cmd *cobra.Command, args []string) {
 	matchCount := 0
 	mismatchCount := 0
 	errorCount := 0
+	skippedCount := 0
 
 	// Column widths
 	const repoWidth = 30
 	const branchWidth = 40
+	const stateWidth = 7
 
-	for _, repo := range repositories {
-		repoName := filepath.Base(repo.Path)
-		currentBranch, err := git.GetCurrentBranch(repo.Path)
-		if err != nil {
+	for _, entry := range entries {
+		repoPadded := padRight(entry.Name, repoWidth)
+
+		if entry.Skipped {
+			skippedCount++
+			log.PrintInfo(fmt.Sprintf("%s [SKIPPED]", repoPadded))
+			continue
+		}
+
+		if entry.Error != "" {
 			errorCount++
-			log.PrintErrorNoExit("", fmt.Sprintf("%s [ERROR: %s]", padRight(repoName, repoWidth), err.Error()), nil)
+			log.PrintErrorNoExit("", fmt.Sprintf("%s [ERROR: %s]", repoPadded, entry.Error), nil)
+			continue
+		}
+
+		state := "clean"
+		if entry.Dirty {
+			state = "dirty"
+		}
+		line := fmt.Sprintf("%s on %s %s %s", repoPadded, padRight(entry.Branch, branchWidth), padRight(state, stateWidth), formatUpstream(entry))
+
+		if entry.OnTarget {
+			matchCount++
+			log.PrintSuccess(line + " [ON TARGET]")
 		} else {
-			onPreferred := preferredBranch != "" && currentBranch == preferredBranch
-			repoPadded := padRight(repoName, repoWidth)
-			branchPadded := padRight(currentBranch, branchWidth)
-			if onPreferred {
-				matchCount++
-				log.PrintSuccess(fmt.Sprintf("%s on %s [ON TARGET]", repoPadded, branchPadded))
-			} else {
-				mismatchCount++
-				targetInfo := ""
-				if preferredBranch != "" {
-					targetInfo = fmt.Sprintf(" (target: %s)", preferredBranch)
-				}
-				log.PrintWarning(fmt.Sprintf("%s on %s%s", repoPadded, branchPadded, targetInfo))
+			mismatchCount++
+			if entry.Target != "" {
+				line += fmt.Sprintf(" (target: %s)", entry.Target)
 			}
+			log.PrintWarning(line)
 		}
 	}
 
 	// Print summary
 	log.PrintInfo("")
+	if skippedCount > 0 {
+		log.PrintInfo(fmt.Sprintf("%d repositories skipped (disabled in configuration)", skippedCount))
+	}
 	if errorCount > 0 {
 		log.PrintWarning(fmt.Sprintf("Summary: %d on target, %d off target, %d errors", matchCount, mismatchCount, errorCount))
 	} else if mismatchCount > 0 {
@@ -101,6 +162,66 @@ func runListCmd(cmd *cobra.Command, args []string) {
 	}
 }
 
+// getListEntry collects the list information of a single repository
+func getListEntry(repo config.Repository, configBranches []string) ListEntry {
+	entry := ListEntry{
+		Name:    repo.Name(),
+		Path:    repo.Path,
+		Labels:  repo.Labels,
+		Skipped: repo.Disabled,
+	}
+
+	// First branch in the repository's fallback order is the preferred one
+	if repoBranches := repo.BranchesFor(configBranches); len(repoBranches) > 0 {
+		entry.Target = repoBranches[0]
+	}
+
+	if repo.Disabled {
+		return entry
+	}
+
+	// Branch, upstream and dirty state all come from a single git status call
+	status, err := workingTreeStatus(repo.Path)
+	if err != nil {
+		entry.Error = strings.TrimSpace(err.Error())
+		return entry
+	}
+
+	entry.Branch = status.Branch
+	entry.Detached = status.Detached
+	if status.Detached {
+		entry.Branch = "HEAD"
+	}
+	entry.Upstream = status.Upstream
+	entry.Ahead = status.Ahead
+	entry.Behind = status.Behind
+	entry.Dirty = status.HasChanges()
+	entry.OnTarget = entry.Target != "" && entry.Branch == entry.Target
+
+	return entry
+}
+
+// formatUpstream describes the upstream of a list entry, e.g. "origin/main ↑1 ↓2"
+func formatUpstream(entry ListEntry) string {
+	if entry.Upstream == "" {
+		return "(no upstream)"
+	}
+	upstream := entry.Upstream
+	if entry.Ahead > 0 {
+		upstream += fmt.Sprintf(" ↑%d", entry.Ahead)
+	}
+	if entry.Behind > 0 {
+		upstream += fmt.Sprintf(" ↓%d", entry.Behind)
+	}
+	return upstream
+}
+
+// unescapeFormat turns the \t and \n escapes typed on the command line into
+// real tabs and newlines
+func unescapeFormat(format string) string {
+	return strings.NewReplacer(`\t`, "\t", `\n`, "\n").Replace(format)
+}
+
 // displayWidth returns the visual width of a string, accounting for wide (CJK) characters
 func displayWidth(s string) int {
 	width := 0
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"git_cli_tool/config"
+	"git_cli_tool/engine"
+	"git_cli_tool/git"
+	"git_cli_tool/log"
+
+	"github.com/spf13/cobra"
+)
+
+// logCmd represents the log command
+var logCmd = &cobra.Command{
+	Use:   "log",
+	Short: "Show commits of all repositories as a single timeline",
+	Long: `Collect the commits of every repository and print them as one
+chronologically sorted timeline, labelled with the repository name.
+Useful for standup notes and release summaries.
+
+Example:
+  git_cli_tool log --since "2 days ago"
+  git_cli_tool log --since yesterday --author me
+  git_cli_tool log --all --limit 20`,
+	Run: runLogCmd,
+}
+
+var (
+	logOptions git.LogOptions
+	logReverse bool
+)
+
+// initLogCmd initializes the log command with its flags
+func initLogCmd() {
+	logCmd.Flags().StringVar(&logOptions.Since, "since", "1 week ago", "Only show commits more recent than this date")
+	logCmd.Flags().StringVar(&logOptions.Author, "author", "", "Only show commits by this author (\"me\" for your user.email)")
+	logCmd.Flags().BoolVar(&logOptions.AllBranches, "all", false, "Include commits of all branches, not just the current one")
+	logCmd.Flags().IntVarP(&logOptions.Limit, "limit", "n", 0, "Maximum number of commits to show (0 = no limit)")
+	logCmd.Flags().BoolVar(&logReverse, "reverse", false, "Show the oldest commits first")
+}
+
+// timelineEntry is a commit labelled with the repository it belongs to
+type timelineEntry struct {
+	RepoName string
+	Commit   git.Commit
+}
+
+// runLogCmd is the main function for the log command
+func runLogCmd(cmd *cobra.Command, args []string) {
+	_, repositories := loadRepositories()
+
+	// Collect the commits of every repository in parallel
+	commits := make([][]git.Commit, len(repositories))
+	errs := engine.ForEachRepository(repositories, parallelOptions(), func(i int, r config.Repository) error {
+		var err error
+		commits[i], err = git.GetCommits(r.Path, logOptions)
+		return err
+	})
+
+	var timeline []timelineEntry
+	repoWidth := 0
+	for i, repo := range repositories {
+		repoName := repo.Name()
+		if errs[i] != nil {
+			if errs[i] != engine.ErrSkipped {
+				log.PrintErrorNoExit(log.ErrOperationFailed, fmt.Sprintf("Error reading log of %s", repoName), errs[i])
+			}
+			continue
+		}
+		for _, commit := range commits[i] {
+			timeline = append(timeline, timelineEntry{RepoName: repoName, Commit: commit})
+		}
+		if width := displayWidth(repoName); width > repoWidth {
+			repoWidth = width
+		}
+	}
+
+	// Newest first, like git log; stable so each repository keeps its own order on ties
+	sort.SliceStable(timeline, func(a, b int) bool {
+		if logReverse {
+			return timeline[a].Commit.Time.Before(timeline[b].Commit.Time)
+		}
+		return timeline[a].Commit.Time.After(timeline[b].Commit.Time)
+	})
+
+	// The per-repository limit also applies to the combined timeline
+	if logOptions.Limit > 0 && len(timeline) > logOptions.Limit {
+		if logReverse {
+			timeline = timeline[len(timeline)-logOptions.Limit:]
+		} else {
+			timeline = timeline[:logOptions.Limit]
+		}
+	}
+
+	for _, entry := range timeline {
+		log.PrintOutput(fmt.Sprintf("%s  %s  %s  %-20s %s",
+			entry.Commit.Time.Format("2006-01-02 15:04"),
+			padRight(entry.RepoName, repoWidth),
+			shortSHA(entry.Commit.SHA),
+			entry.Commit.Author,
+			entry.Commit.Subject))
+	}
+
+	log.PrintInfo("")
+	log.PrintInfo(fmt.Sprintf("%d commits in %d repositories", len(timeline), len(repositories)))
+
+	// Only report the overall result when something went wrong
+	for _, err := range errs {
+		if err != nil {
+			reportFailures("Log", errs)
+			break
+		}
+	}
+}
+
+// shortSHA abbreviates a commit SHA to the usual 7 characters
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"fmt"
+
+	"git_cli_tool/config"
+	"git_cli_tool/engine"
+	"git_cli_tool/git"
+	"git_cli_tool/log"
+
+	"github.com/spf13/cobra"
+)
+
+// maintenanceCmd represents the maintenance command
+var maintenanceCmd = &cobra.Command{
+	Use:   "maintenance",
+	Short: "Compact the object stores and prune stale remote branches of all repositories",
+	Long: `Run "git maintenance run" (or "git gc" on git versions without it) and
+"git remote prune <remote>" in every repository, in parallel. The summary
+shows the size of each object store before and after, and how much disk
+space was reclaimed in total.
+
+With --aggressive, "git gc --aggressive" is run instead. It repacks all
+objects from scratch, which takes much longer but reclaims the most space.
+Unreachable objects are only dropped after git's usual grace period
+(gc.pruneExpire, two weeks by default), so dropped autostashes and commits
+that the branch history refers to can still be restored until then.
+
+Example:
+  git_cli_tool maintenance
+  git_cli_tool maintenance --aggressive`,
+	Args: cobra.NoArgs,
+	Run:  runMaintenanceCmd,
+}
+
+var maintenanceAggressive bool
+
+// initMaintenanceCmd initializes the maintenance command with its flags
+func initMaintenanceCmd() {
+	maintenanceCmd.Flags().BoolVar(&maintenanceAggressive, "aggressive", false, "Run git gc --aggressive instead of git maintenance run")
+}
+
+// MaintenanceResult holds the result of the maintenance of a single repository
+type MaintenanceResult struct {
+	SizeBefore int64
+	SizeAfter  int64
+	Err        error
+}
+
+// runMaintenanceCmd is the main function for the maintenance command
+func runMaintenanceCmd(cmd *cobra.Command, args []string) {
+	_, repositories := loadRepositories()
+
+	if maintenanceAggressive {
+		log.PrintOperation(fmt.Sprintf("Running aggressive gc in %d repositories", len(repositories)))
+	} else {
+		log.PrintOperation(fmt.Sprintf("Running maintenance in %d repositories", len(repositories)))
+	}
+	log.PrintInfo("")
+
+	results := make([]MaintenanceResult, len(repositories))
+	opts, progress := progressOptions("Maintaining", repositories)
+	errs := engine.ForEachRepository(repositories, opts, func(i int, r config.Repository) error {
+		results[i] = maintainRepository(r)
+		return results[i].Err
+	})
+	progress.Stop()
+
+	var reclaimed int64
+	rows := make([][]string, len(repositories))
+	for i, result := range results {
+		switch {
+		case errs[i] == engine.ErrSkipped:
+			rows[i] = []string{repositories[i].Name(), "skipped", "-", "-", "-"}
+		case result.Err != nil:
+			rows[i] = []string{repositories[i].Name(), "failed", "-", "-", "-"}
+		default:
+			reclaimed += result.SizeBefore - result.SizeAfter
+			rows[i] = []string{repositories[i].Name(), "done", formatSize(result.SizeBefore), formatSize(result.SizeAfter), formatSizeChange(result.SizeBefore - result.SizeAfter)}
+		}
+	}
+	printTable([]string{"REPOSITORY", "MAINTENANCE", "BEFORE", "AFTER", "RECLAIMED"}, rows)
+
+	log.PrintInfo("")
+	for i, result := range results {
+		if result.Err != nil && errs[i] != engine.ErrSkipped {
+			log.PrintErrorNoExit("", fmt.Sprintf("%-30s %v", repositories[i].Name(), result.Err), nil)
+		}
+	}
+	log.PrintInfo(fmt.Sprintf("Reclaimed: %s", formatSizeChange(reclaimed)))
+
+	reportFailures("Maintenance", errs)
+}
+
+// maintainRepository compacts the object store of a repository and prunes its
+// remote-tracking branches, measuring the object store before and after
+func maintainRepository(repo config.Repository) MaintenanceResult {
+	var result MaintenanceResult
+	if result.SizeBefore, result.Err = git.ObjectStoreSize(repo.Path); result.Err != nil {
+		return result
+	}
+	if result.Err = git.RunMaintenance(repo.Path, maintenanceAggressive); result.Err != nil {
+		return result
+	}
+	if result.Err = git.PruneRemote(repo.Path, repo.Remote); result.Err != nil {
+		return result
+	}
+	result.SizeAfter, result.Err = git.ObjectStoreSize(repo.Path)
+	return result
+}
+
+// formatSizeChange formats the space reclaimed, which is negative when
+// repacking made the object store grow, e.g. "-4.0 KB"
+func formatSizeChange(bytes int64) string {
+	if bytes < 0 {
+		return "-" + formatSize(-bytes)
+	}
+	return formatSize(bytes)
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"git_cli_tool/config"
+	"git_cli_tool/engine"
+	"git_cli_tool/git"
+	"git_cli_tool/log"
+
+	"github.com/spf13/cobra"
+)
+
+// mergeCmd represents the merge command
+var mergeCmd = &cobra.Command{
+	Use:   "merge <branch>",
+	Short: "Merge a branch into the current branch of all repositories",
+	Long: `Merge the named branch into whatever branch each repository is on. The
+local branch is merged if it exists, otherwise the remote-tracking branch.
+Unlike sync, no branch is switched and branch_dependencies are not consulted.
+
+Repositories without the branch, or on the branch itself, are skipped.
+Conflicts are left in place for manual resolution and reported like sync does.
+
+Example:
+  git_cli_tool merge feature/shared-auth
+  git_cli_tool merge main --ff-only
+  git_cli_tool merge feature/login --squash`,
+	Args: cobra.ExactArgs(1),
+	Run:  runMergeCmd,
+
+	ValidArgsFunction: completeSingleBranch,
+}
+
+var (
+	mergeFFOnly bool
+	mergeNoFF   bool
+	mergeSquash bool
+)
+
+// initMergeCmd initializes the merge command with its flags
+func initMergeCmd() {
+	mergeCmd.Flags().BoolVar(&mergeFFOnly, "ff-only", false, "Refuse to merge unless the current branch can be fast-forwarded")
+	mergeCmd.Flags().BoolVar(&mergeNoFF, "no-ff", false, "Create a merge commit even when the current branch could be fast-forwarded")
+	mergeCmd.Flags().BoolVar(&mergeSquash, "squash", false, "Commit the changes of the branch as a single commit instead of merging it")
+}
+
+// runMergeCmd is the main function for the merge command
+func runMergeCmd(cmd *cobra.Command, args []string) {
+	branch := args[0]
+	modes := 0
+	for _, set := range []bool{mergeFFOnly, mergeNoFF, mergeSquash} {
+		if set {
+			modes++
+		}
+	}
+	if modes > 1 {
+		log.PrintError(log.ErrInvalidArgument, "--ff-only, --no-ff and --squash cannot be combined", nil)
+	}
+
+	_, repositories := loadRepositories()
+
+	log.PrintOperation(fmt.Sprintf("Merging '%s' into the current branches", branch))
+	log.PrintInfo("")
+
+	opts := git.MergeOptions{FFOnly: mergeFFOnly, NoFF: mergeNoFF, Squash: mergeSquash}
+	results := make([]engine.MergeResult, len(repositories))
+	parallel, progress := progressOptions("Merging", repositories)
+	errs := engine.ForEachRepository(repositories, parallel, func(i int, r config.Repository) error {
+		results[i] = engine.MergeRepository(r, r.MapBranch(branch), opts)
+		return results[i].Err
+	})
+	progress.Stop()
+
+	log.PrintInfo("=== Merge Summary ===")
+	mergedCount := 0
+	failCount := 0
+	for i, result := range results {
+		switch {
+		case errs[i] == engine.ErrSkipped:
+			failCount++
+			log.PrintWarning(fmt.Sprintf("%-30s [SKIPPED: earlier failure]", repositories[i].Name()))
+		case result.Err != nil:
+			failCount++
+			log.PrintErrorNoExit("", fmt.Sprintf("%-30s %s", result.RepoName, result.Message), nil)
+		case result.Skipped != "":
+			log.PrintInfo(fmt.Sprintf("%-30s %s", result.RepoName, result.Skipped))
+		case result.UpToDate:
+			log.PrintInfo(fmt.Sprintf("%-30s %s already up to date with %s", result.RepoName, result.Branch, result.Ref))
+		default:
+			mergedCount++
+			log.PrintSuccess(fmt.Sprintf("%-30s merged %s into %s", result.RepoName, result.Ref, result.Branch))
+		}
+	}
+
+	log.PrintInfo("")
+	if failCount == 0 {
+		log.PrintSuccess(fmt.Sprintf("Merged '%s' into %d repositories", branch, mergedCount))
+	} else {
+		log.PrintWarning(fmt.Sprintf("%d merged, %d failed", mergedCount, failCount))
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"git_cli_tool/config"
+	"git_cli_tool/log"
+	"git_cli_tool/notify"
+)
+
+// notifyCompletion posts a summary of a finished command to the notification
+// targets in the configuration. errs holds the error of each repository.
+func notifyCompletion(configObj *config.Configuration, command string, repositories []config.Repository, errs []error, start time.Time) {
+	names := make([]string, len(repositories))
+	for i, repo := range repositories {
+		names[i] = repo.Name()
+	}
+
+	summary := notify.NewSummary(command, names, errs, time.Since(start))
+	if !notify.ShouldSend(configObj.Notifications, summary) {
+		return
+	}
+	if err := notify.Send(configObj.Notifications, summary); err != nil {
+		log.PrintWarning("Failed to send notification: " + err.Error())
+	}
+}
+
+// notifyTicket comments on a ticket in the configured Jira server, if any
+func notifyTicket(configObj *config.Configuration, ticket string, text string) {
+	if ticket == "" || configObj.Notifications.Jira.URL == "" {
+		return
+	}
+	if err := notify.CommentOnTicket(configObj.Notifications.Jira, ticket, text); err != nil {
+		log.PrintWarning(fmt.Sprintf("Failed to comment on %s: %v", ticket, err))
+	}
+}
+
+// ticketSummary describes which repositories an operation on a branch succeeded
+// in, e.g. "git_cli_tool branch create feature/JIRA-1-x: 3 of 3 repositories (api, web, lib)"
+func ticketSummary(operation string, branch string, repositories []config.Repository, errs []error) string {
+	var names []string
+	for i, repo := range repositories {
+		if errs[i] == nil {
+			names = append(names, repo.Name())
+		}
+	}
+	text := fmt.Sprintf("git_cli_tool %s %s: %d of %d repositories", operation, branch, len(names), len(repositories))
+	if len(names) > 0 {
+		text += fmt.Sprintf(" (%s)", strings.Join(names, ", "))
+	}
+	return text
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"git_cli_tool/config"
+	"git_cli_tool/engine"
+	"git_cli_tool/git"
+	"git_cli_tool/gitexec"
+	"git_cli_tool/log"
+)
+
+// Flags controlling how multi-repository operations are run
+var (
+	failFast  bool
+	jobs      int
+	authRetry bool
+)
+
+// Command line of the running command, used to apply the configured defaults
+var (
+	commandName  string // e.g. "pull" or "branch prune"
+	jobsFromFlag bool   // --jobs was given and takes precedence over the defaults
+)
+
+// applyRunDefaults sets --jobs from the defaults section of the configuration
+// for the running command, unless it was given on the command line
+func applyRunDefaults(configObj *config.Configuration) {
+	if jobsFromFlag {
+		return
+	}
+	defaults := configObj.Defaults.For(commandName)
+	switch {
+	case defaults.Parallel != nil && !*defaults.Parallel:
+		jobs = 1
+	case defaults.Jobs > 0:
+		jobs = defaults.Jobs
+	}
+}
+
+// parallelOptions returns the worker pool options selected on the command line
+func parallelOptions() engine.ParallelOptions {
+	return engine.ParallelOptions{
+		Jobs:     jobs,
+		FailFast: failFast,
+	}
+}
+
+// progressOptions returns the worker pool options with a progress line for
+// the repositories attached. The progress must be stopped before printing results.
+func progressOptions(label string, repositories []config.Repository) (engine.ParallelOptions, *log.Progress) {
+	progress := log.StartProgress(label, len(repositories))
+	opts := parallelOptions()
+	opts.Progress = progress
+	return opts, progress
+}
+
+// reportFailures prints how an operation went based on the error of each
+// repository and exits with code 1 if any repository failed or was skipped
+func reportFailures(operation string, errs []error) {
+	failCount := 0
+	skipCount := 0
+	for _, err := range errs {
+		if err == engine.ErrSkipped {
+			skipCount++
+		} else if err != nil {
+			failCount++
+		}
+	}
+
+	if skipCount > 0 {
+		log.PrintWarning(fmt.Sprintf("%d repositories skipped after an earlier failure (--fail-fast)", skipCount))
+	}
+	printAuthHint(errs)
+
+	log.PrintOperationResult(operation, failCount == 0 && skipCount == 0)
+	if failCount > 0 || skipCount > 0 {
+		os.Exit(1)
+	}
+}
+
+// reportPreviewFailures exits with status 1 if a repository could not be
+// previewed; unlike reportFailures, nothing is printed when all succeeded
+func reportPreviewFailures(operation string, errs []error) {
+	for _, err := range errs {
+		if err != nil {
+			reportFailures(operation, errs)
+			return
+		}
+	}
+}
+
+// printAuthHint explains how to provide credentials if any repository failed
+// because the remote asked for them
+func printAuthHint(errs []error) {
+	authCount := 0
+	for _, err := range errs {
+		if errors.Is(err, git.ErrAuthFailed) {
+			authCount++
+		}
+	}
+	if authCount == 0 {
+		return
+	}
+	log.PrintErrorNoExit(log.ErrGitAuthFailed, fmt.Sprintf("%d repositories need credentials", authCount), nil)
+	log.PrintInfo("Hint: set up a credential helper or ssh-agent so git does not have to ask, or rerun with --auth-retry to enter the credentials one repository at a time")
+}
+
+// retryAuthFailures runs fn again, one repository at a time and with terminal
+// prompts enabled, for the repositories that failed because the remote asked
+// for credentials. It only does so with --auth-retry on an interactive
+// terminal; errs is updated with the new results. The output of each
+// repository is flushed right after it ran, before the next one prompts.
+func retryAuthFailures(out *log.Collector, repositories []config.Repository, errs []error, fn func(i int, r config.Repository) error) {
+	if !authRetry || !log.CanPrompt() {
+		return
+	}
+
+	gitexec.SetTerminalPrompts(true)
+	defer gitexec.SetTerminalPrompts(false)
+
+	for i, repo := range repositories {
+		if !errors.Is(errs[i], git.ErrAuthFailed) {
+			continue
+		}
+		log.PrintInfo("")
+		log.PrintOperation(fmt.Sprintf("Retrying %s with credential prompts", repo.Name()))
+		errs[i] = fn(i, repo)
+		out.Flush()
+	}
+}
@@ -0,0 +1,590 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"git_cli_tool/config"
+	"git_cli_tool/engine"
+	"git_cli_tool/forge"
+	"git_cli_tool/git"
+	"git_cli_tool/log"
+
+	"github.com/spf13/cobra"
+)
+
+// prCmd represents the pr command
+var prCmd = &cobra.Command{
+	Use:   "pr",
+	Short: "Manage pull requests (merge requests) of the current branches",
+	Long: `Open and inspect pull requests for the branch each repository is on.
+
+The forge is detected from the remote URL of each repository. gitlab.com works
+out of the box; self-hosted servers are listed in the forge section of the
+configuration. API tokens are read from token_env, token or GITLAB_TOKEN.`,
+}
+
+// prCreateCmd represents the pr create command
+var prCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Open pull requests for the current branch in all repositories",
+	Long: `Open a pull request from the current branch into the base branch in every
+repository where the branch has been pushed and has commits the base does not.
+Repositories that already have an open pull request for the branch are reported
+instead of getting a second one.
+
+Example:
+  git_cli_tool pr create --title "PROJ-123 New login flow"
+  git_cli_tool pr create --base develop --draft`,
+	Args: cobra.NoArgs,
+	Run:  runPRCreateCmd,
+}
+
+// prStatusCmd represents the pr status command
+var prStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the open pull requests of the current branch in all repositories",
+	Long: `List the open pull requests of the current branch of every repository with
+their review state and CI status, to see at a glance which repositories of a
+cross-repository change are still waiting for approval.`,
+	Args: cobra.NoArgs,
+	Run:  runPRStatusCmd,
+}
+
+// prMergeCmd represents the pr merge command
+var prMergeCmd = &cobra.Command{
+	Use:   "merge",
+	Short: "Merge the pull requests of the current branch in all repositories",
+	Long: `Merge the open pull request of the current branch of every repository
+through the forge API, once the pull requests of a cross-repository change
+are approved.
+
+All pull requests are checked first: if any of them is a draft, not approved
+or has failing CI, nothing is merged (override with --force), so the change
+never lands in only some repositories. The pull requests are listed and you
+are asked to confirm unless --yes is given.
+
+With --delete-branch, the source branches are deleted on the forge and
+locally; local branches with commits that were never pushed are kept. With
+--switch (implied by --delete-branch), every merged repository switches
+back to the target branch and fast-forwards it to the merge.
+
+Example:
+  git_cli_tool pr merge --strategy squash
+  git_cli_tool pr merge --strategy squash --delete-branch --yes`,
+	Args: cobra.NoArgs,
+	Run:  runPRMergeCmd,
+}
+
+var (
+	prBase         string
+	prTitle        string
+	prDescription  string
+	prDraft        bool
+	prStrategy     string
+	prDeleteBranch bool
+	prSwitch       bool
+	prForce        bool
+	prYes          bool
+)
+
+// initPRCmd initializes the pr command and its subcommands
+func initPRCmd() {
+	prCreateCmd.Flags().StringVar(&prBase, "base", "", "Branch to merge into (default: sync fallback_branch or the remote's default branch)")
+	prCreateCmd.Flags().StringVar(&prTitle, "title", "", "Title of the pull requests (default: the branch name)")
+	prCreateCmd.Flags().StringVar(&prDescription, "description", "", "Description of the pull requests")
+	prCreateCmd.Flags().BoolVar(&prDraft, "draft", false, "Open the pull requests as drafts")
+
+	prMergeCmd.Flags().StringVar(&prStrategy, "strategy", forge.MergeStrategyMerge, "How to merge: merge, squash or rebase")
+	prMergeCmd.Flags().StringVar(&prBase, "base", "", "Only merge pull requests into this branch")
+	prMergeCmd.Flags().BoolVar(&prDeleteBranch, "delete-branch", false, "Delete the source branches on the forge and locally after merging")
+	prMergeCmd.Flags().BoolVar(&prSwitch, "switch", false, "Switch the merged repositories back to the target branch and fast-forward it")
+	prMergeCmd.Flags().BoolVar(&prForce, "force", false, "Merge even if pull requests are drafts, not approved or have failing CI")
+	prMergeCmd.Flags().BoolVarP(&prYes, "yes", "y", false, "Merge without asking for confirmation")
+
+	prCmd.AddCommand(prCreateCmd)
+	prCmd.AddCommand(prStatusCmd)
+	prCmd.AddCommand(prMergeCmd)
+}
+
+// PRResult holds the result of a pull request operation in a single repository
+type PRResult struct {
+	RepoName string
+	Branch   string
+	Success  bool
+	Message  string
+}
+
+// runPRCreateCmd is the main function for the pr create command
+func runPRCreateCmd(cmd *cobra.Command, args []string) {
+	configObj, repositories := loadRepositories()
+
+	base := prBase
+	if base == "" {
+		base = configObj.Sync.FallbackBranch
+	}
+
+	log.PrintOperation(fmt.Sprintf("Opening pull requests into %s", describeFallback(base)))
+	log.PrintInfo("")
+
+	results := make([]PRResult, len(repositories))
+	errs := engine.ForEachRepository(repositories, parallelOptions(), func(i int, r config.Repository) error {
+		results[i] = createPullRequest(r, fallbackBranchFor(base, r), configObj.Forge)
+		if !results[i].Success {
+			return errors.New(results[i].Message)
+		}
+		return nil
+	})
+
+	printPRResults("Pull Request Summary", repositories, results, errs)
+}
+
+// createPullRequest opens a pull request from the current branch of a repository
+func createPullRequest(repo config.Repository, base string, forgeConfig config.ForgeConfig) PRResult {
+	result := PRResult{RepoName: repo.Name()}
+
+	branch, err := git.GetCurrentBranch(repo.Path)
+	if err != nil {
+		result.Message = err.Error()
+		return result
+	}
+	result.Branch = branch
+	if branch == "HEAD" {
+		result.Message = "HEAD is detached"
+		return result
+	}
+	if branch == base {
+		result.Success = true
+		result.Message = fmt.Sprintf("on %s, nothing to open", base)
+		return result
+	}
+
+	if pushed, _ := git.CheckRemoteBranchExists(repo.Path, repo.Remote, branch); !pushed {
+		result.Message = fmt.Sprintf("%s is not pushed to %s, run 'git_cli_tool push' first", branch, repo.Remote)
+		return result
+	}
+	ahead, err := git.CountCommitsSince(repo.Path, repo.Remote+"/"+base)
+	if err != nil {
+		result.Message = err.Error()
+		return result
+	}
+	if ahead == 0 {
+		result.Success = true
+		result.Message = fmt.Sprintf("no commits ahead of %s, nothing to open", base)
+		return result
+	}
+
+	provider, err := providerFor(repo, forgeConfig)
+	if err != nil {
+		result.Message = err.Error()
+		return result
+	}
+
+	// Do not open a second pull request for the same branch
+	existing, err := provider.FindPullRequests(branch)
+	if err != nil {
+		result.Message = err.Error()
+		return result
+	}
+	for _, pr := range existing {
+		if pr.TargetBranch == base {
+			result.Success = true
+			result.Message = fmt.Sprintf("already open: %s", pr.URL)
+			return result
+		}
+	}
+
+	title := prTitle
+	if title == "" {
+		title = branch
+	}
+	pr, err := provider.CreatePullRequest(forge.CreateOptions{
+		SourceBranch: branch,
+		TargetBranch: base,
+		Title:        title,
+		Description:  prDescription,
+		Draft:        prDraft,
+	})
+	if err != nil {
+		result.Message = err.Error()
+		return result
+	}
+
+	result.Success = true
+	result.Message = fmt.Sprintf("opened %s", pr.URL)
+	return result
+}
+
+// repoPullRequests holds the open pull requests of the current branch of a repository
+type repoPullRequests struct {
+	Branch       string
+	PullRequests []forge.PullRequest
+}
+
+// runPRStatusCmd is the main function for the pr status command
+func runPRStatusCmd(cmd *cobra.Command, args []string) {
+	configObj, repositories := loadRepositories()
+
+	log.PrintOperation("Open pull requests of the current branches")
+	log.PrintInfo("")
+
+	// Query the forges in parallel; results stay in configuration order
+	statuses := make([]repoPullRequests, len(repositories))
+	errs := engine.ForEachRepository(repositories, parallelOptions(), func(i int, r config.Repository) error {
+		var err error
+		statuses[i], err = pullRequestStatus(r, configObj.Forge)
+		return err
+	})
+
+	headers := []string{"REPOSITORY", "BRANCH", "PULL REQUEST", "REVIEW", "CI", "URL"}
+	var rows [][]string
+	approvedCount := 0
+	waitingCount := 0
+	missingCount := 0
+	for i, repo := range repositories {
+		repoName := repo.Name()
+		if errs[i] != nil {
+			continue
+		}
+
+		status := statuses[i]
+		if len(status.PullRequests) == 0 {
+			missingCount++
+			rows = append(rows, []string{repoName, status.Branch, "-", "-", "-", ""})
+			continue
+		}
+
+		for _, pr := range status.PullRequests {
+			if pr.Review == forge.ReviewApproved && !pr.Draft {
+				approvedCount++
+			} else {
+				waitingCount++
+			}
+			rows = append(rows, []string{
+				repoName,
+				status.Branch,
+				fmt.Sprintf("#%d → %s", pr.Number, pr.TargetBranch),
+				formatReview(pr),
+				formatCI(pr.CI),
+				pr.URL,
+			})
+		}
+	}
+
+	if len(rows) > 0 {
+		printTable(headers, rows)
+		log.PrintInfo("")
+	}
+
+	for i, repo := range repositories {
+		if errs[i] != nil && errs[i] != engine.ErrSkipped {
+			log.PrintErrorNoExit(log.ErrOperationFailed, fmt.Sprintf("%-30s %s", repo.Name(), errs[i].Error()), nil)
+		}
+	}
+
+	summary := fmt.Sprintf("%d approved, %d waiting, %d without pull request", approvedCount, waitingCount, missingCount)
+	if waitingCount > 0 || missingCount > 0 {
+		log.PrintWarning(summary)
+	} else {
+		log.PrintSuccess(summary)
+	}
+
+	for _, err := range errs {
+		if err != nil {
+			reportFailures("Pull request status", errs)
+			break
+		}
+	}
+}
+
+// pullRequestStatus looks up the open pull requests of the current branch of a
+// repository, including their review and CI status
+func pullRequestStatus(repo config.Repository, forgeConfig config.ForgeConfig) (repoPullRequests, error) {
+	var status repoPullRequests
+
+	branch, err := git.GetCurrentBranch(repo.Path)
+	if err != nil {
+		return status, err
+	}
+	status.Branch = branch
+	if branch == "HEAD" {
+		return status, fmt.Errorf("HEAD is detached")
+	}
+
+	provider, err := providerFor(repo, forgeConfig)
+	if err != nil {
+		return status, err
+	}
+	prs, err := provider.FindPullRequests(branch)
+	if err != nil {
+		return status, err
+	}
+	for i := range prs {
+		if err := provider.LoadStatus(&prs[i]); err != nil {
+			return status, err
+		}
+	}
+
+	status.PullRequests = prs
+	return status, nil
+}
+
+// prMergePlan is the pull request of the current branch of a repository that pr merge merges
+type prMergePlan struct {
+	Branch      string
+	Provider    forge.Provider
+	PullRequest *forge.PullRequest // nil if the branch has no open pull request
+	Blocker     string             // why the pull request should not be merged yet
+	Unpushed    int                // local commits on the branch that are not on the remote
+}
+
+// runPRMergeCmd is the main function for the pr merge command
+func runPRMergeCmd(cmd *cobra.Command, args []string) {
+	switch prStrategy {
+	case forge.MergeStrategyMerge, forge.MergeStrategySquash, forge.MergeStrategyRebase:
+	default:
+		log.PrintError(log.ErrInvalidArgument, fmt.Sprintf("Unknown --strategy %q, expected merge, squash or rebase", prStrategy), nil)
+	}
+	if prDeleteBranch {
+		prSwitch = true
+	}
+
+	configObj, repositories := loadRepositories()
+
+	log.PrintOperation("Checking the pull requests of the current branches")
+	log.PrintInfo("")
+
+	plans := make([]prMergePlan, len(repositories))
+	errs := engine.ForEachRepository(repositories, parallelOptions(), func(i int, r config.Repository) error {
+		var err error
+		plans[i], err = planPullRequestMerge(r, configObj.Forge)
+		return err
+	})
+
+	var toMerge []config.Repository
+	var toMergePlans []prMergePlan
+	blockedCount := 0
+	for i, repo := range repositories {
+		plan := plans[i]
+		switch {
+		case errs[i] == engine.ErrSkipped:
+			log.PrintWarning(fmt.Sprintf("%-30s [SKIPPED: earlier failure]", repo.Name()))
+		case errs[i] != nil:
+			log.PrintErrorNoExit(log.ErrOperationFailed, fmt.Sprintf("%-30s %v", repo.Name(), errs[i]), nil)
+		case plan.PullRequest == nil:
+			log.PrintInfo(fmt.Sprintf("%-30s %s has no open pull request", repo.Name(), plan.Branch))
+		case plan.Blocker != "" && !prForce:
+			blockedCount++
+			log.PrintWarning(fmt.Sprintf("%-30s #%d → %s %s", repo.Name(), plan.PullRequest.Number, plan.PullRequest.TargetBranch, plan.Blocker))
+		default:
+			log.PrintInfo(fmt.Sprintf("%-30s #%d → %s %s", repo.Name(), plan.PullRequest.Number, plan.PullRequest.TargetBranch, plan.PullRequest.URL))
+			toMerge = append(toMerge, repo)
+			toMergePlans = append(toMergePlans, plan)
+		}
+	}
+	log.PrintInfo("")
+
+	if blockedCount > 0 {
+		log.PrintError(log.ErrOperationFailed, fmt.Sprintf("%d pull requests are not ready to merge, nothing was merged (--force merges anyway)", blockedCount), nil)
+	}
+	reportPreviewFailures("Pull request check", errs)
+	if len(toMerge) == 0 {
+		log.PrintInfo("No pull requests to merge")
+		return
+	}
+
+	if !prYes {
+		confirmed, err := log.Confirm(fmt.Sprintf("Merge %d pull requests (%s)?", len(toMerge), prStrategy))
+		if err != nil {
+			log.PrintError(log.ErrPromptRequired, "Pass --yes to merge without confirmation", err)
+		}
+		if !confirmed {
+			log.PrintInfo("Nothing was merged")
+			return
+		}
+	}
+
+	results := make([]PRResult, len(toMerge))
+	mergeErrs := engine.ForEachRepository(toMerge, parallelOptions(), func(i int, r config.Repository) error {
+		results[i] = mergePullRequest(r, toMergePlans[i])
+		if !results[i].Success {
+			return errors.New(results[i].Message)
+		}
+		return nil
+	})
+
+	printPRResults("Merge Summary", toMerge, results, mergeErrs)
+}
+
+// planPullRequestMerge finds the open pull request of the current branch of a
+// repository and checks whether it is ready to merge
+func planPullRequestMerge(repo config.Repository, forgeConfig config.ForgeConfig) (prMergePlan, error) {
+	var plan prMergePlan
+
+	branch, err := git.GetCurrentBranch(repo.Path)
+	if err != nil {
+		return plan, err
+	}
+	plan.Branch = branch
+	if branch == "HEAD" {
+		return plan, fmt.Errorf("HEAD is detached")
+	}
+
+	if plan.Provider, err = providerFor(repo, forgeConfig); err != nil {
+		return plan, err
+	}
+	prs, err := plan.Provider.FindPullRequests(branch)
+	if err != nil {
+		return plan, err
+	}
+	var candidates []forge.PullRequest
+	for _, pr := range prs {
+		if prBase == "" || pr.TargetBranch == repo.MapBranch(prBase) {
+			candidates = append(candidates, pr)
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return plan, nil
+	case 1:
+	default:
+		return plan, fmt.Errorf("%s has %d open pull requests, choose one with --base", branch, len(candidates))
+	}
+
+	pr := candidates[0]
+	if err := plan.Provider.LoadStatus(&pr); err != nil {
+		return plan, err
+	}
+	plan.PullRequest = &pr
+	switch {
+	case pr.Draft:
+		plan.Blocker = "is a draft"
+	case pr.Review != forge.ReviewApproved:
+		plan.Blocker = "is " + formatReview(pr)
+	case pr.CI == "failed":
+		plan.Blocker = "has failing CI"
+	}
+
+	// Commits that were never pushed are not part of the pull request and
+	// would be lost with the local branch
+	if prDeleteBranch {
+		if plan.Unpushed, err = git.CountCommitsSince(repo.Path, repo.Remote+"/"+branch); err != nil {
+			return plan, err
+		}
+	}
+	return plan, nil
+}
+
+// mergePullRequest merges the pull request of a repository and, if requested,
+// switches back to the target branch and deletes the source branch
+func mergePullRequest(repo config.Repository, plan prMergePlan) PRResult {
+	result := PRResult{RepoName: repo.Name(), Branch: plan.Branch}
+	pr := plan.PullRequest
+
+	err := plan.Provider.MergePullRequest(pr, forge.MergeOptions{Strategy: prStrategy, DeleteSourceBranch: prDeleteBranch})
+	switch {
+	case errors.Is(err, forge.ErrAlreadyMerged):
+		// Merged in the meantime, e.g. by an earlier run or in the browser
+		result.Message = fmt.Sprintf("#%d was already merged into %s", pr.Number, pr.TargetBranch)
+	case err != nil:
+		result.Message = fmt.Sprintf("merging #%d failed: %v", pr.Number, err)
+		return result
+	default:
+		result.Message = fmt.Sprintf("merged #%d into %s (%s)", pr.Number, pr.TargetBranch, prStrategy)
+	}
+	result.Success = true
+	if !prSwitch {
+		return result
+	}
+
+	// The merge is on the remote; bring the target branch up to date with it
+	if err := git.SwitchToBranch(repo.Path, repo.Remote, pr.TargetBranch); err != nil {
+		result.Success = false
+		result.Message += fmt.Sprintf(", switching to %s failed: %v", pr.TargetBranch, err)
+		return result
+	}
+	if err := git.Fetch(repo.Path, repo.Remote); err != nil {
+		result.Success = false
+		result.Message += fmt.Sprintf(", switched to %s but fetching failed: %v", pr.TargetBranch, err)
+		return result
+	}
+	if err := git.FastForward(repo.Path, repo.Remote+"/"+pr.TargetBranch); err != nil {
+		result.Success = false
+		result.Message += fmt.Sprintf(", switched to %s but could not fast-forward it: %v", pr.TargetBranch, err)
+		return result
+	}
+	result.Message += fmt.Sprintf(", switched to %s", pr.TargetBranch)
+	if !prDeleteBranch {
+		return result
+	}
+
+	if plan.Unpushed > 0 {
+		result.Message += fmt.Sprintf(", kept %s with %d unpushed commits", plan.Branch, plan.Unpushed)
+		return result
+	}
+	// Squash and rebase merges leave the branch unmerged in git's eyes
+	if err := git.DeleteBranch(repo.Path, plan.Branch, true); err != nil {
+		result.Success = false
+		result.Message += fmt.Sprintf(", deleting %s failed: %v", plan.Branch, err)
+		return result
+	}
+	result.Message += fmt.Sprintf(", deleted %s", plan.Branch)
+	return result
+}
+
+// formatReview describes the review state of a pull request
+func formatReview(pr forge.PullRequest) string {
+	switch {
+	case pr.Draft:
+		return "draft"
+	case pr.Review == forge.ReviewPending && pr.ApprovalsLeft > 0:
+		return fmt.Sprintf("waiting (%d approvals left)", pr.ApprovalsLeft)
+	case pr.Review == forge.ReviewPending:
+		return "waiting"
+	}
+	return pr.Review
+}
+
+// formatCI describes the CI status of a pull request
+func formatCI(ci string) string {
+	if ci == "" {
+		return "-"
+	}
+	return ci
+}
+
+// providerFor returns the forge provider of a repository's remote
+func providerFor(repo config.Repository, forgeConfig config.ForgeConfig) (forge.Provider, error) {
+	remoteURL, err := git.GetRemoteURL(repo.Path, repo.Remote)
+	if err != nil {
+		return nil, err
+	}
+	return forge.ForRemote(remoteURL, forgeConfig)
+}
+
+// printPRResults prints the results of a pull request operation in configuration
+// order and exits with code 1 if any repository failed
+func printPRResults(title string, repositories []config.Repository, results []PRResult, errs []error) {
+	log.PrintInfo(fmt.Sprintf("=== %s ===", title))
+	successCount := 0
+	failCount := 0
+	for i, result := range results {
+		if errs[i] == engine.ErrSkipped {
+			result = PRResult{RepoName: repositories[i].Name(), Message: "skipped after an earlier failure"}
+		}
+		if result.Success {
+			successCount++
+			log.PrintSuccess(fmt.Sprintf("%-30s %s", result.RepoName, result.Message))
+		} else {
+			failCount++
+			log.PrintErrorNoExit(log.ErrOperationFailed, fmt.Sprintf("%-30s %s", result.RepoName, result.Message), nil)
+		}
+	}
+
+	log.PrintInfo("")
+	if failCount > 0 {
+		log.PrintWarning(fmt.Sprintf("%d succeeded, %d failed", successCount, failCount))
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"git_cli_tool/config"
+	"git_cli_tool/engine"
+	"git_cli_tool/log"
+
+	"github.com/spf13/cobra"
+)
+
+// publishCmd represents the publish command
+var publishCmd = &cobra.Command{
+	Use:   "publish",
+	Short: "Publish the current branch of every repository that has no upstream yet",
+	Long: `Push the current branch of every repository whose branch has no upstream
+to the configured remote (default: origin) and set it as the upstream
+("git push -u"). Repositories whose branch is already published are left
+alone, so no other commits are pushed; use push for that.
+
+Example:
+  git_cli_tool publish`,
+	Args: cobra.NoArgs,
+	Run:  runPublishCmd,
+}
+
+// initPublishCmd initializes the publish command with its flags
+func initPublishCmd() {
+	// No specific flags needed for publish command
+}
+
+// runPublishCmd is the main function for the publish command
+func runPublishCmd(cmd *cobra.Command, args []string) {
+	_, repositories := loadRepositories()
+
+	log.PrintOperation("Publishing unpublished branches")
+	log.PrintInfo("")
+
+	out := newCollector(repositories)
+	results := make([]engine.PublishResult, len(repositories))
+
+	opts, progress := progressOptions("Publishing", repositories)
+	publish := func(i int, r config.Repository) error {
+		results[i] = engine.PublishRepository(r)
+		printPublishResult(out.Repo(r.Path), results[i])
+		return results[i].Err
+	}
+	errs := engine.ForEachRepository(repositories, opts, publish)
+	progress.Stop()
+	out.Flush()
+	retryAuthFailures(out, repositories, errs, publish)
+
+	var published []string
+	alreadyCount := 0
+	failCount := 0
+	for i, err := range errs {
+		switch {
+		case err == engine.ErrSkipped:
+			failCount++
+			log.PrintWarning(fmt.Sprintf("%-30s [SKIPPED: earlier failure]", repositories[i].Name()))
+		case err != nil:
+			failCount++
+		case results[i].Published:
+			published = append(published, repositories[i].Name())
+		case results[i].Upstream != "":
+			alreadyCount++
+		}
+	}
+
+	log.PrintInfo("")
+	printAuthHint(errs)
+	if len(published) > 0 {
+		log.PrintSuccess(fmt.Sprintf("Published %d repositories: %s", len(published), strings.Join(published, ", ")))
+	} else {
+		log.PrintInfo("No branches needed publishing")
+	}
+	if alreadyCount > 0 {
+		log.PrintInfo(fmt.Sprintf("%d repositories were already published", alreadyCount))
+	}
+	if failCount > 0 {
+		log.PrintWarning(fmt.Sprintf("%d repositories failed", failCount))
+		os.Exit(1)
+	}
+}
+
+// printPublishResult prints the outcome of publishing a single repository
+func printPublishResult(out *log.RepoOutput, result engine.PublishResult) {
+	switch {
+	case result.Err != nil:
+		out.PrintErrorNoExit(errorCode(result.Err), fmt.Sprintf("%-30s [FAILED: %v]", result.RepoName, result.Err), nil)
+	case result.Skipped != "":
+		out.PrintInfo(fmt.Sprintf("%-30s nothing to publish (%s)", result.RepoName, result.Skipped))
+	case result.Published:
+		out.PrintSuccess(fmt.Sprintf("%-30s %s (published)", result.RepoName, result.Branch))
+	default:
+		out.PrintInfo(fmt.Sprintf("%-30s %s already tracks %s", result.RepoName, result.Branch, result.Upstream))
+	}
+}
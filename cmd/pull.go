@@ -1,9 +1,12 @@
 package cmd
 
 import (
-	"os"
+	"errors"
+	"fmt"
+	"time"
 
 	"git_cli_tool/config"
+	"git_cli_tool/engine"
 	"git_cli_tool/git"
 	"git_cli_tool/log"
 
@@ -17,36 +20,104 @@ var pullCmd = &cobra.Command{
 	Long: `Pull the latest changes from remote repositories for all repositories
 specified in the configuration file.
 
+With --skip-dirty, repositories with uncommitted changes to tracked files are
+left alone and reported as skipped rather than failed. Untracked files alone do
+not count.
+
+When a pull changes a dependency manifest, such as package-lock.json, go.sum
+or requirements.txt (see dependencies.manifests), a reminder to install the
+dependencies is printed; --auto-install runs the install command instead.
+
 Example:
   git_cli_tool pull
-  git_cli_tool pull --parallel`,
+  git_cli_tool pull --parallel
+  git_cli_tool pull --skip-dirty
+  git_cli_tool pull --auto-install`,
 	Run: runPullCmd,
 }
 
+var (
+	pullSkipDirty   bool
+	pullAutoInstall bool
+)
+
 // initPullCmd initializes the pull command with its flags
 func initPullCmd() {
-	// No specific flags needed for pull command beyond the global ones
+	pullCmd.Flags().BoolVar(&pullSkipDirty, "skip-dirty", false, "Skip repositories with uncommitted changes instead of pulling them")
+	pullCmd.Flags().BoolVar(&pullAutoInstall, "auto-install", false, "Install the dependencies of repositories whose dependency manifests changed")
 }
 
 // runPullCmd is the main function for the pull command
 func runPullCmd(cmd *cobra.Command, args []string) {
-	configObj, err := config.ReadConfig(configFile)
-	if err != nil {
-		log.PrintError(log.ErrConfigReadFailed, "Error reading config", err)
-		os.Exit(1)
+	start := time.Now()
+	configObj, repositories := loadRepositories()
+
+	log.PrintOperation("Pulling latest changes from remote repositories")
+
+	heads := recordHeads(repositories)
+	out := newCollector(repositories)
+	skipped := make([]bool, len(repositories))
+	opts, progress := progressOptions("Pulling", repositories)
+	pull := func(i int, r config.Repository) error {
+		if pullSkipDirty {
+			if changes := uncommittedChanges(r.Path); changes != "" {
+				skipped[i] = true
+				out.Repo(r.Path).PrintWarning(fmt.Sprintf("Skipped %s: uncommitted changes (%s)", r.Path, changes))
+				return nil
+			}
+		}
+		result := engine.PullRepository(r)
+		printPullResult(out.Repo(r.Path), result)
+		if result.Err != nil {
+			return result.Err
+		}
+		if result.HookErr != nil {
+			return result.HookErr
+		}
+		return result.TagErr
 	}
+	errs := engine.ForEachRepository(repositories, opts, pull)
+	progress.Stop()
+	out.Flush()
+	retryAuthFailures(out, repositories, errs, pull)
 
-	// Get the flattened repositories
-	repositories := configObj.FlattenRepositories()
+	for i, err := range checkDependencies(configObj, repositories, heads, pullAutoInstall) {
+		if errs[i] == nil {
+			errs[i] = err
+		}
+	}
 
-	if len(repositories) == 0 {
-		log.PrintError(log.ErrNoConfigRepos, "No repositories found in the configuration file", nil)
-		os.Exit(1)
+	var skippedNames []string
+	for i, repo := range repositories {
+		if skipped[i] {
+			skippedNames = append(skippedNames, repo.Name())
+		}
 	}
 
-	log.PrintOperation("Pulling latest changes from remote repositories")
+	notifyCompletion(configObj, "pull", repositories, errs, start)
+	printDirtySkips(skippedNames)
+	reportFailures("Pull operation", errs)
+}
 
-	git.PullRepositories(repositories)
+// printPullResult prints how syncing the tags and pulling a repository went
+func printPullResult(out *log.RepoOutput, result engine.PullResult) {
+	if result.TagErr != nil {
+		out.PrintErrorNoExit(log.ErrGitTagOperationFailed, fmt.Sprintf("Error syncing tags in %s", result.RepoPath), result.TagErr)
+	} else {
+		out.PrintSuccess(fmt.Sprintf("Successfully synced tags in %s", result.RepoPath))
+	}
 
-	log.PrintSuccess("Pull operation completed")
+	switch {
+	case errors.Is(result.Err, git.ErrAuthFailed):
+		out.PrintErrorNoExit(log.ErrGitAuthFailed, fmt.Sprintf("Authentication required to pull in %s", result.RepoPath), nil)
+	case result.Err != nil:
+		out.PrintErrorNoExit(log.ErrGitPullFailed, fmt.Sprintf("Error pulling in %s", result.RepoPath), result.Err)
+	default:
+		out.PrintSuccess(fmt.Sprintf("Successfully pulled in %s", result.RepoPath))
+	}
+	out.PrintInfo(result.Output)
+
+	if result.HookErr != nil {
+		out.PrintErrorNoExit(log.ErrHookFailed, fmt.Sprintf("Hook failed in %s", result.RepoPath), result.HookErr)
+	}
 }
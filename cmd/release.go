@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"git_cli_tool/config"
+	"git_cli_tool/engine"
+	"git_cli_tool/git"
+	"git_cli_tool/log"
+
+	"github.com/spf13/cobra"
+)
+
+// releaseCmd represents the release command
+var releaseCmd = &cobra.Command{
+	Use:   "release",
+	Short: "Coordinate releases across all repositories",
+}
+
+// releaseCutCmd represents the release cut command
+var releaseCutCmd = &cobra.Command{
+	Use:   "cut <release-branch>",
+	Short: "Create a release branch from the base branch in all repositories",
+	Long: `Create a release branch in every repository from the latest base branch
+(release.base in the configuration, otherwise the sync fallback_branch or the
+default branch of the repository's remote),
+push it with upstream and optionally tag the cut point.
+
+The cut is recorded in the branch history as a snapshot named after the release
+branch, so 'git_cli_tool revert <release-branch>' switches all repositories to it.
+Named snapshots are never trimmed from the history.
+
+Running the cut again after it failed in some repositories completes it: branches
+and tags that already exist are kept, and only what is missing is created and
+pushed.
+
+Example:
+  git_cli_tool release cut release/1.4
+  git_cli_tool release cut release/1.4 --tag v1.4.0-rc.0
+  git_cli_tool release cut release/1.4 --tag v1.4.0-rc.0 --sign`,
+	Args: cobra.ExactArgs(1),
+	Run:  runReleaseCutCmd,
+}
+
+var (
+	releaseBase string
+	releaseTag  string
+	releasePush bool
+	releaseSign bool
+)
+
+// initReleaseCmd initializes the release command and its subcommands
+func initReleaseCmd() {
+	releaseCutCmd.Flags().StringVar(&releaseBase, "base", "", "Branch to cut the release from (default from release.base)")
+	releaseCutCmd.Flags().StringVar(&releaseTag, "tag", "", "Tag the cut point with this name")
+	releaseCutCmd.Flags().BoolVar(&releasePush, "push", true, "Push the release branch (and tag) to the remote")
+	releaseCutCmd.Flags().BoolVar(&releaseSign, "sign", false, "Sign the tag with the repositories' GPG or SSH signing key")
+
+	releaseCmd.AddCommand(releaseCutCmd)
+}
+
+// ReleaseResult holds the result of a release operation in a single repository
+type ReleaseResult struct {
+	RepoPath string
+	RepoName string
+	Success  bool
+	Message  string
+}
+
+// runReleaseCutCmd is the main function for the release cut command
+func runReleaseCutCmd(cmd *cobra.Command, args []string) {
+	releaseBranch := args[0]
+	configObj, repositories := loadRepositories()
+
+	base := releaseBase
+	if base == "" {
+		base = configObj.Release.Base
+	}
+	if base == "" {
+		base = configObj.Sync.FallbackBranch
+	}
+
+	log.PrintOperation(fmt.Sprintf("Cutting %s from %s", releaseBranch, describeFallback(base)))
+	log.PrintInfo("")
+
+	results := make([]ReleaseResult, len(repositories))
+	errs := engine.ForEachRepository(repositories, parallelOptions(), func(i int, r config.Repository) error {
+		results[i] = cutRelease(r, r.MapBranch(releaseBranch), fallbackBranchFor(base, r))
+		if !results[i].Success {
+			return errors.New(results[i].Message)
+		}
+		return nil
+	})
+
+	// Print summary
+	log.PrintInfo("=== Release Cut Summary ===")
+	successCount := 0
+	failCount := 0
+	for i, result := range results {
+		if errs[i] == engine.ErrSkipped {
+			result = ReleaseResult{RepoName: repositories[i].Name(), Message: "skipped after an earlier failure"}
+		}
+		if result.Success {
+			successCount++
+			log.PrintSuccess(fmt.Sprintf("%-30s %s", result.RepoName, result.Message))
+		} else {
+			failCount++
+			log.PrintErrorNoExit(log.ErrOperationFailed, fmt.Sprintf("%-30s %s", result.RepoName, result.Message), nil)
+		}
+	}
+
+	// Record the release branches of the repositories that were cut as a named snapshot
+	if successCount > 0 {
+		if err := recordReleaseSnapshot(releaseBranch, repositories, results); err != nil {
+			log.PrintErrorNoExit(log.ErrHistoryStateFailed, "Error recording release snapshot", err)
+		} else {
+			log.PrintInfo("")
+			log.PrintInfo(fmt.Sprintf("Recorded snapshot '%s' in the branch history", releaseBranch))
+		}
+	}
+
+	log.PrintInfo("")
+	if failCount == 0 {
+		log.PrintSuccess(fmt.Sprintf("Release %s cut in all %d repositories!", releaseBranch, successCount))
+	} else {
+		log.PrintWarning(fmt.Sprintf("%d succeeded, %d failed", successCount, failCount))
+		os.Exit(1)
+	}
+}
+
+// cutRelease creates, pushes and optionally tags the release branch of a single
+// repository. Steps that were already done, e.g. by an earlier run that failed
+// halfway, are left as they are, so the cut can simply be run again.
+func cutRelease(repo config.Repository, releaseBranch string, base string) ReleaseResult {
+	result := ReleaseResult{RepoPath: repo.Path, RepoName: repo.Name()}
+
+	// Cut from the latest published state of the base branch
+	if err := git.FetchRepository(repo); err != nil {
+		result.Message = err.Error()
+		return result
+	}
+
+	var steps []string
+	branchRef, exists, err := git.ResolveBranch(repo.Path, repo.Remote, releaseBranch)
+	if err != nil {
+		result.Message = err.Error()
+		return result
+	}
+	if exists {
+		steps = append(steps, "branch already cut")
+	} else {
+		baseRef := repo.Remote + "/" + base
+		if remoteExists, _ := git.CheckRemoteBranchExists(repo.Path, repo.Remote, base); !remoteExists {
+			localExists, _ := git.CheckBranchExists(repo.Path, base)
+			if !localExists {
+				result.Message = fmt.Sprintf("base branch '%s' not found", base)
+				return result
+			}
+			baseRef = base
+		}
+		if err := git.CreateBranch(repo.Path, releaseBranch, baseRef); err != nil {
+			result.Message = err.Error()
+			return result
+		}
+		branchRef = releaseBranch
+		steps = append(steps, fmt.Sprintf("created from %s", baseRef))
+	}
+
+	if releasePush {
+		pushed, err := git.CheckRemoteBranchExists(repo.Path, repo.Remote, releaseBranch)
+		if err != nil {
+			result.Message = err.Error()
+			return result
+		}
+		if !pushed {
+			if err := git.PushBranch(repo.Path, repo.Remote, releaseBranch); err != nil {
+				result.Message = err.Error()
+				return result
+			}
+			steps = append(steps, "pushed")
+		}
+	}
+
+	if releaseTag != "" {
+		var remoteTags map[string]string
+		if releasePush {
+			if remoteTags, err = git.ListRemoteTags(repo.Path, repo.Remote); err != nil {
+				result.Message = err.Error()
+				return result
+			}
+		}
+		_, onRemote := remoteTags[releaseTag]
+
+		tagged := git.TagExists(repo.Path, releaseTag)
+		if !tagged && !onRemote {
+			if err := git.CreateTag(repo.Path, releaseTag, branchRef, "Release cut "+releaseBranch, releaseSign); err != nil {
+				result.Message = err.Error()
+				return result
+			}
+		}
+		if releasePush && !onRemote {
+			if err := git.PushTag(repo.Path, repo.Remote, releaseTag); err != nil {
+				result.Message = err.Error()
+				return result
+			}
+		}
+
+		switch {
+		case !tagged && !onRemote:
+			steps = append(steps, fmt.Sprintf("tagged %s", releaseTag))
+		case releasePush && !onRemote:
+			steps = append(steps, fmt.Sprintf("pushed the existing tag %s", releaseTag))
+		default:
+			steps = append(steps, fmt.Sprintf("tag %s already exists", releaseTag))
+		}
+	}
+
+	result.Message = strings.Join(steps, ", ")
+	result.Success = true
+	return result
+}
+
+// recordReleaseSnapshot saves a history entry named after the release, pointing
+// every successfully cut repository at its release branch
+func recordReleaseSnapshot(releaseBranch string, repositories []config.Repository, results []ReleaseResult) error {
+	state := &config.BranchState{
+		Timestamp:    time.Now().Format(time.RFC3339),
+		Name:         releaseBranch,
+		Description:  "Release cut " + releaseBranch,
+		Repositories: make(map[string]config.RepositoryState),
+		Configured:   configuredPaths(),
+	}
+	for i, result := range results {
+		if result.Success {
+			state.Repositories[result.RepoPath] = config.RepositoryState{Branch: repositories[i].MapBranch(releaseBranch)}
+		}
+	}
+
+	_, history, err := config.ReadHistory()
+	if err != nil {
+		return err
+	}
+	return config.SaveStateToHistory(state, history)
+}
@@ -0,0 +1,253 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"git_cli_tool/config"
+	"git_cli_tool/engine"
+	"git_cli_tool/git"
+	"git_cli_tool/log"
+
+	"github.com/spf13/cobra"
+)
+
+// remoteCmd represents the remote command
+var remoteCmd = &cobra.Command{
+	Use:   "remote",
+	Short: "Change git remotes across all repositories",
+	Long: `Rewrite or add git remotes in every repository, for example after moving
+to a new git host.
+
+The new URL is either given as a Go template, executed per repository with
+the fields .Name, .Path and .URL, or derived with --map from an existing URL.
+.URL is the current URL of the remote for set-url, and the URL of the
+repository's configured remote for add. Use --dry-run to preview the URLs.`,
+}
+
+// remoteSetURLCmd represents the remote set-url command
+var remoteSetURLCmd = &cobra.Command{
+	Use:   "set-url <name> [url-template]",
+	Short: "Change the URL of a remote in all repositories",
+	Long: `Change the URL of the named remote in every repository.
+
+With --map old=new, the first mapping whose old part occurs in the current
+URL is applied; repositories whose URL matches no mapping are left unchanged.
+--map can be given several times.
+
+Example:
+  git_cli_tool remote set-url origin --map github.com=git.example.com --dry-run
+  git_cli_tool remote set-url origin --map 'git@old-host:=git@new-host:team/'
+  git_cli_tool remote set-url origin 'git@git.example.com:team/{{.Name}}.git'`,
+	Args: cobra.RangeArgs(1, 2),
+	Run:  runRemoteSetURLCmd,
+}
+
+// remoteAddCmd represents the remote add command
+var remoteAddCmd = &cobra.Command{
+	Use:   "add <name> [url-template]",
+	Short: "Add a remote to all repositories",
+	Long: `Add the named remote to every repository.
+
+With --map old=new, the URL of the new remote is derived from the URL of
+the repository's configured remote.
+
+Example:
+  git_cli_tool remote add mirror --map github.com=git.example.com
+  git_cli_tool remote add upstream 'git@github.com:upstream/{{.Name}}.git' --dry-run`,
+	Args: cobra.RangeArgs(1, 2),
+	Run:  runRemoteAddCmd,
+}
+
+var (
+	remoteMaps   []string
+	remoteDryRun bool
+)
+
+// initRemoteCmd initializes the remote command and its subcommands
+func initRemoteCmd() {
+	for _, c := range []*cobra.Command{remoteSetURLCmd, remoteAddCmd} {
+		c.Flags().StringArrayVar(&remoteMaps, "map", nil, "Derive the new URL by replacing old with new (old=new, can be repeated)")
+		c.Flags().BoolVar(&remoteDryRun, "dry-run", false, "Show the new URLs without changing anything")
+	}
+
+	remoteCmd.AddCommand(remoteSetURLCmd)
+	remoteCmd.AddCommand(remoteAddCmd)
+}
+
+// RemoteURLFields are the fields available to URL templates
+type RemoteURLFields struct {
+	Name string
+	Path string
+	URL  string
+}
+
+// remoteChange is the planned or applied change of a remote in a repository
+type remoteChange struct {
+	RepoName string
+	OldURL   string
+	NewURL   string
+	Err      error
+}
+
+// urlMapping replaces Old with New in a URL
+type urlMapping struct {
+	Old string
+	New string
+}
+
+// runRemoteSetURLCmd is the main function for the remote set-url command
+func runRemoteSetURLCmd(cmd *cobra.Command, args []string) {
+	name := args[0]
+	newURL := remoteURLFunc(args)
+	_, repositories := loadRepositories()
+
+	changes := make([]remoteChange, len(repositories))
+	engine.ForEachRepository(repositories, parallelOptions(), func(i int, r config.Repository) error {
+		change := remoteChange{RepoName: r.Name()}
+		change.OldURL, change.Err = git.GetRemoteURL(r.Path, name)
+		if change.Err == nil {
+			change.NewURL, change.Err = newURL(r, change.OldURL)
+		}
+		changes[i] = change
+		return change.Err
+	})
+
+	applyRemoteChanges(repositories, changes, "set-url", name, func(r config.Repository, url string) error {
+		return git.SetRemoteURL(r.Path, name, url)
+	})
+}
+
+// runRemoteAddCmd is the main function for the remote add command
+func runRemoteAddCmd(cmd *cobra.Command, args []string) {
+	name := args[0]
+	newURL := remoteURLFunc(args)
+	_, repositories := loadRepositories()
+
+	changes := make([]remoteChange, len(repositories))
+	engine.ForEachRepository(repositories, parallelOptions(), func(i int, r config.Repository) error {
+		change := remoteChange{RepoName: r.Name()}
+		if _, err := git.GetRemoteURL(r.Path, name); err == nil {
+			change.Err = fmt.Errorf("remote %s already exists", name)
+		} else {
+			var baseURL string
+			baseURL, change.Err = git.GetRemoteURL(r.Path, r.Remote)
+			if change.Err == nil {
+				change.NewURL, change.Err = newURL(r, baseURL)
+			}
+		}
+		changes[i] = change
+		return change.Err
+	})
+
+	applyRemoteChanges(repositories, changes, "add", name, func(r config.Repository, url string) error {
+		return git.AddRemote(r.Path, name, url)
+	})
+}
+
+// remoteURLFunc returns the function computing the new URL of a repository from
+// the URL template argument or the --map flags, exiting when neither or both are given
+func remoteURLFunc(args []string) func(repo config.Repository, url string) (string, error) {
+	if len(args) > 1 && len(remoteMaps) > 0 {
+		log.PrintError(log.ErrInvalidArgument, "Give either a URL template or --map, not both", nil)
+	}
+
+	if len(args) > 1 {
+		tmpl, err := template.New("url").Option("missingkey=error").Parse(args[1])
+		if err != nil {
+			log.PrintError(log.ErrInvalidArgument, "Invalid URL template", err)
+		}
+		return func(repo config.Repository, url string) (string, error) {
+			var newURL strings.Builder
+			if err := tmpl.Execute(&newURL, RemoteURLFields{Name: repo.Name(), Path: repo.Path, URL: url}); err != nil {
+				return "", fmt.Errorf("failed to execute URL template: %v", err)
+			}
+			return newURL.String(), nil
+		}
+	}
+
+	if len(remoteMaps) == 0 {
+		log.PrintError(log.ErrInvalidArgument, "Give a URL template or at least one --map old=new", nil)
+	}
+
+	var mappings []urlMapping
+	for _, m := range remoteMaps {
+		parts := strings.SplitN(m, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			log.PrintError(log.ErrInvalidArgument, fmt.Sprintf("Invalid --map %q, expected old=new", m), nil)
+		}
+		mappings = append(mappings, urlMapping{Old: parts[0], New: parts[1]})
+	}
+	return func(repo config.Repository, url string) (string, error) {
+		for _, mapping := range mappings {
+			if strings.Contains(url, mapping.Old) {
+				return strings.Replace(url, mapping.Old, mapping.New, 1), nil
+			}
+		}
+		return url, nil
+	}
+}
+
+// applyRemoteChanges previews the changes with --dry-run, otherwise applies them
+// and prints the summary. Exits with status 1 if any repository failed.
+func applyRemoteChanges(repositories []config.Repository, changes []remoteChange, operation string, name string, apply func(config.Repository, string) error) {
+	if remoteDryRun {
+		log.PrintOperation(fmt.Sprintf("Dry-run: remote %s %s", operation, name))
+	} else {
+		log.PrintOperation(fmt.Sprintf("Remote %s %s", operation, name))
+	}
+	log.PrintInfo("")
+
+	// set-url leaves repositories whose URL would not change alone
+	unchanged := func(change remoteChange) bool {
+		return operation == "set-url" && change.NewURL == change.OldURL
+	}
+
+	errs := make([]error, len(repositories))
+	if !remoteDryRun {
+		errs = engine.ForEachRepository(repositories, parallelOptions(), func(i int, r config.Repository) error {
+			if changes[i].Err != nil || unchanged(changes[i]) {
+				return changes[i].Err
+			}
+			changes[i].Err = apply(r, changes[i].NewURL)
+			return changes[i].Err
+		})
+	}
+
+	successCount := 0
+	failCount := 0
+	for i, change := range changes {
+		switch {
+		case errs[i] == engine.ErrSkipped:
+			failCount++
+			log.PrintWarning(fmt.Sprintf("%-30s [SKIPPED: earlier failure]", change.RepoName))
+		case change.Err != nil:
+			failCount++
+			log.PrintErrorNoExit("", fmt.Sprintf("%-30s %v", change.RepoName, strings.TrimSpace(change.Err.Error())), nil)
+		case unchanged(change):
+			successCount++
+			log.PrintInfo(fmt.Sprintf("%-30s %s (unchanged)", change.RepoName, change.OldURL))
+		case operation == "set-url":
+			successCount++
+			log.PrintSuccess(fmt.Sprintf("%-30s %s → %s", change.RepoName, change.OldURL, change.NewURL))
+		default:
+			successCount++
+			log.PrintSuccess(fmt.Sprintf("%-30s %s", change.RepoName, change.NewURL))
+		}
+	}
+
+	log.PrintInfo("")
+	if failCount > 0 {
+		log.PrintWarning(fmt.Sprintf("%d succeeded, %d failed", successCount, failCount))
+		os.Exit(1)
+	}
+	if remoteDryRun {
+		log.PrintInfo("No changes made (dry-run)")
+	} else if operation == "add" {
+		log.PrintSuccess(fmt.Sprintf("Remote %s added to all %d repositories!", name, successCount))
+	} else {
+		log.PrintSuccess(fmt.Sprintf("Remote %s updated in all %d repositories!", name, successCount))
+	}
+}
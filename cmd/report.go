@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"git_cli_tool/git"
+	"git_cli_tool/log"
+
+	"github.com/spf13/cobra"
+)
+
+// Formats of the --output flag of commands that print a report
+const (
+	outputText     = "text"
+	outputJSON     = "json"
+	outputCSV      = "csv"
+	outputMarkdown = "markdown"
+)
+
+// outputFormat is the --output flag of the running command
+var outputFormat string
+
+// addOutputFlag adds the --output flag to a command that prints a report
+func addOutputFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", outputText, "Output format: text, json, csv or markdown")
+}
+
+// beginReport validates --output and reports whether the command prints a
+// report instead of its text output. Informational messages are hidden then,
+// so standard output only holds the report.
+func beginReport() bool {
+	switch outputFormat {
+	case outputText, "":
+		return false
+	case outputJSON, outputCSV, outputMarkdown:
+		if !log.Enabled(log.LevelDebug) {
+			log.SetLevel(log.LevelWarn)
+		}
+		return true
+	}
+	log.PrintError(log.ErrInvalidArgument, fmt.Sprintf("Unknown --output format %q, expected text, json, csv or markdown", outputFormat), nil)
+	return false
+}
+
+// printReport writes a report to standard output in the --output format:
+// records as JSON, or headers and rows as CSV or a Markdown table
+func printReport(headers []string, rows [][]string, records any) {
+	var err error
+	switch outputFormat {
+	case outputJSON:
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(records)
+	case outputCSV:
+		writer := csv.NewWriter(os.Stdout)
+		writer.Write(headers)
+		writer.WriteAll(rows)
+		err = writer.Error()
+	case outputMarkdown:
+		err = writeMarkdownTable(headers, rows)
+	}
+	if err != nil {
+		log.PrintError(log.ErrOperationFailed, "Failed to write the report", err)
+	}
+}
+
+// writeMarkdownTable writes headers and rows as a GitHub-flavored Markdown table
+func writeMarkdownTable(headers []string, rows [][]string) error {
+	var table strings.Builder
+	writeRow := func(cells []string) {
+		table.WriteString("|")
+		for _, cell := range cells {
+			cell = strings.ReplaceAll(strings.ReplaceAll(cell, "|", `\|`), "\n", " ")
+			table.WriteString(" " + cell + " |")
+		}
+		table.WriteString("\n")
+	}
+
+	writeRow(headers)
+	separators := make([]string, len(headers))
+	for i := range separators {
+		separators[i] = "---"
+	}
+	writeRow(separators)
+	for _, row := range rows {
+		writeRow(row)
+	}
+
+	_, err := os.Stdout.WriteString(table.String())
+	return err
+}
+
+// errorCode returns the code from log/errors.go that reports give for the
+// error of a repository, so scripts can tell failure categories apart
+func errorCode(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, git.ErrAuthFailed):
+		return log.ErrGitAuthFailed
+	case errors.Is(err, git.ErrCherryPickConflict):
+		return log.ErrGitConflict
+	}
+	return log.ErrOperationFailed
+}
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"git_cli_tool/config"
+	"git_cli_tool/engine"
+	"git_cli_tool/log"
+
+	"github.com/spf13/cobra"
+)
+
+// resetCmd represents the reset command
+var resetCmd = &cobra.Command{
+	Use:   "reset --hard-origin",
+	Short: "Reset all repositories to their upstream branches",
+	Long: `Wipe the workspace back to the state of the remote: every repository is
+fetched and its current branch is hard-reset to its upstream tracking branch.
+Local commits and uncommitted changes to tracked files are discarded;
+untracked files are kept.
+
+Before anything is reset, the branch, HEAD commit and a patch of the
+uncommitted changes of every repository are recorded in the branch history.
+If that fails, nothing is reset. Undo the reset with:
+  git_cli_tool revert
+
+On a terminal, the repositories are listed as a checklist first so that
+repositories with work in progress can be left out; --yes skips it.
+
+Example:
+  git_cli_tool reset --hard-origin
+  git_cli_tool reset --hard-origin --only api --no-fetch`,
+	Args: cobra.NoArgs,
+	Run:  runResetCmd,
+}
+
+var (
+	resetHardOrigin bool
+	resetNoFetch    bool
+	resetYes        bool
+)
+
+// initResetCmd initializes the reset command with its flags
+func initResetCmd() {
+	resetCmd.Flags().BoolVar(&resetHardOrigin, "hard-origin", false, "Hard-reset the current branch to its upstream tracking branch")
+	resetCmd.Flags().BoolVar(&resetNoFetch, "no-fetch", false, "Reset to the upstream as last fetched")
+	resetCmd.Flags().BoolVarP(&resetYes, "yes", "y", false, "Reset all selected repositories without listing them for deselection")
+}
+
+// runResetCmd is the main function for the reset command
+func runResetCmd(cmd *cobra.Command, args []string) {
+	if !resetHardOrigin {
+		log.PrintError(log.ErrInvalidArgument, "reset discards local work; confirm with --hard-origin", nil)
+	}
+
+	_, repositories := loadRepositories()
+
+	if !resetYes {
+		details := make([]string, len(repositories))
+		for i := range details {
+			details[i] = "reset to upstream"
+		}
+		repositories, _ = deselectRepositories[any]("Repositories to reset:", repositories, details, nil)
+		if len(repositories) == 0 {
+			log.PrintInfo("Nothing was reset")
+			return
+		}
+	}
+
+	// The snapshot is mandatory: without it the reset could not be undone
+	state, err := captureState(repositories, "reset", "before reset --hard-origin", engine.CaptureTracked, nil)
+	if err != nil {
+		log.PrintError(log.ErrHistoryStateFailed, "Failed to record the current state, nothing was reset", err)
+	}
+	log.PrintSuccess("Current state saved to history")
+	log.PrintInfo("")
+
+	log.PrintOperation("Resetting repositories to their upstream branches")
+	log.PrintInfo("")
+
+	results := make([]engine.ResetResult, len(repositories))
+	opts, progress := progressOptions("Resetting", repositories)
+	errs := engine.ForEachRepository(repositories, opts, func(i int, r config.Repository) error {
+		results[i] = engine.ResetToUpstream(r, !resetNoFetch)
+		return results[i].Err
+	})
+	progress.Stop()
+
+	successCount := 0
+	failCount := 0
+	for i, result := range results {
+		switch {
+		case errs[i] == engine.ErrSkipped:
+			failCount++
+			log.PrintWarning(fmt.Sprintf("%-30s [SKIPPED: earlier failure]", repositories[i].Name()))
+		case result.Err != nil:
+			failCount++
+			log.PrintErrorNoExit("", fmt.Sprintf("%-30s %v", result.RepoName, result.Err), nil)
+		default:
+			successCount++
+			line := fmt.Sprintf("%-30s %s → %s (%s)", result.RepoName, shortSHA(result.From), shortSHA(result.To), result.Upstream)
+			if result.From == result.To {
+				line = fmt.Sprintf("%-30s %s (%s, unchanged)", result.RepoName, shortSHA(result.To), result.Upstream)
+			}
+			if state.Repositories[result.RepoPath].Patch != "" {
+				line += " [CHANGES SAVED]"
+			}
+			log.PrintSuccess(line)
+		}
+	}
+
+	log.PrintInfo("")
+	log.PrintInfo("Undo with 'git_cli_tool revert'")
+	if failCount == 0 {
+		log.PrintSuccess(fmt.Sprintf("All %d repositories reset successfully!", successCount))
+	} else {
+		log.PrintWarning(fmt.Sprintf("%d succeeded, %d failed", successCount, failCount))
+		os.Exit(1)
+	}
+}
+
+// captureState records the branch, HEAD and uncommitted changes of all
+// repositories in the branch history, tagged with the command about to run,
+// failing if any repository cannot be recorded. If record is not nil, it adds
+// what else the command is about to change to the state of each repository,
+// e.g. the commit of a branch other than the current one.
+func captureState(repositories []config.Repository, command string, description string, changes engine.CapturedChanges, record func(config.Repository, *config.RepositoryState) error) (*config.BranchState, error) {
+	now := time.Now()
+	repoStates := make([]config.RepositoryState, len(repositories))
+	errs := engine.ForEachRepository(repositories, engine.ParallelOptions{Jobs: jobs}, func(i int, r config.Repository) error {
+		var err error
+		if repoStates[i], err = engine.CaptureRepositoryState(r, now, changes); err == nil && record != nil {
+			err = record(r, &repoStates[i])
+		}
+		return err
+	})
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", repositories[i].Name(), err)
+		}
+	}
+
+	state := &config.BranchState{
+		Timestamp:    now.Format(time.RFC3339),
+		Description:  description,
+		Command:      command,
+		Repositories: make(map[string]config.RepositoryState),
+		Configured:   configuredPaths(),
+	}
+	for i, repo := range repositories {
+		state.Repositories[repo.Path] = repoStates[i]
+	}
+
+	_, history, err := config.ReadHistory()
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err := config.SaveStateToHistory(state, history); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// autoSnapshot records the state of the repositories before a destructive
+// command, unless auto_snapshot is turned off, so that revert can return to
+// it. The command is stopped if the state cannot be recorded. See captureState
+// for record.
+func autoSnapshot(configObj *config.Configuration, repositories []config.Repository, command string, description string, changes engine.CapturedChanges, record func(config.Repository, *config.RepositoryState) error) {
+	if !configObj.AutoSnapshotEnabled() || len(repositories) == 0 {
+		return
+	}
+	if _, err := captureState(repositories, command, description, changes, record); err != nil {
+		log.PrintError(log.ErrHistoryStateFailed, "Failed to record the current state, nothing was changed", err)
+	}
+	log.PrintSuccess("Current state saved to history, undo with 'git_cli_tool revert'")
+}
@@ -2,10 +2,13 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 	"strconv"
+	"time"
 
 	"git_cli_tool/config"
+	"git_cli_tool/engine"
 	"git_cli_tool/git"
 	"git_cli_tool/log"
 
@@ -13,35 +16,60 @@ import (
 )
 
 var (
-	applyStashes bool
+	applyStashes  bool
+	revertAt      string
+	revertAgo     string
+	revertPartial bool
+	revertReset   bool
 )
 
 // revertCmd represents the revert command
 var revertCmd = &cobra.Command{
-	Use:   "revert [index]",
-	Short: "Revert to a previous branch state (defaults to latest if no index provided)",
-	Args:  cobra.MaximumNArgs(1),
-	Run:   runRevertCmd,
+	Use:   "revert [index|name]",
+	Short: "Revert to a previous branch state (defaults to latest if no index or name provided)",
+	Long: `Revert the repositories to a state recorded in the history, given by its
+index in the history output (newest first), by the name of a named snapshot, or
+by time with --at or --ago, which select the newest state recorded at or before
+that time. Without any of them the latest state is used.
+
+--at takes a local time such as "2024-05-01 14:00" or "2024-05-01", or an
+RFC3339 time. --ago takes a duration such as "2h", "90m" or "3d".
+
+States recorded before commands like sync or reset also hold the commit of each
+branch. Branches are only fast-forwarded to it, so newer commits are never lost;
+a branch that has moved past it since, e.g. by the merges of a sync, is reported.
+Pass --reset to reset such branches to the recorded commit with git reset --hard;
+the newer commits are left in the reflog.
+Branches such a command deleted, e.g. feature done, are created again at their
+recorded commit, and the stashes it dropped are put back into the stash list.
+
+If repositories were added to or removed from the configuration since the state
+was recorded, the revert stops and lists them. Pass --partial to revert only the
+repositories that are both recorded and configured. Repositories that were
+configured but left out of the state on purpose, e.g. the ones a clean did not
+touch, stay as they are without --partial.
+
+Example:
+  git_cli_tool revert 2
+  git_cli_tool revert release/1.4
+  git_cli_tool revert --at "2024-05-01 14:00"
+  git_cli_tool revert --ago 2h
+  git_cli_tool revert 0 --reset`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runRevertCmd,
 }
 
 // initRevertCmd initializes the revert command with its flags
 func initRevertCmd() {
 	revertCmd.Flags().BoolVar(&applyStashes, "apply-stashes", true, "Apply stashes when reverting (if any exist)")
+	revertCmd.Flags().StringVar(&revertAt, "at", "", "Revert to the newest state recorded at or before this time, e.g. \"2024-05-01 14:00\"")
+	revertCmd.Flags().BoolVar(&revertPartial, "partial", false, "Revert even if the state does not cover the configured repositories")
+	revertCmd.Flags().BoolVar(&revertReset, "reset", false, "Reset branches that moved past or diverged from the recorded commit back to it (git reset --hard)")
+	revertCmd.Flags().StringVar(&revertAgo, "ago", "", "Revert to the newest state recorded at least this long ago, e.g. 2h or 3d")
 }
 
 // runRevertCmd is the main function for the revert command
 func runRevertCmd(cmd *cobra.Command, args []string) {
-	// Parse the history index argument, default to 0 (most recent) if not provided
-	index := 0
-	if len(args) > 0 {
-		var err error
-		index, err = strconv.Atoi(args[0])
-		if err != nil {
-			log.PrintError(log.ErrInvalidArgument, "Error parsing index", err)
-			os.Exit(1)
-		}
-	}
-
 	history, err := config.LoadBranchHistory()
 	if err != nil {
 		log.PrintError(log.ErrHistoryReadFailed, "Error loading branch history", err)
@@ -53,21 +81,79 @@ func runRevertCmd(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	// Convert the user-provided index to the actual array index
-	// User sees newest first (index 0), but array stores oldest first
-	actualIndex := len(history.States) - 1 - index
+	if revertAt != "" && revertAgo != "" {
+		log.PrintError(log.ErrInvalidArgument, "--at and --ago cannot be combined", nil)
+	}
+	if (revertAt != "" || revertAgo != "") && len(args) > 0 {
+		log.PrintError(log.ErrInvalidArgument, "An index or name cannot be combined with --at or --ago", nil)
+	}
 
-	if actualIndex < 0 || actualIndex >= len(history.States) {
-		log.PrintError(log.ErrHistoryIndexInvalid, "Invalid index", nil)
-		log.PrintInfo("Valid range: 0-" + strconv.Itoa(len(history.States)-1))
-		os.Exit(1)
+	var index, actualIndex int
+	switch {
+	case revertAt != "":
+		at, err := config.ParseHistoryTime(revertAt, time.Local)
+		if err != nil {
+			log.PrintError(log.ErrInvalidArgument, fmt.Sprintf("Invalid --at '%s'", revertAt), err)
+		}
+		index, actualIndex = findHistoryStateAt(history, at)
+	case revertAgo != "":
+		ago, err := config.ParseHistoryAge(revertAgo)
+		if err != nil {
+			log.PrintError(log.ErrInvalidArgument, fmt.Sprintf("Invalid --ago '%s'", revertAgo), err)
+		}
+		index, actualIndex = findHistoryStateAt(history, time.Now().Add(-ago))
+	default:
+		arg := ""
+		if len(args) > 0 {
+			arg = args[0]
+		}
+		index, actualIndex = findHistoryState(history, arg)
 	}
 
 	// Get the state to revert to
 	state := history.States[actualIndex]
 
+	// The configuration provides the remote for each repository and which ones are disabled
+	configObj := loadConfig()
+
+	// Only revert the repositories selected by --only/--exclude/--label/--repo
+	selectedState := state
+	selectedState.Repositories = make(map[string]config.RepositoryState)
+	for _, repo := range engine.StateRepositories(state, configObj.AllRepositories(), isSelected) {
+		selectedState.Repositories[repo.Path] = state.Repositories[repo.Path]
+	}
+	if len(selectedState.Repositories) == 0 {
+		log.PrintError(log.ErrRepoNotFound, fmt.Sprintf("No repository recorded in state [%d] is selected", index), nil)
+	}
+
+	// Repositories added to the configuration since the state was recorded would
+	// silently stay as they are, so a revert that does not cover them needs --partial
+	unrecorded, unconfigured := engine.StateCoverage(selectedState, configObj.AllRepositories())
+	unrecorded = filterRepositories(unrecorded)
+	if len(unrecorded) > 0 || len(unconfigured) > 0 {
+		printStateCoverage(unrecorded, unconfigured)
+		if !revertPartial {
+			log.PrintInfo("")
+			log.PrintError(log.ErrHistoryStateIncomplete, fmt.Sprintf("State [%d] does not cover the configured repositories; pass --partial to revert the repositories it covers", index), nil)
+		}
+		for _, repoPath := range unconfigured {
+			delete(selectedState.Repositories, repoPath)
+		}
+		log.PrintInfo("")
+	}
+
+	// Record where the repositories are now, so the revert itself can be undone;
+	// repositories that no longer exist cannot be recorded and are left to the revert to report
+	var current []config.Repository
+	for _, repo := range configObj.FlattenRepositories() {
+		if _, ok := selectedState.Repositories[repo.Path]; ok && git.ValidateRepository(repo.Path) == nil {
+			current = append(current, repo)
+		}
+	}
+	autoSnapshot(configObj, current, "revert", fmt.Sprintf("before revert to [%d]", index), engine.CaptureNoChanges, nil)
+
 	// Revert to the selected state
-	err = git.RevertToState(state, applyStashes)
+	err = revertToState(selectedState, configObj.AllRepositories(), applyStashes, revertReset, failFast)
 	if err != nil {
 		log.PrintError(log.ErrOperationFailed, "Error during revert", err)
 		os.Exit(1)
@@ -78,3 +164,85 @@ func runRevertCmd(cmd *cobra.Command, args []string) {
 		log.PrintInfo("Description: " + state.Description)
 	}
 }
+
+// printStateCoverage lists the configured repositories without an entry in a
+// history state and the recorded repositories that are no longer configured
+func printStateCoverage(unrecorded []config.Repository, unconfigured []string) {
+	if len(unrecorded) > 0 {
+		log.PrintWarning("Configured but not recorded in this state, left as they are:")
+		for _, repo := range unrecorded {
+			log.PrintWarning(fmt.Sprintf("  %-30s %s", repo.Name(), repo.Path))
+		}
+	}
+	if len(unconfigured) > 0 {
+		log.PrintWarning("Recorded in this state but no longer configured, not reverted:")
+		for _, repoPath := range unconfigured {
+			log.PrintWarning("  " + repoPath)
+		}
+	}
+}
+
+// revertToState reverts the repositories to a history state and prints the outcome of each
+func revertToState(state config.BranchState, repositories []config.Repository, applyStashes bool, resetCommits bool, failFast bool) error {
+	log.PrintOperation(fmt.Sprintf("Reverting to branch state from %s", state.Timestamp))
+	if state.Description != "" {
+		log.PrintInfo(fmt.Sprintf("Description: %s", state.Description))
+	}
+
+	results, err := engine.RevertToState(state, repositories, applyStashes, resetCommits, failFast)
+
+	stopped := false
+	for _, result := range results {
+		switch {
+		case result.Err == engine.ErrSkipped:
+			if !stopped {
+				log.PrintWarning("Stopping after the first failure (--fail-fast)")
+				stopped = true
+			}
+		case result.Skipped == engine.SkipDisabled:
+			log.PrintInfo(fmt.Sprintf("Skipping %s: %s", result.RepoPath, result.Skipped))
+		case result.Skipped != "":
+			log.PrintWarning(fmt.Sprintf("Skipping %s: %s", result.RepoPath, result.Skipped))
+		case result.Err != nil:
+			log.PrintErrorNoExit(log.ErrGitCheckoutFailed, fmt.Sprintf("Error switching branch in %s", result.RepoPath), result.Err)
+		default:
+			if result.Branch == "HEAD" {
+				log.PrintSuccess(fmt.Sprintf("Successfully detached HEAD at %s in %s", shortSHA(result.Commit), result.RepoPath))
+			} else if result.Recreated {
+				log.PrintSuccess(fmt.Sprintf("Recreated branch %s at the recorded commit %s and switched to it in %s", result.Branch, shortSHA(result.Commit), result.RepoPath))
+			} else {
+				log.PrintSuccess(fmt.Sprintf("Successfully switched to branch %s in %s", result.Branch, result.RepoPath))
+			}
+			if result.StashErr != nil {
+				log.PrintErrorNoExit(log.ErrGitApplyStashFailed, fmt.Sprintf("Error applying stash in %s", result.RepoPath), result.StashErr)
+			} else if result.StashApplied {
+				log.PrintSuccess(fmt.Sprintf("Successfully applied stash in %s", result.RepoPath))
+			}
+			if result.ResetFrom != "" {
+				log.PrintWarning(fmt.Sprintf("Reset %s to the recorded commit %s in %s; it was at %s", result.Branch, shortSHA(result.Commit), result.RepoPath, shortSHA(result.ResetFrom)))
+			} else if result.CommitRestored {
+				log.PrintSuccess(fmt.Sprintf("Restored the recorded commit in %s", result.RepoPath))
+			}
+			for _, branch := range result.Branches {
+				if branch.Recreated {
+					log.PrintSuccess(fmt.Sprintf("Recreated branch %s at the recorded commit %s in %s", branch.Branch, shortSHA(branch.Commit), result.RepoPath))
+				} else if branch.From != "" {
+					log.PrintWarning(fmt.Sprintf("Reset branch %s to the recorded commit %s in %s; it was at %s", branch.Branch, shortSHA(branch.Commit), result.RepoPath, shortSHA(branch.From)))
+				} else {
+					log.PrintSuccess(fmt.Sprintf("Restored the recorded commit of branch %s in %s", branch.Branch, result.RepoPath))
+				}
+			}
+			if result.StashesStored > 0 {
+				log.PrintSuccess(fmt.Sprintf("Put %d dropped stashes back into the stash list in %s", result.StashesStored, result.RepoPath))
+			}
+			if result.PatchApplied {
+				log.PrintSuccess(fmt.Sprintf("Restored the recorded uncommitted changes in %s", result.RepoPath))
+			}
+			if result.RestoreErr != nil {
+				log.PrintErrorNoExit(log.ErrOperationFailed, fmt.Sprintf("Error restoring the recorded state in %s", result.RepoPath), result.RestoreErr)
+			}
+		}
+	}
+
+	return err
+}
@@ -4,26 +4,59 @@ package cmd
 import (
 	"fmt"
 	"os"
-	
+	"strings"
+	"time"
+
+	"git_cli_tool/git"
+	"git_cli_tool/gitexec"
+	"git_cli_tool/log"
+
 	"github.com/spf13/cobra"
 )
 
 // Global flags used across multiple commands
 var (
-	configFile string
+	configFile     string
+	streamOutput   bool
+	verbose        bool
+	quiet          bool
+	noColor        bool
+	nonInteractive bool
+	logFile        string
+	trace          bool
+	refetch        bool
 )
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
-	Use:   "git_cli_tool",
-	Short: "Switch branches in multiple Git repositories",
-	Long:  `A CLI tool that switches branches in multiple Git repositories based on a YAML configuration file.`,
+	Use:              "git_cli_tool",
+	Short:            "Switch branches in multiple Git repositories",
+	Long:             `A CLI tool that switches branches in multiple Git repositories based on a YAML configuration file.`,
+	PersistentPreRun: configureLogging,
 }
 
 // Initialize adds all child commands to the root command
 func Initialize() {
 	// Global flags
 	rootCmd.PersistentFlags().StringVarP(&configFile, "config", "c", "git_cli_tool.yml", "Path to configuration file")
+	rootCmd.PersistentFlags().StringSliceVar(&onlyRepos, "only", nil, "Only operate on repositories matching these names or path globs")
+	rootCmd.PersistentFlags().StringSliceVar(&excludeRepos, "exclude", nil, "Skip repositories matching these names or path globs")
+	rootCmd.PersistentFlags().StringSliceVar(&repoLabels, "label", nil, "Only operate on repositories carrying all of these labels")
+	rootCmd.PersistentFlags().StringVar(&repoTarget, "repo", "", "Only operate on the one repository with this name or path")
+	rootCmd.PersistentFlags().BoolVar(&useHere, "here", false, "Operate on the repository in the working directory, even if it is not configured; adds it to the selection when combined with --only, --exclude, --label or --repo")
+	rootCmd.PersistentFlags().BoolVar(&streamOutput, "stream", false, "Print output of parallel operations as it happens instead of grouped per repository")
+	rootCmd.PersistentFlags().BoolVar(&failFast, "fail-fast", false, "Stop processing further repositories as soon as one fails")
+	rootCmd.PersistentFlags().IntVarP(&jobs, "jobs", "j", 0, "Maximum number of repositories processed in parallel (0 = all at once)")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Do not use or update the cache of branch lists and ahead/behind counts")
+	rootCmd.PersistentFlags().BoolVar(&refetch, "refetch", false, "Fetch a repository every time it is needed instead of at most once per run")
+	rootCmd.PersistentFlags().BoolVar(&authRetry, "auth-retry", false, "Retry repositories that need credentials one at a time, letting git prompt for them")
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Show debug messages")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Only show warnings, errors and command results")
+	rootCmd.PersistentFlags().BoolVar(&trace, "trace", false, "Print every git command before it runs")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "Append a transcript of every git command, its output and exit code to this file")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colored output (also disabled by the NO_COLOR environment variable)")
+	rootCmd.PersistentFlags().BoolVar(&nonInteractive, "non-interactive", false, "Never prompt, fail instead; also disables colors and progress and streams output (default when CI is set)")
+	initCompletion()
 	
 	// Add all subcommands
 	initSwitchCmd()
@@ -33,8 +66,38 @@ func Initialize() {
 	initRevertCmd()
 	initPullCmd()
 	initPushCmd()
+	initPublishCmd()
 	initStatusCmd()
 	initSyncCmd()
+	initMergeCmd()
+	initConflictsCmd()
+	initGrepCmd()
+	initLogCmd()
+	initDiffCmd()
+	initChangedCmd()
+	initCherryPickCmd()
+	initBackportCmd()
+	initReleaseCmd()
+	initVersionCmd()
+	initPRCmd()
+	initHooksCmd()
+	initWatchCmd()
+	initServeCmd()
+	initRemoteCmd()
+	initBackupCmd()
+	initMaintenanceCmd()
+	initFsckCmd()
+	initFeatureCmd()
+	initResetCmd()
+	initCleanCmd()
+	initSnapshotCmd()
+	initStashCmd()
+	initBranchCmd()
+	initCheckoutTagCmd()
+	initCompareCmd()
+	initVerifyCmd()
+	initCommitCmd()
+	initVerifySignaturesCmd()
 	
 	// Add commands to root command
 	rootCmd.AddCommand(switchCmd)
@@ -44,8 +107,80 @@ func Initialize() {
 	rootCmd.AddCommand(revertCmd)
 	rootCmd.AddCommand(pullCmd)
 	rootCmd.AddCommand(pushCmd)
+	rootCmd.AddCommand(publishCmd)
 	rootCmd.AddCommand(statusCmd)
 	rootCmd.AddCommand(syncCmd)
+	rootCmd.AddCommand(mergeCmd)
+	rootCmd.AddCommand(conflictsCmd)
+	rootCmd.AddCommand(grepCmd)
+	rootCmd.AddCommand(logCmd)
+	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(changedCmd)
+	rootCmd.AddCommand(cherryPickCmd)
+	rootCmd.AddCommand(backportCmd)
+	rootCmd.AddCommand(releaseCmd)
+	rootCmd.AddCommand(featureCmd)
+	rootCmd.AddCommand(versionCmd)
+	rootCmd.AddCommand(prCmd)
+	rootCmd.AddCommand(hooksCmd)
+	rootCmd.AddCommand(watchCmd)
+	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(remoteCmd)
+	rootCmd.AddCommand(backupCmd)
+	rootCmd.AddCommand(maintenanceCmd)
+	rootCmd.AddCommand(fsckCmd)
+	rootCmd.AddCommand(resetCmd)
+	rootCmd.AddCommand(cleanCmd)
+	rootCmd.AddCommand(snapshotCmd)
+	rootCmd.AddCommand(stashCmd)
+	rootCmd.AddCommand(branchCmd)
+	rootCmd.AddCommand(checkoutTagCmd)
+	rootCmd.AddCommand(compareCmd)
+	rootCmd.AddCommand(verifyCmd)
+	rootCmd.AddCommand(commitCmd)
+	rootCmd.AddCommand(verifySignaturesCmd)
+}
+
+// configureLogging sets up colors, prompts and the log level from the global output flags
+func configureLogging(cmd *cobra.Command, args []string) {
+	// CI systems set CI; a prompt there would wait forever
+	if os.Getenv("CI") != "" && !cmd.Flags().Changed("non-interactive") {
+		nonInteractive = true
+	}
+	if nonInteractive {
+		log.SetInteractive(false)
+		streamOutput = true
+	}
+	log.ConfigureColor(noColor || nonInteractive)
+
+	if verbose && quiet {
+		log.PrintError(log.ErrInvalidArgument, "--verbose and --quiet cannot be combined", nil)
+	}
+	if verbose {
+		log.SetLevel(log.LevelDebug)
+	} else if quiet {
+		log.SetLevel(log.LevelWarn)
+	}
+
+	gitexec.SetTrace(trace)
+	git.SetRefetch(refetch)
+	commandName = strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+	jobsFromFlag = cmd.Flags().Changed("jobs")
+	if logFile != "" {
+		openTranscript(logFile)
+	}
+}
+
+// openTranscript appends a transcript of all git commands of this run to a file
+func openTranscript(path string) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		log.PrintError(log.ErrLogFileFailed, "Failed to open log file", err)
+	}
+
+	// Each run starts with the command line, so runs appended to the same file can be told apart
+	fmt.Fprintf(file, "=== %s: %s ===\n\n", time.Now().Format(time.RFC3339), strings.Join(os.Args, " "))
+	gitexec.SetTranscript(file)
 }
 
 // Execute executes the root command
@@ -0,0 +1,269 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"git_cli_tool/config"
+	"git_cli_tool/git"
+	"git_cli_tool/gitexec"
+	"git_cli_tool/log"
+)
+
+// Repository selection flags shared by all commands
+var (
+	onlyRepos    []string
+	excludeRepos []string
+	repoLabels   []string
+	repoTarget   string
+	useHere      bool
+)
+
+// loadConfig reads the configuration file, exiting on failure
+func loadConfig() *config.Configuration {
+	// With --here, the tool also works in a repository without any configuration
+	if _, err := os.Stat(configFile); useHere && os.IsNotExist(err) {
+		return &config.Configuration{}
+	}
+
+	configObj, err := config.ReadConfig(configFile)
+	if err != nil {
+		log.PrintError(log.ErrConfigReadFailed, "Error reading config", err)
+		os.Exit(1)
+	}
+
+	// The log file can also be set in the configuration; --log-file takes precedence
+	if logFile == "" && configObj.LogFile != "" {
+		logFile = configObj.LogFile
+		openTranscript(logFile)
+	}
+
+	if configObj.GitBinary != "" {
+		if _, err := exec.LookPath(configObj.GitBinary); err != nil {
+			log.PrintError(log.ErrConfigParseFailed, "Invalid git_binary", err)
+		}
+		gitexec.SetBinary(configObj.GitBinary)
+	}
+	gitexec.SetGlobalArgs(configObj.GitGlobalArgs)
+	gitexec.SetRemoteConfig(proxyConfig(configObj.Proxy))
+	configureCache(configObj)
+	applyRunDefaults(configObj)
+	git.SetFetchOptions(git.FetchOptions{
+		Prune:      configObj.Fetch.PruneEnabled(),
+		AllRemotes: configObj.Fetch.AllRemotes,
+		Tags:       configObj.Fetch.Tags,
+	})
+
+	// Paths written for the other side of WSL that could not be translated
+	for _, repo := range configObj.FlattenRepositories() {
+		if problem := config.ForeignPath(repo.Path); problem != "" {
+			if _, err := os.Stat(repo.Path); err != nil {
+				log.PrintError(log.ErrRepoInvalidPath, fmt.Sprintf("Repository path %s is %s", repo.Path, problem), nil)
+			}
+		}
+	}
+	return configObj
+}
+
+// proxyConfig returns the git configuration that sends remote HTTP(S) traffic
+// through the configured proxies, without changing the configuration of the
+// repositories
+func proxyConfig(proxy config.ProxyConfig) []gitexec.ConfigEntry {
+	var entries []gitexec.ConfigEntry
+	if proxy.URL != "" {
+		entries = append(entries, gitexec.ConfigEntry{Key: "http.proxy", Value: proxy.URL})
+	}
+
+	hosts := make([]string, 0, len(proxy.Hosts))
+	for host := range proxy.Hosts {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	for _, host := range hosts {
+		// http.<url>.proxy applies to remotes whose URL starts with <url>
+		urls := []string{"https://" + host, "http://" + host}
+		if strings.Contains(host, "://") {
+			urls = []string{host}
+		}
+		for _, url := range urls {
+			entries = append(entries, gitexec.ConfigEntry{Key: "http." + url + ".proxy", Value: proxy.Hosts[host]})
+		}
+	}
+	return entries
+}
+
+// configuredPaths returns the paths of the enabled repositories of the
+// configuration, recorded with each history state so that revert can tell the
+// repositories a command left out from the ones added since. It is empty if
+// the configuration cannot be read, e.g. with --here.
+func configuredPaths() []string {
+	configObj, err := config.ReadConfig(configFile)
+	if err != nil {
+		return nil
+	}
+	var paths []string
+	for _, repo := range configObj.FlattenRepositories() {
+		paths = append(paths, repo.Path)
+	}
+	return paths
+}
+
+// loadRepositories reads the configuration file and returns it together with
+// the enabled repositories selected by the --only, --exclude, --label and --repo flags.
+// Exits if no repositories are left. With --here, the repository in the
+// working directory is used instead, see hereRepositories.
+func loadRepositories() (*config.Configuration, []config.Repository) {
+	configObj := loadConfig()
+	if useHere {
+		return configObj, hereRepositories(configObj)
+	}
+
+	repositories := configObj.FlattenRepositories()
+	if len(repositories) == 0 {
+		log.PrintError(log.ErrNoConfigRepos, "No repositories found in the configuration file", nil)
+		os.Exit(1)
+	}
+
+	repositories = filterRepositories(repositories)
+	if len(repositories) == 0 && repoTarget != "" {
+		log.PrintError(log.ErrRepoNotFound, fmt.Sprintf("No selected repository is named %s or located there", repoTarget), nil)
+	}
+	if len(repositories) == 0 {
+		log.PrintError(log.ErrNoConfigRepos, "No repositories match the --only/--exclude/--label filters", nil)
+		os.Exit(1)
+	}
+
+	// --repo names exactly one repository
+	if repoTarget != "" && len(repositories) > 1 {
+		paths := make([]string, len(repositories))
+		for i, repo := range repositories {
+			paths[i] = repo.Path
+		}
+		log.PrintError(log.ErrRepoNotFound, fmt.Sprintf("--repo %s names %d repositories (%s); pass an alias or path instead", repoTarget, len(repositories), strings.Join(paths, ", ")), nil)
+	}
+
+	return configObj, repositories
+}
+
+// hereRepositories returns the repositories --here selects: the repository
+// the working directory is in, configured or not. Combined with the other
+// selection flags, it is added to the configured repositories they select.
+func hereRepositories(configObj *config.Configuration) []config.Repository {
+	root, err := git.FindRepositoryRoot(".")
+	if err != nil {
+		log.PrintError(log.ErrRepoNotGit, "--here needs the working directory to be inside a git repository", err)
+	}
+	here, _ := configObj.RepositoryAt(root)
+	here.Disabled = false
+
+	if len(onlyRepos) == 0 && len(excludeRepos) == 0 && len(repoLabels) == 0 && repoTarget == "" {
+		return []config.Repository{here}
+	}
+	repositories := filterRepositories(configObj.FlattenRepositories())
+	for _, repo := range repositories {
+		if repo.Path == here.Path {
+			return repositories
+		}
+	}
+	return append(repositories, here)
+}
+
+// isTarget reports whether a repository is the one --repo names: by its
+// name, its folder name, or its path, relative to the working directory or
+// absolute. Unlike --only, no wildcards are allowed.
+func isTarget(repo config.Repository, target string) bool {
+	if repo.Name() == target || filepath.Base(repo.Path) == target {
+		return true
+	}
+	targetPath, err := filepath.Abs(target)
+	if err != nil {
+		return false
+	}
+	repoPath, err := filepath.Abs(repo.Path)
+	return err == nil && repoPath == targetPath
+}
+
+// filterRepositories applies the selection flags to a list of repositories
+func filterRepositories(repositories []config.Repository) []config.Repository {
+	var selected []config.Repository
+	for _, repo := range repositories {
+		if isSelected(repo) {
+			selected = append(selected, repo)
+		}
+	}
+	return selected
+}
+
+// isSelected reports whether a repository passes the --only, --exclude, --label
+// and --repo flags. Patterns match the repository name or its path and may
+// contain glob wildcards; a repository must carry all labels given with --label.
+func isSelected(repo config.Repository) bool {
+	if repoTarget != "" && !isTarget(repo, repoTarget) {
+		return false
+	}
+	if len(onlyRepos) > 0 && !matchesAny(repo, onlyRepos) {
+		return false
+	}
+	if !repo.HasLabels(repoLabels) {
+		return false
+	}
+	return !matchesAny(repo, excludeRepos)
+}
+
+// newCollector creates an output collector that prints the repositories in
+// configuration order, or streams output live when --stream is set
+func newCollector(repositories []config.Repository) *log.Collector {
+	keys := make([]string, len(repositories))
+	for i, repo := range repositories {
+		keys[i] = repo.Path
+	}
+	return log.NewCollector(keys, streamOutput)
+}
+
+// matchesAny reports whether the repository matches any of the patterns
+func matchesAny(repo config.Repository, patterns []string) bool {
+	for _, pattern := range patterns {
+		if repo.Matches(pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// deselectRepositories shows the repositories a destructive command is about to
+// change as a checklist with all of them checked, so that single repositories
+// can be left out before confirming. details describes the change in each
+// repository and plans, if not nil, holds per-repository data that is filtered
+// along with the repositories. Without a terminal to ask on nothing is asked
+// and the repositories are returned unchanged.
+func deselectRepositories[T any](question string, repositories []config.Repository, details []string, plans []T) ([]config.Repository, []T) {
+	if !log.CanPrompt() {
+		return repositories, plans
+	}
+
+	options := make([]string, len(repositories))
+	for i, repo := range repositories {
+		options[i] = fmt.Sprintf("%-30s %s", repo.Name(), details[i])
+	}
+	checked, err := log.SelectMany(question, options)
+	if err != nil {
+		log.PrintError(log.ErrPromptRequired, "Failed to read the selected repositories", err)
+	}
+
+	var selected []config.Repository
+	var selectedPlans []T
+	for i, repo := range repositories {
+		if !checked[i] {
+			continue
+		}
+		selected = append(selected, repo)
+		if plans != nil {
+			selectedPlans = append(selectedPlans, plans[i])
+		}
+	}
+	return selected, selectedPlans
+}
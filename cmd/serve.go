@@ -0,0 +1,393 @@
+package cmd
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"git_cli_tool/config"
+	"git_cli_tool/engine"
+	"git_cli_tool/git"
+	"git_cli_tool/log"
+
+	"github.com/spf13/cobra"
+)
+
+const defaultServeListen = ":8080"
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Expose repository status and operations over a REST API",
+	Long: `Start an HTTP server that reports the state of the workspace and can
+trigger switch, pull and sync, for dashboards and chat bots.
+
+Every request must send the shared token from the configuration as
+"Authorization: Bearer <token>". The server refuses to start without a token.
+Only one switch, pull or sync runs at a time; a second one gets 409 Conflict.
+
+Endpoints:
+  GET  /api/status     status of every repository
+  GET  /api/branches   current, local and remote branches of every repository
+  POST /api/switch     {"branches": ["feature/x", "main"], "autostash": "name"}
+                       (branches default to switch_branches_fallback)
+  POST /api/pull       pull every repository
+  POST /api/sync       {"branch": "feature/x"}
+
+Example config:
+  serve:
+    listen: "127.0.0.1:8080"
+    token_env: "GIT_CLI_TOOL_TOKEN"   # or token: "..."
+
+Example:
+  git_cli_tool serve
+  git_cli_tool serve --listen :9090
+  curl -H "Authorization: Bearer $GIT_CLI_TOOL_TOKEN" localhost:8080/api/status`,
+	Args: cobra.NoArgs,
+	Run:  runServeCmd,
+}
+
+var serveListen string
+
+// initServeCmd initializes the serve command with its flags
+func initServeCmd() {
+	serveCmd.Flags().StringVar(&serveListen, "listen", "", "Address to listen on (default from serve.listen, or "+defaultServeListen+")")
+}
+
+// server holds the state shared by the HTTP handlers
+type server struct {
+	config       *config.Configuration
+	repositories []config.Repository
+	token        string
+	busy         sync.Mutex // held while an operation changes the repositories
+}
+
+// operationResult is the outcome of an operation in a single repository
+type operationResult struct {
+	Repository string `json:"repository"`
+	Success    bool   `json:"success"`
+	Message    string `json:"message"`
+	ErrorCode  string `json:"error_code,omitempty"` // code from log/errors.go, set when it failed
+}
+
+// operationResponse is returned by the switch, pull and sync endpoints
+type operationResponse struct {
+	Operation string            `json:"operation"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+	Results   []operationResult `json:"results"`
+}
+
+// branchList holds the branches of a repository
+type branchList struct {
+	Repository string   `json:"repository"`
+	Path       string   `json:"path"`
+	Current    string   `json:"current"`
+	Local      []string `json:"local"`
+	Remote     []string `json:"remote"`
+	Error      string   `json:"error,omitempty"`
+	ErrorCode  string   `json:"error_code,omitempty"` // code from log/errors.go, set with Error
+}
+
+// runServeCmd is the main function for the serve command
+func runServeCmd(cmd *cobra.Command, args []string) {
+	configObj, repositories := loadRepositories()
+
+	token := configObj.Serve.Token
+	if configObj.Serve.TokenEnv != "" {
+		token = os.Getenv(configObj.Serve.TokenEnv)
+	}
+	if token == "" {
+		log.PrintError(log.ErrInvalidArgument, "serve requires a token: set serve.token or serve.token_env in the configuration", nil)
+	}
+
+	listen := serveListen
+	if listen == "" {
+		listen = configObj.Serve.Listen
+	}
+	if listen == "" {
+		listen = defaultServeListen
+	}
+
+	s := &server{config: configObj, repositories: repositories, token: token}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/status", s.handleStatus)
+	mux.HandleFunc("/api/branches", s.handleBranches)
+	mux.HandleFunc("/api/switch", s.handleSwitch)
+	mux.HandleFunc("/api/pull", s.handlePull)
+	mux.HandleFunc("/api/sync", s.handleSync)
+
+	log.PrintOperation(fmt.Sprintf("Serving %d repositories on %s", len(repositories), listen))
+	httpServer := &http.Server{
+		Addr:              listen,
+		Handler:           s.authenticate(forgetFetches(mux)),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	if err := httpServer.ListenAndServe(); err != nil {
+		log.PrintError(log.ErrServeFailed, "Failed to start the server", err)
+	}
+}
+
+// authenticate rejects requests that do not carry the shared token and logs the others
+func (s *server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			log.PrintWarning(fmt.Sprintf("%s %s from %s: unauthorized", r.Method, r.URL.Path, r.RemoteAddr))
+			writeJSONError(w, http.StatusUnauthorized, "missing or invalid token")
+			return
+		}
+		log.PrintInfo(fmt.Sprintf("%s %s from %s", r.Method, r.URL.Path, r.RemoteAddr))
+		next.ServeHTTP(w, r)
+	})
+}
+
+// forgetFetches makes every request fetch the repositories again. Within a
+// request, each repository is fetched only once, like in a command line run,
+// but the server runs for a long time and must not report the remote state
+// it fetched for an earlier request.
+func forgetFetches(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		git.ForgetFetches()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleStatus reports the status of every repository
+func (s *server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodGet) {
+		return
+	}
+	writeJSON(w, http.StatusOK, collectStatuses(s.repositories, true))
+}
+
+// handleBranches reports the current, local and remote branches of every repository
+func (s *server) handleBranches(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	lists := make([]branchList, len(s.repositories))
+	engine.ForEachRepository(s.repositories, parallelOptions(), func(i int, repo config.Repository) error {
+		lists[i] = branchList{Repository: repo.Name(), Path: repo.Path}
+		current, err := git.GetCurrentBranch(repo.Path)
+		if err == nil {
+			lists[i].Current = current
+			lists[i].Local, lists[i].Remote, err = git.ListBranches(repo.Path, repo.Remote)
+		}
+		if err != nil {
+			lists[i].Error = strings.TrimSpace(err.Error())
+			lists[i].ErrorCode = errorCode(err)
+		}
+		return err
+	})
+	writeJSON(w, http.StatusOK, lists)
+}
+
+// handleSwitch switches every repository to the first existing branch of the requested list
+func (s *server) handleSwitch(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Branches  []string `json:"branches"`
+		Autostash string   `json:"autostash"`
+	}
+	if !s.beginOperation(w, r, &request) {
+		return
+	}
+	defer s.busy.Unlock()
+
+	configBranches := s.config.SwitchBranchesFallback
+	if len(configBranches) == 0 {
+		configBranches = s.config.Branches // backwards compatibility
+	}
+	if len(request.Branches) == 0 && len(configBranches) == 0 {
+		writeJSONError(w, http.StatusBadRequest, "no branches requested and none configured")
+		return
+	}
+
+	branchesFor := func(repo config.Repository) []string {
+		if len(request.Branches) > 0 {
+			return repo.MapBranches(request.Branches)
+		}
+		return switchOrderFor(s.config, repo, configBranches)
+	}
+
+	// Record the state before the switch so it can be reverted from the command line
+	if s.config.RecordHistory {
+		if _, history, err := config.ReadHistory(); err == nil || os.IsNotExist(err) {
+			if state, err := collectCurrentState(s.repositories); err == nil {
+				config.SaveStateToHistory(state, history)
+			}
+		}
+	}
+
+	response := operationResponse{Operation: "switch"}
+	for _, result := range engine.SwitchRepositories(s.repositories, branchesFor, request.Autostash, configStashOptions(s.config), parallelOptions()) {
+		message := fmt.Sprintf("%s → %s", result.FromBranch, result.ToBranch)
+		code := ""
+		switch {
+		case !result.Success:
+			message = result.Message
+			code = errorCode(result.Err)
+			if code == "" {
+				code = log.ErrGitCheckoutFailed
+			}
+		case result.HookErr != nil:
+			message += fmt.Sprintf(", but %v", result.HookErr)
+			code = log.ErrHookFailed
+		case result.AlreadyOnIt:
+			message = "already on " + result.ToBranch
+		}
+		response.add(result.RepoName, code, message)
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handlePull pulls every repository
+func (s *server) handlePull(w http.ResponseWriter, r *http.Request) {
+	if !s.beginOperation(w, r, nil) {
+		return
+	}
+	defer s.busy.Unlock()
+
+	results := make([]engine.PullResult, len(s.repositories))
+	engine.ForEachRepository(s.repositories, parallelOptions(), func(i int, repo config.Repository) error {
+		results[i] = engine.PullRepository(repo)
+		return results[i].Err
+	})
+
+	response := operationResponse{Operation: "pull"}
+	for i, result := range results {
+		message := strings.TrimSpace(result.Output)
+		var err error
+		code := ""
+		switch {
+		case errors.Is(result.Err, git.ErrAuthFailed):
+			err, code = result.Err, log.ErrGitAuthFailed
+		case result.Err != nil:
+			err, code = result.Err, log.ErrGitPullFailed
+		case result.HookErr != nil:
+			err, code = result.HookErr, log.ErrHookFailed
+		}
+		if err != nil {
+			message = strings.TrimSpace(fmt.Sprintf("%v\n%s", err, result.Output))
+		}
+		response.add(s.repositories[i].Name(), code, message)
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleSync syncs the requested branch with its parent in every repository
+func (s *server) handleSync(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Branch string `json:"branch"`
+	}
+	if !s.beginOperation(w, r, &request) {
+		return
+	}
+	defer s.busy.Unlock()
+
+	if request.Branch == "" {
+		writeJSONError(w, http.StatusBadRequest, "branch is required")
+		return
+	}
+
+	parentBranches, fallbackBranch, mergeOpts := syncSources(s.config, request.Branch)
+	results := make([]engine.SyncResult, len(s.repositories))
+	engine.ForEachRepository(s.repositories, parallelOptions(), func(i int, repo config.Repository) error {
+		results[i] = engine.SyncRepository(serverLogger{}, repo, repo.MapBranch(request.Branch), repo.MapBranches(parentBranches), fallbackBranchFor(fallbackBranch, repo), mergeOpts)
+		return nil
+	})
+
+	response := operationResponse{Operation: "sync"}
+	for _, result := range results {
+		message := result.Message
+		if kept := keptMerges(result); len(kept) > 0 {
+			message += "; " + strings.Join(kept, "; ")
+		}
+		code := ""
+		switch {
+		case !result.Success && result.Conflict:
+			code = log.ErrGitConflict
+		case !result.Success:
+			code = log.ErrOperationFailed
+		default:
+			message = strings.Join(syncSummary(result), "; ")
+			if result.HookErr != nil {
+				message += fmt.Sprintf(", but %v", result.HookErr)
+				code = log.ErrHookFailed
+			}
+		}
+		response.add(result.RepoName, code, message)
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// beginOperation checks the method, decodes the optional JSON body into request
+// and takes the operation lock. It writes the error response and returns false
+// when the operation cannot start; otherwise the caller must release s.busy.
+func (s *server) beginOperation(w http.ResponseWriter, r *http.Request, request interface{}) bool {
+	if !requireMethod(w, r, http.MethodPost) {
+		return false
+	}
+	if request != nil && r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid JSON body: "+err.Error())
+			return false
+		}
+	}
+	if !s.busy.TryLock() {
+		writeJSONError(w, http.StatusConflict, "another operation is already running")
+		return false
+	}
+	return true
+}
+
+// add appends the result of a repository and updates the counts. The
+// repository failed if it has an error code.
+func (o *operationResponse) add(repoName string, errorCode string, message string) {
+	if errorCode == "" {
+		o.Succeeded++
+	} else {
+		o.Failed++
+	}
+	o.Results = append(o.Results, operationResult{Repository: repoName, Success: errorCode == "", Message: message, ErrorCode: errorCode})
+}
+
+// serverLogger forwards the progress messages of an operation to the debug log
+type serverLogger struct{}
+
+func (serverLogger) PrintDebug(message string) {
+	log.PrintDebug(message)
+}
+
+// requireMethod rejects requests that do not use the given method
+func requireMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method != method {
+		w.Header().Set("Allow", method)
+		writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return false
+	}
+	return true
+}
+
+// writeJSON writes value as an indented JSON response
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(value)
+}
+
+// writeJSONError writes an error response of the form {"error": message}
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
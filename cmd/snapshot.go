@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"git_cli_tool/config"
+	"git_cli_tool/engine"
+	"git_cli_tool/log"
+
+	"github.com/spf13/cobra"
+)
+
+// snapshotCmd represents the snapshot command
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Record named snapshots of all repositories in the branch history",
+}
+
+// snapshotSaveCmd represents the snapshot save command
+var snapshotSaveCmd = &cobra.Command{
+	Use:   "save <name>",
+	Short: "Save the branch and HEAD of all repositories as a named snapshot",
+	Long: `Record the current branch and HEAD commit of every repository in the branch
+history under a name. Restore it with:
+  git_cli_tool revert <name>
+
+With --with-diff, the uncommitted changes of every dirty repository,
+including untracked files, are also written as patch files to
+git_cli_tool-patches/<timestamp>-<name>/ next to the history file. Unlike
+stashes, the patches are plain files that can be inspected and copied;
+revert re-applies them. The working trees are not changed.
+
+Example:
+  git_cli_tool snapshot save before-upgrade
+  git_cli_tool snapshot save wip --with-diff
+  git_cli_tool snapshot save login-review --ticket JIRA-1234`,
+	Args: cobra.ExactArgs(1),
+	Run:  runSnapshotSaveCmd,
+}
+
+var snapshotWithDiff bool
+
+// initSnapshotCmd initializes the snapshot command and its subcommands
+func initSnapshotCmd() {
+	snapshotSaveCmd.Flags().BoolVar(&snapshotWithDiff, "with-diff", false, "Also save uncommitted changes, including untracked files, as patch files")
+	snapshotSaveCmd.Flags().StringVar(&historyTicket, "ticket", "", "Ticket ID stored with the snapshot, e.g. JIRA-1234")
+
+	snapshotCmd.AddCommand(snapshotSaveCmd)
+}
+
+// runSnapshotSaveCmd is the main function for the snapshot save command
+func runSnapshotSaveCmd(cmd *cobra.Command, args []string) {
+	name := args[0]
+	if _, err := strconv.Atoi(name); err == nil || strings.ContainsAny(name, `/\`) {
+		log.PrintError(log.ErrInvalidArgument, fmt.Sprintf("Invalid snapshot name '%s': it must not be a number or contain path separators", name), nil)
+	}
+
+	_, repositories := loadRepositories()
+	now := time.Now()
+
+	patchDir := ""
+	if snapshotWithDiff {
+		var err error
+		if patchDir, err = config.SnapshotPatchDir(name, now); err != nil {
+			log.PrintError(log.ErrHistoryStateFailed, "Failed to create the patch directory", err)
+		}
+	}
+
+	log.PrintOperation(fmt.Sprintf("Saving snapshot '%s'", name))
+	log.PrintInfo("")
+
+	repoStates := make([]config.RepositoryState, len(repositories))
+	errs := engine.ForEachRepository(repositories, engine.ParallelOptions{Jobs: jobs}, func(i int, r config.Repository) error {
+		var err error
+		repoStates[i], err = engine.SnapshotRepository(r, patchDir)
+		return err
+	})
+
+	state := &config.BranchState{
+		Timestamp:    now.Format(time.RFC3339),
+		Name:         name,
+		Description:  "saved with 'snapshot save'",
+		Ticket:       historyTicket,
+		Repositories: make(map[string]config.RepositoryState),
+		Configured:   configuredPaths(),
+	}
+	failCount := 0
+	patchCount := 0
+	for i, repo := range repositories {
+		if errs[i] != nil {
+			failCount++
+			log.PrintErrorNoExit("", fmt.Sprintf("%-30s %v", repo.Name(), errs[i]), nil)
+			continue
+		}
+		state.Repositories[repo.Path] = repoStates[i]
+
+		line := fmt.Sprintf("%-30s %s (%s)", repo.Name(), repoStates[i].Branch, shortSHA(repoStates[i].Commit))
+		if repoStates[i].Patch != "" {
+			patchCount++
+			line += " [CHANGES SAVED]"
+		}
+		log.PrintSuccess(line)
+	}
+
+	// A partial snapshot could not restore the workspace, so it is not recorded
+	if failCount > 0 {
+		if patchDir != "" {
+			os.RemoveAll(patchDir)
+		}
+		log.PrintInfo("")
+		log.PrintError(log.ErrHistoryStateFailed, fmt.Sprintf("%d repositories could not be recorded, no snapshot saved", failCount), nil)
+	}
+
+	_, history, err := config.ReadHistory()
+	if err != nil && !os.IsNotExist(err) {
+		log.PrintError(log.ErrHistoryReadFailed, "Error loading branch history", err)
+	}
+	if err := config.SaveStateToHistory(state, history); err != nil {
+		log.PrintError(log.ErrHistoryStateFailed, "Error saving snapshot", err)
+	}
+
+	log.PrintInfo("")
+	if patchDir != "" {
+		log.PrintInfo(fmt.Sprintf("Saved uncommitted changes of %d repositories to %s", patchCount, patchDir))
+	}
+	log.PrintSuccess(fmt.Sprintf("Snapshot '%s' saved, restore it with 'git_cli_tool revert %s'", name, name))
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+
+	"git_cli_tool/config"
+	"git_cli_tool/engine"
+	"git_cli_tool/git"
+	"git_cli_tool/log"
+
+	"github.com/spf13/cobra"
+)
+
+// stashCmd represents the stash command
+var stashCmd = &cobra.Command{
+	Use:   "stash",
+	Short: "Stash changes across all repositories",
+}
+
+// stashPushCmd represents the stash push command
+var stashPushCmd = &cobra.Command{
+	Use:   "push <name> [pathspec...]",
+	Short: "Stash the changes of all repositories under a name",
+	Long: `Stash the uncommitted changes of every repository with the message
+"GitSwitch: <name>", like the autostash of the switch command. By default
+untracked files are included.
+
+--tracked-only leaves untracked files alone, --keep-index keeps staged
+changes in place, and pathspecs limit the stash to matching paths. The
+defaults come from the stash section of the configuration.
+
+Example config:
+  stash:
+    tracked_only: true
+    pathspec: ["src/"]
+
+Example:
+  git_cli_tool stash push wip
+  git_cli_tool stash push wip --tracked-only --keep-index
+  git_cli_tool stash push generated -- api/generated/`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  runStashPushCmd,
+}
+
+var (
+	stashTrackedOnly bool
+	stashKeepIndex   bool
+	stashPathspec    []string
+)
+
+// initStashCmd initializes the stash command and its subcommands
+func initStashCmd() {
+	stashPushCmd.Flags().BoolVar(&stashTrackedOnly, "tracked-only", false, "Leave untracked files in the working tree (default from stash.tracked_only)")
+	stashPushCmd.Flags().BoolVar(&stashKeepIndex, "keep-index", false, "Keep staged changes in the index and working tree (default from stash.keep_index)")
+
+	stashCmd.AddCommand(stashPushCmd)
+}
+
+// runStashPushCmd is the main function for the stash push command
+func runStashPushCmd(cmd *cobra.Command, args []string) {
+	configObj, repositories := loadRepositories()
+	name := args[0]
+	stashPathspec = args[1:]
+	opts := stashOptions(cmd, configObj, "")
+
+	log.PrintOperation(fmt.Sprintf("Stashing changes as 'GitSwitch: %s'", name))
+	log.PrintInfo("")
+
+	stashCommits := make([]string, len(repositories))
+	errs := engine.ForEachRepository(repositories, parallelOptions(), func(i int, r config.Repository) error {
+		var err error
+		stashCommits[i], err = git.StashChanges(r.Path, name, opts)
+		return err
+	})
+
+	for i, repo := range repositories {
+		switch {
+		case errs[i] == engine.ErrSkipped:
+			log.PrintWarning(fmt.Sprintf("%-30s [SKIPPED: earlier failure]", repo.Name()))
+		case errs[i] != nil:
+			log.PrintErrorNoExit("", fmt.Sprintf("%-30s %v", repo.Name(), errs[i]), nil)
+		case stashCommits[i] == "":
+			log.PrintInfo(fmt.Sprintf("%-30s nothing to stash", repo.Name()))
+		default:
+			log.PrintSuccess(fmt.Sprintf("%-30s stashed %s", repo.Name(), shortSHA(stashCommits[i])))
+		}
+	}
+
+	log.PrintInfo("")
+	reportFailures("Stash", errs)
+}
+
+// configStashOptions returns the stash scope configured in the stash section
+func configStashOptions(configObj *config.Configuration) git.StashOptions {
+	return git.StashOptions{
+		TrackedOnly: configObj.Stash.TrackedOnly,
+		KeepIndex:   configObj.Stash.KeepIndex,
+		Pathspec:    configObj.Stash.Pathspec,
+	}
+}
+
+// stashOptions returns the configured stash scope, overridden by the stash
+// flags of cmd that were given. prefix is prepended to the flag names, e.g. "stash-".
+func stashOptions(cmd *cobra.Command, configObj *config.Configuration, prefix string) git.StashOptions {
+	opts := configStashOptions(configObj)
+	if cmd.Flags().Changed(prefix + "tracked-only") {
+		opts.TrackedOnly = stashTrackedOnly
+	}
+	if cmd.Flags().Changed(prefix + "keep-index") {
+		opts.KeepIndex = stashKeepIndex
+	}
+	if len(stashPathspec) > 0 {
+		opts.Pathspec = stashPathspec
+	}
+	return opts
+}
@@ -3,12 +3,12 @@ package cmd
 
 import (
 	"fmt"
-	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
 	"git_cli_tool/config"
+	"git_cli_tool/engine"
+	"git_cli_tool/git"
 	"git_cli_tool/log"
 
 	"github.com/spf13/cobra"
@@ -23,58 +23,99 @@ are ahead/behind their remote tracking branch, or have other notable states.
 
 This is a filtered view - clean repositories that are in sync are not shown.
 
+With --output json, csv or markdown, the same repositories are printed as a
+report (with the --long details) to paste into spreadsheets or wiki pages.
+
 Example:
   git_cli_tool status
-  git_cli_tool status --all   # Show all repositories, not just those with issues`,
+  git_cli_tool status --all     # Show all repositories, not just those with issues
+  git_cli_tool status --fetch   # Fetch (with prune) first so ahead/behind counts are current
+  git_cli_tool status --long    # Table with upstream and last commit details
+  git_cli_tool status --all --output csv > status.csv`,
 	Run: runStatusCmd,
 }
 
-var showAll bool
+var (
+	showAll     bool
+	fetchStatus bool
+	longStatus  bool
+)
 
 // initStatusCmd initializes the status command with its flags
 func initStatusCmd() {
 	statusCmd.Flags().BoolVar(&showAll, "all", false, "Show all repositories, not just those with issues")
+	statusCmd.Flags().BoolVar(&fetchStatus, "fetch", false, "Fetch all repositories before computing status (default from status.auto_fetch)")
+	statusCmd.Flags().BoolVar(&longStatus, "long", false, "Show an aligned table including upstream and last commit details")
+	addOutputFlag(statusCmd)
 }
 
 // RepoStatus holds the status information for a repository
 type RepoStatus struct {
-	Path            string
-	Branch          string
-	HasChanges      bool
-	UntrackedFiles  int
-	StagedChanges   int
-	UnstagedChanges int
-	Ahead           int
-	Behind          int
-	Error           string
+	Name            string `json:"name"` // alias or folder name
+	Path            string `json:"path"`
+	Branch          string `json:"branch"`
+	Detached        bool   `json:"detached"`       // HEAD is not on a branch
+	Head            string `json:"head,omitempty"` // short SHA of HEAD, set when detached
+	Tag             string `json:"tag,omitempty"`  // tag at HEAD, set when detached
+	HasChanges      bool   `json:"has_changes"`
+	UntrackedFiles  int    `json:"untracked_files"`
+	StagedChanges   int    `json:"staged_changes"`
+	UnstagedChanges int    `json:"unstaged_changes"`
+	Ahead           int    `json:"ahead"`
+	Behind          int    `json:"behind"`
+	InProgress      string `json:"in_progress,omitempty"` // merge, rebase, cherry-pick, revert or am left in progress
+	Conflicts       int    `json:"conflicts"`             // unmerged paths
+	Stashes         int    `json:"stashes"`               // all stashes
+	GitSwitchStash  int    `json:"gitswitch_stashes"`     // stashes created by GitSwitch
+	Upstream        string `json:"upstream,omitempty"`    // upstream tracking branch, if any
+	CommitSHA       string `json:"commit_sha,omitempty"`  // last commit, collected for --long
+	CommitAuthor    string `json:"commit_author,omitempty"`
+	CommitAge       string `json:"commit_age,omitempty"` // relative, e.g. "3 days ago"
+	Error           string `json:"error,omitempty"`
+	ErrorCode       string `json:"error_code,omitempty"` // code from log/errors.go, set with Error
+}
+
+// NeedsAttention reports whether the repository has changes, sync issues or errors
+func (s RepoStatus) NeedsAttention() bool {
+	return s.Error != "" || s.HasChanges || s.Ahead > 0 || s.Behind > 0 || s.InProgress != "" || s.Conflicts > 0 || s.Detached
 }
 
 // runStatusCmd is the main function for the status command
 func runStatusCmd(cmd *cobra.Command, args []string) {
-	configObj, err := config.ReadConfig(configFile)
-	if err != nil {
-		log.PrintError(log.ErrConfigReadFailed, "Error reading config", err)
-		os.Exit(1)
-	}
-
-	repositories := configObj.FlattenRepositories()
-	if len(repositories) == 0 {
-		log.PrintError(log.ErrNoConfigRepos, "No repositories found in the configuration file", nil)
-		os.Exit(1)
+	report := beginReport()
+	configObj, repositories := loadRepositories()
+
+	// Refresh remote tracking info so ahead/behind counts are not stale
+	if fetchStatus || (configObj.Status.AutoFetch && !cmd.Flags().Changed("fetch")) {
+		log.PrintOperation("Fetching from remotes...")
+		errs := engine.FetchRepositories(repositories, parallelOptions())
+		for i, err := range errs {
+			if err != nil && err != engine.ErrSkipped {
+				log.PrintWarning(fmt.Sprintf("%-30s fetch failed, status may be stale: %v", repositories[i].Name(), err))
+			}
+		}
+		printAuthHint(errs)
 	}
 
 	log.PrintOperation("Checking repository status...")
 
-	var statuses []RepoStatus
-	issueCount := 0
+	statuses := collectStatuses(repositories, longStatus || report)
 
-	for _, repo := range repositories {
-		status := getRepoStatus(repo.Path)
-		statuses = append(statuses, status)
-
-		if status.Error != "" || status.HasChanges || status.Ahead > 0 || status.Behind > 0 {
+	issueCount := 0
+	shown := []RepoStatus{}
+	for _, status := range statuses {
+		if status.NeedsAttention() {
 			issueCount++
 		}
+		if showAll || status.NeedsAttention() {
+			shown = append(shown, status)
+		}
+	}
+
+	if report {
+		headers, rows := statusTable(shown)
+		printReport(headers, rows, shown)
+		return
 	}
 
 	// Print results
@@ -84,14 +125,13 @@ func runStatusCmd(cmd *cobra.Command, args []string) {
 	}
 
 	log.PrintInfo("")
-	for _, status := range statuses {
-		hasIssue := status.Error != "" || status.HasChanges || status.Ahead > 0 || status.Behind > 0
 
-		if !showAll && !hasIssue {
-			continue
+	if longStatus {
+		printStatusTable(shown)
+	} else {
+		for _, status := range shown {
+			printRepoStatus(status)
 		}
-
-		printRepoStatus(status)
 	}
 
 	log.PrintInfo("")
@@ -102,79 +142,80 @@ func runStatusCmd(cmd *cobra.Command, args []string) {
 	}
 }
 
-func getRepoStatus(repoPath string) RepoStatus {
-	absPath, err := filepath.Abs(repoPath)
+// collectStatuses collects the status of all repositories concurrently, in configuration order
+func collectStatuses(repositories []config.Repository, withDetails bool) []RepoStatus {
+	statuses := make([]RepoStatus, len(repositories))
+	engine.ForEachRepository(repositories, engine.ParallelOptions{Jobs: jobs}, func(i int, r config.Repository) error {
+		statuses[i] = getRepoStatus(r, withDetails)
+		return nil
+	})
+	return statuses
+}
+
+// getRepoStatus collects the status of a repository. withDetails also collects
+// the last commit information shown by --long.
+func getRepoStatus(repo config.Repository, withDetails bool) RepoStatus {
+	absPath, err := filepath.Abs(repo.Path)
 	if err != nil {
-		return RepoStatus{Path: repoPath, Error: "failed to resolve path"}
+		return RepoStatus{Name: repo.Name(), Path: repo.Path, Error: "failed to resolve path", ErrorCode: log.ErrRepoInvalidPath}
 	}
 
-	status := RepoStatus{Path: absPath}
+	status := RepoStatus{Name: repo.Name(), Path: absPath}
 
 	// Check if it's a git repository
-	if _, err := os.Stat(filepath.Join(absPath, ".git")); os.IsNotExist(err) {
+	if err := git.ValidateRepository(absPath); err != nil {
 		status.Error = "not a git repository"
+		status.ErrorCode = log.ErrRepoNotGit
 		return status
 	}
 
-	// Get current branch
-	branchCmd := exec.Command("git", "-C", absPath, "rev-parse", "--abbrev-ref", "HEAD")
-	branchOutput, err := branchCmd.CombinedOutput()
-	if err != nil {
-		status.Error = "failed to get branch"
-		return status
-	}
-	status.Branch = strings.TrimSpace(string(branchOutput))
-
-	// Get status --porcelain for changes
-	statusCmd := exec.Command("git", "-C", absPath, "status", "--porcelain")
-	statusOutput, err := statusCmd.CombinedOutput()
+	// Branch, upstream, ahead/behind and changes in a single invocation
+	treeStatus, err := workingTreeStatus(absPath)
 	if err != nil {
 		status.Error = "failed to get status"
+		status.ErrorCode = errorCode(err)
 		return status
 	}
 
-	// Parse status output
-	lines := strings.Split(strings.TrimSpace(string(statusOutput)), "\n")
-	for _, line := range lines {
-		if len(line) < 2 {
-			continue
+	status.Branch = treeStatus.Branch
+	status.Upstream = treeStatus.Upstream
+	status.Ahead = treeStatus.Ahead
+	status.Behind = treeStatus.Behind
+	status.HasChanges = treeStatus.HasChanges()
+	status.StagedChanges = treeStatus.StagedChanges
+	status.UnstagedChanges = treeStatus.UnstagedChanges
+	status.UntrackedFiles = treeStatus.UntrackedFiles
+	status.Conflicts = treeStatus.Conflicts
+
+	if treeStatus.Detached {
+		status.Branch = "HEAD"
+		status.Detached = true
+		status.Head = treeStatus.Head
+		if len(status.Head) > 7 {
+			status.Head = status.Head[:7]
 		}
-		status.HasChanges = true
-		indexStatus := line[0]
-		workTreeStatus := line[1]
+		status.Tag = git.GetExactTag(absPath)
+	}
 
-		if indexStatus == '?' {
-			status.UntrackedFiles++
-		} else if indexStatus != ' ' {
-			status.StagedChanges++
-		}
+	// Detect merges, rebases and cherry-picks that were stopped midway
+	status.InProgress, _ = git.GetOperationInProgress(absPath)
 
-		if workTreeStatus != ' ' && workTreeStatus != '?' {
-			status.UnstagedChanges++
-		}
-	}
+	// Count stashes so stashed work is not forgotten
+	status.Stashes, status.GitSwitchStash, _ = git.CountStashes(absPath)
 
-	// Get ahead/behind count
-	revListCmd := exec.Command("git", "-C", absPath, "rev-list", "--left-right", "--count", "@{upstream}...HEAD")
-	revOutput, err := revListCmd.CombinedOutput()
-	if err == nil {
-		parts := strings.Fields(strings.TrimSpace(string(revOutput)))
-		if len(parts) == 2 {
-			fmt.Sscanf(parts[0], "%d", &status.Behind)
-			fmt.Sscanf(parts[1], "%d", &status.Ahead)
-		}
+	if withDetails {
+		status.CommitSHA, status.CommitAuthor, status.CommitAge, _ = git.GetLastCommit(absPath)
 	}
-	// If error, it might not have an upstream - that's ok, leave ahead/behind as 0
 
 	return status
 }
 
 func printRepoStatus(status RepoStatus) {
 	// Get just the repo name for display
-	repoName := filepath.Base(status.Path)
+	repoName := status.Name
 
 	if status.Error != "" {
-		log.PrintErrorNoExit("", fmt.Sprintf("%-30s [ERROR: %s]", repoName, status.Error), nil)
+		log.PrintErrorNoExit(status.ErrorCode, fmt.Sprintf("%-30s [ERROR: %s]", repoName, status.Error), nil)
 		return
 	}
 
@@ -183,10 +224,28 @@ func printRepoStatus(status RepoStatus) {
 
 	// Branch info
 	branchInfo := fmt.Sprintf("on %s", status.Branch)
+	if status.Detached {
+		branchInfo = fmt.Sprintf("DETACHED HEAD at %s", status.Head)
+		if status.Tag != "" {
+			branchInfo = fmt.Sprintf("DETACHED HEAD at %s (%s)", status.Tag, status.Head)
+		}
+	}
 	parts = append(parts, branchInfo)
 
+	// In-progress operation and conflicts
+	if status.InProgress != "" || status.Conflicts > 0 {
+		var stateParts []string
+		if status.InProgress != "" {
+			stateParts = append(stateParts, strings.ToUpper(status.InProgress)+" IN PROGRESS")
+		}
+		if status.Conflicts > 0 {
+			stateParts = append(stateParts, fmt.Sprintf("%d conflicted", status.Conflicts))
+		}
+		parts = append(parts, strings.Join(stateParts, ", "))
+	}
+
 	// Changes info
-	if status.HasChanges {
+	if status.HasChanges && status.StagedChanges+status.UnstagedChanges+status.UntrackedFiles > 0 {
 		var changesParts []string
 		if status.StagedChanges > 0 {
 			changesParts = append(changesParts, fmt.Sprintf("%d staged", status.StagedChanges))
@@ -212,10 +271,82 @@ func printRepoStatus(status RepoStatus) {
 		parts = append(parts, strings.Join(syncParts, ", "))
 	}
 
+	// Stash info is shown but does not need attention by itself
+	stashInfo := ""
+	if status.Stashes > 0 {
+		stashInfo = fmt.Sprintf(" | %d stashed", status.Stashes)
+		if status.GitSwitchStash > 0 {
+			stashInfo += fmt.Sprintf(" (%d by GitSwitch)", status.GitSwitchStash)
+		}
+	}
+
 	// Determine color/status
-	if status.HasChanges || status.Ahead > 0 || status.Behind > 0 {
-		log.PrintWarning(fmt.Sprintf("%-30s %s", repoName, strings.Join(parts, " | ")))
+	if status.NeedsAttention() {
+		log.PrintWarning(fmt.Sprintf("%-30s %s%s", repoName, strings.Join(parts, " | "), stashInfo))
 	} else {
-		log.PrintSuccess(fmt.Sprintf("%-30s %s | clean", repoName, parts[0]))
+		log.PrintSuccess(fmt.Sprintf("%-30s %s | clean%s", repoName, parts[0], stashInfo))
+	}
+}
+
+// printStatusTable prints the statuses as an aligned table with upstream and last commit details
+func printStatusTable(statuses []RepoStatus) {
+	printTable(statusTable(statuses))
+}
+
+// statusTable returns the headers and rows of the status table
+func statusTable(statuses []RepoStatus) ([]string, [][]string) {
+	headers := []string{"REPOSITORY", "BRANCH", "CHANGES", "SYNC", "UPSTREAM", "COMMIT", "AUTHOR", "AGE"}
+	var rows [][]string
+
+	for _, status := range statuses {
+		repoName := status.Name
+		if status.Error != "" {
+			rows = append(rows, []string{repoName, "ERROR: " + status.Error, "", "", "", "", "", ""})
+			continue
+		}
+
+		branch := status.Branch
+		if status.Detached {
+			branch = "(detached " + status.Head + ")"
+			if status.Tag != "" {
+				branch = "(detached " + status.Tag + ")"
+			}
+		}
+
+		var changes []string
+		if status.InProgress != "" {
+			changes = append(changes, strings.ToUpper(status.InProgress))
+		}
+		if status.Conflicts > 0 {
+			changes = append(changes, fmt.Sprintf("%d conflicted", status.Conflicts))
+		}
+		if status.StagedChanges > 0 {
+			changes = append(changes, fmt.Sprintf("%d staged", status.StagedChanges))
+		}
+		if status.UnstagedChanges > 0 {
+			changes = append(changes, fmt.Sprintf("%d unstaged", status.UnstagedChanges))
+		}
+		if status.UntrackedFiles > 0 {
+			changes = append(changes, fmt.Sprintf("%d untracked", status.UntrackedFiles))
+		}
+		if status.Stashes > 0 {
+			changes = append(changes, fmt.Sprintf("%d stashed", status.Stashes))
+		}
+		if len(changes) == 0 {
+			changes = append(changes, "clean")
+		}
+
+		sync := "-"
+		if status.Upstream != "" {
+			sync = fmt.Sprintf("↑%d ↓%d", status.Ahead, status.Behind)
+		}
+
+		upstream := status.Upstream
+		if upstream == "" {
+			upstream = "(none)"
+		}
+
+		rows = append(rows, []string{repoName, branch, strings.Join(changes, ", "), sync, upstream, status.CommitSHA, status.CommitAuthor, status.CommitAge})
 	}
+	return headers, rows
 }
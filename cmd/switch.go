@@ -2,14 +2,15 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
-	"os/exec"
-	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	"git_cli_tool/config"
+	"git_cli_tool/engine"
 	"git_cli_tool/git"
 	"git_cli_tool/log"
 
@@ -20,7 +21,24 @@ var (
 	autostash          string
 	storeHistory       bool
 	historyDescription string
+	historyTicket      string
 	dryRun             bool
+	strictSwitch       bool
+	atomicSwitch       bool
+	reapplyStashes     bool
+	dropStashes        bool
+	detachSwitch       bool
+	fuzzySwitch        bool
+	forceSwitch        bool
+	onDirty            string
+	switchAutoInstall  bool
+)
+
+// Ways to handle repositories with uncommitted changes before a switch (--on-dirty)
+const (
+	onDirtyStash = "stash"
+	onDirtySkip  = "skip"
+	onDirtyFail  = "fail"
 )
 
 // switchCmd represents the switch command
@@ -28,6 +46,8 @@ var switchCmd = &cobra.Command{
 	Use:   "switch",
 	Short: "Switch branches based on configuration",
 	Run:   runSwitchCmd,
+
+	ValidArgsFunction: completeBranches,
 }
 
 // initSwitchCmd initializes the switch command with its flags
@@ -35,53 +55,110 @@ func initSwitchCmd() {
 	switchCmd.Flags().StringVarP(&autostash, "autostash", "a", "", "Stash changes with the provided name before switching branches")
 	switchCmd.Flags().BoolVar(&storeHistory, "store-history", true, "Store branch state in history before switching")
 	switchCmd.Flags().StringVar(&historyDescription, "description", "", "Description for the history entry")
+	switchCmd.Flags().StringVar(&historyTicket, "ticket", "", "Ticket ID stored with the history entry (default: taken from the branch name per branch_template)")
 	switchCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Preview what branches would be switched to without making changes")
+	switchCmd.Flags().BoolVar(&strictSwitch, "strict", false, "Fail and roll back if the repositories end up on different branches")
+	switchCmd.Flags().BoolVar(&atomicSwitch, "atomic", false, "Roll every repository back to where it was if the switch fails in any of them")
+	switchCmd.Flags().BoolVar(&reapplyStashes, "apply-stashes", false, "Re-apply the autostash recorded when a repository left the branch it switches back to (default: on with --autostash or --on-dirty=stash)")
+	switchCmd.Flags().BoolVar(&dropStashes, "drop-stashes", false, "Drop autostashes from the stash list after re-applying them")
+	switchCmd.Flags().BoolVar(&detachSwitch, "detach", false, "Check out the given tag or commit with a detached HEAD in every repository")
+	switchCmd.Flags().BoolVar(&fuzzySwitch, "fuzzy", false, "Treat the first branch as part of a branch name, e.g. a ticket ID, and switch to the branch containing it")
+	switchCmd.Flags().BoolVar(&forceSwitch, "force", false, "Switch even if repositories have uncommitted changes, leaving it to git to carry them over")
+	switchCmd.Flags().StringVar(&onDirty, "on-dirty", "", "What to do with repositories that have uncommitted changes: stash, skip or fail (default fail, unless --autostash or --force is given)")
+	switchCmd.Flags().BoolVar(&switchAutoInstall, "auto-install", false, "Install the dependencies of repositories whose dependency manifests changed")
+	switchCmd.Flags().BoolVar(&stashTrackedOnly, "stash-tracked-only", false, "Leave untracked files out of the autostash (default from stash.tracked_only)")
+	switchCmd.Flags().BoolVar(&stashKeepIndex, "stash-keep-index", false, "Keep staged changes out of the autostash (default from stash.keep_index)")
+	switchCmd.Flags().StringSliceVar(&stashPathspec, "stash-path", nil, "Only autostash changes to these paths (default from stash.pathspec)")
 }
 
 // runSwitchCmd is the main function for the switch command
 func runSwitchCmd(cmd *cobra.Command, args []string) {
-	// Read the configuration file
-	configObj, err := config.ReadConfig(configFile)
-	if err != nil {
-		log.PrintError(log.ErrConfigReadFailed, "Error reading config", err)
-		os.Exit(1)
+	start := time.Now()
+
+	if detachSwitch && len(args) != 1 {
+		log.PrintError(log.ErrInvalidArgument, "--detach needs exactly one tag or commit", nil)
+	}
+	if fuzzySwitch && (detachSwitch || len(args) == 0) {
+		log.PrintError(log.ErrInvalidArgument, "--fuzzy needs a branch pattern and cannot be combined with --detach", nil)
 	}
 
-	// Ensure we have branches to switch to (check new field first, then legacy)
-	configBranches := configObj.SwitchBranchesFallback
-	if len(configBranches) == 0 {
-		configBranches = configObj.Branches // backwards compatibility
+	switch onDirty {
+	case "", onDirtyStash, onDirtySkip, onDirtyFail:
+	default:
+		log.PrintError(log.ErrInvalidArgument, fmt.Sprintf("--on-dirty must be %s, %s or %s, not %q", onDirtyStash, onDirtySkip, onDirtyFail, onDirty), nil)
+	}
+	if onDirty != "" && (autostash != "" || forceSwitch) {
+		log.PrintError(log.ErrInvalidArgument, "--on-dirty cannot be combined with --autostash or --force", nil)
 	}
 
-	if len(configBranches) == 0 && len(args) == 0 {
-		log.PrintError(log.ErrNoConfigBranches, "No branches specified in the configuration file", nil)
-		os.Exit(1)
+	// Read the configuration file and select repositories
+	configObj, repositories := loadRepositories()
+
+	if fuzzySwitch {
+		args[0] = resolveFuzzyBranch(repositories, args[0])
+	}
+	if historyTicket == "" && len(args) > 0 && !detachSwitch {
+		historyTicket = configObj.TicketFromBranch(args[0])
 	}
 
-	// Get the repositories from the config
-	repositories := configObj.FlattenRepositories()
-	if len(repositories) == 0 {
-		log.PrintError(log.ErrNoConfigRepos, "No repositories found in the configuration file", nil)
-		os.Exit(1)
+	// Ensure we have branches to switch to (check new field first, then legacy)
+	configBranches := configObj.SwitchBranchesFallback
+	if len(configBranches) == 0 {
+		configBranches = configObj.Branches // backwards compatibility
 	}
 
-	// Determine branches to try
+	// Determine branches to try; without any, each repository switches to its
+	// fallback branch
 	var branches []string
 	if len(args) > 0 {
 		branches = args
 	} else {
 		branches = configBranches
 	}
+	describeBranches := strings.Join(branches, ", ")
+	if len(branches) == 0 {
+		describeBranches = describeFallback(configObj.Sync.FallbackBranch)
+	}
+
+	// Branches given on the command line are only renamed by each repository's
+	// branch map, while the configured order is preceded by the repository's own
+	// list, or is the fallback branch when nothing is configured
+	branchesFor := func(repo config.Repository) []string {
+		if len(args) > 0 {
+			return repo.MapBranches(args)
+		}
+		return switchOrderFor(configObj, repo, configBranches)
+	}
 
 	// Handle dry-run mode
+	if dryRun && detachSwitch {
+		runDetachDryRun(repositories, args[0])
+		return
+	}
 	if dryRun {
-		runDryRun(repositories, branches)
+		runDryRun(repositories, branchesFor)
 		return
 	}
 
+	// Check for uncommitted changes before anything is changed, rather than
+	// letting git checkout fail halfway through
+	if autostash == "" && !forceSwitch && onDirty != onDirtyStash {
+		dirty, untracked := findDirtyRepositories(repositories)
+		printUntrackedNote(untracked)
+		if len(dirty) > 0 && onDirty == onDirtySkip {
+			repositories = skipDirtyRepositories(repositories, dirty)
+		} else if len(dirty) > 0 {
+			printDirtyReport(dirty)
+			os.Exit(1)
+		}
+	}
+
 	// If recording history is enabled, save the current state
+	var historyState *config.BranchState
+	var history *config.BranchHistory
 	if configObj.RecordHistory {
-		_, history, err := config.ReadHistory()
+		var err error
+		_, history, err = config.ReadHistory()
 		if err == nil || os.IsNotExist(err) {
 			// Attempt to save the current state
 			state, err := collectCurrentState(repositories)
@@ -89,73 +166,439 @@ func runSwitchCmd(cmd *cobra.Command, args []string) {
 				log.PrintWarning("Error saving branch history: " + err.Error())
 			} else {
 				config.SaveStateToHistory(state, history)
+				historyState = state
 				log.PrintSuccess("Current branch state saved to history")
 			}
 		}
 	}
 
+	// Strict and atomic mode need a snapshot to roll back to
+	var snapshot *config.BranchState
+	if strictSwitch || atomicSwitch {
+		var err error
+		snapshot, err = collectCurrentState(repositories)
+		if err != nil && atomicSwitch {
+			log.PrintError(log.ErrOperationFailed, "Cannot record the current branches, which --atomic needs to roll back", err)
+		}
+	}
+
 	stashName := autostash
-	stash := autostash != ""
+	stash := autostash != "" || onDirty == onDirtyStash
 
 	// If no stashName was provided, use first branch name
-	if stash && stashName == "" && len(branches) > 0 {
-		stashName = branches[0]
+	if stash && stashName == "" {
+		switch {
+		case len(branches) > 0:
+			stashName = branches[0]
+		case configObj.Sync.FallbackBranch != "":
+			stashName = configObj.Sync.FallbackBranch
+		default:
+			stashName = "default-branch"
+		}
 	}
 
-	// Actually switch branches now
-	log.PrintOperation("Switching repositories to branches: " + strings.Join(branches, ", "))
-	log.PrintInfo("")
-
-	// If stashing, remember which repositories had changes stashed
-	stashedRepos := make(map[string]bool)
+	// Remember where each repository started so autostashes can be re-applied
+	// in repositories that return to the branch they were created on
+	if !cmd.Flags().Changed("apply-stashes") {
+		reapplyStashes = stash
+	}
+	fromBranches := make(map[string]string)
+	if reapplyStashes {
+		for _, repo := range repositories {
+			fromBranches[repo.Path], _ = git.GetCurrentBranch(repo.Path)
+		}
+	}
 
-	// Perform the branch switching
-	if stash {
-		stashedRepos = git.SwitchBranchesWithStash(repositories, branches, stashName)
-		_ = stashedRepos // used for history if needed
+	// Actually switch branches now
+	heads := recordHeads(repositories)
+	var results []git.SwitchResult
+	if detachSwitch {
+		log.PrintOperation("Detaching repositories at " + args[0])
 		log.PrintInfo("")
-		log.PrintSuccess("Branch switch completed")
+
+		opts, progress := progressOptions("Switching", repositories)
+		results = engine.DetachRepositories(repositories, args[0], stashName, stashOptions(cmd, configObj, "stash-"), opts)
+		progress.Stop()
 	} else {
-		// Process with real-time output
-		successCount := 0
-		failCount := 0
-
-		// Parallel execution with channel for results
-		resultsChan := make(chan git.SwitchResult, len(repositories))
-		
-		// Launch goroutines
-		for _, repo := range repositories {
-			go func(r config.Repository) {
-				resultsChan <- git.SwitchBranchWithResult(r.Path, branches)
-			}(repo)
-		}
-
-		// Collect results as they come in
-		for i := 0; i < len(repositories); i++ {
-			result := <-resultsChan
-			
-			if result.Success {
-				successCount++
-				if result.AlreadyOnIt {
-					log.PrintSuccess(fmt.Sprintf("%-30s %s → [ALREADY ON TARGET]", result.RepoName, result.ToBranch))
-				} else if result.FromRemote {
-					log.PrintSuccess(fmt.Sprintf("%-30s %s → %s (from remote)", result.RepoName, result.FromBranch, result.ToBranch))
-				} else {
-					log.PrintSuccess(fmt.Sprintf("%-30s %s → %s", result.RepoName, result.FromBranch, result.ToBranch))
-				}
-		} else {
-				failCount++
-				log.PrintWarning(fmt.Sprintf("%-30s %s → [FAILED: %s]", result.RepoName, result.FromBranch, result.Message))
+		log.PrintOperation("Switching repositories to branches: " + describeBranches)
+		log.PrintInfo("")
+
+		opts, progress := progressOptions("Switching", repositories)
+		results = engine.SwitchRepositories(repositories, branchesFor, stashName, stashOptions(cmd, configObj, "stash-"), opts)
+		progress.Stop()
+	}
+	failCount := printSwitchSummary(results)
+
+	// Remember the stashes created in each repository
+	stashedRepos := make(map[string]string)
+	for _, result := range results {
+		if result.Stashed {
+			stashedRepos[result.RepoPath] = result.StashCommit
+		}
+	}
+
+	// Record the stashes in the saved state, so reverting to it re-applies them
+	if historyState != nil && len(stashedRepos) > 0 {
+		recordStashes(historyState, stashedRepos, stashName)
+		history.States[len(history.States)-1] = *historyState
+		if err := config.SaveBranchHistory(history); err != nil {
+			log.PrintWarning("Error recording stashes in branch history: " + err.Error())
+		}
+	}
+
+	if atomicSwitch && failCount > 0 {
+		rollBackSwitch(repositories, snapshot, stashedRepos, stashName, failCount)
+	}
+	if strictSwitch {
+		enforceConsistentBranches(repositories, snapshot, stashedRepos, stashName)
+	}
+
+	if reapplyStashes {
+		reapplyBranchStashes(repositories, fromBranches)
+	}
+
+	installErrs := checkDependencies(configObj, repositories, heads, switchAutoInstall)
+
+	errs := make([]error, len(results))
+	for i, result := range results {
+		if !result.Success {
+			errs[i] = result.Err
+			if errs[i] == nil {
+				errs[i] = errors.New(result.Message)
 			}
+		} else if result.HookErr != nil {
+			errs[i] = result.HookErr
+		} else if installErrs[i] != nil {
+			errs[i] = installErrs[i]
+			failCount++
 		}
+	}
+	notifyCompletion(configObj, "switch", repositories, errs, start)
+
+	if failCount > 0 {
+		os.Exit(1)
+	}
+}
 
+// switchOrderFor returns the configured fallback order of a repository, or its
+// fallback branch (see fallbackBranchFor) when no order is configured
+func switchOrderFor(configObj *config.Configuration, repo config.Repository, configBranches []string) []string {
+	branches := repo.BranchesFor(configBranches)
+	if len(branches) == 0 {
+		branches = []string{fallbackBranchFor(configObj.Sync.FallbackBranch, repo)}
+	}
+	return branches
+}
+
+// dirtyRepository is a repository with uncommitted changes
+type dirtyRepository struct {
+	Repo   config.Repository
+	Status git.WorkingTreeStatus
+}
+
+// findDirtyRepositories returns the repositories with uncommitted changes to
+// tracked files and, separately, those with only untracked files, which git
+// carries across the switch, in configuration order. Repositories whose status
+// cannot be read are left to the switch to report.
+func findDirtyRepositories(repositories []config.Repository) ([]dirtyRepository, []dirtyRepository) {
+	statuses := make([]git.WorkingTreeStatus, len(repositories))
+	errs := engine.ForEachRepository(repositories, parallelOptions(), func(i int, r config.Repository) error {
+		var err error
+		statuses[i], err = git.GetWorkingTreeStatusWithoutCounts(r.Path)
+		return err
+	})
+
+	var dirty, untracked []dirtyRepository
+	for i, status := range statuses {
+		switch {
+		case errs[i] != nil:
+		case status.HasTrackedChanges():
+			dirty = append(dirty, dirtyRepository{Repo: repositories[i], Status: status})
+		case status.UntrackedFiles > 0:
+			untracked = append(untracked, dirtyRepository{Repo: repositories[i], Status: status})
+		}
+	}
+	return dirty, untracked
+}
+
+// printUntrackedNote mentions the repositories that only have untracked files;
+// they are switched, and the files stay in the working tree
+func printUntrackedNote(untracked []dirtyRepository) {
+	for _, u := range untracked {
+		log.PrintInfo(fmt.Sprintf("%-30s %s, kept in the working tree", u.Repo.Name(), describeDirtyStatus(u.Status)))
+	}
+	if len(untracked) > 0 {
 		log.PrintInfo("")
-		if failCount == 0 {
-			log.PrintSuccess(fmt.Sprintf("All %d repositories switched successfully!", successCount))
+	}
+}
+
+// skipDirtyRepositories reports the repositories with uncommitted changes as
+// skipped and returns the others
+func skipDirtyRepositories(repositories []config.Repository, dirty []dirtyRepository) []config.Repository {
+	skip := make(map[string]bool)
+	for _, d := range dirty {
+		skip[d.Repo.Path] = true
+		log.PrintWarning(fmt.Sprintf("%-30s [SKIPPED: %s]", d.Repo.Name(), describeDirtyStatus(d.Status)))
+	}
+	log.PrintInfo("")
+
+	var clean []config.Repository
+	for _, repo := range repositories {
+		if !skip[repo.Path] {
+			clean = append(clean, repo)
+		}
+	}
+	return clean
+}
+
+// printDirtyReport lists the repositories with uncommitted changes and the ways to go on
+func printDirtyReport(dirty []dirtyRepository) {
+	log.PrintErrorNoExit(log.ErrGitUncommittedChanges, fmt.Sprintf("%d repositories have uncommitted changes, nothing was switched", len(dirty)), nil)
+	for _, d := range dirty {
+		branch := d.Status.Branch
+		if d.Status.Detached {
+			branch = "detached HEAD"
+		}
+		log.PrintWarning(fmt.Sprintf("%-30s on %s: %s", d.Repo.Name(), branch, describeDirtyStatus(d.Status)))
+	}
+	log.PrintInfo("")
+	log.PrintInfo("Commit or stash the changes, or switch again with one of:")
+	log.PrintInfo("  --autostash <name>  stash the changes, re-applied when switching back")
+	log.PrintInfo("  --on-dirty=skip     leave these repositories on their current branch")
+	log.PrintInfo("  --force             switch anyway, git carries the changes over where it can")
+}
+
+// describeDirtyStatus summarizes the uncommitted changes of a repository, e.g. "2 staged, 1 untracked"
+func describeDirtyStatus(status git.WorkingTreeStatus) string {
+	var changes []string
+	if status.Conflicts > 0 {
+		changes = append(changes, fmt.Sprintf("%d conflicted", status.Conflicts))
+	}
+	if status.StagedChanges > 0 {
+		changes = append(changes, fmt.Sprintf("%d staged", status.StagedChanges))
+	}
+	if status.UnstagedChanges > 0 {
+		changes = append(changes, fmt.Sprintf("%d unstaged", status.UnstagedChanges))
+	}
+	if status.UntrackedFiles > 0 {
+		changes = append(changes, fmt.Sprintf("%d untracked", status.UntrackedFiles))
+	}
+	return strings.Join(changes, ", ")
+}
+
+// uncommittedChanges describes the uncommitted changes of a repository, or
+// returns an empty string if it has none or its status cannot be read.
+// Untracked files alone do not count, since git carries them along.
+func uncommittedChanges(repoPath string) string {
+	status, err := git.GetWorkingTreeStatusWithoutCounts(repoPath)
+	if err != nil || !status.HasTrackedChanges() {
+		return ""
+	}
+	return describeDirtyStatus(status)
+}
+
+// printDirtySkips warns about the repositories left out for uncommitted changes (--skip-dirty)
+func printDirtySkips(names []string) {
+	if len(names) > 0 {
+		log.PrintWarning(fmt.Sprintf("%d repositories with uncommitted changes were skipped: %s", len(names), strings.Join(names, ", ")))
+	}
+}
+
+// reapplyBranchStashes re-applies, in every repository that changed branch, the
+// autostash recorded in the history when the repository last left the branch it
+// is now on. The stash is applied by its commit SHA, only if it is still in the
+// stash list, and marked as re-applied so later switches leave it alone.
+func reapplyBranchStashes(repositories []config.Repository, fromBranches map[string]string) {
+	history, err := config.LoadBranchHistory()
+	if err != nil {
+		log.PrintWarning("Cannot re-apply autostashes: " + err.Error())
+		return
+	}
+
+	restored := false
+	for _, repo := range repositories {
+		currentBranch, err := git.GetCurrentBranch(repo.Path)
+		if err != nil || currentBranch == fromBranches[repo.Path] {
+			continue
+		}
+		index := history.FindBranchStash(repo.Path, currentBranch)
+		if index < 0 {
+			continue
+		}
+
+		// A stash that was popped or dropped by hand has been dealt with already
+		repoState := history.States[index].Repositories[repo.Path]
+		stashIndex, err := git.FindStashCommit(repo.Path, repoState.StashCommit)
+		if err != nil || stashIndex == "" {
+			continue
+		}
+		if err := git.ApplyStashCommit(repo.Path, repoState.StashCommit); err != nil {
+			log.PrintErrorNoExit(log.ErrGitApplyStashFailed, fmt.Sprintf("Error re-applying stash in %s", repo.Path), err)
+			continue
+		}
+
+		repoState.StashRestored = true
+		history.States[index].Repositories[repo.Path] = repoState
+		restored = true
+		log.PrintSuccess(fmt.Sprintf("%-30s re-applied %s created on %s", repo.Name(), stashIndex, currentBranch))
+
+		if dropStashes {
+			if err := git.DropStashCommit(repo.Path, repoState.StashCommit); err != nil {
+				log.PrintErrorNoExit(log.ErrGitApplyStashFailed, fmt.Sprintf("Error dropping the re-applied stash in %s", repo.Path), err)
+			}
+		}
+	}
+
+	if restored {
+		if err := config.SaveBranchHistory(history); err != nil {
+			log.PrintWarning("Error recording re-applied stashes in branch history: " + err.Error())
+		}
+	}
+}
+
+// printSwitchSummary prints one line per repository, in configuration order, followed by the totals.
+// Returns the number of repositories that failed or were skipped.
+func printSwitchSummary(results []git.SwitchResult) int {
+	successCount := 0
+	failCount := 0
+
+	for _, result := range results {
+		stashInfo := ""
+		if result.Detached {
+			stashInfo = " (detached)"
+		}
+		if result.Stashed {
+			stashInfo += " [STASHED]"
+		}
+
+		if result.Success && result.HookErr != nil {
+			failCount++
+			log.PrintWarning(fmt.Sprintf("%-30s %s → %s [HOOK FAILED: %v]%s", result.RepoName, result.FromBranch, result.ToBranch, result.HookErr, stashInfo))
+		} else if result.Success {
+			successCount++
+			if result.AlreadyOnIt {
+				log.PrintSuccess(fmt.Sprintf("%-30s %s → [ALREADY ON TARGET]%s", result.RepoName, result.ToBranch, stashInfo))
+			} else if result.FromRemote {
+				log.PrintSuccess(fmt.Sprintf("%-30s %s → %s (from remote)%s", result.RepoName, result.FromBranch, result.ToBranch, stashInfo))
+			} else {
+				log.PrintSuccess(fmt.Sprintf("%-30s %s → %s%s", result.RepoName, result.FromBranch, result.ToBranch, stashInfo))
+			}
+		} else if result.Err == engine.ErrSkipped {
+			failCount++
+			log.PrintWarning(fmt.Sprintf("%-30s [SKIPPED: earlier failure]", result.RepoName))
 		} else {
-			log.PrintWarning(fmt.Sprintf("%d succeeded, %d failed", successCount, failCount))
+			failCount++
+			log.PrintWarning(fmt.Sprintf("%-30s %s → [FAILED: %s]%s", result.RepoName, result.FromBranch, result.Message, stashInfo))
 		}
 	}
+
+	log.PrintInfo("")
+	if failCount == 0 {
+		log.PrintSuccess(fmt.Sprintf("All %d repositories switched successfully!", successCount))
+	} else {
+		log.PrintWarning(fmt.Sprintf("%d succeeded, %d failed", successCount, failCount))
+	}
+	return failCount
+}
+
+// enforceConsistentBranches verifies that all repositories ended up on the same
+// branch (after applying their branch maps). If they did not, every repository
+// is rolled back to the snapshot, re-applying stashes created during the switch.
+func enforceConsistentBranches(repositories []config.Repository, snapshot *config.BranchState, stashedRepos map[string]string, stashName string) {
+	reposByBranch := make(map[string][]string)
+	for _, repo := range repositories {
+		branch, err := git.GetCurrentBranch(repo.Path)
+		if err != nil {
+			branch = "(unknown)"
+		} else {
+			branch = repo.UnmapBranch(branch)
+		}
+		reposByBranch[branch] = append(reposByBranch[branch], repo.Name())
+	}
+
+	if len(reposByBranch) <= 1 {
+		return
+	}
+
+	log.PrintInfo("")
+	log.PrintWarning("Repositories ended up on different branches:")
+	branchNames := make([]string, 0, len(reposByBranch))
+	for branch := range reposByBranch {
+		branchNames = append(branchNames, branch)
+	}
+	sort.Strings(branchNames)
+	for _, branch := range branchNames {
+		log.PrintWarning(fmt.Sprintf("  %-30s %s", branch, strings.Join(reposByBranch[branch], ", ")))
+	}
+
+	// Record the stashes created during this run so the rollback re-applies them
+	recordStashes(snapshot, stashedRepos, stashName)
+
+	log.PrintInfo("")
+	log.PrintOperation("Rolling back to the state before the switch...")
+	revertToState(*snapshot, repositories, true, false, false)
+
+	log.PrintError(log.ErrGitBranchesDiverged, "Switch rolled back because --strict requires all repositories on the same branch", nil)
+}
+
+// rollBackSwitch reverts every repository to the snapshot taken before an
+// --atomic switch that failed in some of them, re-applying the autostashes
+// created during the switch, and exits.
+func rollBackSwitch(repositories []config.Repository, snapshot *config.BranchState, stashedRepos map[string]string, stashName string, failCount int) {
+	recordStashes(snapshot, stashedRepos, stashName)
+
+	log.PrintInfo("")
+	log.PrintOperation("Rolling back to the state before the switch...")
+	if err := revertToState(*snapshot, repositories, true, false, false); err != nil {
+		log.PrintError(log.ErrOperationFailed, "Rolling back the switch failed; run 'git_cli_tool revert' to finish it", err)
+	}
+
+	log.PrintError(log.ErrOperationFailed, fmt.Sprintf("Switch rolled back because it failed in %d repositories (--atomic)", failCount), nil)
+}
+
+// recordStashes records the autostashes created by a switch in a saved
+// state, so reverting to that state re-applies them
+func recordStashes(state *config.BranchState, stashedRepos map[string]string, stashName string) {
+	for repoPath, stashCommit := range stashedRepos {
+		if repoState, ok := state.Repositories[repoPath]; ok {
+			repoState.StashName = stashName
+			repoState.StashCommit = stashCommit
+			state.Repositories[repoPath] = repoState
+		}
+	}
+}
+
+// resolveFuzzyBranch returns the branch of the repositories whose name contains
+// pattern (case-insensitive), asking which one to use if several do. Exits if
+// none match or the choice cannot be made.
+func resolveFuzzyBranch(repositories []config.Repository, pattern string) string {
+	var candidates []string
+	for _, branch := range collectBranchNames(repositories) {
+		if branch == pattern {
+			return branch
+		}
+		if strings.Contains(strings.ToLower(branch), strings.ToLower(pattern)) {
+			candidates = append(candidates, branch)
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		log.PrintError(log.ErrGitBranchNotFound, fmt.Sprintf("No branch matching '%s' in any repository", pattern), nil)
+	case 1:
+		log.PrintInfo(fmt.Sprintf("Resolved '%s' to %s", pattern, candidates[0]))
+		return candidates[0]
+	}
+
+	choice, err := log.Choose(fmt.Sprintf("Several branches match '%s':", pattern), candidates)
+	if err != nil {
+		log.PrintErrorNoExit(log.ErrPromptRequired, fmt.Sprintf("'%s' matches %d branches; use a longer pattern", pattern, len(candidates)), err)
+		for _, candidate := range candidates {
+			log.PrintInfo("  " + candidate)
+		}
+		os.Exit(1)
+	}
+	return candidates[choice]
 }
 
 // collectCurrentState collects the current branch state of all repositories
@@ -163,7 +606,9 @@ func collectCurrentState(repositories []config.Repository) (*config.BranchState,
 	state := &config.BranchState{
 		Timestamp:    time.Now().Format(time.RFC3339),
 		Description:  historyDescription,
+		Ticket:       historyTicket,
 		Repositories: make(map[string]config.RepositoryState),
+		Configured:   configuredPaths(),
 	}
 
 	for _, repo := range repositories {
@@ -173,22 +618,67 @@ func collectCurrentState(repositories []config.Repository) (*config.BranchState,
 			continue
 		}
 
-		state.Repositories[repo.Path] = config.RepositoryState{
-			Branch:    currentBranch,
-			StashName: "",
-		}
+		// The commit restores a detached HEAD, and shows where a branch was
+		repoState := config.RepositoryState{Branch: currentBranch}
+		repoState.Commit, _ = git.GetHeadCommit(repo.Path)
+		state.Repositories[repo.Path] = repoState
 	}
 
 	return state, nil
 }
 
+// recordCurrentState saves the current branches of the repositories in the branch
+// history if record_history is enabled. Failures only print a warning.
+func recordCurrentState(configObj *config.Configuration, repositories []config.Repository, description string) {
+	if !configObj.RecordHistory {
+		return
+	}
+	_, history, err := config.ReadHistory()
+	if err != nil && !os.IsNotExist(err) {
+		return
+	}
+	state, err := collectCurrentState(repositories)
+	if err == nil {
+		state.Description = description
+		err = config.SaveStateToHistory(state, history)
+	}
+	if err != nil {
+		log.PrintWarning("Error saving branch history: " + err.Error())
+		return
+	}
+	log.PrintSuccess("Current branch state saved to history")
+}
+
+// runDetachDryRun shows which repositories know the tag or commit a switch --detach would check out
+func runDetachDryRun(repositories []config.Repository, ref string) {
+	log.PrintOperation(fmt.Sprintf("Dry-run: Checking which repositories have %s...", ref))
+	log.PrintInfo("")
+
+	for _, repo := range repositories {
+		currentBranch, err := git.GetCurrentBranch(repo.Path)
+		if err != nil {
+			log.PrintErrorNoExit("", fmt.Sprintf("%-30s [ERROR: %s]", repo.Name(), err.Error()), nil)
+			continue
+		}
+
+		if git.CommitExists(repo.Path, ref) {
+			log.PrintInfo(fmt.Sprintf("%-30s %s → %s (detached)", repo.Name(), currentBranch, ref))
+		} else {
+			log.PrintWarning(fmt.Sprintf("%-30s %s → [NOT FOUND LOCALLY] (%s will be fetched)", repo.Name(), currentBranch, repo.Remote))
+		}
+	}
+
+	log.PrintInfo("")
+	log.PrintOperation("Dry-run complete. No changes were made.")
+}
+
 // runDryRun performs a dry-run of the switch command, showing what would happen
-func runDryRun(repositories []config.Repository, branches []string) {
+func runDryRun(repositories []config.Repository, branchesFor func(config.Repository) []string) {
 	log.PrintOperation("Dry-run: Checking which branches would be used...")
 	log.PrintInfo("")
 
 	for _, repo := range repositories {
-		repoName := filepath.Base(repo.Path)
+		repoName := repo.Name()
 		currentBranch, err := git.GetCurrentBranch(repo.Path)
 		if err != nil {
 			log.PrintErrorNoExit("", fmt.Sprintf("%-30s [ERROR: %s]", repoName, err.Error()), nil)
@@ -196,7 +686,8 @@ func runDryRun(repositories []config.Repository, branches []string) {
 		}
 
 		// Find which branch would be used
-		targetBranch, source := findTargetBranch(repo.Path, branches)
+		branches := branchesFor(repo)
+		targetBranch, source := engine.FindTargetBranch(repo.Path, repo.Remote, branches)
 
 		if targetBranch == "" {
 			log.PrintWarning(fmt.Sprintf("%-30s %s → [NO MATCH] (none of %v found)", repoName, currentBranch, branches))
@@ -214,37 +705,3 @@ func runDryRun(repositories []config.Repository, branches []string) {
 	log.PrintInfo("")
 	log.PrintOperation("Dry-run complete. No changes were made.")
 }
-
-// findTargetBranch finds which branch would be used for a repository
-// Returns the branch name and source ("local" or "remote"), or empty string if none found
-func findTargetBranch(repoPath string, branches []string) (string, string) {
-	absPath, err := filepath.Abs(repoPath)
-	if err != nil {
-		return "", ""
-	}
-
-	// Try each branch in order
-	for _, branch := range branches {
-		// Check if branch exists locally
-		exists, err := git.CheckBranchExists(absPath, branch)
-		if err == nil && exists {
-			return branch, "local"
-		}
-	}
-
-	// None found locally, try fetching and checking remote
-	log.PrintDebug(fmt.Sprintf("Fetching remote for %s...", filepath.Base(repoPath)))
-	fetchCmd := exec.Command("git", "-C", absPath, "fetch")
-	fetchCmd.CombinedOutput() // Ignore errors, just try
-
-	for _, branch := range branches {
-		// Check if remote branch exists
-		exists, err := git.CheckRemoteBranchExists(absPath, branch)
-		if err == nil && exists {
-			return branch, "remote"
-		}
-	}
-
-	return "", ""
-}
-
@@ -4,11 +4,11 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"os/exec"
-	"path/filepath"
 	"strings"
+	"time"
 
 	"git_cli_tool/config"
+	"git_cli_tool/engine"
 	"git_cli_tool/git"
 	"git_cli_tool/log"
 
@@ -32,89 +32,136 @@ Will:
 2. Merge 'feature/base' into it (bringing in any new commits)
 
 Parent branches are defined in the config file under 'branch_dependencies'.
-If a parent branch is not found, it falls back to 'main' (or the configured fallback_branch).
+A branch can have several parents, which are merged in order; parents that do
+not exist are left out. If no parent branch is found, it falls back to the
+configured fallback_branch, or else to the default branch of each repository's
+remote (origin/HEAD), or 'main'.
 
 Example config:
   branch_dependencies:
     "feature/extension": "feature/base"
     "feature/part2": "feature/part1"
-  fallback_branch: "main"`,
+    "feature/combined": ["feature/a", "feature/b"]
+    "feature/ui":
+      parent: "develop"
+      ff_only: true
+  fallback_branch: "main"
+
+The merge settings of a dependency (ff_only, no_ff, strategy_option) can be
+overridden with --ff-only, --no-ff and -X, e.g. -X theirs to resolve
+conflicting hunks in favor of the parent branch.
+
+Merges that stop on conflicts are left in place for manual resolution. With
+--abort-on-conflict, they are aborted right away, so those repositories have
+no conflicts left and are reported as needing a manual sync. Parents merged
+before the failed one stay merged in either case and are listed. With --skip-dirty,
+repositories with uncommitted changes are left alone and reported as skipped.
+
+If notifications.jira is configured, the ticket in the branch name (see
+branch_template) or --ticket is commented on with the result.`,
 	Args: cobra.ExactArgs(1),
 	Run:  runSyncCmd,
+
+	ValidArgsFunction: completeSingleBranch,
 }
 
+var (
+	syncTicket         string
+	syncFFOnly         bool
+	syncNoFF           bool
+	syncStrategyOption string
+	syncAbortConflict  bool
+	syncSkipDirty      bool
+)
+
 // initSyncCmd initializes the sync command with its flags
 func initSyncCmd() {
-	// No specific flags needed for now
-}
-
-// SyncResult holds the result of syncing a single repository
-type SyncResult struct {
-	RepoPath     string
-	RepoName     string
-	TargetBranch string
-	ParentBranch string
-	Success      bool
-	Message      string
-	WasFallback  bool
+	syncCmd.Flags().BoolVar(&syncFFOnly, "ff-only", false, "Refuse to merge unless the branch can be fast-forwarded")
+	syncCmd.Flags().BoolVar(&syncNoFF, "no-ff", false, "Always create a merge commit")
+	syncCmd.Flags().StringVarP(&syncStrategyOption, "strategy-option", "X", "", "Option of the merge strategy, e.g. ours or theirs (like git merge -X)")
+	syncCmd.Flags().BoolVar(&syncAbortConflict, "abort-on-conflict", false, "Abort merges that stop on conflicts, leaving those repositories as they were")
+	syncCmd.Flags().BoolVar(&syncSkipDirty, "skip-dirty", false, "Skip repositories with uncommitted changes instead of syncing them")
+	syncCmd.Flags().StringVar(&syncTicket, "ticket", "", "Ticket to comment on in Jira (default: taken from the branch name per branch_template)")
 }
 
 // runSyncCmd is the main function for the sync command
 func runSyncCmd(cmd *cobra.Command, args []string) {
+	start := time.Now()
 	targetBranch := args[0]
 
-	// Read configuration
-	configObj, err := config.ReadConfig(configFile)
-	if err != nil {
-		log.PrintError(log.ErrConfigReadFailed, "Error reading config", err)
-		os.Exit(1)
-	}
-
-	repositories := configObj.FlattenRepositories()
-	if len(repositories) == 0 {
-		log.PrintError(log.ErrNoConfigRepos, "No repositories found in the configuration file", nil)
-		os.Exit(1)
+	// Read configuration and select repositories
+	configObj, repositories := loadRepositories()
+
+	parentBranches, fallbackBranch, mergeOpts := syncSources(configObj, targetBranch)
+	switch {
+	case syncFFOnly && syncNoFF:
+		log.PrintError(log.ErrInvalidArgument, "--ff-only and --no-ff cannot be combined", nil)
+	case syncFFOnly:
+		mergeOpts.FFOnly, mergeOpts.NoFF = true, false
+	case syncNoFF:
+		mergeOpts.FFOnly, mergeOpts.NoFF = false, true
 	}
-
-	// Determine parent branch from config (using nested sync config)
-	parentBranch := ""
-	if configObj.Sync.BranchDependencies != nil {
-		parentBranch = configObj.Sync.BranchDependencies[targetBranch]
-	}
-
-	// Determine fallback branch
-	fallbackBranch := configObj.Sync.FallbackBranch
-	if fallbackBranch == "" {
-		fallbackBranch = defaultFallbackBranch
+	if syncStrategyOption != "" {
+		mergeOpts.StrategyOption = syncStrategyOption
 	}
+	mergeOpts.AbortOnConflict = syncAbortConflict
 
 	log.PrintOperation(fmt.Sprintf("Syncing branch '%s' across all repositories", targetBranch))
-	if parentBranch != "" {
-		log.PrintInfo(fmt.Sprintf("Parent branch: %s (fallback: %s)", parentBranch, fallbackBranch))
+	if len(parentBranches) == 1 {
+		log.PrintInfo(fmt.Sprintf("Parent branch: %s (fallback: %s)", parentBranches[0], describeFallback(fallbackBranch)))
+	} else if len(parentBranches) > 1 {
+		log.PrintInfo(fmt.Sprintf("Parent branches, merged in order: %s (fallback: %s)", strings.Join(parentBranches, ", "), describeFallback(fallbackBranch)))
 	} else {
-		log.PrintInfo(fmt.Sprintf("No parent defined, will sync with: %s", fallbackBranch))
+		log.PrintInfo(fmt.Sprintf("No parent defined, will sync with: %s", describeFallback(fallbackBranch)))
 	}
+	// The branch merged into is recorded too when a repository is on another one
+	autoSnapshot(configObj, repositories, "sync", "before sync "+targetBranch, engine.CaptureNoChanges, func(r config.Repository, state *config.RepositoryState) error {
+		engine.CaptureBranchCommit(r, state, r.MapBranch(targetBranch))
+		return nil
+	})
 	log.PrintInfo("")
 
-	resultsChan := make(chan SyncResult, len(repositories))
+	// Results are stored in configuration order; progress output is grouped per repository
+	results := make([]engine.SyncResult, len(repositories))
+	dirty := make([]string, len(repositories)) // uncommitted changes of the repositories skipped for them
+	out := newCollector(repositories)
 
-	// Launch goroutines for parallel sync
-	for _, repo := range repositories {
-		go func(r config.Repository) {
-			resultsChan <- syncRepository(r.Path, targetBranch, parentBranch, fallbackBranch)
-		}(repo)
+	// Sync in parallel
+	errs := engine.ForEachRepository(repositories, parallelOptions(), func(i int, r config.Repository) error {
+		if syncSkipDirty {
+			if dirty[i] = uncommittedChanges(r.Path); dirty[i] != "" {
+				return nil
+			}
+		}
+		// Branch names are translated through the repository's branch map
+		results[i] = engine.SyncRepository(out.Repo(r.Path), r, r.MapBranch(targetBranch), r.MapBranches(parentBranches), fallbackBranchFor(fallbackBranch, r), mergeOpts)
+		if !results[i].Success {
+			return fmt.Errorf("%s", results[i].Message)
+		}
+		return results[i].HookErr
+	})
+	out.Flush()
+
+	// Repositories skipped in fail-fast mode have no result yet
+	for i, err := range errs {
+		if err == engine.ErrSkipped {
+			results[i] = engine.SyncResult{
+				RepoPath: repositories[i].Path,
+				RepoName: repositories[i].Name(),
+				Message:  "skipped after an earlier failure",
+			}
+		}
 	}
 
-	// Collect results
-	var results []SyncResult
+	// Count results
 	successCount := 0
 	failCount := 0
 
-	for i := 0; i < len(repositories); i++ {
-		result := <-resultsChan
-		results = append(results, result)
-
-		if result.Success {
+	for i, result := range results {
+		if dirty[i] != "" {
+			continue
+		}
+		if result.Success && result.HookErr == nil {
 			successCount++
 		} else {
 			failCount++
@@ -124,135 +171,129 @@ func runSyncCmd(cmd *cobra.Command, args []string) {
 	// Print summary
 	log.PrintInfo("")
 	log.PrintInfo("=== Sync Summary ===")
-	for _, result := range results {
-		if result.Success {
-			syncInfo := fmt.Sprintf("merged %s", result.ParentBranch)
-			if result.WasFallback {
-				syncInfo += " (fallback)"
+	var needsManualSync, skippedDirty []string
+	for i, result := range results {
+		if dirty[i] != "" {
+			skippedDirty = append(skippedDirty, repositories[i].Name())
+			log.PrintWarning(fmt.Sprintf("%-30s [SKIPPED: uncommitted changes, %s]", repositories[i].Name(), dirty[i]))
+		} else if result.Success {
+			// Each merged parent is reported on its own line
+			syncInfo := syncSummary(result)
+			for _, line := range syncInfo[:len(syncInfo)-1] {
+				log.PrintSuccess(fmt.Sprintf("%-30s %s", result.RepoName, line))
+			}
+			last := syncInfo[len(syncInfo)-1]
+			if result.HookErr != nil {
+				log.PrintErrorNoExit("", fmt.Sprintf("%-30s %s, but %v", result.RepoName, last, result.HookErr), nil)
+				continue
 			}
-			log.PrintSuccess(fmt.Sprintf("%-30s %s", result.RepoName, syncInfo))
+			log.PrintSuccess(fmt.Sprintf("%-30s %s", result.RepoName, last))
 		} else {
-			log.PrintErrorNoExit("", fmt.Sprintf("%-30s %s", result.RepoName, result.Message), nil)
+			// Parents merged before the failed one stay merged, even when it was aborted
+			for _, line := range keptMerges(result) {
+				log.PrintWarning(fmt.Sprintf("%-30s %s", result.RepoName, line))
+			}
+			if result.Aborted {
+				needsManualSync = append(needsManualSync, result.RepoName)
+				log.PrintWarning(fmt.Sprintf("%-30s %s", result.RepoName, result.Message))
+			} else {
+				log.PrintErrorNoExit("", fmt.Sprintf("%-30s %s", result.RepoName, result.Message), nil)
+			}
 		}
 	}
+	if len(needsManualSync) > 0 {
+		log.PrintInfo("")
+		log.PrintWarning(fmt.Sprintf("%d repositories need a manual sync of %s: %s", len(needsManualSync), targetBranch, strings.Join(needsManualSync, ", ")))
+	}
+	if len(skippedDirty) > 0 {
+		log.PrintInfo("")
+		printDirtySkips(skippedDirty)
+	}
+
+	notifyCompletion(configObj, "sync", repositories, errs, start)
+
+	ticket := syncTicket
+	if ticket == "" {
+		ticket = configObj.TicketFromBranch(targetBranch)
+	}
+	notifyTicket(configObj, ticket, ticketSummary("sync", targetBranch, repositories, errs))
 
 	log.PrintInfo("")
 	if failCount == 0 {
 		log.PrintSuccess(fmt.Sprintf("All %d repositories synced successfully!", successCount))
 	} else {
 		log.PrintWarning(fmt.Sprintf("%d succeeded, %d failed", successCount, failCount))
+		os.Exit(1)
 	}
 }
 
-// syncRepository syncs a single repository
-func syncRepository(repoPath, targetBranch, parentBranch, fallbackBranch string) SyncResult {
-	absPath, err := filepath.Abs(repoPath)
-	if err != nil {
-		return SyncResult{
-			RepoPath: repoPath,
-			RepoName: filepath.Base(repoPath),
-			Success:  false,
-			Message:  "failed to resolve path",
+// syncSummary describes a successful sync of a repository with one line per
+// merged branch and, if anything came in, how many commits and changed files
+func syncSummary(result engine.SyncResult) []string {
+	lines := make([]string, len(result.Merges))
+	for i, merge := range result.Merges {
+		lines[i] = fmt.Sprintf("merged %s", merge.Branch)
+		if result.WasFallback {
+			lines[i] += " (fallback)"
 		}
-	}
-
-	repoName := filepath.Base(absPath)
-	result := SyncResult{
-		RepoPath:     absPath,
-		RepoName:     repoName,
-		TargetBranch: targetBranch,
-	}
-
-	// Check if it's a git repository
-	if _, err := os.Stat(filepath.Join(absPath, ".git")); os.IsNotExist(err) {
-		result.Message = "not a git repository"
-		return result
-	}
-
-	// Fetch from remote first
-	log.PrintDebug(fmt.Sprintf("[%s] Fetching from remote...", repoName))
-	fetchCmd := exec.Command("git", "-C", absPath, "fetch", "--all")
-	fetchCmd.CombinedOutput() // Ignore fetch errors, continue anyway
-
-	// Check if target branch exists (local or remote)
-	targetExists, _ := git.CheckBranchExists(absPath, targetBranch)
-	if !targetExists {
-		// Check remote
-		remoteExists, _ := git.CheckRemoteBranchExists(absPath, targetBranch)
-		if !remoteExists {
-			result.Message = fmt.Sprintf("branch '%s' not found", targetBranch)
-			return result
+		switch {
+		case merge.UpToDate:
+			lines[i] += ", already up to date"
+		case merge.Commits > 0 || merge.Changes.Files > 0:
+			lines[i] += fmt.Sprintf(": %d commits, %d files (+%d -%d)", merge.Commits, merge.Changes.Files, merge.Changes.Insertions, merge.Changes.Deletions)
 		}
 	}
+	return lines
+}
 
-	// Switch to target branch
-	log.PrintDebug(fmt.Sprintf("[%s] Switching to %s...", repoName, targetBranch))
-	err = git.SwitchBranchWithFallback(absPath, []string{targetBranch})
-	if err != nil {
-		result.Message = fmt.Sprintf("failed to switch to '%s': %v", targetBranch, err)
-		return result
-	}
-
-	// Determine which parent branch to use
-	branchToMerge := parentBranch
-	useFallback := false
-
-	if branchToMerge != "" {
-		// Check if parent branch exists
-		parentExists, _ := git.CheckBranchExists(absPath, branchToMerge)
-		if !parentExists {
-			remoteParentExists, _ := git.CheckRemoteBranchExists(absPath, branchToMerge)
-			if !remoteParentExists {
-				// Parent not found, use fallback
-				branchToMerge = fallbackBranch
-				useFallback = true
-			}
+// keptMerges describes the merges a failed sync did in a repository before the
+// merge that failed, one line per parent branch that brought anything in.
+// Those merges remain on the branch; only the failed merge is aborted.
+func keptMerges(result engine.SyncResult) []string {
+	var lines []string
+	for _, merge := range result.Merges {
+		if !merge.UpToDate {
+			lines = append(lines, fmt.Sprintf("merged %s before the failure: %d commits, %d files (+%d -%d), remains on %s",
+				merge.Branch, merge.Commits, merge.Changes.Files, merge.Changes.Insertions, merge.Changes.Deletions, result.TargetBranch))
 		}
-	} else {
-		// No parent defined, use fallback
-		branchToMerge = fallbackBranch
-		useFallback = true
 	}
+	return lines
+}
 
-	result.ParentBranch = branchToMerge
-	result.WasFallback = useFallback
-
-	// Make sure the branch we're merging from exists
-	mergeExists, _ := git.CheckBranchExists(absPath, branchToMerge)
-	if !mergeExists {
-		remoteMergeExists, _ := git.CheckRemoteBranchExists(absPath, branchToMerge)
-		if !remoteMergeExists {
-			result.Message = fmt.Sprintf("branch '%s' not found to merge from", branchToMerge)
-			return result
-		}
-		// Use remote version
-		branchToMerge = "origin/" + branchToMerge
+// syncSources returns the parent branches configured for a branch (none if
+// there are none), the configured fallback branch merged when there is no
+// parent (empty if unset, see fallbackBranchFor), and the configured merge settings
+func syncSources(configObj *config.Configuration, targetBranch string) ([]string, string, git.MergeOptions) {
+	// Determine parent branch from config (using nested sync config)
+	dependency := configObj.Sync.BranchDependencies[targetBranch]
+	opts := git.MergeOptions{
+		FFOnly:         dependency.FFOnly,
+		NoFF:           dependency.NoFF,
+		StrategyOption: dependency.StrategyOption,
 	}
 
-	// Perform the merge
-	log.PrintDebug(fmt.Sprintf("[%s] Merging %s...", repoName, branchToMerge))
-	mergeCmd := exec.Command("git", "-C", absPath, "merge", branchToMerge, "--no-edit")
-	mergeOutput, err := mergeCmd.CombinedOutput()
-	
-	if err != nil {
-		// Check if it's a merge conflict
-		if strings.Contains(string(mergeOutput), "CONFLICT") || strings.Contains(string(mergeOutput), "Automatic merge failed") {
-			result.Message = "CONFLICT - resolve manually"
-			// Leave conflicts in place for manual resolution
-			return result
+	return dependency.Parents, configObj.Sync.FallbackBranch, opts
+}
+
+// fallbackBranchFor returns the fallback branch of a repository: the configured
+// fallback branch, or, when none is configured, the default branch of the
+// repository's remote (origin/HEAD), or main if the remote has none recorded
+func fallbackBranchFor(fallbackBranch string, repo config.Repository) string {
+	if fallbackBranch == "" {
+		// The detected branch is the repository's own name, so it is not mapped
+		if detected, err := git.GetDefaultBranch(repo.Path, repo.Remote); err == nil && detected != "" {
+			return detected
 		}
-		result.Message = fmt.Sprintf("merge failed: %s", strings.TrimSpace(string(mergeOutput)))
-		return result
+		fallbackBranch = defaultFallbackBranch
 	}
+	return repo.MapBranch(fallbackBranch)
+}
 
-	result.Success = true
-	
-	// Check if there were actually changes merged
-	if strings.Contains(string(mergeOutput), "Already up to date") {
-		result.Message = "already up to date"
-	} else {
-		result.Message = "merged successfully"
+// describeFallback describes the fallback branch for the output, which differs
+// between repositories when none is configured
+func describeFallback(fallbackBranch string) string {
+	if fallbackBranch == "" {
+		return "default branch of each repository"
 	}
-
-	return result
+	return fallbackBranch
 }
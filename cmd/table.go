@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"strings"
+
+	"git_cli_tool/log"
+)
+
+// printTable prints rows as aligned columns under a header line.
+// Column widths account for wide (CJK) characters.
+func printTable(headers []string, rows [][]string) {
+	widths := make([]int, len(headers))
+	for i, header := range headers {
+		widths[i] = displayWidth(header)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			if w := displayWidth(cell); i < len(widths) && w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+
+	log.PrintOutput(formatTableRow(headers, widths))
+	separators := make([]string, len(headers))
+	for i, width := range widths {
+		separators[i] = strings.Repeat("-", width)
+	}
+	log.PrintOutput(formatTableRow(separators, widths))
+
+	for _, row := range rows {
+		log.PrintOutput(formatTableRow(row, widths))
+	}
+}
+
+// formatTableRow pads every cell but the last to its column width
+func formatTableRow(cells []string, widths []int) string {
+	padded := make([]string, len(cells))
+	for i, cell := range cells {
+		if i < len(cells)-1 {
+			padded[i] = padRight(cell, widths[i])
+		} else {
+			padded[i] = cell
+		}
+	}
+	return strings.Join(padded, "  ")
+}
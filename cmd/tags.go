@@ -2,48 +2,267 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
+	"strconv"
+	"time"
 
 	"git_cli_tool/config"
+	"git_cli_tool/engine"
 	"git_cli_tool/git"
 	"git_cli_tool/log"
 
 	"github.com/spf13/cobra"
 )
 
+var tagsYes bool
+
 // tagsCmd represents the tags command
 var tagsCmd = &cobra.Command{
 	Use:   "tags",
 	Short: "Delete local tags and fetch tags from remote for all repositories",
-	Long:  `Delete all local tags and fetch remote tags for all repositories defined in the configuration file.`,
-	Run:   runTagsCmd,
+	Long: `Delete all local tags and fetch remote tags for all repositories defined in the configuration file.
+
+Local tags that are missing on the remote are deleted and tags that point
+elsewhere on the remote are moved. Before that, the number of tags each
+repository would lose is listed for confirmation, and all local tags are
+recorded in git_cli_tool-tags.yml next to the history file. Re-create them with:
+  git_cli_tool tags restore
+
+Example:
+  git_cli_tool tags
+  git_cli_tool tags --yes
+  git_cli_tool tags restore`,
+	Run: runTagsCmd,
+}
+
+// tagsRestoreCmd represents the tags restore command
+var tagsRestoreCmd = &cobra.Command{
+	Use:   "restore [index]",
+	Short: "Re-create the local tags recorded before the tags command replaced them (defaults to the latest record)",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   runTagsRestoreCmd,
+}
+
+// tagChanges are the local tags of a repository that syncing with the remote
+// would delete or move
+type tagChanges struct {
+	local   map[string]string // all local tags, as recorded before syncing
+	deleted int
+	moved   int
 }
 
 // initTagsCmd initializes the tags command with its flags
 func initTagsCmd() {
-	// The tags command already has access to the global configFile and parallel flags
+	tagsCmd.Flags().BoolVarP(&tagsYes, "yes", "y", false, "Delete and move local tags without asking for confirmation")
+
+	tagsCmd.AddCommand(tagsRestoreCmd)
 }
 
 // runTagsCmd is the main function for the tags command
 func runTagsCmd(cmd *cobra.Command, args []string) {
-	// Read the configuration file
-	configObj, err := config.ReadConfig(configFile)
-	if err != nil {
-		log.PrintError(log.ErrConfigReadFailed, "Error reading config", err)
-		os.Exit(1)
+	// Read the configuration file and select repositories
+	configObj, repositories := loadRepositories()
+
+	repositories, previewErrs, confirmed := confirmTagChanges(repositories)
+	if !confirmed {
+		return
 	}
+	autoSnapshot(configObj, repositories, "tags", "before tags", engine.CaptureNoChanges, nil)
 
-	// Get the flattened repositories
-	repositories := configObj.FlattenRepositories()
+	log.PrintOperation("Refreshing tags in all repositories")
+
+	out := newCollector(repositories)
+	opts, progress := progressOptions("Refreshing tags", repositories)
+	errs := engine.ForEachRepository(repositories, opts, func(_ int, r config.Repository) error {
+		repoOut := out.Repo(r.Path)
+		repoOut.PrintOperation(fmt.Sprintf("Syncing tags for %s", r.Path))
+
+		if err := git.SyncTags(r.Path, r.Remote); err != nil {
+			repoOut.PrintErrorNoExit(log.ErrGitTagOperationFailed, fmt.Sprintf("Error syncing tags in %s", r.Path), err)
+			return err
+		}
 
-	if len(repositories) == 0 {
-		log.PrintError(log.ErrNoConfigRepos, "No repositories found in the configuration file", nil)
-		os.Exit(1)
+		repoOut.PrintSuccess(fmt.Sprintf("Successfully synced tags in %s", r.Path))
+		return nil
+	})
+	progress.Stop()
+	out.Flush()
+
+	reportFailures("Tags refresh", append(errs, previewErrs...))
+}
+
+// confirmTagChanges lists how many local tags each repository would lose,
+// asks for confirmation unless --yes is given, and records the local tags of
+// the repositories that will be synced. Returns those repositories and the
+// errors of the repositories that could not be previewed, which are left out,
+// or false if the user declined.
+func confirmTagChanges(repositories []config.Repository) ([]config.Repository, []error, bool) {
+	changes := make([]tagChanges, len(repositories))
+	errs := engine.ForEachRepository(repositories, parallelOptions(), func(i int, r config.Repository) error {
+		local, err := git.ListTags(r.Path)
+		if err != nil {
+			return err
+		}
+		remote, err := git.ListRemoteTags(r.Path, r.Remote)
+		if err != nil {
+			return err
+		}
+		changes[i].local = local
+		for tag, object := range local {
+			if remoteObject, ok := remote[tag]; !ok {
+				changes[i].deleted++
+			} else if remoteObject != object {
+				changes[i].moved++
+			}
+		}
+		return nil
+	})
+
+	var toSync, losing []config.Repository
+	var details []string
+	var losingChanges []tagChanges
+	for i, repo := range repositories {
+		switch {
+		case errs[i] == engine.ErrSkipped:
+			log.PrintWarning(fmt.Sprintf("%-30s [SKIPPED: earlier failure]", repo.Name()))
+		case errs[i] != nil:
+			log.PrintErrorNoExit(log.ErrGitTagOperationFailed, fmt.Sprintf("%-30s %v", repo.Name(), errs[i]), nil)
+		case changes[i].deleted+changes[i].moved == 0:
+			toSync = append(toSync, repo)
+		default:
+			detail := fmt.Sprintf("%d to delete, %d to move", changes[i].deleted, changes[i].moved)
+			log.PrintWarning(fmt.Sprintf("%-30s %s", repo.Name(), detail))
+			losing = append(losing, repo)
+			details = append(details, detail)
+			losingChanges = append(losingChanges, changes[i])
+		}
 	}
 
-	log.PrintOperation("Refreshing tags in all repositories")
+	if len(losing) > 0 && !tagsYes {
+		log.PrintInfo("")
+		losing, losingChanges = deselectRepositories("Repositories to replace local tags in:", losing, details, losingChanges)
+		tagCount := 0
+		for _, repoChanges := range losingChanges {
+			tagCount += repoChanges.deleted + repoChanges.moved
+		}
 
-	git.ProcessTags(repositories)
+		confirmed := false
+		if len(losing) > 0 {
+			var err error
+			confirmed, err = log.Confirm(fmt.Sprintf("Delete or move %d local tags in %d repositories?", tagCount, len(losing)))
+			if err != nil {
+				log.PrintError(log.ErrPromptRequired, "Pass --yes to replace local tags without confirmation", err)
+			}
+		}
+		if !confirmed {
+			log.PrintInfo("No tags were changed")
+			reportPreviewFailures("Tags preview", errs)
+			return nil, nil, false
+		}
+	}
+
+	// Keep the configuration order
+	selected := make(map[string]bool)
+	for _, repo := range append(toSync, losing...) {
+		selected[repo.Path] = true
+	}
+	var kept []config.Repository
+	var failed []error
+	record := config.TagRecord{
+		Timestamp:    time.Now().Format(time.RFC3339),
+		Repositories: make(map[string]map[string]string),
+	}
+	for i, repo := range repositories {
+		if selected[repo.Path] {
+			kept = append(kept, repo)
+			record.Repositories[repo.Path] = changes[i].local
+		} else if errs[i] != nil {
+			failed = append(failed, errs[i])
+		}
+	}
+
+	// Record the local tags of every repository that will be synced, so that
+	// tags restore can re-create the ones that are deleted or moved
+	if len(losing) > 0 {
+		recordsPath, err := config.SaveTagRecord(record)
+		if err != nil {
+			log.PrintError(log.ErrGitTagOperationFailed, "Failed to record the local tags, no tags were changed", err)
+		}
+		log.PrintInfo(fmt.Sprintf("Local tags recorded in %s; re-create them with 'git_cli_tool tags restore'", recordsPath))
+		log.PrintInfo("")
+	}
+	return kept, failed, true
+}
+
+// runTagsRestoreCmd is the main function for the tags restore command
+func runTagsRestoreCmd(cmd *cobra.Command, args []string) {
+	records, err := config.LoadTagRecords()
+	if err != nil {
+		log.PrintError(log.ErrHistoryReadFailed, "Error loading tag records", err)
+	}
+	if len(records.Records) == 0 {
+		log.PrintInfo("No tag records found.")
+		return
+	}
+
+	// Index 0 is the most recent record
+	index := 0
+	if len(args) > 0 {
+		index, err = strconv.Atoi(args[0])
+		if err != nil || index < 0 || index >= len(records.Records) {
+			log.PrintErrorNoExit(log.ErrHistoryIndexInvalid, fmt.Sprintf("Invalid index '%s'", args[0]), nil)
+			log.PrintInfo("Valid range: 0-" + strconv.Itoa(len(records.Records)-1))
+			os.Exit(1)
+		}
+	}
+	record := records.Records[len(records.Records)-1-index]
+
+	_, repositories := loadRepositories()
+
+	log.PrintOperation(fmt.Sprintf("Restoring local tags recorded at %s", record.Timestamp))
+	log.PrintInfo("")
+
+	restored := make([]int, len(repositories))
+	errs := engine.ForEachRepository(repositories, parallelOptions(), func(i int, r config.Repository) error {
+		recorded, ok := record.Repositories[r.Path]
+		if !ok {
+			restored[i] = -1
+			return nil
+		}
+		local, err := git.ListTags(r.Path)
+		if err != nil {
+			return err
+		}
+		for tag, object := range recorded {
+			if local[tag] == object {
+				continue
+			}
+			if err := git.RestoreTag(r.Path, tag, object); err != nil {
+				return err
+			}
+			restored[i]++
+		}
+		return nil
+	})
+
+	for i, repo := range repositories {
+		switch {
+		case errs[i] == engine.ErrSkipped:
+			log.PrintWarning(fmt.Sprintf("%-30s [SKIPPED: earlier failure]", repo.Name()))
+		case errs[i] != nil:
+			log.PrintErrorNoExit(log.ErrGitTagOperationFailed, fmt.Sprintf("%-30s %v", repo.Name(), errs[i]), nil)
+		case restored[i] < 0:
+			log.PrintInfo(fmt.Sprintf("%-30s not in the record", repo.Name()))
+		case len(record.Repositories[repo.Path]) == 0:
+			log.PrintInfo(fmt.Sprintf("%-30s had no tags", repo.Name()))
+		case restored[i] == 0:
+			log.PrintInfo(fmt.Sprintf("%-30s all %d tags present", repo.Name(), len(record.Repositories[repo.Path])))
+		default:
+			log.PrintSuccess(fmt.Sprintf("%-30s restored %d tags", repo.Name(), restored[i]))
+		}
+	}
+	log.PrintInfo("")
 
-	log.PrintSuccess("Tags refresh completed")
+	reportFailures("Tags restore", errs)
 }
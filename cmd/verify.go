@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"git_cli_tool/config"
+	"git_cli_tool/engine"
+	"git_cli_tool/git"
+	"git_cli_tool/log"
+
+	"github.com/spf13/cobra"
+)
+
+// verifyCmd represents the verify command
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check that all repositories are on a branch, clean and up to date",
+	Long: `Verify that every repository is on the given branch (or one of the
+allowed fallbacks), has no uncommitted changes or untracked files, and is
+neither ahead of nor behind its upstream branch. Exits with a non-zero code
+otherwise, so CI can use it as a gate before multi-repository builds.
+
+Branch names are renamed by each repository's branch_map.
+
+Example:
+  git_cli_tool verify --branch feature/x
+  git_cli_tool verify --branch feature/x --fallback develop --fallback main
+  git_cli_tool verify --branch release/1.4 --fetch`,
+	Args: cobra.NoArgs,
+	Run:  runVerifyCmd,
+}
+
+var (
+	verifyBranch    string
+	verifyFallbacks []string
+	verifyFetch     bool
+)
+
+// initVerifyCmd initializes the verify command with its flags
+func initVerifyCmd() {
+	verifyCmd.Flags().StringVar(&verifyBranch, "branch", "", "Branch every repository must be on (required)")
+	verifyCmd.Flags().StringSliceVar(&verifyFallbacks, "fallback", nil, "Other branch a repository may be on instead (repeatable)")
+	verifyCmd.Flags().BoolVar(&verifyFetch, "fetch", false, "Fetch all repositories first so the upstream comparison is current")
+	verifyCmd.MarkFlagRequired("branch")
+}
+
+// runVerifyCmd is the main function for the verify command
+func runVerifyCmd(cmd *cobra.Command, args []string) {
+	_, repositories := loadRepositories()
+	allowed := append([]string{verifyBranch}, verifyFallbacks...)
+
+	if verifyFetch {
+		log.PrintOperation("Fetching from remotes...")
+		errs := engine.FetchRepositories(repositories, parallelOptions())
+		for i, err := range errs {
+			if err != nil && err != engine.ErrSkipped {
+				log.PrintErrorNoExit("", fmt.Sprintf("%-30s fetch failed: %v", repositories[i].Name(), err), nil)
+				os.Exit(1)
+			}
+		}
+	}
+
+	log.PrintOperation("Verifying repositories are on " + strings.Join(allowed, " or "))
+	log.PrintInfo("")
+
+	statuses := make([]git.WorkingTreeStatus, len(repositories))
+	errs := engine.ForEachRepository(repositories, engine.ParallelOptions{Jobs: jobs}, func(i int, r config.Repository) error {
+		var err error
+		statuses[i], err = git.GetWorkingTreeStatus(r.Path)
+		return err
+	})
+
+	failCount := 0
+	for i, repo := range repositories {
+		if errs[i] != nil {
+			failCount++
+			log.PrintErrorNoExit("", fmt.Sprintf("%-30s %v", repo.Name(), errs[i]), nil)
+			continue
+		}
+		problems := verifyProblems(statuses[i], repo.MapBranches(allowed))
+		if len(problems) > 0 {
+			failCount++
+			log.PrintWarning(fmt.Sprintf("%-30s %s", repo.Name(), strings.Join(problems, ", ")))
+			continue
+		}
+		log.PrintSuccess(fmt.Sprintf("%-30s %s", repo.Name(), statuses[i].Branch))
+	}
+
+	log.PrintInfo("")
+	if failCount > 0 {
+		log.PrintErrorNoExit(log.ErrOperationFailed, fmt.Sprintf("%d of %d repositories failed verification", failCount, len(repositories)), nil)
+		os.Exit(1)
+	}
+	log.PrintSuccess(fmt.Sprintf("All %d repositories verified", len(repositories)))
+}
+
+// verifyProblems lists why a repository does not pass verification: not on one
+// of the allowed branches, uncommitted changes, or out of date with its upstream
+func verifyProblems(status git.WorkingTreeStatus, allowed []string) []string {
+	var problems []string
+
+	switch {
+	case status.Detached:
+		problems = append(problems, "detached HEAD")
+	case !slices.Contains(allowed, status.Branch):
+		problems = append(problems, "on "+status.Branch)
+	}
+
+	if status.HasChanges() {
+		problems = append(problems, "uncommitted changes")
+	}
+
+	switch {
+	case status.Detached:
+	case status.Upstream == "":
+		problems = append(problems, "no upstream")
+	default:
+		if status.Ahead > 0 {
+			problems = append(problems, fmt.Sprintf("%d ahead of %s", status.Ahead, status.Upstream))
+		}
+		if status.Behind > 0 {
+			problems = append(problems, fmt.Sprintf("%d behind %s", status.Behind, status.Upstream))
+		}
+	}
+	return problems
+}
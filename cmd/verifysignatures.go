@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"git_cli_tool/config"
+	"git_cli_tool/engine"
+	"git_cli_tool/git"
+	"git_cli_tool/log"
+
+	"github.com/spf13/cobra"
+)
+
+// verifySignaturesCmd represents the verify-signatures command
+var verifySignaturesCmd = &cobra.Command{
+	Use:   "verify-signatures [revision]",
+	Short: "Check that commits are signed in all repositories",
+	Long: `Check the GPG or SSH signature of HEAD (or the given ref) in every
+repository, or of every commit in a range such as origin/main..HEAD. The
+signatures are checked with each repository's own configuration, e.g.
+gpg.format and gpg.ssh.allowedSignersFile for SSH signatures.
+
+Signatures with unknown trust count as signed. Unsigned commits, bad,
+expired or revoked signatures, and signatures that cannot be checked are
+listed, and the command exits with a non-zero code, for compliance audits.
+
+Example:
+  git_cli_tool verify-signatures
+  git_cli_tool verify-signatures origin/main..HEAD
+  git_cli_tool verify-signatures v1.4.0..v1.5.0`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runVerifySignaturesCmd,
+}
+
+// initVerifySignaturesCmd initializes the verify-signatures command with its flags
+func initVerifySignaturesCmd() {
+	// The verify-signatures command only uses the global flags
+}
+
+// runVerifySignaturesCmd is the main function for the verify-signatures command
+func runVerifySignaturesCmd(cmd *cobra.Command, args []string) {
+	revision := "HEAD"
+	if len(args) > 0 {
+		revision = args[0]
+	}
+	_, repositories := loadRepositories()
+
+	log.PrintOperation("Verifying signatures of " + revision)
+	log.PrintInfo("")
+
+	signatures := make([][]git.CommitSignature, len(repositories))
+	errs := engine.ForEachRepository(repositories, engine.ParallelOptions{Jobs: jobs}, func(i int, r config.Repository) error {
+		var err error
+		signatures[i], err = git.GetSignatures(r.Path, revision)
+		return err
+	})
+
+	failCount := 0
+	for i, repo := range repositories {
+		if errs[i] != nil {
+			failCount++
+			log.PrintErrorNoExit("", fmt.Sprintf("%-30s %v", repo.Name(), errs[i]), nil)
+			continue
+		}
+
+		var invalid []git.CommitSignature
+		for _, signature := range signatures[i] {
+			if !signature.Valid() {
+				invalid = append(invalid, signature)
+			}
+		}
+
+		switch {
+		case len(invalid) > 0:
+			failCount++
+			log.PrintWarning(fmt.Sprintf("%-30s %d of %d commits not validly signed", repo.Name(), len(invalid), len(signatures[i])))
+			for _, signature := range invalid {
+				log.PrintWarning(fmt.Sprintf("    %s %s", shortSHA(signature.SHA), signature.Describe()))
+			}
+		case len(signatures[i]) == 0:
+			log.PrintInfo(fmt.Sprintf("%-30s no commits in %s", repo.Name(), revision))
+		case len(signatures[i]) == 1:
+			log.PrintSuccess(fmt.Sprintf("%-30s %s %s", repo.Name(), shortSHA(signatures[i][0].SHA), signatures[i][0].Describe()))
+		default:
+			log.PrintSuccess(fmt.Sprintf("%-30s %d commits signed", repo.Name(), len(signatures[i])))
+		}
+	}
+
+	log.PrintInfo("")
+	if failCount > 0 {
+		log.PrintErrorNoExit(log.ErrOperationFailed, fmt.Sprintf("%d of %d repositories have commits without a valid signature", failCount, len(repositories)), nil)
+		os.Exit(1)
+	}
+	log.PrintSuccess(fmt.Sprintf("All commits signed in %d repositories", len(repositories)))
+}
@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"git_cli_tool/config"
+	"git_cli_tool/engine"
+	"git_cli_tool/git"
+	"git_cli_tool/log"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultVersionCommitMessage = "Bump version to {{.Version}}"
+	defaultVersionTag           = "v{{.Number}}"
+)
+
+// versionCmd represents the version command
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Manage the versions of all repositories",
+}
+
+// versionBumpCmd represents the version bump command
+var versionBumpCmd = &cobra.Command{
+	Use:   "bump <major|minor|patch|version>",
+	Short: "Update version files, commit and tag in all repositories",
+	Long: `Update the version files of every repository, commit them and tag the commit.
+
+Version files are configured under version.files, or per repository with
+version_files. Each file has a path and an optional regular expression whose
+first group is the version; package.json, Chart.yaml and VERSION files work
+without a pattern. The current version is read from the first file.
+
+The commit message and tag name are Go templates with the fields .Version,
+.Number (the version without a "v" prefix), .Previous, .Repo and .Ticket (the
+ticket ID in the current branch name, following branch_template)
+(defaults: "Bump version to {{.Version}}" and "v{{.Number}}").
+
+Example:
+  git_cli_tool version bump minor
+  git_cli_tool version bump 2.0.0 --only api-service
+  git_cli_tool version bump patch --dry-run`,
+	Args: cobra.ExactArgs(1),
+	Run:  runVersionBumpCmd,
+}
+
+var (
+	versionCommit bool
+	versionTag    bool
+	versionDryRun bool
+	versionSign   bool
+)
+
+// initVersionCmd initializes the version command and its subcommands
+func initVersionCmd() {
+	versionBumpCmd.Flags().BoolVar(&versionCommit, "commit", true, "Commit the updated version files")
+	versionBumpCmd.Flags().BoolVar(&versionTag, "tag", true, "Tag the version commit (requires --commit)")
+	versionBumpCmd.Flags().BoolVar(&versionDryRun, "dry-run", false, "Show the new versions without changing anything")
+	versionBumpCmd.Flags().BoolVar(&versionSign, "sign", false, "Sign the version commit and tag with the repositories' GPG or SSH signing key")
+
+	versionCmd.AddCommand(versionBumpCmd)
+}
+
+// versionTemplateData is the data available to the commit message and tag templates
+type versionTemplateData struct {
+	Version  string
+	Number   string // Version without a "v" prefix
+	Previous string
+	Repo     string
+	Ticket   string // ticket ID in the current branch name, empty if there is none
+}
+
+// runVersionBumpCmd is the main function for the version bump command
+func runVersionBumpCmd(cmd *cobra.Command, args []string) {
+	bump := args[0]
+	if _, err := git.BumpVersion("0.0.0", bump); err != nil {
+		log.PrintError(log.ErrInvalidArgument, "Invalid version bump, expected major, minor, patch or a version", err)
+	}
+	configObj, repositories := loadRepositories()
+
+	messageTemplate := parseVersionTemplate("commit_message", configObj.Version.CommitMessage, defaultVersionCommitMessage)
+	tagTemplate := parseVersionTemplate("tag", configObj.Version.Tag, defaultVersionTag)
+
+	if versionDryRun {
+		log.PrintOperation(fmt.Sprintf("Dry run: bumping versions (%s)", bump))
+	} else {
+		log.PrintOperation(fmt.Sprintf("Bumping versions (%s)", bump))
+	}
+	log.PrintInfo("")
+
+	results := make([]ReleaseResult, len(repositories))
+	errs := engine.ForEachRepository(repositories, parallelOptions(), func(i int, r config.Repository) error {
+		results[i] = bumpRepositoryVersion(r, bump, configObj, messageTemplate, tagTemplate)
+		if !results[i].Success {
+			return errors.New(results[i].Message)
+		}
+		return nil
+	})
+
+	// Print summary
+	log.PrintInfo("=== Version Summary ===")
+	successCount := 0
+	failCount := 0
+	for i, result := range results {
+		if errs[i] == engine.ErrSkipped {
+			result = ReleaseResult{RepoName: repositories[i].Name(), Message: "skipped after an earlier failure"}
+		}
+		if result.Success {
+			successCount++
+			log.PrintSuccess(fmt.Sprintf("%-30s %s", result.RepoName, result.Message))
+		} else {
+			failCount++
+			log.PrintErrorNoExit(log.ErrOperationFailed, fmt.Sprintf("%-30s %s", result.RepoName, result.Message), nil)
+		}
+	}
+
+	log.PrintInfo("")
+	if failCount == 0 {
+		log.PrintSuccess(fmt.Sprintf("All %d repositories processed successfully!", successCount))
+	} else {
+		log.PrintWarning(fmt.Sprintf("%d succeeded, %d failed", successCount, failCount))
+		os.Exit(1)
+	}
+}
+
+// parseVersionTemplate parses a configured template, falling back to the default
+func parseVersionTemplate(name string, text string, defaultText string) *template.Template {
+	if text == "" {
+		text = defaultText
+	}
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		log.PrintError(log.ErrConfigParseFailed, fmt.Sprintf("Invalid version.%s template", name), err)
+	}
+	return tmpl
+}
+
+// executeVersionTemplate renders a commit message or tag template
+func executeVersionTemplate(tmpl *template.Template, data versionTemplateData) (string, error) {
+	var text strings.Builder
+	if err := tmpl.Execute(&text, data); err != nil {
+		return "", err
+	}
+	return text.String(), nil
+}
+
+// bumpRepositoryVersion updates, commits and tags the version files of a single repository
+func bumpRepositoryVersion(repo config.Repository, bump string, configObj *config.Configuration, messageTemplate *template.Template, tagTemplate *template.Template) ReleaseResult {
+	result := ReleaseResult{RepoPath: repo.Path, RepoName: repo.Name()}
+
+	if len(repo.VersionFiles) == 0 {
+		result.Success = true
+		result.Message = "no version files configured"
+		return result
+	}
+
+	previous, err := git.ReadVersion(repo.Path, repo.VersionFiles[0])
+	if err != nil {
+		result.Message = err.Error()
+		return result
+	}
+	version, err := git.BumpVersion(previous, bump)
+	if err != nil {
+		result.Message = err.Error()
+		return result
+	}
+	result.Message = fmt.Sprintf("%s -> %s", previous, version)
+
+	data := versionTemplateData{
+		Version:  version,
+		Number:   strings.TrimPrefix(version, "v"),
+		Previous: previous,
+		Repo:     result.RepoName,
+	}
+	if branch, err := git.GetCurrentBranch(repo.Path); err == nil {
+		data.Ticket = configObj.TicketFromBranch(repo.UnmapBranch(branch))
+	}
+	message, err := executeVersionTemplate(messageTemplate, data)
+	if err != nil {
+		result.Message = err.Error()
+		return result
+	}
+	tag, err := executeVersionTemplate(tagTemplate, data)
+	if err != nil {
+		result.Message = err.Error()
+		return result
+	}
+
+	// A tag that exists already would only make the run fail after committing
+	if versionCommit && versionTag && git.TagExists(repo.Path, tag) {
+		result.Message += fmt.Sprintf(", but tag %s already exists", tag)
+		return result
+	}
+
+	if versionDryRun {
+		// Make sure every file can be updated before reporting success
+		for _, file := range repo.VersionFiles[1:] {
+			if _, err := git.ReadVersion(repo.Path, file); err != nil {
+				result.Message = err.Error()
+				return result
+			}
+		}
+		if versionCommit && versionTag {
+			result.Message += fmt.Sprintf(" (would tag %s)", tag)
+		}
+		result.Success = true
+		return result
+	}
+
+	var paths []string
+	for _, file := range repo.VersionFiles {
+		if err := git.WriteVersion(repo.Path, file, version); err != nil {
+			result.Message = err.Error()
+			return result
+		}
+		paths = append(paths, file.Path)
+	}
+
+	if versionCommit {
+		if err := git.CommitFiles(repo.Path, message, paths, versionSign); err != nil {
+			result.Message = err.Error()
+			return result
+		}
+		result.Message += ", committed"
+
+		if versionTag {
+			if err := git.CreateTag(repo.Path, tag, "HEAD", message, versionSign); err != nil {
+				result.Message = err.Error()
+				return result
+			}
+			result.Message += fmt.Sprintf(", tagged %s", tag)
+		}
+	}
+
+	result.Success = true
+	return result
+}
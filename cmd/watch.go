@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"git_cli_tool/config"
+	"git_cli_tool/engine"
+	"git_cli_tool/git"
+	"git_cli_tool/log"
+
+	"github.com/spf13/cobra"
+)
+
+const defaultWatchInterval = time.Minute
+
+// watchCmd represents the watch command
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Keep the status of all repositories fresh",
+	Long: `Periodically fetch all repositories and recompute their status.
+
+On a terminal the status table is redrawn in place after every refresh;
+otherwise a one-line summary is printed. With --json-file the statuses are
+also written to a JSON file that other tools (editor plugins, status bars)
+can read. Stop with Ctrl+C.
+
+Example config:
+  watch:
+    interval: 2m
+    json_file: "/tmp/git_cli_tool_status.json"
+
+Example:
+  git_cli_tool watch
+  git_cli_tool watch --interval 30s --no-fetch
+  git_cli_tool watch --json-file status.json`,
+	Args: cobra.NoArgs,
+	Run:  runWatchCmd,
+}
+
+var (
+	watchInterval time.Duration
+	watchNoFetch  bool
+	watchJSONFile string
+)
+
+// initWatchCmd initializes the watch command with its flags
+func initWatchCmd() {
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", defaultWatchInterval, "Time between refreshes (default from watch.interval)")
+	watchCmd.Flags().BoolVar(&watchNoFetch, "no-fetch", false, "Only recompute the local status, do not fetch")
+	watchCmd.Flags().StringVar(&watchJSONFile, "json-file", "", "Write the statuses to this JSON file after every refresh (default from watch.json_file)")
+}
+
+// watchSnapshot is the content of the JSON status file
+type watchSnapshot struct {
+	UpdatedAt    time.Time    `json:"updated_at"`
+	Repositories []RepoStatus `json:"repositories"`
+}
+
+// runWatchCmd is the main function for the watch command
+func runWatchCmd(cmd *cobra.Command, args []string) {
+	configObj, repositories := loadRepositories()
+
+	interval := configObj.Watch.Interval
+	if cmd.Flags().Changed("interval") || interval == 0 {
+		interval = watchInterval
+	}
+	if interval <= 0 {
+		log.PrintError(log.ErrInvalidArgument, "--interval must be positive", nil)
+	}
+
+	jsonFile := watchJSONFile
+	if jsonFile == "" {
+		jsonFile = configObj.Watch.JSONFile
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		refreshWatch(repositories, interval, jsonFile)
+
+		select {
+		case <-ticker.C:
+		case <-interrupt:
+			log.PrintInfo("")
+			log.PrintInfo("Stopped watching")
+			return
+		}
+	}
+}
+
+// refreshWatch fetches, recomputes and shows the status of all repositories once
+func refreshWatch(repositories []config.Repository, interval time.Duration, jsonFile string) {
+	var fetchErrs []error
+	if !watchNoFetch {
+		// Every refresh fetches again
+		git.ForgetFetches()
+		fetchErrs = engine.FetchRepositories(repositories, parallelOptions())
+	}
+
+	statuses := collectStatuses(repositories, true)
+	updatedAt := time.Now()
+
+	issueCount := 0
+	for _, status := range statuses {
+		if status.NeedsAttention() {
+			issueCount++
+		}
+	}
+
+	if log.ClearScreen() {
+		log.PrintOperation(fmt.Sprintf("Repository status at %s (refreshing every %s, Ctrl+C to stop)", updatedAt.Format("15:04:05"), interval))
+		log.PrintInfo("")
+		printStatusTable(statuses)
+		log.PrintInfo("")
+		for i, err := range fetchErrs {
+			if err != nil {
+				log.PrintWarning(fmt.Sprintf("%-30s fetch failed, status may be stale: %v", repositories[i].Name(), err))
+			}
+		}
+	}
+
+	summary := fmt.Sprintf("[%s] %d of %d repositories need attention", updatedAt.Format("15:04:05"), issueCount, len(repositories))
+	if issueCount > 0 {
+		log.PrintWarning(summary)
+	} else {
+		log.PrintSuccess(summary)
+	}
+
+	if jsonFile != "" {
+		if err := writeWatchSnapshot(jsonFile, watchSnapshot{UpdatedAt: updatedAt, Repositories: statuses}); err != nil {
+			log.PrintWarning(fmt.Sprintf("Failed to write %s: %v", jsonFile, err))
+		}
+	}
+}
+
+// writeWatchSnapshot writes the status file through a temporary file, so
+// readers never see a partially written file
+func writeWatchSnapshot(path string, snapshot watchSnapshot) error {
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
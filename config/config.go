@@ -3,42 +3,467 @@ package config
 import (
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
+// DefaultRemote is the remote used when none is configured
+const DefaultRemote = "origin"
+
+// DefaultBranchTemplate is the name of branches created for a ticket when no
+// branch_template is configured
+const DefaultBranchTemplate = "feature/{ticket}-{slug}"
+
+// ticketPattern matches ticket IDs such as JIRA-1234, or plain issue numbers
+const ticketPattern = `[A-Za-z][A-Za-z0-9]*-[0-9]+|[0-9]+`
+
 // SyncConfig holds configuration for the sync command
 type SyncConfig struct {
-	BranchDependencies map[string]string `yaml:"branch_dependencies,omitempty"` // child -> parent mapping
-	FallbackBranch     string            `yaml:"fallback_branch,omitempty"`     // default: "main"
+	BranchDependencies map[string]BranchDependency `yaml:"branch_dependencies,omitempty"` // child -> parent mapping
+	FallbackBranch     string                      `yaml:"fallback_branch,omitempty"`     // default: "main"
+}
+
+// BranchDependency holds the parent branches sync merges into a branch, in
+// order. It can be written as the plain parent branch name, a list of parent
+// branches, or a mapping with merge settings.
+type BranchDependency struct {
+	Parents        BranchList `yaml:"parent"`                    // one branch or a list of branches
+	FFOnly         bool       `yaml:"ff_only,omitempty"`         // refuse to merge unless the branch can be fast-forwarded
+	NoFF           bool       `yaml:"no_ff,omitempty"`           // always create a merge commit
+	StrategyOption string     `yaml:"strategy_option,omitempty"` // passed to git merge -X, e.g. "ours" or "theirs"
+}
+
+// UnmarshalYAML accepts the plain string, the list and the mapping form of a dependency
+func (d *BranchDependency) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.MappingNode {
+		return value.Decode(&d.Parents)
+	}
+
+	type plainDependency BranchDependency
+	return value.Decode((*plainDependency)(d))
+}
+
+// BranchList is a list of branch names that can also be written as a single name
+type BranchList []string
+
+// UnmarshalYAML accepts both a single branch name and a list of names
+func (l *BranchList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*l = BranchList{value.Value}
+		return nil
+	}
+	return value.Decode((*[]string)(l))
+}
+
+// StatusConfig holds configuration for the status command
+type StatusConfig struct {
+	AutoFetch bool `yaml:"auto_fetch,omitempty"` // fetch all repositories before computing status
+}
+
+// FetchConfig holds the settings of the fetches done by switch, sync, status and other commands
+type FetchConfig struct {
+	Prune      *bool `yaml:"prune,omitempty"`       // delete remote-tracking branches gone on the remote (default: true)
+	AllRemotes bool  `yaml:"all_remotes,omitempty"` // fetch all remotes, not only the repository's remote
+	Tags       bool  `yaml:"tags,omitempty"`        // fetch all tags of the remote
+}
+
+// PruneEnabled reports whether fetches prune remote-tracking branches
+func (f FetchConfig) PruneEnabled() bool {
+	return f.Prune == nil || *f.Prune
+}
+
+// RunDefaults holds defaults of the flags that control how repositories are processed
+type RunDefaults struct {
+	Parallel *bool `yaml:"parallel,omitempty"` // false processes one repository at a time, like --jobs 1
+	Jobs     int   `yaml:"jobs,omitempty"`     // maximum number of repositories processed in parallel, like --jobs
+}
+
+// DefaultsConfig holds the defaults of all commands and per-command overrides
+type DefaultsConfig struct {
+	RunDefaults `yaml:",inline"`
+	Commands    map[string]RunDefaults `yaml:"commands,omitempty"` // by command, e.g. "pull" or "branch prune"
+}
+
+// For returns the defaults of a command: the settings of its entry in
+// Commands, falling back to the settings for all commands
+func (d DefaultsConfig) For(command string) RunDefaults {
+	defaults := d.RunDefaults
+	override, ok := d.Commands[command]
+	if !ok {
+		return defaults
+	}
+	if override.Parallel != nil {
+		defaults.Parallel = override.Parallel
+	}
+	if override.Jobs > 0 {
+		defaults.Jobs = override.Jobs
+	}
+	return defaults
+}
+
+// CacheConfig holds settings of the on-disk cache of read-only queries used by
+// list, status and shell completion
+type CacheConfig struct {
+	Disabled bool          `yaml:"disabled,omitempty"` // always run the queries, like --no-cache
+	TTL      time.Duration `yaml:"ttl,omitempty"`      // how long branch lists are reused, default two minutes
+}
+
+// WatchConfig holds settings for the watch command
+type WatchConfig struct {
+	Interval time.Duration `yaml:"interval,omitempty"`  // time between refreshes, default one minute
+	JSONFile string        `yaml:"json_file,omitempty"` // status file rewritten after every refresh
+}
+
+// ServeConfig holds settings for the HTTP server of the serve command
+type ServeConfig struct {
+	Listen   string `yaml:"listen,omitempty"`    // address to listen on, default ":8080"
+	Token    string `yaml:"token,omitempty"`     // shared token clients must send, prefer token_env
+	TokenEnv string `yaml:"token_env,omitempty"` // environment variable holding the shared token
+}
+
+// BranchConfig holds settings for the branch command
+type BranchConfig struct {
+	Protected []string `yaml:"protected,omitempty"` // branch name patterns never deleted, default: main, master, develop and the fallback branch
+}
+
+// StashConfig holds the defaults for what autostashes and "stash push" stash
+type StashConfig struct {
+	TrackedOnly bool     `yaml:"tracked_only,omitempty"` // leave untracked files in the working tree
+	KeepIndex   bool     `yaml:"keep_index,omitempty"`   // keep staged changes in the index and working tree
+	Pathspec    []string `yaml:"pathspec,omitempty"`     // only stash changes to these paths
+}
+
+// CleanConfig holds settings for the clean command
+type CleanConfig struct {
+	Flags []string `yaml:"flags,omitempty"` // git clean flags, default -d -x
+}
+
+// BackupConfig holds settings for the backup command
+type BackupConfig struct {
+	Dest string `yaml:"dest,omitempty"` // directory holding the mirror clones
+}
+
+// ReleaseConfig holds settings for the release command
+type ReleaseConfig struct {
+	Base string `yaml:"base,omitempty"` // branch releases are cut from, default: sync fallback_branch or the remote's default branch
+}
+
+// VersionFile is a file holding the version of a repository
+type VersionFile struct {
+	Path    string `yaml:"path"`              // relative to the repository
+	Pattern string `yaml:"pattern,omitempty"` // regular expression whose first group is the version, derived from the file name if empty
+}
+
+// VersionConfig holds settings for the version command
+type VersionConfig struct {
+	Files         []VersionFile `yaml:"files,omitempty"`          // version files of every repository, unless overridden per repository
+	CommitMessage string        `yaml:"commit_message,omitempty"` // Go template, default: "Bump version to {{.Version}}"
+	Tag           string        `yaml:"tag,omitempty"`            // Go template, default: "v{{.Number}}"
+}
+
+// CommitConfig holds settings for the commit command
+type CommitConfig struct {
+	Template string `yaml:"template,omitempty"` // Go template with .Message, .Ticket, .Branch and .Repo, default: "{{.Message}}"
+	Pattern  string `yaml:"pattern,omitempty"`  // regular expression commit messages must match with --verify, default: Conventional Commits
+	Verify   bool   `yaml:"verify,omitempty"`   // verify messages without passing --verify
+}
+
+// DependencyManifest is a file whose changes mean the dependencies of a
+// repository have to be installed again
+type DependencyManifest struct {
+	Pattern string `yaml:"pattern"`           // file name, or path in the repository, with shell wildcards, e.g. "package-lock.json" or "services/*/go.sum"
+	Install string `yaml:"install,omitempty"` // command run in the directory of the manifest, e.g. "npm ci"
+}
+
+// DependenciesConfig holds the dependency manifests checked after switch and pull
+type DependenciesConfig struct {
+	Manifests []DependencyManifest `yaml:"manifests,omitempty"` // default: package-lock.json, go.sum and requirements.txt
+}
+
+// ForgeInstance is a self-hosted or SaaS code hosting server
+type ForgeInstance struct {
+	URL      string `yaml:"url"`                 // e.g. https://gitlab.example.com
+	Token    string `yaml:"token,omitempty"`     // API token, prefer token_env
+	TokenEnv string `yaml:"token_env,omitempty"` // environment variable holding the API token
+}
+
+// ForgeConfig holds the code hosting servers used by the pr command
+type ForgeConfig struct {
+	GitLab      []ForgeInstance `yaml:"gitlab,omitempty"`       // gitlab.com is known without configuration
+	AzureDevOps ForgeInstance   `yaml:"azure_devops,omitempty"` // token settings for Azure DevOps Services, the organization comes from the remote URL
+}
+
+// JiraConfig holds the Jira server that is commented on when a ticket's branch
+// is created or synced
+type JiraConfig struct {
+	URL      string `yaml:"url"`                 // e.g. https://example.atlassian.net
+	Email    string `yaml:"email,omitempty"`     // Jira Cloud account of the API token; without it the token is sent as a bearer token (Jira Server/Data Center)
+	Token    string `yaml:"token,omitempty"`     // API token, prefer token_env
+	TokenEnv string `yaml:"token_env,omitempty"` // environment variable holding the API token
+}
+
+// NotificationsConfig holds where summaries of long running commands are posted
+type NotificationsConfig struct {
+	SlackWebhook  string        `yaml:"slack_webhook,omitempty"`   // Slack incoming webhook URL
+	Webhook       string        `yaml:"webhook,omitempty"`         // URL receiving the summary as a JSON POST
+	OnlyOnFailure bool          `yaml:"only_on_failure,omitempty"` // only notify when a repository failed
+	MinDuration   time.Duration `yaml:"min_duration,omitempty"`    // only notify runs taking at least this long, e.g. "30s"
+	Jira          JiraConfig    `yaml:"jira,omitempty"`            // ticket comments on branch create and sync
+}
+
+// HooksConfig holds shell commands run in a repository before and after a command.
+// They are defined globally and per repository; global hooks run first.
+type HooksConfig struct {
+	PreSwitch  []string `yaml:"pre_switch,omitempty"`
+	PostSwitch []string `yaml:"post_switch,omitempty"`
+	PrePull    []string `yaml:"pre_pull,omitempty"`
+	PostPull   []string `yaml:"post_pull,omitempty"`
+	PreSync    []string `yaml:"pre_sync,omitempty"`
+	PostSync   []string `yaml:"post_sync,omitempty"`
+}
+
+// merge returns the hooks of h followed by the hooks of other
+func (h HooksConfig) merge(other HooksConfig) HooksConfig {
+	join := func(a, b []string) []string {
+		return append(append([]string{}, a...), b...)
+	}
+	return HooksConfig{
+		PreSwitch:  join(h.PreSwitch, other.PreSwitch),
+		PostSwitch: join(h.PostSwitch, other.PostSwitch),
+		PrePull:    join(h.PrePull, other.PrePull),
+		PostPull:   join(h.PostPull, other.PostPull),
+		PreSync:    join(h.PreSync, other.PreSync),
+		PostSync:   join(h.PostSync, other.PostSync),
+	}
+}
+
+// GitHooksConfig holds the shared git hook scripts installed by the hooks command
+type GitHooksConfig struct {
+	Dir  string `yaml:"dir,omitempty"`  // directory with the scripts, e.g. pre-commit, commit-msg, pre-push
+	Link bool   `yaml:"link,omitempty"` // create symbolic links instead of copies
+}
+
+// ProxyConfig holds the HTTP(S) proxy git uses to reach remotes
+type ProxyConfig struct {
+	URL   string            `yaml:"url,omitempty"`   // proxy for all hosts, e.g. http://proxy.example.com:8080
+	Hosts map[string]string `yaml:"hosts,omitempty"` // proxy per remote host, overriding url; "" connects directly
 }
 
 // Configuration represents the YAML configuration file structure
 type Configuration struct {
-	SwitchBranchesFallback []string              `yaml:"switch_branches_fallback"` // renamed from "branches"
-	Branches               []string              `yaml:"branches,omitempty"`       // kept for backwards compatibility
-	RecordHistory          bool                  `yaml:"record_history,omitempty"`
-	Repositories           []map[string][]string `yaml:"repositories"`
-	Sync                   SyncConfig            `yaml:"sync,omitempty"` // nested sync configuration
+	SwitchBranchesFallback []string                       `yaml:"switch_branches_fallback"` // renamed from "branches"
+	Branches               []string                       `yaml:"branches,omitempty"`       // kept for backwards compatibility
+	RecordHistory          bool                           `yaml:"record_history,omitempty"`
+	AutoSnapshot           *bool                          `yaml:"auto_snapshot,omitempty"`   // record the state before revert, clean, tags and sync (default: true)
+	Remote                 string                         `yaml:"remote,omitempty"`          // default remote for all repositories
+	BranchTemplate         string                         `yaml:"branch_template,omitempty"` // name of branches created for a ticket, e.g. "feature/{ticket}-{slug}"
+	GitBinary              string                         `yaml:"git_binary,omitempty"`      // git executable to run instead of "git" from the PATH
+	GitGlobalArgs          []string                       `yaml:"git_global_args,omitempty"` // options added to every git command, e.g. ["-c", "core.longpaths=true"]
+	Repositories           []map[string][]RepositoryEntry `yaml:"repositories"`
+	Skip                   []string                       `yaml:"skip,omitempty"`          // repository names or paths excluded from all operations
+	Sync                   SyncConfig                     `yaml:"sync,omitempty"`          // nested sync configuration
+	Fetch                  FetchConfig                    `yaml:"fetch,omitempty"`         // options of every fetch
+	Cache                  CacheConfig                    `yaml:"cache,omitempty"`         // on-disk cache of read-only queries
+	Defaults               DefaultsConfig                 `yaml:"defaults,omitempty"`      // defaults of --jobs for all or single commands
+	Status                 StatusConfig                   `yaml:"status,omitempty"`        // nested status configuration
+	Watch                  WatchConfig                    `yaml:"watch,omitempty"`         // nested watch configuration
+	Serve                  ServeConfig                    `yaml:"serve,omitempty"`         // nested serve configuration
+	Backup                 BackupConfig                   `yaml:"backup,omitempty"`        // nested backup configuration
+	Clean                  CleanConfig                    `yaml:"clean,omitempty"`         // nested clean configuration
+	Stash                  StashConfig                    `yaml:"stash,omitempty"`         // what autostashes and stash push include
+	Branch                 BranchConfig                   `yaml:"branch,omitempty"`        // nested branch configuration
+	Release                ReleaseConfig                  `yaml:"release,omitempty"`       // nested release configuration
+	Version                VersionConfig                  `yaml:"version,omitempty"`       // nested version configuration
+	Commit                 CommitConfig                   `yaml:"commit,omitempty"`        // message template and validation of the commit command
+	Forge                  ForgeConfig                    `yaml:"forge,omitempty"`         // code hosting servers for pull requests
+	Notifications          NotificationsConfig            `yaml:"notifications,omitempty"` // summaries posted after switch, sync and pull
+	LogFile                string                         `yaml:"log_file,omitempty"`      // transcript of every git command, overridden by --log-file
+	Hooks                  HooksConfig                    `yaml:"hooks,omitempty"`         // commands run before/after switch, pull and sync
+	GitHooks               GitHooksConfig                 `yaml:"git_hooks,omitempty"`     // shared git hook scripts
+	Dependencies           DependenciesConfig             `yaml:"dependencies,omitempty"`  // manifests checked for changes after switch and pull
+	Proxy                  ProxyConfig                    `yaml:"proxy,omitempty"`         // HTTP(S) proxy for remote operations
+}
+
+// RepositoryEntry is a subfolder entry under a parent path. It can be written
+// either as a plain folder name or as a mapping with a name and per-repository settings
+type RepositoryEntry struct {
+	Name                   string            `yaml:"name"`
+	Alias                  string            `yaml:"alias,omitempty"`  // display name used in output and filters instead of the folder name
+	Labels                 []string          `yaml:"labels,omitempty"` // selected with --label, e.g. [go, deployable, team-payments]
+	Remote                 string            `yaml:"remote,omitempty"`
+	SwitchBranchesFallback []string          `yaml:"switch_branches_fallback,omitempty"` // tried before the global list
+	BranchMap              map[string]string `yaml:"branch_map,omitempty"`               // global name -> name used in this repository
+	Disabled               bool              `yaml:"disabled,omitempty"`                 // kept in the config but excluded from all operations
+	VersionFiles           []VersionFile     `yaml:"version_files,omitempty"`            // replaces the global version files
+	Hooks                  HooksConfig       `yaml:"hooks,omitempty"`                    // run after the global hooks
+}
+
+// UnmarshalYAML accepts both the plain string and the mapping form of an entry
+func (e *RepositoryEntry) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		e.Name = value.Value
+		return nil
+	}
+
+	type plainEntry RepositoryEntry
+	return value.Decode((*plainEntry)(e))
 }
 
 // Repository represents a Git repository configuration
 type Repository struct {
-	Path string
+	Path         string
+	Alias        string // display name, empty to use the folder name
+	Labels       []string
+	Remote       string
+	Branches     []string
+	BranchMap    map[string]string
+	Disabled     bool
+	VersionFiles []VersionFile
+	Hooks        HooksConfig // global and per-repository hooks combined
+}
+
+// Name returns the display name of the repository: its alias, or its folder name
+func (r Repository) Name() string {
+	if r.Alias != "" {
+		return r.Alias
+	}
+	return filepath.Base(r.Path)
+}
+
+// Matches reports whether the repository matches a name or path glob pattern.
+// The name is the alias or the folder name. Paths are matched segment by
+// segment with forward slashes: * and ? never cross a slash, while a ** segment
+// matches any number of directories, e.g. "**/frontend/*".
+func (r Repository) Matches(pattern string) bool {
+	pattern = filepath.ToSlash(pattern)
+	if matched, _ := path.Match(pattern, r.Name()); matched {
+		return true
+	}
+	repoPath := filepath.ToSlash(r.Path)
+	if matched, _ := path.Match(pattern, path.Base(repoPath)); matched {
+		return true
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(repoPath, "/"))
+}
+
+// matchSegments matches the segments of a path against those of a pattern,
+// where a "**" segment matches zero or more path segments
+func matchSegments(pattern []string, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if matched, _ := path.Match(pattern[0], segments[0]); !matched {
+		return false
+	}
+	return matchSegments(pattern[1:], segments[1:])
+}
+
+// HasLabels reports whether the repository carries all of the given labels
+func (r Repository) HasLabels(labels []string) bool {
+	for _, label := range labels {
+		if !slices.Contains(r.Labels, label) {
+			return false
+		}
+	}
+	return true
+}
+
+// MapBranch returns the name this repository uses for the given branch
+func (r Repository) MapBranch(branch string) string {
+	if mapped, ok := r.BranchMap[branch]; ok && mapped != "" {
+		return mapped
+	}
+	return branch
+}
+
+// UnmapBranch returns the global name for a branch of this repository,
+// reversing the branch map
+func (r Repository) UnmapBranch(branch string) string {
+	for global, mapped := range r.BranchMap {
+		if mapped == branch {
+			return global
+		}
+	}
+	return branch
+}
+
+// MapBranches applies the repository's branch map to every branch in the list,
+// dropping duplicates while keeping the original order
+func (r Repository) MapBranches(branches []string) []string {
+	seen := make(map[string]bool)
+	var mapped []string
+	for _, branch := range branches {
+		name := r.MapBranch(branch)
+		if !seen[name] {
+			seen[name] = true
+			mapped = append(mapped, name)
+		}
+	}
+	return mapped
+}
+
+// BranchesFor returns the fallback order for this repository: its own
+// fallback list is consulted before the global one
+func (r Repository) BranchesFor(global []string) []string {
+	return r.MapBranches(append(append([]string{}, r.Branches...), global...))
+}
+
+// AutoSnapshotEnabled reports whether the state is recorded in the history
+// before destructive commands
+func (c *Configuration) AutoSnapshotEnabled() bool {
+	return c.AutoSnapshot == nil || *c.AutoSnapshot
 }
 
 // FlattenRepositories converts the hierarchical parent-subfolders structure
-// into a flat list of Repository objects with full paths
+// into a flat list of Repository objects with full paths.
+// Disabled and skipped repositories are left out.
 func (c *Configuration) FlattenRepositories() []Repository {
+	var enabledRepos []Repository
+	for _, repo := range c.AllRepositories() {
+		if !repo.Disabled {
+			enabledRepos = append(enabledRepos, repo)
+		}
+	}
+	return enabledRepos
+}
+
+// AllRepositories returns every configured repository, including disabled and
+// skipped ones, which are marked with Disabled
+func (c *Configuration) AllRepositories() []Repository {
 	var flatRepos []Repository
 
 	for _, parentRepoMap := range c.Repositories {
 		for parentPath, subFolders := range parentRepoMap {
-			for _, subFolder := range subFolders {
-				fullPath := filepath.Join(parentPath, subFolder)
-				flatRepos = append(flatRepos, Repository{Path: fullPath})
+			for _, entry := range subFolders {
+				fullPath := filepath.Join(parentPath, entry.Name)
+				flatRepos = append(flatRepos, Repository{
+					Path:         fullPath,
+					Alias:        entry.Alias,
+					Labels:       entry.Labels,
+					Remote:       c.remoteFor(entry),
+					Branches:     entry.SwitchBranchesFallback,
+					BranchMap:    entry.BranchMap,
+					Disabled:     entry.Disabled || c.isSkipped(entry, fullPath),
+					VersionFiles: c.versionFilesFor(entry),
+					Hooks:        c.Hooks.merge(entry.Hooks),
+				})
 			}
 		}
 	}
@@ -46,6 +471,100 @@ func (c *Configuration) FlattenRepositories() []Repository {
 	return flatRepos
 }
 
+// RepositoryAt returns the repository in the directory path and whether it is
+// configured. A configured repository, even a disabled one, is returned with its
+// settings; otherwise the repository gets the global settings, such as the remote.
+func (c *Configuration) RepositoryAt(path string) (Repository, bool) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		absPath = filepath.Clean(path)
+	}
+	for _, repo := range c.AllRepositories() {
+		if repoPath, err := filepath.Abs(repo.Path); err == nil && repoPath == absPath {
+			return repo, true
+		}
+	}
+
+	var entry RepositoryEntry
+	return Repository{
+		Path:         absPath,
+		Remote:       c.remoteFor(entry),
+		VersionFiles: c.versionFilesFor(entry),
+		Hooks:        c.Hooks.merge(entry.Hooks),
+	}, false
+}
+
+// isSkipped reports whether a repository is listed in the skip list, either
+// by its alias, its folder name or its full path
+func (c *Configuration) isSkipped(entry RepositoryEntry, fullPath string) bool {
+	for _, skip := range c.Skip {
+		if (entry.Alias != "" && skip == entry.Alias) || skip == entry.Name || filepath.Clean(skip) == filepath.Clean(fullPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteFor returns the remote for an entry, preferring the per-repository
+// setting over the global one
+func (c *Configuration) remoteFor(entry RepositoryEntry) string {
+	if entry.Remote != "" {
+		return entry.Remote
+	}
+	if c.Remote != "" {
+		return c.Remote
+	}
+	return DefaultRemote
+}
+
+// versionFilesFor returns the version files of an entry, preferring the
+// per-repository list over the global one
+func (c *Configuration) versionFilesFor(entry RepositoryEntry) []VersionFile {
+	if len(entry.VersionFiles) > 0 {
+		return entry.VersionFiles
+	}
+	return c.Version.Files
+}
+
+// branchTemplate returns the configured branch template or the default one
+func (c *Configuration) branchTemplate() string {
+	if c.BranchTemplate != "" {
+		return c.BranchTemplate
+	}
+	return DefaultBranchTemplate
+}
+
+// BranchName expands the branch template with a ticket ID and a slug. The slug
+// is lowercased with runs of other characters than letters and digits replaced
+// by "-"; without a slug, separators left at the end are dropped.
+func (c *Configuration) BranchName(ticket string, slug string) (string, error) {
+	template := c.branchTemplate()
+	if ticket == "" && strings.Contains(template, "{ticket}") {
+		return "", fmt.Errorf("branch template %q needs a ticket ID", template)
+	}
+	slug = strings.Trim(regexp.MustCompile(`[^a-z0-9]+`).ReplaceAllString(strings.ToLower(slug), "-"), "-")
+	name := strings.NewReplacer("{ticket}", ticket, "{slug}", slug).Replace(template)
+	name = strings.TrimRight(name, "-_/.")
+	if name == "" {
+		return "", fmt.Errorf("branch template %q expands to an empty name", template)
+	}
+	return name, nil
+}
+
+// TicketFromBranch returns the ticket ID of a branch named after the branch
+// template, or an empty string if the branch does not follow it
+func (c *Configuration) TicketFromBranch(branch string) string {
+	prefix, _, found := strings.Cut(c.branchTemplate(), "{ticket}")
+	if !found || strings.Contains(prefix, "{slug}") {
+		return ""
+	}
+	re := regexp.MustCompile("^" + regexp.QuoteMeta(prefix) + "(" + ticketPattern + ")(?:[^A-Za-z0-9]|$)")
+	if match := re.FindStringSubmatch(branch); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
 // ReadConfig reads and parses the configuration file
 func ReadConfig(configPath string) (*Configuration, error) {
 	absPath, err := filepath.Abs(configPath)
@@ -59,29 +578,28 @@ func ReadConfig(configPath string) (*Configuration, error) {
 		return nil, fmt.Errorf("failed to read config file: %v", err)
 	}
 
-	// Convert content to string
-	content := string(data)
-
-	// On Windows, ensure backslashes in paths are properly handled
-	// by doubling them in the YAML content before parsing
-	if filepath.Separator == '\\' {
-		// Use regex to find paths in the format "X:\path\to\something"
-		re := regexp.MustCompile(`"([A-Za-z]:(?:\\[^"\\]+)+)"`)
-		content = re.ReplaceAllStringFunc(content, func(match string) string {
-			// Remove the surrounding quotes
-			path := match[1 : len(match)-1]
-			// Convert to forward slashes which YAML handles better
-			normalizedPath := filepath.ToSlash(path)
-			// Return with quotes
-			return `"` + normalizedPath + `"`
-		})
-	}
-
-	// Now parse the modified content as YAML
+	// Parse the content as YAML, with Windows paths normalized
 	var config Configuration
-	if err := yaml.Unmarshal([]byte(content), &config); err != nil {
+	if err := yaml.Unmarshal([]byte(normalizeWindowsPaths(string(data))), &config); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %v", err)
 	}
+	config.localizePaths()
 
 	return &config, nil
 }
+
+// normalizeWindowsPaths converts the backslashes of quoted Windows paths in the
+// configuration to forward slashes, so they are not read as YAML escapes, also
+// on Linux, where configurations shared with Windows are read in WSL
+func normalizeWindowsPaths(content string) string {
+	// Use regex to find paths in the format "X:\path\to\something"
+	re := regexp.MustCompile(`"([A-Za-z]:(?:\\[^"\\]+)+)"`)
+	return re.ReplaceAllStringFunc(content, func(match string) string {
+		// Remove the surrounding quotes
+		path := match[1 : len(match)-1]
+		// Convert to forward slashes which YAML handles better
+		normalizedPath := strings.ReplaceAll(path, `\`, "/")
+		// Return with quotes
+		return `"` + normalizedPath + `"`
+	})
+}
@@ -0,0 +1,356 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BranchDependencyEdit is a planned change of sync.branch_dependencies in the
+// configuration file. Only the lines of the entry that changes are touched;
+// the rest of the file, with its comments, blank lines and quoting, is kept
+// byte for byte.
+type BranchDependencyEdit struct {
+	Previous string   // parents registered before, joined with commas; empty if none
+	Removed  []string // lines of the file that are replaced
+	Added    []string // lines written instead of them, or added
+
+	path    string
+	mode    os.FileMode
+	content []byte
+}
+
+// PlanBranchDependency plans registering parent as the parent branch of child
+// under sync.branch_dependencies in the configuration file. A dependency in the
+// mapping form keeps its merge settings. Only block style YAML is edited; for
+// other layouts, e.g. a branch_dependencies flow mapping, an error is returned
+// and the entry has to be added by hand, see BranchDependencySnippet.
+func PlanBranchDependency(configPath string, child string, parent string) (*BranchDependencyEdit, error) {
+	absPath, err := filepath.Abs(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve absolute path: %v", err)
+	}
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %v", err)
+	}
+	data, err := os.ReadFile(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %v", err)
+	}
+
+	// Normalizing only changes characters inside quotes, so the positions
+	// of the nodes still match the lines of the file
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(normalizeWindowsPaths(string(data))), &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %v", err)
+	}
+
+	file := splitLines(string(data))
+	edit := &BranchDependencyEdit{path: absPath, mode: info.Mode().Perm()}
+	if err := planDependencyEdit(edit, file, &doc, child, parent); err != nil {
+		return nil, err
+	}
+	edit.content = []byte(file.String())
+
+	// Make sure the edited file says what it should
+	var edited struct {
+		Sync SyncConfig `yaml:"sync"`
+	}
+	if err := yaml.Unmarshal([]byte(normalizeWindowsPaths(string(edit.content))), &edited); err != nil {
+		return nil, fmt.Errorf("cannot edit the config file in place: %v", err)
+	}
+	if parents := edited.Sync.BranchDependencies[child].Parents; len(parents) != 1 || parents[0] != parent {
+		return nil, fmt.Errorf("cannot edit the config file in place: its layout is not supported")
+	}
+	return edit, nil
+}
+
+// Write saves the edited configuration file
+func (e *BranchDependencyEdit) Write() error {
+	if err := os.WriteFile(e.path, e.content, e.mode); err != nil {
+		return fmt.Errorf("failed to write config file: %v", err)
+	}
+	return nil
+}
+
+// BranchDependencySnippet returns the configuration that registers parent as
+// the parent branch of child, to add to the configuration file by hand
+func BranchDependencySnippet(child string, parent string) string {
+	return fmt.Sprintf("sync:\n  branch_dependencies:\n    %s: %s\n", formatScalar(child, 0), formatScalar(parent, 0))
+}
+
+// planDependencyEdit finds where child is (or belongs) under
+// sync.branch_dependencies and plans the change of those lines
+func planDependencyEdit(edit *BranchDependencyEdit, file *lines, doc *yaml.Node, child string, parent string) error {
+	if len(doc.Content) == 0 {
+		edit.appendLines(file, "sync:", "  branch_dependencies:", "    "+formatScalar(child, 0)+": "+formatScalar(parent, 0))
+		return nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("failed to parse config file: not a mapping")
+	}
+
+	syncKey, sync := mappingValue(root, "sync")
+	switch {
+	case sync == nil:
+		edit.appendLines(file, "sync:", "  branch_dependencies:", "    "+formatScalar(child, 0)+": "+formatScalar(parent, 0))
+		return nil
+	case isEmptyValue(sync):
+		indent := syncKey.Column - 1 + 2
+		edit.insert(file, syncKey.Line, pad(indent)+"branch_dependencies:", pad(indent+2)+formatScalar(child, 0)+": "+formatScalar(parent, 0))
+		return nil
+	case !isBlockMapping(sync):
+		return fmt.Errorf("cannot edit the config file in place: sync is not a block mapping")
+	}
+
+	dependenciesKey, dependencies := mappingValue(sync, "branch_dependencies")
+	switch {
+	case dependencies == nil:
+		indent := sync.Content[0].Column - 1
+		last := sync.Content[len(sync.Content)-2]
+		edit.insert(file, file.entryEnd(last)+1, pad(indent)+"branch_dependencies:", pad(indent+2)+formatScalar(child, 0)+": "+formatScalar(parent, 0))
+		return nil
+	case isEmptyValue(dependencies):
+		indent := dependenciesKey.Column - 1 + 2
+		edit.insert(file, dependenciesKey.Line, pad(indent)+formatScalar(child, 0)+": "+formatScalar(parent, 0))
+		return nil
+	case !isBlockMapping(dependencies):
+		return fmt.Errorf("cannot edit the config file in place: branch_dependencies is not a block mapping")
+	}
+
+	// New entries are quoted like the first one
+	keyStyle := dependencies.Content[0].Style & (yaml.DoubleQuotedStyle | yaml.SingleQuotedStyle)
+	valueStyle := yaml.Style(0)
+	if first := dependencies.Content[1]; first.Kind == yaml.ScalarNode {
+		valueStyle = first.Style & (yaml.DoubleQuotedStyle | yaml.SingleQuotedStyle)
+	}
+
+	childKey, dependency := mappingValue(dependencies, child)
+	if dependency == nil {
+		indent := dependencies.Content[0].Column - 1
+		last := dependencies.Content[len(dependencies.Content)-2]
+		edit.insert(file, file.entryEnd(last)+1, pad(indent)+formatScalar(child, keyStyle)+": "+formatScalar(parent, valueStyle))
+		return nil
+	}
+	edit.Previous = describeParents(dependency)
+
+	// A dependency in the mapping form keeps its merge settings
+	if dependency.Kind == yaml.MappingNode {
+		if !isBlockMapping(dependency) {
+			return fmt.Errorf("cannot edit the config file in place: %s is a flow mapping", child)
+		}
+		parentKey, parentValue := mappingValue(dependency, "parent")
+		if parentValue == nil {
+			indent := dependency.Content[0].Column - 1
+			edit.insert(file, childKey.Line, pad(indent)+"parent: "+formatScalar(parent, valueStyle))
+			return nil
+		}
+		edit.replaceValue(file, parentKey, parentValue, parent)
+		return nil
+	}
+	edit.replaceValue(file, childKey, dependency, parent)
+	return nil
+}
+
+// describeParents returns the parent branches of a dependency joined with commas
+func describeParents(dependency *yaml.Node) string {
+	if dependency.Kind == yaml.MappingNode {
+		if _, parent := mappingValue(dependency, "parent"); parent != nil {
+			dependency = parent
+		}
+	}
+	if dependency.Kind == yaml.SequenceNode {
+		parents := make([]string, len(dependency.Content))
+		for i, item := range dependency.Content {
+			parents[i] = item.Value
+		}
+		return strings.Join(parents, ", ")
+	}
+	return dependency.Value
+}
+
+// replaceValue sets the value of a mapping entry to parent. A single-line
+// scalar is replaced within its line, keeping a comment after it; any other
+// value is replaced together with its key by a single line.
+func (e *BranchDependencyEdit) replaceValue(file *lines, key *yaml.Node, value *yaml.Node, parent string) {
+	style := value.Style & (yaml.DoubleQuotedStyle | yaml.SingleQuotedStyle)
+	index := key.Line - 1
+	if value.Kind == yaml.ScalarNode && value.Line == key.Line && value.Style&(yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+		line := file.lines[index]
+		start := byteOffset(line, value.Column-1)
+		end := start + scalarLength(line[start:], value.Style)
+		e.replace(file, index, index, line[:start]+formatScalar(parent, style)+line[end:])
+		return
+	}
+
+	line := file.lines[index]
+	keyStart := byteOffset(line, key.Column-1)
+	keyEnd := keyStart + scalarLength(line[keyStart:], key.Style)
+	e.replace(file, index, file.entryEnd(key), line[:keyEnd]+": "+formatScalar(parent, style))
+}
+
+// appendLines adds lines at the end of the file, after a blank line
+func (e *BranchDependencyEdit) appendLines(file *lines, added ...string) {
+	if n := len(file.lines); n > 0 && strings.TrimSpace(file.lines[n-1]) != "" {
+		added = append([]string{""}, added...)
+	}
+	file.trailingNewline = true
+	e.insert(file, len(file.lines), added...)
+}
+
+// insert adds lines before the line with the given index
+func (e *BranchDependencyEdit) insert(file *lines, index int, added ...string) {
+	e.Added = added
+	file.lines = append(file.lines[:index], append(append([]string{}, added...), file.lines[index:]...)...)
+}
+
+// replace replaces the lines from first to last (both included) with a line
+func (e *BranchDependencyEdit) replace(file *lines, first int, last int, line string) {
+	e.Removed = append([]string{}, file.lines[first:last+1]...)
+	e.Added = []string{line}
+	file.lines = append(file.lines[:first], append([]string{line}, file.lines[last+1:]...)...)
+}
+
+// lines is a file split into lines without their line endings
+type lines struct {
+	lines           []string
+	eol             string // "\n", or "\r\n" for files written on Windows
+	trailingNewline bool
+}
+
+// splitLines splits a file into lines
+func splitLines(content string) *lines {
+	file := &lines{eol: "\n"}
+	if strings.Contains(content, "\r\n") {
+		file.eol = "\r\n"
+	}
+	if content == "" {
+		return file
+	}
+	file.trailingNewline = strings.HasSuffix(content, "\n")
+	file.lines = strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	for i, line := range file.lines {
+		file.lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return file
+}
+
+// String joins the lines again
+func (l *lines) String() string {
+	content := strings.Join(l.lines, l.eol)
+	if l.trailingNewline {
+		content += l.eol
+	}
+	return content
+}
+
+// entryEnd returns the index of the last line of a mapping entry: the lines
+// after its key that are indented further (or list items at the same
+// indentation), without the comments and blank lines that follow them
+func (l *lines) entryEnd(key *yaml.Node) int {
+	column := key.Column - 1
+	end := key.Line - 1
+	for i := end + 1; i < len(l.lines); i++ {
+		trimmed := strings.TrimSpace(l.lines[i])
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := len(l.lines[i]) - len(strings.TrimLeft(l.lines[i], " "))
+		if indent > column || (indent == column && (trimmed == "-" || strings.HasPrefix(trimmed, "- "))) {
+			end = i
+			continue
+		}
+		break
+	}
+	return end
+}
+
+// mappingValue returns the key and value nodes stored under key in a mapping,
+// or nil if the key is missing
+func mappingValue(mapping *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i], mapping.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// isEmptyValue reports whether a key has no value at all, as in "sync:"
+func isEmptyValue(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.Tag == "!!null" && node.Value == ""
+}
+
+// isBlockMapping reports whether a node is a mapping in block style with at
+// least one entry
+func isBlockMapping(node *yaml.Node) bool {
+	return node.Kind == yaml.MappingNode && node.Style&yaml.FlowStyle == 0 && len(node.Content) > 0
+}
+
+// formatScalar writes a string as a YAML scalar, quoted as given by style or
+// as needed
+func formatScalar(value string, style yaml.Style) string {
+	data, err := yaml.Marshal(&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value, Style: style})
+	if err != nil {
+		return fmt.Sprintf("%q", value)
+	}
+	return strings.TrimSuffix(string(data), "\n")
+}
+
+// scalarLength returns the length in bytes of the scalar at the start of
+// text: up to the closing quote of a quoted scalar, or up to a comment, the
+// colon of a key or the end of the line for a plain one
+func scalarLength(text string, style yaml.Style) int {
+	switch {
+	case style&yaml.DoubleQuotedStyle != 0:
+		for i := 1; i < len(text); i++ {
+			switch text[i] {
+			case '\\':
+				i++
+			case '"':
+				return i + 1
+			}
+		}
+	case style&yaml.SingleQuotedStyle != 0:
+		for i := 1; i < len(text); i++ {
+			if text[i] == '\'' {
+				if i+1 < len(text) && text[i+1] == '\'' {
+					i++
+					continue
+				}
+				return i + 1
+			}
+		}
+	default:
+		end := len(text)
+		if i := strings.Index(text, " #"); i >= 0 {
+			end = i
+		}
+		if i := strings.Index(text[:end], ": "); i >= 0 {
+			end = i
+		}
+		return len(strings.TrimRight(strings.TrimSuffix(text[:end], ":"), " \t"))
+	}
+	return len(text)
+}
+
+// byteOffset converts a column counted in characters to an offset in bytes
+func byteOffset(line string, column int) int {
+	offset := 0
+	for i := 0; i < column && offset < len(line); i++ {
+		_, size := utf8.DecodeRuneInString(line[offset:])
+		offset += size
+	}
+	return offset
+}
+
+// pad returns the indentation of the given width
+func pad(width int) string {
+	return strings.Repeat(" ", width)
+}
@@ -0,0 +1,325 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPlanBranchDependency(t *testing.T) {
+	tests := []struct {
+		name         string
+		content      string
+		child        string
+		parent       string
+		want         string
+		wantPrevious string
+		wantRemoved  []string
+		wantAdded    []string
+	}{
+		{
+			name: "no sync section",
+			content: `# Repositories of the team
+
+repositories:
+  - path: ./api   # the backend
+  - path: "./web"
+`,
+			child:  "feature/login",
+			parent: "develop",
+			want: `# Repositories of the team
+
+repositories:
+  - path: ./api   # the backend
+  - path: "./web"
+
+sync:
+  branch_dependencies:
+    feature/login: develop
+`,
+			wantAdded: []string{"", "sync:", "  branch_dependencies:", "    feature/login: develop"},
+		},
+		{
+			name:    "empty file",
+			content: "",
+			child:   "feature/login",
+			parent:  "develop",
+			want: `sync:
+  branch_dependencies:
+    feature/login: develop
+`,
+			wantAdded: []string{"sync:", "  branch_dependencies:", "    feature/login: develop"},
+		},
+		{
+			name: "sync without branch dependencies",
+			content: `repositories:
+  - path: ./api
+
+sync:
+    # merge into the fallback branch
+    fallback_branch: main
+
+    abort_on_conflict: true
+# hooks of the team
+hooks:
+  pre_switch: make check
+`,
+			child:  "feature/login",
+			parent: "develop",
+			want: `repositories:
+  - path: ./api
+
+sync:
+    # merge into the fallback branch
+    fallback_branch: main
+
+    abort_on_conflict: true
+    branch_dependencies:
+      feature/login: develop
+# hooks of the team
+hooks:
+  pre_switch: make check
+`,
+			wantAdded: []string{"    branch_dependencies:", "      feature/login: develop"},
+		},
+		{
+			name: "empty sync section",
+			content: `sync:   # nothing yet
+
+repositories:
+  - path: ./api
+`,
+			child:  "feature/login",
+			parent: "develop",
+			want: `sync:   # nothing yet
+  branch_dependencies:
+    feature/login: develop
+
+repositories:
+  - path: ./api
+`,
+			wantAdded: []string{"  branch_dependencies:", "    feature/login: develop"},
+		},
+		{
+			name: "new dependency after the others",
+			content: `sync:
+  branch_dependencies:
+    # the release train
+    "release/1.4": "main"
+
+    "feature/a":
+      - "develop"
+      - "release/1.4"   # backports
+
+  # keep going after conflicts
+  abort_on_conflict: false
+`,
+			child:  "feature/login",
+			parent: "develop",
+			want: `sync:
+  branch_dependencies:
+    # the release train
+    "release/1.4": "main"
+
+    "feature/a":
+      - "develop"
+      - "release/1.4"   # backports
+    "feature/login": "develop"
+
+  # keep going after conflicts
+  abort_on_conflict: false
+`,
+			wantAdded: []string{`    "feature/login": "develop"`},
+		},
+		{
+			name: "replace a parent keeps the comment",
+			content: `sync:
+  branch_dependencies:
+    feature/login: 'main'   # for now
+
+    feature/other: main
+`,
+			child:  "feature/login",
+			parent: "develop",
+			want: `sync:
+  branch_dependencies:
+    feature/login: 'develop'   # for now
+
+    feature/other: main
+`,
+			wantPrevious: "main",
+			wantRemoved:  []string{"    feature/login: 'main'   # for now"},
+			wantAdded:    []string{"    feature/login: 'develop'   # for now"},
+		},
+		{
+			name: "replace a list of parents",
+			content: `sync:
+  branch_dependencies:
+    feature/login:
+      - develop
+      - main
+
+    # unrelated
+    feature/other: main
+`,
+			child:  "feature/login",
+			parent: "release/1.4",
+			want: `sync:
+  branch_dependencies:
+    feature/login: release/1.4
+
+    # unrelated
+    feature/other: main
+`,
+			wantPrevious: "develop, main",
+			wantRemoved:  []string{"    feature/login:", "      - develop", "      - main"},
+			wantAdded:    []string{"    feature/login: release/1.4"},
+		},
+		{
+			name: "mapping form keeps the merge settings",
+			content: `sync:
+  branch_dependencies:
+    feature/login:
+      parent: main # the old parent
+      no_ff: true
+`,
+			child:  "feature/login",
+			parent: "develop",
+			want: `sync:
+  branch_dependencies:
+    feature/login:
+      parent: develop # the old parent
+      no_ff: true
+`,
+			wantPrevious: "main",
+			wantRemoved:  []string{"      parent: main # the old parent"},
+			wantAdded:    []string{"      parent: develop # the old parent"},
+		},
+		{
+			name: "mapping form without a parent",
+			content: `sync:
+  branch_dependencies:
+    feature/login:
+      strategy_option: ours
+`,
+			child:  "feature/login",
+			parent: "develop",
+			want: `sync:
+  branch_dependencies:
+    feature/login:
+      parent: develop
+      strategy_option: ours
+`,
+			wantAdded: []string{"      parent: develop"},
+		},
+		{
+			name:      "Windows line endings",
+			content:   "repositories:\r\n  - path: \"C:\\src\\api\"\r\n\r\nsync:\r\n  branch_dependencies:\r\n    feature/a: main\r\n",
+			child:     "feature/login",
+			parent:    "develop",
+			want:      "repositories:\r\n  - path: \"C:\\src\\api\"\r\n\r\nsync:\r\n  branch_dependencies:\r\n    feature/a: main\r\n    feature/login: develop\r\n",
+			wantAdded: []string{"    feature/login: develop"},
+		},
+		{
+			name: "names that need quoting",
+			content: `sync:
+  branch_dependencies:
+    feature/a: main
+`,
+			child:  "#12",
+			parent: "true",
+			want: `sync:
+  branch_dependencies:
+    feature/a: main
+    '#12': "true"
+`,
+			wantAdded: []string{`    '#12': "true"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfigFile(t, tt.content)
+
+			edit, err := PlanBranchDependency(path, tt.child, tt.parent)
+			if err != nil {
+				t.Fatalf("PlanBranchDependency() error = %v", err)
+			}
+			if edit.Previous != tt.wantPrevious {
+				t.Errorf("Previous = %q, want %q", edit.Previous, tt.wantPrevious)
+			}
+			if !reflect.DeepEqual(edit.Removed, tt.wantRemoved) {
+				t.Errorf("Removed = %q, want %q", edit.Removed, tt.wantRemoved)
+			}
+			if !reflect.DeepEqual(edit.Added, tt.wantAdded) {
+				t.Errorf("Added = %q, want %q", edit.Added, tt.wantAdded)
+			}
+
+			// Planning alone leaves the file alone
+			if data, _ := os.ReadFile(path); string(data) != tt.content {
+				t.Fatalf("file changed before Write():\n%s", data)
+			}
+			if err := edit.Write(); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			if data, _ := os.ReadFile(path); string(data) != tt.want {
+				t.Errorf("file =\n%s\nwant\n%s", data, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanBranchDependencyUnsupported(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{
+			name:    "flow mapping",
+			content: "sync:\n  branch_dependencies: {feature/a: main}\n",
+		},
+		{
+			name:    "flow mapping dependency",
+			content: "sync:\n  branch_dependencies:\n    feature/login: {parent: main, no_ff: true}\n",
+		},
+		{
+			name:    "sync is not a mapping",
+			content: "sync: off\n",
+		},
+		{
+			name:    "not a mapping",
+			content: "- path: ./api\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfigFile(t, tt.content)
+			if _, err := PlanBranchDependency(path, "feature/login", "develop"); err == nil {
+				t.Fatal("PlanBranchDependency() succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestBranchDependencySnippet(t *testing.T) {
+	want := "sync:\n  branch_dependencies:\n    feature/login: develop\n"
+	if got := BranchDependencySnippet("feature/login", "develop"); got != want {
+		t.Errorf("BranchDependencySnippet() = %q, want %q", got, want)
+	}
+	if !strings.Contains(BranchDependencySnippet("#12", "main"), "'#12': main") {
+		t.Errorf("BranchDependencySnippet() does not quote the branch name")
+	}
+}
+
+// writeConfigFile writes a configuration file for a test and returns its path
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "git_cli_tool.yml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
@@ -2,30 +2,59 @@
 package config
 
 import (
+	"crypto/sha256"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
+	"git_cli_tool/gitexec"
+
 	"gopkg.in/yaml.v3"
 )
 
-// MaxHistorySize is the maximum number of history entries to keep
+// MaxHistorySize is the maximum number of history entries recorded by switch
+// to keep; named states are never trimmed and can exceed it
 const MaxHistorySize = 50
 
+// MaxAutoSnapshots is the maximum number of states recorded automatically
+// before other commands (with Command set) to keep, so that they do not push
+// the entries of switch out of the history
+const MaxAutoSnapshots = 50
+
 // RepositoryState represents the state of a repository at a specific time
 type RepositoryState struct {
-	Branch    string `yaml:"branch"`
-	StashName string `yaml:"stash,omitempty"` // Will be empty if no stash was created
+	Branch        string            `yaml:"branch"`
+	StashName     string            `yaml:"stash,omitempty"`          // Will be empty if no stash was created
+	StashCommit   string            `yaml:"stash_commit,omitempty"`   // SHA of the stash; older states only have the name
+	Commit        string            `yaml:"commit,omitempty"`         // HEAD, restored by revert for a detached HEAD or states recorded before a command; older states only have it then
+	Patch         string            `yaml:"patch,omitempty"`          // patch file with the uncommitted changes, if any
+	Branches      map[string]string `yaml:"branches,omitempty"`       // commits of other local branches the command was about to change, e.g. the branch sync merges into
+	StashRestored bool              `yaml:"stash_restored,omitempty"` // the stash was re-applied by a switch back to the branch
+	Stashes       []StashRecord     `yaml:"stashes,omitempty"`        // stashes the command was about to drop, newest first, e.g. those of the branch feature done deletes
+}
+
+// StashRecord is a stash recorded in a state, so that it can be put back into
+// the stash list after it was dropped
+type StashRecord struct {
+	Commit  string `yaml:"commit"`
+	Subject string `yaml:"subject"` // subject in the stash list, e.g. "On feature/x: GitSwitch: main"
 }
 
 // BranchState represents a snapshot of all repositories at a specific time
 type BranchState struct {
 	Timestamp    string                     `yaml:"timestamp"`
+	Name         string                     `yaml:"name,omitempty"` // set for named snapshots, e.g. release cuts
 	Description  string                     `yaml:"description,omitempty"`
+	Ticket       string                     `yaml:"ticket,omitempty"`  // issue tracker ticket the state belongs to, e.g. JIRA-1234
+	Command      string                     `yaml:"command,omitempty"` // command the state was recorded before, e.g. "sync"
 	Repositories map[string]RepositoryState `yaml:"repositories"`
+	// Configured lists the enabled repositories of the configuration when the
+	// state was recorded, so repositories a command left out on purpose can be
+	// told apart from repositories added later. Older states do not have it.
+	Configured []string `yaml:"configured,omitempty"`
 }
 
 // BranchHistory stores the history of branch states
@@ -68,6 +97,71 @@ func GetHistoryFilePath() (string, error) {
 	return filepath.Join(exeDir, "git_cli_tool-history.yml"), nil
 }
 
+// SavePatch writes the uncommitted changes of a repository, recorded in a history
+// state, to a patch file next to the history file and returns its absolute path
+func SavePatch(repo Repository, timestamp time.Time, patch string) (string, error) {
+	dir, err := patchDir()
+	if err != nil {
+		return "", err
+	}
+	return WritePatch(dir, fmt.Sprintf("%s-%s", timestamp.Format("20060102-150405"), PatchName(repo)), patch)
+}
+
+// PatchName returns the name of the patch files of a repository: its display
+// name followed by a short hash of its path, since repositories in different
+// folders can have the same name
+func PatchName(repo Repository) string {
+	repoPath, err := filepath.Abs(repo.Path)
+	if err != nil {
+		repoPath = repo.Path
+	}
+	sum := sha256.Sum256([]byte(filepath.ToSlash(repoPath)))
+	return fmt.Sprintf("%s-%x", strings.ReplaceAll(repo.Name(), "/", "-"), sum[:4])
+}
+
+// SnapshotPatchDir creates the directory holding the patch files of a named
+// snapshot, next to the history file, and returns its absolute path
+func SnapshotPatchDir(name string, timestamp time.Time) (string, error) {
+	dir, err := patchDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, fmt.Sprintf("%s-%s", timestamp.Format("20060102-150405"), name))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create patch directory: %v", err)
+	}
+	return dir, nil
+}
+
+// WritePatch writes a patch to <dir>/<name>.patch and returns its absolute path
+func WritePatch(dir string, name string, patch string) (string, error) {
+	path, err := filepath.Abs(filepath.Join(dir, name+".patch"))
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(patch), 0644); err != nil {
+		return "", fmt.Errorf("failed to write patch: %v", err)
+	}
+	return path, nil
+}
+
+// patchDir creates the git_cli_tool-patches directory next to the history file
+// and returns its absolute path
+func patchDir() (string, error) {
+	historyPath, err := GetHistoryFilePath()
+	if err != nil {
+		return "", err
+	}
+	dir, err := filepath.Abs(filepath.Join(filepath.Dir(historyPath), "git_cli_tool-patches"))
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create patch directory: %v", err)
+	}
+	return dir, nil
+}
+
 // LoadBranchHistory loads the branch history from file
 func LoadBranchHistory() (*BranchHistory, error) {
 	historyPath, err := GetHistoryFilePath()
@@ -103,10 +197,7 @@ func SaveBranchHistory(history *BranchHistory) error {
 		return err
 	}
 
-	// Trim history to keep only the most recent entries
-	if len(history.States) > MaxHistorySize {
-		history.States = history.States[len(history.States)-MaxHistorySize:]
-	}
+	history.trim()
 
 	// Marshal to YAML
 	data, err := yaml.Marshal(history)
@@ -122,6 +213,91 @@ func SaveBranchHistory(history *BranchHistory) error {
 	return nil
 }
 
+// trim drops the oldest entries beyond MaxHistorySize among the states recorded
+// by switch and beyond MaxAutoSnapshots among those recorded before other
+// commands. Named states, such as release cuts, are never dropped.
+func (h *BranchHistory) trim() {
+	counts := make(map[bool]int) // by whether the state was recorded before a command
+	for _, state := range h.States {
+		if state.Name == "" {
+			counts[state.Command != ""]++
+		}
+	}
+	excess := map[bool]int{
+		false: counts[false] - MaxHistorySize,
+		true:  counts[true] - MaxAutoSnapshots,
+	}
+	if excess[false] <= 0 && excess[true] <= 0 {
+		return
+	}
+
+	kept := make([]BranchState, 0, len(h.States))
+	for _, state := range h.States {
+		auto := state.Command != ""
+		if state.Name == "" && excess[auto] > 0 {
+			excess[auto]--
+			continue
+		}
+		kept = append(kept, state)
+	}
+	h.States = kept
+}
+
+// StateAt returns the index into States of the newest state recorded at or
+// before a point in time, or -1 if there is none. States with an unreadable
+// timestamp are passed over.
+func (h *BranchHistory) StateAt(at time.Time) int {
+	for i := len(h.States) - 1; i >= 0; i-- {
+		recorded, err := time.Parse(time.RFC3339, h.States[i].Timestamp)
+		if err == nil && !recorded.After(at) {
+			return i
+		}
+	}
+	return -1
+}
+
+// historyTimeLayouts are the formats accepted for a point in time, e.g. by revert --at
+var historyTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// ParseHistoryTime parses a point in time in one of historyTimeLayouts, in loc
+// unless it includes an offset
+func ParseHistoryTime(value string, loc *time.Location) (time.Time, error) {
+	for _, layout := range historyTimeLayouts {
+		if t, err := time.ParseInLocation(layout, strings.TrimSpace(value), loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("expected a time like \"2024-05-01 14:00\", \"2024-05-01\" or RFC3339")
+}
+
+// ParseHistoryAge parses how long ago something was, as a Go duration such as
+// "2h" or "90m", or a number of days such as "3d"
+func ParseHistoryAge(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("expected a number of days like \"3d\"")
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("expected a duration like \"2h\", \"90m\" or \"3d\"")
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("the duration cannot be negative")
+	}
+	return d, nil
+}
+
 // CreateBranchStateSnapshot creates a snapshot of the current branch state for all repositories
 func CreateBranchStateSnapshot(repositories []Repository, description string, stashNameByRepo map[string]string) (*BranchState, error) {
 	state := BranchState{
@@ -137,9 +313,8 @@ func CreateBranchStateSnapshot(repositories []Repository, description string, st
 		if err == nil {
 			branchName = currentBranch
 		} else {
-			// Fallback to direct command if the GetCurrentBranch function fails
-			gitCmd := fmt.Sprintf("git -C \"%s\" rev-parse --abbrev-ref HEAD", repo.Path)
-			cmdOut, cmdErr := execCommand("cmd", "/c", gitCmd)
+			// Fallback to asking git directly if the GetCurrentBranch function fails
+			cmdOut, cmdErr := gitexec.Command("-C", repo.Path, "rev-parse", "--abbrev-ref", "HEAD").Output()
 			if cmdErr == nil {
 				// Make sure to trim any whitespace or newlines
 				branchName = strings.TrimSpace(string(cmdOut))
@@ -183,6 +358,33 @@ func SaveStateToHistory(state *BranchState, history *BranchHistory) error {
 	return SaveBranchHistory(history)
 }
 
+// FindNamedState returns the index of the most recent state with the given name, or -1
+func (h *BranchHistory) FindNamedState(name string) int {
+	for i := len(h.States) - 1; i >= 0; i-- {
+		if h.States[i].Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// FindBranchStash returns the index of the newest state that recorded the
+// repository on the given branch, if that state also recorded an autostash that
+// was not re-applied yet, or -1
+func (h *BranchHistory) FindBranchStash(repoPath string, branch string) int {
+	for i := len(h.States) - 1; i >= 0; i-- {
+		repoState, ok := h.States[i].Repositories[repoPath]
+		if !ok || repoState.Branch != branch {
+			continue
+		}
+		if repoState.StashCommit == "" || repoState.StashRestored {
+			return -1
+		}
+		return i
+	}
+	return -1
+}
+
 // ReadHistory loads the branch history from file
 func ReadHistory() (string, *BranchHistory, error) {
 	historyPath, err := GetHistoryFilePath()
@@ -194,12 +396,6 @@ func ReadHistory() (string, *BranchHistory, error) {
 	return historyPath, history, err
 }
 
-// Helper function to execute commands
-func execCommand(command string, args ...string) ([]byte, error) {
-	cmd := exec.Command(command, args...)
-	return cmd.Output()
-}
-
 // GetCurrentBranch gets the current branch name of a repository
 // This is a duplicate of the function in the git package to avoid import cycles
 func GetCurrentBranch(repoPath string) (string, error) {
@@ -208,15 +404,12 @@ func GetCurrentBranch(repoPath string) (string, error) {
 		return "", fmt.Errorf("failed to resolve absolute path: %v", err)
 	}
 
-	// Check if repository exists
-	if _, err := os.Stat(filepath.Join(absPath, ".git")); os.IsNotExist(err) {
-		return "", fmt.Errorf("not a git repository or directory does not exist")
-	}
-
-	cmd := exec.Command("git", "-C", absPath, "rev-parse", "--abbrev-ref", "HEAD")
+	// git itself detects the repository, including worktrees and submodules whose
+	// .git is a file; outside a repository the error output says so
+	cmd := gitexec.Command("-C", absPath, "rev-parse", "--abbrev-ref", "HEAD")
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		return "", fmt.Errorf("failed to get current branch: %v", err)
+		return "", fmt.Errorf("failed to get current branch: %v\n%s", err, strings.TrimSpace(string(output)))
 	}
 
 	return strings.TrimSpace(string(output)), nil
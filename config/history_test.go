@@ -0,0 +1,148 @@
+package config
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestBranchHistoryTrim(t *testing.T) {
+	var history BranchHistory
+	history.States = append(history.States, BranchState{Timestamp: "named", Name: "release/1.4"})
+	for i := 0; i < MaxHistorySize; i++ {
+		history.States = append(history.States, BranchState{Timestamp: fmt.Sprintf("switch %d", i)})
+	}
+	for i := 0; i < MaxAutoSnapshots+3; i++ {
+		history.States = append(history.States, BranchState{Timestamp: fmt.Sprintf("sync %d", i), Command: "sync"})
+	}
+	history.States = append(history.States, BranchState{Timestamp: "switch new"})
+
+	history.trim()
+
+	counts := make(map[string]int)
+	for _, state := range history.States {
+		switch {
+		case state.Name != "":
+			counts["named"]++
+		case state.Command != "":
+			counts["auto"]++
+		default:
+			counts["switch"]++
+		}
+	}
+	if counts["named"] != 1 || counts["switch"] != MaxHistorySize || counts["auto"] != MaxAutoSnapshots {
+		t.Errorf("kept %v, want 1 named, %d switch and %d auto", counts, MaxHistorySize, MaxAutoSnapshots)
+	}
+
+	// The oldest entries of each kind go first
+	if first := history.States[1].Timestamp; first != "switch 1" {
+		t.Errorf("oldest switch entry kept = %q, want %q", first, "switch 1")
+	}
+	if first := history.States[MaxHistorySize].Timestamp; first != "sync 3" {
+		t.Errorf("oldest automatic snapshot kept = %q, want %q", first, "sync 3")
+	}
+}
+
+func TestParseHistoryTime(t *testing.T) {
+	berlin := time.FixedZone("CEST", 2*60*60)
+
+	tests := []struct {
+		value   string
+		loc     *time.Location
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "2024-05-01 14:00", loc: time.UTC, want: time.Date(2024, 5, 1, 14, 0, 0, 0, time.UTC)},
+		{value: "2024-05-01 14:00:30", loc: time.UTC, want: time.Date(2024, 5, 1, 14, 0, 30, 0, time.UTC)},
+		{value: "2024-05-01T14:00", loc: time.UTC, want: time.Date(2024, 5, 1, 14, 0, 0, 0, time.UTC)},
+		{value: "2024-05-01T14:00:30", loc: time.UTC, want: time.Date(2024, 5, 1, 14, 0, 30, 0, time.UTC)},
+		{value: "  2024-05-01  ", loc: time.UTC, want: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		// Times without an offset are in the given location
+		{value: "2024-05-01 14:00", loc: berlin, want: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)},
+		// An offset wins over the location
+		{value: "2024-05-01T14:00:00+05:00", loc: berlin, want: time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)},
+		{value: "2024-05-01T14:00:00Z", loc: berlin, want: time.Date(2024, 5, 1, 14, 0, 0, 0, time.UTC)},
+		{value: "01.05.2024", loc: time.UTC, wantErr: true},
+		{value: "2024-05-01 25:00", loc: time.UTC, wantErr: true},
+		{value: "", loc: time.UTC, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseHistoryTime(tt.value, tt.loc)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseHistoryTime() error = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !got.Equal(tt.want) {
+				t.Errorf("ParseHistoryTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseHistoryAge(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "2h", want: 2 * time.Hour},
+		{value: "90m", want: 90 * time.Minute},
+		{value: "1h30m", want: 90 * time.Minute},
+		{value: "3d", want: 72 * time.Hour},
+		{value: "0d", want: 0},
+		{value: " 3d ", want: 72 * time.Hour},
+		{value: "-2h", wantErr: true},
+		{value: "-1d", wantErr: true},
+		{value: "1.5d", wantErr: true},
+		{value: "3w", wantErr: true},
+		{value: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseHistoryAge(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseHistoryAge() error = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseHistoryAge() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBranchHistoryStateAt(t *testing.T) {
+	// Oldest first, recorded in different time zones
+	history := BranchHistory{States: []BranchState{
+		{Timestamp: "2024-05-01T10:00:00Z"},
+		{Timestamp: "2024-05-01T14:00:00+02:00"}, // 12:00 UTC
+		{Timestamp: "not a time"},
+		{Timestamp: "2024-05-01T13:00:00Z"},
+	}}
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want int
+	}{
+		{name: "before the oldest", at: time.Date(2024, 5, 1, 9, 59, 59, 0, time.UTC), want: -1},
+		{name: "exactly the oldest", at: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), want: 0},
+		{name: "between entries", at: time.Date(2024, 5, 1, 11, 0, 0, 0, time.UTC), want: 0},
+		{name: "entry with an offset", at: time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC), want: 1},
+		{name: "in another zone", at: time.Date(2024, 5, 1, 8, 30, 0, 0, time.FixedZone("EDT", -4*60*60)), want: 1},
+		{name: "after the newest", at: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), want: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := history.StateAt(tt.at); got != tt.want {
+				t.Errorf("StateAt() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	if got := (&BranchHistory{}).StateAt(time.Now()); got != -1 {
+		t.Errorf("StateAt() of an empty history = %d, want -1", got)
+	}
+}
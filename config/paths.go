@@ -0,0 +1,124 @@
+package config
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// windowsDrivePath matches absolute Windows paths such as C:\code or C:/code
+var windowsDrivePath = regexp.MustCompile(`^([A-Za-z]):(?:[\\/](.*))?$`)
+
+// wslUNCPath matches paths into a WSL distribution as seen from Windows, such
+// as \\wsl$\Ubuntu\home\me or \\wsl.localhost\Ubuntu\home\me
+var wslUNCPath = regexp.MustCompile(`(?i)^[\\/]{2}wsl(?:\$|\.localhost)[\\/]([^\\/]+)(?:[\\/](.*))?$`)
+
+// defaultWSLMountRoot is where WSL mounts the Windows drives unless
+// automount.root is set in /etc/wsl.conf
+const defaultWSLMountRoot = "/mnt/"
+
+// IsWSL reports whether the tool runs on Linux inside the Windows Subsystem for Linux
+var IsWSL = sync.OnceValue(func() bool {
+	if runtime.GOOS != "linux" {
+		return false
+	}
+	if os.Getenv("WSL_DISTRO_NAME") != "" {
+		return true
+	}
+	release, err := os.ReadFile("/proc/sys/kernel/osrelease")
+	return err == nil && strings.Contains(strings.ToLower(string(release)), "microsoft")
+})
+
+// wslMountRoot returns the directory WSL mounts the Windows drives in, e.g. "/mnt/"
+var wslMountRoot = sync.OnceValue(func() string {
+	file, err := os.Open("/etc/wsl.conf")
+	if err != nil {
+		return defaultWSLMountRoot
+	}
+	defer file.Close()
+
+	section := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.ToLower(strings.Trim(line, "[]"))
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !found || section != "automount" || strings.TrimSpace(key) != "root" {
+			continue
+		}
+		root := strings.Trim(strings.TrimSpace(value), `"`)
+		if root != "" {
+			return strings.TrimSuffix(root, "/") + "/"
+		}
+	}
+	return defaultWSLMountRoot
+})
+
+// LocalPath translates a path written for Windows or WSL into the form the
+// running system uses, so one configuration file works in both: inside WSL,
+// C:\code becomes /mnt/c/code and \\wsl$\<distro>\home\me becomes /home/me; on
+// Windows, /mnt/c/code becomes C:\code. Other paths are returned unchanged.
+func LocalPath(p string) string {
+	switch {
+	case IsWSL():
+		if match := windowsDrivePath.FindStringSubmatch(p); match != nil {
+			return path.Join(wslMountRoot()+strings.ToLower(match[1]), strings.ReplaceAll(match[2], `\`, "/"))
+		}
+		if match := wslUNCPath.FindStringSubmatch(p); match != nil && strings.EqualFold(match[1], os.Getenv("WSL_DISTRO_NAME")) {
+			return path.Join("/", strings.ReplaceAll(match[2], `\`, "/"))
+		}
+	case runtime.GOOS == "windows":
+		rest, found := strings.CutPrefix(filepath.ToSlash(p), defaultWSLMountRoot)
+		if found && len(rest) >= 1 && (len(rest) == 1 || rest[1] == '/') && isDriveLetter(rest[0]) {
+			return filepath.Join(strings.ToUpper(rest[:1])+`:\`, filepath.FromSlash(rest[1:]))
+		}
+	}
+	return p
+}
+
+// ForeignPath explains why a path cannot be used on the running system when it
+// is written for the other side of WSL and LocalPath could not translate it,
+// e.g. a Windows drive path on Linux outside WSL. It is empty otherwise.
+func ForeignPath(p string) string {
+	p = LocalPath(p)
+	switch {
+	case runtime.GOOS == "windows" && strings.HasPrefix(filepath.ToSlash(p), "/"):
+		return "a Linux path, which Windows cannot open; use a drive path or \\\\wsl$\\<distro>\\... instead"
+	case runtime.GOOS != "windows" && windowsDrivePath.MatchString(p):
+		return "a Windows path, which is only translated to /mnt/<drive>/... inside WSL"
+	case runtime.GOOS != "windows" && wslUNCPath.MatchString(p):
+		return "a path into another WSL distribution, which cannot be opened from here"
+	}
+	return ""
+}
+
+// isDriveLetter reports whether c is a letter that can name a Windows drive
+func isDriveLetter(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// localizePaths translates the paths of the configuration with LocalPath
+func (c *Configuration) localizePaths() {
+	for i, parentRepoMap := range c.Repositories {
+		localized := make(map[string][]RepositoryEntry, len(parentRepoMap))
+		for parentPath, subFolders := range parentRepoMap {
+			localized[LocalPath(parentPath)] = subFolders
+		}
+		c.Repositories[i] = localized
+	}
+	for i, skip := range c.Skip {
+		c.Skip[i] = LocalPath(skip)
+	}
+	c.LogFile = LocalPath(c.LogFile)
+	c.Watch.JSONFile = LocalPath(c.Watch.JSONFile)
+	c.Backup.Dest = LocalPath(c.Backup.Dest)
+	c.GitHooks.Dir = LocalPath(c.GitHooks.Dir)
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MaxTagRecords is the maximum number of tag records to keep
+const MaxTagRecords = 20
+
+// TagRecord lists the local tags of every repository before the tags
+// command replaced them with the tags of the remote
+type TagRecord struct {
+	Timestamp    string                       `yaml:"timestamp"`
+	Repositories map[string]map[string]string `yaml:"repositories"` // repository path -> tag -> object
+}
+
+// TagRecords stores the tag records, oldest first
+type TagRecords struct {
+	Records []TagRecord `yaml:"records"`
+}
+
+// GetTagRecordsFilePath returns the path to the tag records file, next to the
+// branch history file
+func GetTagRecordsFilePath() (string, error) {
+	historyPath, err := GetHistoryFilePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(historyPath), "git_cli_tool-tags.yml"), nil
+}
+
+// LoadTagRecords loads the tag records from file
+func LoadTagRecords() (*TagRecords, error) {
+	recordsPath, err := GetTagRecordsFilePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(recordsPath)
+	if os.IsNotExist(err) {
+		return &TagRecords{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tag records file: %v", err)
+	}
+
+	var records TagRecords
+	if err := yaml.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("failed to parse tag records file: %v", err)
+	}
+	return &records, nil
+}
+
+// SaveTagRecord adds a record to the tag records file, keeping the most recent
+// MaxTagRecords, and returns the path of the file
+func SaveTagRecord(record TagRecord) (string, error) {
+	records, err := LoadTagRecords()
+	if err != nil {
+		return "", err
+	}
+	records.Records = append(records.Records, record)
+	if len(records.Records) > MaxTagRecords {
+		records.Records = records.Records[len(records.Records)-MaxTagRecords:]
+	}
+
+	data, err := yaml.Marshal(records)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal tag records to YAML: %v", err)
+	}
+	recordsPath, err := GetTagRecordsFilePath()
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(recordsPath, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write tag records file: %v", err)
+	}
+	return recordsPath, nil
+}
@@ -0,0 +1,67 @@
+package engine
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+
+	"git_cli_tool/config"
+	"git_cli_tool/git"
+)
+
+// DependencyChange is a directory of a repository whose dependency manifests changed
+type DependencyChange struct {
+	Dir     string   // directory of the manifests relative to the repository, "." for the top
+	Files   []string // changed manifests in the directory
+	Install string   // command installing the dependencies, empty if none is configured
+}
+
+// DetectDependencyChanges returns the dependency manifests that differ between
+// two commits of a repository, grouped by directory and install command in the
+// order the files are listed by git. A pattern without a slash matches the file
+// name in any directory, otherwise the path in the repository.
+func DetectDependencyChanges(repo config.Repository, from string, to string, manifests []config.DependencyManifest) ([]DependencyChange, error) {
+	files, err := git.GetChangedFiles(repo.Path, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []DependencyChange
+	for _, file := range files {
+		manifest, ok := matchManifest(file, manifests)
+		if !ok {
+			continue
+		}
+
+		dir := path.Dir(file)
+		i := 0
+		for i < len(changes) && (changes[i].Dir != dir || changes[i].Install != manifest.Install) {
+			i++
+		}
+		if i == len(changes) {
+			changes = append(changes, DependencyChange{Dir: dir, Install: manifest.Install})
+		}
+		changes[i].Files = append(changes[i].Files, file)
+	}
+	return changes, nil
+}
+
+// matchManifest returns the first manifest whose pattern matches a file
+func matchManifest(file string, manifests []config.DependencyManifest) (config.DependencyManifest, bool) {
+	for _, manifest := range manifests {
+		name := file
+		if !strings.Contains(manifest.Pattern, "/") {
+			name = path.Base(file)
+		}
+		if matched, _ := path.Match(manifest.Pattern, name); matched {
+			return manifest, true
+		}
+	}
+	return config.DependencyManifest{}, false
+}
+
+// InstallDependencies runs the install command of a dependency change in the
+// directory of its manifests, like a hook named "install"
+func InstallDependencies(repo config.Repository, change DependencyChange) error {
+	return runHooksIn(repo, filepath.Join(repo.Path, filepath.FromSlash(change.Dir)), "install", []string{change.Install})
+}
@@ -0,0 +1,67 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+
+	"git_cli_tool/config"
+	"git_cli_tool/gitexec/gitexectest"
+)
+
+func TestDetectDependencyChanges(t *testing.T) {
+	manifests := []config.DependencyManifest{
+		{Pattern: "package-lock.json", Install: "npm ci"},
+		{Pattern: "go.sum", Install: "go mod download"},
+		{Pattern: "services/*/requirements.txt", Install: "pip install -r requirements.txt"},
+		{Pattern: "Gemfile.lock"},
+	}
+
+	tests := []struct {
+		name  string
+		files string // output of git diff --name-only
+		want  []DependencyChange
+	}{
+		{
+			name:  "no manifest changed",
+			files: "main.go\nREADME.md\n",
+		},
+		{
+			name:  "manifest at the top",
+			files: "main.go\ngo.sum\ngo.mod\n",
+			want:  []DependencyChange{{Dir: ".", Files: []string{"go.sum"}, Install: "go mod download"}},
+		},
+		{
+			name:  "file names match in any directory",
+			files: "web/package-lock.json\nadmin/package-lock.json\n",
+			want: []DependencyChange{
+				{Dir: "web", Files: []string{"web/package-lock.json"}, Install: "npm ci"},
+				{Dir: "admin", Files: []string{"admin/package-lock.json"}, Install: "npm ci"},
+			},
+		},
+		{
+			name:  "paths only match in the repository",
+			files: "services/auth/requirements.txt\nrequirements.txt\n",
+			want:  []DependencyChange{{Dir: "services/auth", Files: []string{"services/auth/requirements.txt"}, Install: "pip install -r requirements.txt"}},
+		},
+		{
+			name:  "manifest without install command",
+			files: "Gemfile.lock\n",
+			want:  []DependencyChange{{Dir: ".", Files: []string{"Gemfile.lock"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := gitexectest.New(t)
+			fake.On("diff --name-only abc def --", gitexectest.Result{Stdout: tt.files})
+
+			got, err := DetectDependencyChanges(config.Repository{Path: "repo"}, "abc", "def", manifests)
+			if err != nil {
+				t.Fatalf("DetectDependencyChanges() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DetectDependencyChanges() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package engine
+
+import (
+	"fmt"
+
+	"git_cli_tool/config"
+	"git_cli_tool/git"
+)
+
+// DriftResult compares the current state of a repository with a recorded state
+type DriftResult struct {
+	RepoPath      string
+	RepoName      string
+	Branch        string // branch recorded in the history
+	Commit        string // HEAD recorded in the history, empty for older states
+	CurrentBranch string // "HEAD" when detached
+	CurrentCommit string
+	Ahead         int // commits on the current HEAD that are not in the recorded commit
+	Behind        int // commits in the recorded commit that are not on the current HEAD
+	StashName     string
+	StashCommit   string
+	StashExists   bool // the recorded stash is still in the stash list
+	Err           error
+}
+
+// BranchChanged reports whether another branch is checked out than was recorded
+func (d DriftResult) BranchChanged() bool {
+	return d.CurrentBranch != d.Branch
+}
+
+// Drifted reports whether the repository is no longer in the recorded state
+func (d DriftResult) Drifted() bool {
+	return d.BranchChanged() || (d.Commit != "" && d.CurrentCommit != d.Commit)
+}
+
+// CompareToState compares the branch, HEAD and stash of a repository with the
+// state recorded for it in the history
+func CompareToState(repo config.Repository, state config.RepositoryState) DriftResult {
+	repoPath := repo.Path
+	result := DriftResult{
+		RepoPath:    repoPath,
+		RepoName:    repo.Name(),
+		Branch:      state.Branch,
+		Commit:      state.Commit,
+		StashName:   state.StashName,
+		StashCommit: state.StashCommit,
+	}
+
+	status, err := git.GetWorkingTreeStatus(repoPath)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.CurrentBranch = status.Branch
+	if status.Detached {
+		result.CurrentBranch = "HEAD"
+	}
+	result.CurrentCommit = status.Head
+
+	if state.Commit != "" && state.Commit != status.Head {
+		if !git.CommitExists(repoPath, state.Commit) {
+			result.Err = fmt.Errorf("recorded commit %s no longer exists", state.Commit)
+			return result
+		}
+		if result.Ahead, result.Behind, result.Err = git.CountAheadBehind(repoPath, state.Commit, "HEAD"); result.Err != nil {
+			return result
+		}
+	}
+
+	if state.StashCommit != "" || state.StashName != "" {
+		var stash string
+		var err error
+		if state.StashCommit != "" {
+			stash, err = git.FindStashCommit(repoPath, state.StashCommit)
+		} else {
+			stash, err = git.FindStash(repoPath, state.StashName)
+		}
+		if err != nil {
+			result.Err = err
+			return result
+		}
+		result.StashExists = stash != ""
+	}
+	return result
+}
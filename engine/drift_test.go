@@ -0,0 +1,107 @@
+package engine
+
+import (
+	"testing"
+
+	"git_cli_tool/config"
+	"git_cli_tool/gitexec/gitexectest"
+)
+
+func TestCompareToState(t *testing.T) {
+	const head = "1111111111111111111111111111111111111111"
+	const recorded = "2222222222222222222222222222222222222222"
+
+	tests := []struct {
+		name        string
+		status      string
+		state       config.RepositoryState
+		responses   map[string]gitexectest.Result
+		wantDrifted bool
+		wantAhead   int
+		wantBehind  int
+		wantStash   bool
+		wantErr     bool
+	}{
+		{
+			name:   "unchanged",
+			status: "# branch.oid " + head + "\n# branch.head main\n",
+			state:  config.RepositoryState{Branch: "main", Commit: head},
+		},
+		{
+			name:        "other branch checked out",
+			status:      "# branch.oid " + head + "\n# branch.head feature/x\n",
+			state:       config.RepositoryState{Branch: "main"},
+			wantDrifted: true,
+		},
+		{
+			name:   "commits since the recorded commit",
+			status: "# branch.oid " + head + "\n# branch.head main\n",
+			state:  config.RepositoryState{Branch: "main", Commit: recorded},
+			responses: map[string]gitexectest.Result{
+				"rev-parse --verify": {},
+				"rev-list":           {Stdout: "1\t3\n"},
+			},
+			wantDrifted: true,
+			wantAhead:   3,
+			wantBehind:  1,
+		},
+		{
+			name:   "recorded commit was garbage collected",
+			status: "# branch.oid " + head + "\n# branch.head main\n",
+			state:  config.RepositoryState{Branch: "main", Commit: recorded},
+			responses: map[string]gitexectest.Result{
+				"rev-parse --verify": {ExitCode: 1},
+			},
+			wantErr: true,
+		},
+		{
+			name:   "detached HEAD recorded as HEAD",
+			status: "# branch.oid " + head + "\n# branch.head (detached)\n",
+			state:  config.RepositoryState{Branch: "HEAD", Commit: head},
+		},
+		{
+			name:   "recorded stash still exists",
+			status: "# branch.oid " + head + "\n# branch.head main\n",
+			state:  config.RepositoryState{Branch: "main", StashName: "GitSwitch: main-1"},
+			responses: map[string]gitexectest.Result{
+				"stash list": {Stdout: "stash@{0}: On main: GitSwitch: main-1\n"},
+			},
+			wantStash: true,
+		},
+		{
+			name:   "recorded stash was dropped",
+			status: "# branch.oid " + head + "\n# branch.head main\n",
+			state:  config.RepositoryState{Branch: "main", StashName: "GitSwitch: main-1"},
+			responses: map[string]gitexectest.Result{
+				"stash list": {Stdout: "stash@{0}: On main: WIP\n"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := gitexectest.New(t)
+			fake.On("status", gitexectest.Result{Stdout: tt.status})
+			for args, result := range tt.responses {
+				fake.On(args, result)
+			}
+
+			got := CompareToState(config.Repository{Path: "/work/api"}, tt.state)
+			if (got.Err != nil) != tt.wantErr {
+				t.Fatalf("CompareToState() error = %v, wantErr %v", got.Err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Drifted() != tt.wantDrifted {
+				t.Errorf("Drifted() = %v, want %v", got.Drifted(), tt.wantDrifted)
+			}
+			if got.Ahead != tt.wantAhead || got.Behind != tt.wantBehind {
+				t.Errorf("ahead/behind = %d/%d, want %d/%d", got.Ahead, got.Behind, tt.wantAhead, tt.wantBehind)
+			}
+			if got.StashExists != tt.wantStash {
+				t.Errorf("StashExists = %v, want %v", got.StashExists, tt.wantStash)
+			}
+		})
+	}
+}
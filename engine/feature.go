@@ -0,0 +1,123 @@
+package engine
+
+import (
+	"fmt"
+
+	"git_cli_tool/config"
+	"git_cli_tool/git"
+)
+
+// FeatureDoneResult holds the result of closing out a feature branch in a single repository
+type FeatureDoneResult struct {
+	RepoPath       string
+	RepoName       string
+	Branch         string
+	Base           string
+	Switched       bool // the repository was switched to the base branch
+	Pulled         bool
+	DeletedLocal   string // SHA the deleted local branch pointed to
+	DeletedRemote  bool
+	KeptLocal      bool // the local branch is not merged into the base branch and was kept
+	KeptRemote     bool // the remote branch is not merged into the base branch and was kept
+	DroppedStashes int
+	Err            error
+	HookErr        error // a post_pull hook failed after a successful pull
+}
+
+// FinishFeature closes out a feature branch in a repository: it switches to
+// the base branch, pulls it, deletes the feature branch locally and on the
+// remote and, once the local branch is gone, drops the GitSwitch stashes of the
+// branch. Branches that are not merged into the pulled base branch are kept
+// with their stashes unless force is set, e.g. after a squash merge, which git
+// cannot recognize as merged.
+func FinishFeature(repo config.Repository, branch string, base string, force bool) FeatureDoneResult {
+	result := FeatureDoneResult{
+		RepoPath: repo.Path,
+		RepoName: repo.Name(),
+		Branch:   branch,
+		Base:     base,
+	}
+	if branch == base {
+		result.Err = fmt.Errorf("%s is the base branch", branch)
+		return result
+	}
+
+	current, err := git.GetCurrentBranch(repo.Path)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if current != base {
+		if result.Err = git.SwitchToBranch(repo.Path, repo.Remote, base); result.Err != nil {
+			return result
+		}
+		result.Switched = true
+	}
+
+	// Fetch first, so the remote-tracking branch tells whether the remote branch was merged
+	if result.Err = git.Fetch(repo.Path, repo.Remote); result.Err != nil {
+		return result
+	}
+	pull := PullRepository(repo)
+	if pull.Err != nil {
+		result.Err = fmt.Errorf("pull failed: %w", pull.Err)
+		return result
+	}
+	result.Pulled = true
+	result.HookErr = pull.HookErr
+
+	mergedLocal, mergedRemote, err := git.ListMergedBranches(repo.Path, "HEAD", repo.Remote)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	localExists, err := git.CheckBranchExists(repo.Path, branch)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if localExists {
+		if force || contains(mergedLocal, branch) {
+			sha, _ := git.ResolveCommit(repo.Path, branch)
+			if result.Err = git.DeleteBranch(repo.Path, branch, true); result.Err != nil {
+				return result
+			}
+			result.DeletedLocal = sha
+		} else {
+			result.KeptLocal = true
+		}
+	}
+
+	remoteExists, err := git.RemoteHasBranch(repo.Path, repo.Remote, branch)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if remoteExists {
+		if force || contains(mergedRemote, branch) {
+			if result.Err = git.DeleteRemoteBranch(repo.Path, repo.Remote, branch); result.Err != nil {
+				return result
+			}
+			result.DeletedRemote = true
+		} else {
+			result.KeptRemote = true
+		}
+	}
+
+	// The stashes of a kept branch still belong to it
+	if result.DeletedLocal != "" || force {
+		result.DroppedStashes, result.Err = git.DropBranchStashes(repo.Path, branch)
+	}
+	return result
+}
+
+// contains reports whether a list of names contains name
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
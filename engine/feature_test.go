@@ -0,0 +1,84 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"git_cli_tool/config"
+	"git_cli_tool/gitexec/gitexectest"
+)
+
+func TestFinishFeature(t *testing.T) {
+	const sha = "1111111111111111111111111111111111111111"
+
+	tests := []struct {
+		name        string
+		merged      string
+		force       bool
+		wantDeleted bool
+	}{
+		{
+			name:        "merged branch is deleted",
+			merged:      "refs/heads/main\nrefs/heads/feature/x\nrefs/remotes/origin/main\nrefs/remotes/origin/feature/x\n",
+			wantDeleted: true,
+		},
+		{
+			name:   "unmerged branch is kept",
+			merged: "refs/heads/main\nrefs/remotes/origin/main\n",
+		},
+		{
+			name:        "unmerged branch is deleted with force",
+			merged:      "refs/heads/main\nrefs/remotes/origin/main\n",
+			force:       true,
+			wantDeleted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.Mkdir(filepath.Join(dir, ".git"), 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			fake := gitexectest.New(t)
+			fake.On("rev-parse --abbrev-ref HEAD", gitexectest.Result{Stdout: "main\n"})
+			fake.On("rev-parse", gitexectest.Result{Stdout: sha + "\n"})
+			fake.On("fetch", gitexectest.Result{})
+			fake.On("pull", gitexectest.Result{})
+			fake.On("for-each-ref --merged HEAD", gitexectest.Result{Stdout: tt.merged})
+			fake.On("show-ref", gitexectest.Result{})
+			fake.On("ls-remote", gitexectest.Result{})
+			fake.On("branch -D feature/x", gitexectest.Result{})
+			fake.On("push origin --delete feature/x", gitexectest.Result{})
+			fake.On("stash list", gitexectest.Result{Stdout: "stash@{0} On feature/x: GitSwitch: main\n"})
+			fake.On("stash drop", gitexectest.Result{})
+
+			result := FinishFeature(config.Repository{Path: dir, Remote: "origin"}, "feature/x", "main", tt.force)
+			if result.Err != nil {
+				t.Fatalf("FinishFeature() error = %v", result.Err)
+			}
+			if (result.DeletedLocal == sha) != tt.wantDeleted || result.DeletedRemote != tt.wantDeleted {
+				t.Errorf("FinishFeature() = %+v, want deleted %v", result, tt.wantDeleted)
+			}
+			if result.KeptLocal == tt.wantDeleted || result.KeptRemote == tt.wantDeleted {
+				t.Errorf("FinishFeature() = %+v, want kept %v", result, !tt.wantDeleted)
+			}
+			// The stashes of a kept branch are kept too
+			wantDropped := 0
+			if tt.wantDeleted {
+				wantDropped = 1
+			}
+			if result.DroppedStashes != wantDropped {
+				t.Errorf("DroppedStashes = %d, want %d", result.DroppedStashes, wantDropped)
+			}
+			if !tt.wantDeleted && fake.Ran("stash drop") {
+				t.Errorf("dropped the stashes of a kept branch; calls: %q", fake.Calls())
+			}
+			if fake.Ran("checkout") || fake.Ran("switch") {
+				t.Errorf("switched although already on the base branch; calls: %q", fake.Calls())
+			}
+		})
+	}
+}
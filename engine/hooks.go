@@ -0,0 +1,59 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"git_cli_tool/config"
+)
+
+// HookError is returned when a hook command fails
+type HookError struct {
+	Hook    string // e.g. "post_switch"
+	Command string
+	Output  string
+	Err     error
+}
+
+func (e *HookError) Error() string {
+	message := fmt.Sprintf("%s hook '%s' failed: %v", e.Hook, e.Command, e.Err)
+	if output := strings.TrimSpace(e.Output); output != "" {
+		// The last line usually says what went wrong
+		lines := strings.Split(output, "\n")
+		message += ": " + strings.TrimSpace(lines[len(lines)-1])
+	}
+	return message
+}
+
+// RunHooks runs hook commands with the shell in the repository directory, stopping at
+// the first failure. The hook name and repository path are passed to the commands in
+// the GIT_CLI_TOOL_HOOK and GIT_CLI_TOOL_REPO environment variables.
+func RunHooks(repo config.Repository, hook string, commands []string) error {
+	return runHooksIn(repo, repo.Path, hook, commands)
+}
+
+// runHooksIn is RunHooks with the commands run in dir instead of the repository directory
+func runHooksIn(repo config.Repository, dir string, hook string, commands []string) error {
+	for _, command := range commands {
+		cmd := shellCommand(command)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_CLI_TOOL_HOOK="+hook, "GIT_CLI_TOOL_REPO="+repo.Path)
+
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			return &HookError{Hook: hook, Command: command, Output: string(output), Err: err}
+		}
+	}
+	return nil
+}
+
+// shellCommand returns a command that runs a command line with the platform's shell
+func shellCommand(command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/C", command)
+	}
+	return exec.Command("sh", "-c", command)
+}
@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"git_cli_tool/config"
+	"git_cli_tool/git"
+)
+
+// MergeResult holds the result of merging a branch into the current branch of a single repository
+type MergeResult struct {
+	RepoPath string
+	RepoName string
+	Branch   string // current branch the ref was merged into
+	Ref      string // merged ref, e.g. origin/feature/base when there is no local branch
+	Skipped  string // why nothing was merged, e.g. the repository has no such branch
+	Success  bool
+	UpToDate bool
+	Message  string
+	Err      error
+}
+
+// MergeRepository merges a branch into whatever branch a repository is on,
+// using the local branch if it exists and otherwise the remote-tracking
+// branch. Repositories without the branch, and those on the branch itself,
+// are skipped. Conflicts are left in place for manual resolution.
+func MergeRepository(repo config.Repository, branch string, opts git.MergeOptions) MergeResult {
+	result := MergeResult{
+		RepoPath: repo.Path,
+		RepoName: repo.Name(),
+	}
+
+	if result.Err = git.ValidateRepository(repo.Path); result.Err != nil {
+		result.Message = "not a git repository"
+		return result
+	}
+
+	// Fetch from remote first; a failed fetch merges what was fetched before
+	git.Fetch(repo.Path, repo.Remote)
+
+	current, err := git.GetCurrentBranch(repo.Path)
+	switch {
+	case err != nil:
+		result.Err = err
+		result.Message = err.Error()
+		return result
+	case current == "HEAD":
+		result.Err = fmt.Errorf("detached HEAD")
+		result.Message = "detached HEAD, nothing to merge into"
+		return result
+	case current == branch:
+		result.Branch = current
+		result.Skipped = "already on " + branch
+		return result
+	}
+	result.Branch = current
+
+	ref, exists, err := git.ResolveBranch(repo.Path, repo.Remote, branch)
+	if err != nil {
+		result.Err = err
+		result.Message = err.Error()
+		return result
+	}
+	if !exists {
+		result.Skipped = fmt.Sprintf("no branch %s", branch)
+		return result
+	}
+	result.Ref = ref
+
+	output, err := git.MergeBranch(repo.Path, ref, opts)
+	switch {
+	case errors.Is(err, git.ErrMergeConflict):
+		result.Err = err
+		result.Message = "CONFLICT - resolve manually"
+	case err == git.ErrNotFastForward:
+		result.Err = err
+		result.Message = fmt.Sprintf("%s has diverged from %s, not possible to fast-forward", current, ref)
+	case err != nil:
+		result.Err = err
+		result.Message = err.Error()
+	case strings.Contains(output, "Already up to date"):
+		result.Success = true
+		result.UpToDate = true
+		result.Message = "already up to date"
+	default:
+		result.Success = true
+		result.Message = "merged successfully"
+	}
+	return result
+}
@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"git_cli_tool/config"
+	"git_cli_tool/git"
+	"git_cli_tool/gitexec/gitexectest"
+)
+
+func TestMergeRepository(t *testing.T) {
+	found := gitexectest.Result{}
+	missing := gitexectest.Result{ExitCode: 1}
+
+	tests := []struct {
+		name        string
+		current     string
+		local       gitexectest.Result
+		remote      gitexectest.Result
+		merge       gitexectest.Result
+		wantRef     string
+		wantSkipped bool
+		wantSuccess bool
+	}{
+		{
+			name:        "merges the local branch",
+			current:     "feature/x",
+			local:       found,
+			merge:       gitexectest.Result{Stdout: "Merge made by the 'ort' strategy.\n"},
+			wantRef:     "main",
+			wantSuccess: true,
+		},
+		{
+			name:        "falls back to the remote-tracking branch",
+			current:     "feature/x",
+			local:       missing,
+			remote:      found,
+			merge:       gitexectest.Result{Stdout: "Already up to date.\n"},
+			wantRef:     "origin/main",
+			wantSuccess: true,
+		},
+		{
+			name:        "no such branch",
+			current:     "feature/x",
+			local:       missing,
+			remote:      missing,
+			wantSkipped: true,
+		},
+		{
+			name:        "on the branch itself",
+			current:     "main",
+			wantSkipped: true,
+		},
+		{
+			name:    "conflict",
+			current: "feature/x",
+			local:   found,
+			merge:   gitexectest.Result{Stdout: "CONFLICT (content): Merge conflict in a.txt\n", ExitCode: 1},
+			wantRef: "main",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.Mkdir(filepath.Join(dir, ".git"), 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			fake := gitexectest.New(t)
+			fake.On("fetch", found)
+			fake.On("rev-parse --abbrev-ref HEAD", gitexectest.Result{Stdout: tt.current + "\n"})
+			fake.On("show-ref --verify --quiet refs/heads/main", tt.local)
+			fake.On("show-ref --verify --quiet refs/remotes/origin/main", tt.remote)
+			fake.On("merge", tt.merge)
+
+			result := MergeRepository(config.Repository{Path: dir, Remote: "origin"}, "main", git.MergeOptions{})
+			if result.Success != tt.wantSuccess || (result.Skipped != "") != tt.wantSkipped || result.Ref != tt.wantRef {
+				t.Errorf("MergeRepository() = %+v", result)
+			}
+			if merged := fake.Ran("merge"); merged != (tt.wantRef != "") {
+				t.Errorf("merged = %v; calls: %q", merged, fake.Calls())
+			}
+		})
+	}
+}
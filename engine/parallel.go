@@ -0,0 +1,90 @@
+// Package engine runs operations across many repositories. It holds the
+// multi-repository orchestration of the command line tool without printing
+// anything or exiting, so it can be embedded in other programs: results and
+// errors are returned to the caller.
+package engine
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"git_cli_tool/config"
+	"git_cli_tool/git"
+)
+
+// ErrSkipped is reported for repositories that were not processed because an
+// earlier repository failed in fail-fast mode
+var ErrSkipped = errors.New("skipped after an earlier failure (--fail-fast)")
+
+// Progress is notified when each repository of a parallel operation starts and finishes
+type Progress interface {
+	Start(name string)
+	Done(name string)
+}
+
+// ParallelOptions controls how multi-repository operations are run
+type ParallelOptions struct {
+	Jobs     int      // maximum number of repositories processed at once (0 = all at once)
+	FailFast bool     // stop starting new repositories after the first failure
+	Progress Progress // optional, receives the repository names as they start and finish
+}
+
+// ForEachRepository runs fn for every repository (with its index) on a pool of workers
+// and returns the error of each repository, in the same order. With FailFast, repositories
+// that have not been started when a failure occurs are not run and get ErrSkipped.
+func ForEachRepository(repositories []config.Repository, opts ParallelOptions, fn func(i int, repo config.Repository) error) []error {
+	errs := make([]error, len(repositories))
+
+	jobs := opts.Jobs
+	if jobs <= 0 || jobs > len(repositories) {
+		jobs = len(repositories)
+	}
+
+	var failed atomic.Bool
+	var wg sync.WaitGroup
+	indexes := make(chan int)
+
+	wg.Add(jobs)
+	for w := 0; w < jobs; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if opts.FailFast && failed.Load() {
+					errs[i] = ErrSkipped
+					if opts.Progress != nil {
+						opts.Progress.Done(repositories[i].Name())
+					}
+					continue
+				}
+
+				name := repositories[i].Name()
+				if opts.Progress != nil {
+					opts.Progress.Start(name)
+				}
+				if err := fn(i, repositories[i]); err != nil {
+					errs[i] = err
+					failed.Store(true)
+				}
+				if opts.Progress != nil {
+					opts.Progress.Done(name)
+				}
+			}
+		}()
+	}
+
+	for i := range repositories {
+		indexes <- i
+	}
+	close(indexes)
+
+	wg.Wait()
+	return errs
+}
+
+// FetchRepositories fetches all repositories in parallel and returns the error of each repository
+func FetchRepositories(repositories []config.Repository, opts ParallelOptions) []error {
+	return ForEachRepository(repositories, opts, func(_ int, r config.Repository) error {
+		return git.FetchRepository(r)
+	})
+}
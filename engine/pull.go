@@ -0,0 +1,43 @@
+package engine
+
+import (
+	"git_cli_tool/config"
+	"git_cli_tool/git"
+	"git_cli_tool/gitexec"
+)
+
+// PullResult holds the result of pulling a single repository
+type PullResult struct {
+	RepoPath string
+	TagErr   error  // tags could not be synced; the pull itself may still have succeeded
+	Err      error  // the pull or its pre_pull hook failed
+	HookErr  error  // a post_pull hook failed after a successful pull
+	Output   string // output of git pull
+}
+
+// PullRepository syncs the tags of a repository and pulls its current branch
+// from the configured remote, running the repository's pre_pull and post_pull hooks around it
+func PullRepository(repo config.Repository) PullResult {
+	result := PullResult{RepoPath: repo.Path}
+
+	if result.Err = RunHooks(repo, "pre_pull", repo.Hooks.PrePull); result.Err != nil {
+		return result
+	}
+
+	// Sync tags before pulling
+	result.TagErr = git.SyncTags(repo.Path, repo.Remote)
+
+	// Pull the current branch from the configured remote when it is known
+	pullArgs := []string{"-C", repo.Path, "pull"}
+	if branch, err := git.GetCurrentBranch(repo.Path); err == nil && branch != "HEAD" {
+		pullArgs = append(pullArgs, repo.Remote, branch)
+	}
+	output, err := gitexec.Command(pullArgs...).CombinedOutput()
+	result.Output = string(output)
+	result.Err = git.WrapAuthFailure(err, result.Output)
+
+	if err == nil {
+		result.HookErr = RunHooks(repo, "post_pull", repo.Hooks.PostPull)
+	}
+	return result
+}
@@ -0,0 +1,136 @@
+package engine
+
+import (
+	"path/filepath"
+	"strings"
+
+	"git_cli_tool/config"
+	"git_cli_tool/git"
+	"git_cli_tool/gitexec"
+)
+
+// PushResult holds the result of pushing a single repository
+type PushResult struct {
+	RepoPath   string
+	RepoName   string
+	Branch     string
+	Success    bool
+	Message    string
+	Published  bool
+	AuthFailed bool // the remote asked for credentials or rejected them
+}
+
+// PublishResult holds the result of publishing the current branch of a single repository
+type PublishResult struct {
+	RepoPath  string
+	RepoName  string
+	Branch    string
+	Upstream  string // the upstream the branch already had, if it was published before
+	Skipped   string // why there is nothing to publish, e.g. a detached HEAD
+	Published bool
+	Err       error
+}
+
+// PublishRepository pushes the current branch of a repository to the remote
+// and sets it as the branch's upstream, unless the branch already has an
+// upstream. Nothing is pushed for branches that are already published.
+func PublishRepository(repo config.Repository) PublishResult {
+	result := PublishResult{
+		RepoPath: repo.Path,
+		RepoName: repo.Name(),
+	}
+
+	status, err := git.GetWorkingTreeStatusWithoutCounts(repo.Path)
+	switch {
+	case err != nil:
+		result.Err = err
+	case status.Detached:
+		result.Skipped = "detached HEAD"
+	case status.Head == "":
+		result.Branch = status.Branch
+		result.Skipped = "no commits"
+	case status.Upstream != "":
+		result.Branch = status.Branch
+		result.Upstream = status.Upstream
+	default:
+		result.Branch = status.Branch
+		result.Err = git.PushBranch(repo.Path, repo.Remote, status.Branch)
+		result.Published = result.Err == nil
+	}
+	return result
+}
+
+// PushRepository pushes the current branch of a repository, publishing it
+// (setting its upstream) on the remote when it has no upstream yet
+func PushRepository(repo config.Repository) PushResult {
+	remote := repo.Remote
+	absPath, err := filepath.Abs(repo.Path)
+
+	result := PushResult{
+		RepoPath: repo.Path,
+		RepoName: repo.Name(),
+	}
+
+	if err != nil {
+		result.Message = "failed to resolve path"
+		return result
+	}
+
+	// Check if repository exists
+	if err := git.ValidateRepository(absPath); err != nil {
+		result.Message = "not a git repository"
+		return result
+	}
+
+	// Get current branch
+	branch, err := git.GetCurrentBranch(absPath)
+	if err != nil {
+		result.Message = "failed to get current branch"
+		return result
+	}
+	result.Branch = branch
+
+	// Check if upstream is set
+	upstreamCmd := gitexec.Command("-C", absPath, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{u}")
+	upstreamOutput, upstreamErr := upstreamCmd.CombinedOutput()
+
+	if upstreamErr != nil || strings.TrimSpace(string(upstreamOutput)) == "" {
+		// No upstream set, publish the branch
+		pushCmd := gitexec.Command("-C", absPath, "push", "-u", remote, branch)
+		output, err := pushCmd.CombinedOutput()
+		if err != nil {
+			result.Message = strings.TrimSpace(string(output))
+			if result.Message == "" {
+				result.Message = err.Error()
+			}
+			result.AuthFailed = git.IsAuthFailure(result.Message)
+			return result
+		}
+		result.Success = true
+		result.Published = true
+		result.Message = "published and pushed"
+		return result
+	}
+
+	// Upstream exists, regular push
+	pushCmd := gitexec.Command("-C", absPath, "push")
+	output, err := pushCmd.CombinedOutput()
+	if err != nil {
+		result.Message = strings.TrimSpace(string(output))
+		if result.Message == "" {
+			result.Message = err.Error()
+		}
+		result.AuthFailed = git.IsAuthFailure(result.Message)
+		return result
+	}
+
+	result.Success = true
+	outputStr := strings.TrimSpace(string(output))
+	if strings.Contains(outputStr, "Everything up-to-date") {
+		result.Message = "up to date"
+	} else {
+		result.Message = "pushed"
+	}
+
+	return result
+}
@@ -0,0 +1,55 @@
+package engine
+
+import (
+	"testing"
+
+	"git_cli_tool/config"
+	"git_cli_tool/gitexec/gitexectest"
+)
+
+func TestPublishRepository(t *testing.T) {
+	const head = "1111111111111111111111111111111111111111"
+
+	tests := []struct {
+		name          string
+		status        string
+		wantPublished bool
+		wantUpstream  string
+		wantSkipped   bool
+	}{
+		{
+			name:          "unpublished branch",
+			status:        "# branch.oid " + head + "\n# branch.head feature/x\n",
+			wantPublished: true,
+		},
+		{
+			name:         "already published",
+			status:       "# branch.oid " + head + "\n# branch.head feature/x\n# branch.upstream origin/feature/x\n",
+			wantUpstream: "origin/feature/x",
+		},
+		{
+			name:        "detached HEAD",
+			status:      "# branch.oid " + head + "\n# branch.head (detached)\n",
+			wantSkipped: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := gitexectest.New(t)
+			fake.On("status", gitexectest.Result{Stdout: tt.status})
+			fake.On("push", gitexectest.Result{})
+
+			result := PublishRepository(config.Repository{Path: "repo", Remote: "origin"})
+			if result.Err != nil {
+				t.Fatalf("PublishRepository() error = %v", result.Err)
+			}
+			if result.Published != tt.wantPublished || result.Upstream != tt.wantUpstream || (result.Skipped != "") != tt.wantSkipped {
+				t.Errorf("PublishRepository() = %+v", result)
+			}
+			if pushed := fake.Ran("push -u origin feature/x"); pushed != tt.wantPublished {
+				t.Errorf("pushed = %v, want %v; calls: %q", pushed, tt.wantPublished, fake.Calls())
+			}
+		})
+	}
+}
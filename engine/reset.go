@@ -0,0 +1,131 @@
+package engine
+
+import (
+	"fmt"
+	"time"
+
+	"git_cli_tool/config"
+	"git_cli_tool/git"
+)
+
+// ResetResult holds the result of resetting a single repository to its upstream
+type ResetResult struct {
+	RepoPath string
+	RepoName string
+	Branch   string
+	Upstream string
+	From     string // HEAD before the reset
+	To       string // HEAD after the reset
+	Err      error
+}
+
+// CapturedChanges selects which uncommitted changes CaptureRepositoryState
+// records besides the branch and HEAD: those the following command discards
+type CapturedChanges int
+
+const (
+	CaptureNoChanges CapturedChanges = iota // the command keeps the working tree, e.g. sync
+	CaptureTracked                          // changes to tracked files, discarded by a hard reset
+	CaptureUntracked                        // untracked files that are not ignored, deleted by clean
+)
+
+// CaptureRepositoryState records the branch, HEAD and uncommitted changes of a
+// repository, so it can be restored after a destructive operation. The changes
+// are written to a patch file named after the repository and timestamp.
+func CaptureRepositoryState(repo config.Repository, timestamp time.Time, changes CapturedChanges) (config.RepositoryState, error) {
+	var state config.RepositoryState
+
+	status, err := git.GetWorkingTreeStatus(repo.Path)
+	if err != nil {
+		return state, err
+	}
+	state.Branch = status.Branch
+	if status.Detached {
+		state.Branch = "HEAD"
+	}
+	state.Commit = status.Head
+
+	patch := ""
+	switch {
+	case changes == CaptureTracked && status.StagedChanges+status.UnstagedChanges+status.Conflicts > 0:
+		patch, err = git.DiffHead(repo.Path)
+	case changes == CaptureUntracked && status.UntrackedFiles > 0:
+		patch, err = git.DiffUntracked(repo.Path)
+	}
+	if err != nil {
+		return state, err
+	}
+	if patch != "" {
+		if state.Patch, err = config.SavePatch(repo, timestamp, patch); err != nil {
+			return state, err
+		}
+	}
+	return state, nil
+}
+
+// CaptureBranchCommit adds the commit of a local branch other than the current
+// one to a recorded repository state, for commands that change that branch,
+// like sync merging into it. A branch that does not exist locally is left out.
+func CaptureBranchCommit(repo config.Repository, state *config.RepositoryState, branch string) {
+	if branch == "" || branch == state.Branch {
+		return
+	}
+	commit, err := git.ResolveCommit(repo.Path, "refs/heads/"+branch)
+	if err != nil {
+		return
+	}
+	if state.Branches == nil {
+		state.Branches = make(map[string]string)
+	}
+	state.Branches[branch] = commit
+}
+
+// CaptureBranchStashes records the GitSwitch stashes of a branch in the state
+// of a repository, for commands that drop them, so that revert can put them back
+func CaptureBranchStashes(repo config.Repository, state *config.RepositoryState, branch string) error {
+	stashes, err := git.BranchStashes(repo.Path, branch)
+	if err != nil {
+		return err
+	}
+	for _, stash := range stashes {
+		state.Stashes = append(state.Stashes, config.StashRecord{Commit: stash.Commit, Subject: stash.Subject})
+	}
+	return nil
+}
+
+// ResetToUpstream fetches a repository (unless fetch is false) and hard-resets its
+// current branch to the upstream tracking branch, discarding local commits and
+// uncommitted changes to tracked files
+func ResetToUpstream(repo config.Repository, fetch bool) ResetResult {
+	result := ResetResult{RepoPath: repo.Path, RepoName: repo.Name()}
+
+	if fetch {
+		if result.Err = git.FetchRepository(repo); result.Err != nil {
+			return result
+		}
+	}
+
+	status, err := git.GetWorkingTreeStatus(repo.Path)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Branch = status.Branch
+	result.Upstream = status.Upstream
+	result.From = status.Head
+
+	switch {
+	case status.Detached:
+		result.Err = fmt.Errorf("HEAD is detached")
+		return result
+	case status.Upstream == "":
+		result.Err = fmt.Errorf("branch %s has no upstream", status.Branch)
+		return result
+	}
+
+	if result.Err = git.ResetHard(repo.Path, status.Upstream); result.Err != nil {
+		return result
+	}
+	result.To, result.Err = git.GetHeadCommit(repo.Path)
+	return result
+}
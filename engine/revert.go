@@ -0,0 +1,367 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+
+	"git_cli_tool/config"
+	"git_cli_tool/git"
+)
+
+// Reasons a repository is not reverted
+const (
+	SkipNoBranch = "no branch recorded in history"
+	SkipDisabled = "disabled in configuration"
+)
+
+// RevertResult holds the result of reverting a single repository
+type RevertResult struct {
+	RepoPath       string
+	Branch         string          // branch recorded in the history, "HEAD" for a detached HEAD
+	Commit         string          // commit recorded in the history, if any
+	Skipped        string          // reason the repository was not reverted, if any
+	StashApplied   bool            // the recorded stash was applied
+	Recreated      bool            // the recorded branch no longer existed and was created at the recorded commit
+	CommitRestored bool            // the branch was fast-forwarded or reset back to the recorded commit
+	ResetFrom      string          // commit the branch was reset from with resetCommits; newer commits are left in the reflog
+	Branches       []BranchRestore // other recorded branches that were moved back to their commits
+	PatchApplied   bool            // the recorded patch of uncommitted changes was applied
+	StashesStored  int             // recorded stashes that were dropped and put back into the stash list
+	Err            error           // switching the branch failed, or ErrSkipped after an earlier failure
+	StashErr       error           // the branch was restored but the recorded stash could not be applied
+	RestoreErr     error           // the branch was restored but the recorded commit or patch could not be
+}
+
+// BranchRestore is a branch other than the recorded one that revert moved back
+// to its recorded commit
+type BranchRestore struct {
+	Branch    string
+	Commit    string // recorded commit the branch points at again
+	From      string // commit the branch was reset from with resetCommits, if it was not fast-forwarded
+	Recreated bool   // the branch no longer existed and was created at the commit
+}
+
+// Failed reports whether the repository could not be fully reverted
+func (r RevertResult) Failed() bool {
+	return (r.Err != nil && r.Err != ErrSkipped) || r.StashErr != nil || r.RestoreErr != nil
+}
+
+// StateCoverage compares the repositories recorded in a history state with the
+// configured ones. It returns the enabled repositories the state has no entry
+// for and that were not configured when it was recorded, in configuration
+// order, and the sorted recorded paths that are no longer configured.
+// Repositories that were configured but left out of the state, e.g. the ones
+// clean had nothing to do in, are covered on purpose. For states that do not
+// list the repositories configured at the time, every enabled repository
+// without an entry is returned.
+func StateCoverage(state config.BranchState, repositories []config.Repository) ([]config.Repository, []string) {
+	recordedWith := make(map[string]bool)
+	for _, repoPath := range state.Configured {
+		recordedWith[repoPath] = true
+	}
+
+	configured := make(map[string]bool)
+	var unrecorded []config.Repository
+	for _, repo := range repositories {
+		configured[repo.Path] = true
+		if _, ok := state.Repositories[repo.Path]; !ok && !repo.Disabled && !recordedWith[repo.Path] {
+			unrecorded = append(unrecorded, repo)
+		}
+	}
+
+	var unconfigured []string
+	for repoPath := range state.Repositories {
+		if !configured[repoPath] {
+			unconfigured = append(unconfigured, repoPath)
+		}
+	}
+	sort.Strings(unconfigured)
+	return unrecorded, unconfigured
+}
+
+// StateRepositories returns the repositories recorded in a history state that
+// keep selects, sorted by path. Recorded paths that are still configured get
+// their configured repository, so that selection by alias or label and the
+// remote apply; the others get a repository with just the path.
+func StateRepositories(state config.BranchState, repositories []config.Repository, keep func(config.Repository) bool) []config.Repository {
+	configured := make(map[string]config.Repository)
+	for _, repo := range repositories {
+		configured[repo.Path] = repo
+	}
+
+	var selected []config.Repository
+	for repoPath := range state.Repositories {
+		repo, ok := configured[repoPath]
+		if !ok {
+			repo = config.Repository{Path: repoPath}
+		}
+		if keep(repo) {
+			selected = append(selected, repo)
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool { return selected[i].Path < selected[j].Path })
+	return selected
+}
+
+// RevertToState reverts all repositories to the state described in the history,
+// returning one result per recorded repository, sorted by path.
+// The configured repositories are used to look up the remote of each recorded path;
+// repositories that are disabled in the configuration are left untouched.
+// For states recorded before a command, branches only move forward to the
+// recorded commit, unless resetCommits is set, which resets them to it, e.g. to
+// undo a sync.
+// With failFast, the remaining repositories are not reverted after the first failure
+// and get ErrSkipped. An error is returned if any repository could not be reverted.
+func RevertToState(state config.BranchState, repositories []config.Repository, applyStashes bool, resetCommits bool, failFast bool) ([]RevertResult, error) {
+	remotes := make(map[string]string)
+	disabled := make(map[string]bool)
+	for _, repo := range repositories {
+		remotes[repo.Path] = repo.Remote
+		disabled[repo.Path] = repo.Disabled
+	}
+
+	paths := make([]string, 0, len(state.Repositories))
+	for repoPath := range state.Repositories {
+		paths = append(paths, repoPath)
+	}
+	sort.Strings(paths)
+
+	results := make([]RevertResult, 0, len(paths))
+	failCount := 0
+	for _, repoPath := range paths {
+		branchInfo := state.Repositories[repoPath]
+		result := RevertResult{RepoPath: repoPath, Branch: branchInfo.Branch, Commit: branchInfo.Commit}
+
+		switch {
+		case failFast && failCount > 0:
+			result.Err = ErrSkipped
+		case branchInfo.Branch == "":
+			// Shouldn't happen, but just in case
+			result.Skipped = SkipNoBranch
+		case disabled[repoPath]:
+			result.Skipped = SkipDisabled
+		default:
+			revertRepository(&result, remotes[repoPath], branchInfo, state.Command != "", applyStashes, resetCommits)
+		}
+
+		if result.Failed() {
+			failCount++
+		}
+		results = append(results, result)
+	}
+
+	if failCount > 0 {
+		return results, fmt.Errorf("%d repositories could not be reverted", failCount)
+	}
+	return results, nil
+}
+
+// revertRepository switches a repository back to its recorded branch and, if
+// requested, re-applies the stash recorded with it. States recorded before a
+// command (restoreCommits) also restore the recorded commits, recreating deleted
+// branches, the dropped stashes and the uncommitted changes; in the others, such
+// as those of switch, the commit is only informative.
+func revertRepository(result *RevertResult, remote string, state config.RepositoryState, restoreCommits bool, applyStashes bool, resetCommits bool) {
+	if remote == "" {
+		remote = config.DefaultRemote
+	}
+
+	// A detached HEAD is restored by checking out the recorded commit again
+	detached := result.Branch == "HEAD"
+	switch {
+	case detached && state.Commit == "":
+		result.Err = fmt.Errorf("detached HEAD recorded without its commit")
+		return
+	case detached:
+		if result.Err = git.DetachHead(result.RepoPath, remote, state.Commit); result.Err != nil {
+			return
+		}
+	default:
+		// A branch the command deleted, e.g. feature done, is created again at the recorded commit
+		if restoreCommits && state.Commit != "" {
+			if exists, err := git.CheckBranchExists(result.RepoPath, result.Branch); err == nil && !exists {
+				if result.Err = recreateBranch(result.RepoPath, result.Branch, state.Commit); result.Err != nil {
+					return
+				}
+				result.Recreated = true
+			}
+		}
+		if result.Err = git.SwitchToBranch(result.RepoPath, remote, result.Branch); result.Err != nil {
+			return
+		}
+	}
+
+	// Other branches the command changed, e.g. the one sync merged into, and the
+	// stashes it dropped do not depend on the checked out commit, so they come first
+	if restoreCommits {
+		branches := make([]string, 0, len(state.Branches))
+		for branch := range state.Branches {
+			branches = append(branches, branch)
+		}
+		sort.Strings(branches)
+		for _, branch := range branches {
+			commit := state.Branches[branch]
+			exists, err := git.CheckBranchExists(result.RepoPath, branch)
+			if err != nil {
+				result.RestoreErr = err
+				return
+			}
+			if !exists {
+				if result.RestoreErr = recreateBranch(result.RepoPath, branch, commit); result.RestoreErr != nil {
+					return
+				}
+				result.Branches = append(result.Branches, BranchRestore{Branch: branch, Commit: commit, Recreated: true})
+				continue
+			}
+			from, moved, err := restoreBranch(result.RepoPath, branch, commit, resetCommits)
+			if err != nil {
+				result.RestoreErr = err
+				return
+			}
+			if moved {
+				result.Branches = append(result.Branches, BranchRestore{Branch: branch, Commit: commit, From: from})
+			}
+		}
+
+		// Stashes the command dropped, e.g. those of the branch feature done deleted
+		if result.StashesStored, result.RestoreErr = restoreStashes(result.RepoPath, state.Stashes); result.RestoreErr != nil {
+			return
+		}
+	}
+
+	// The commit comes before the stash and patch, which were recorded on top of it
+	if restoreCommits && state.Commit != "" && !detached {
+		if result.ResetFrom, result.CommitRestored, result.RestoreErr = restoreCommit(result.RepoPath, state.Commit, resetCommits); result.RestoreErr != nil {
+			return
+		}
+	}
+
+	// States recorded with the stash SHA apply exactly that stash; older ones search by name
+	if applyStashes && (state.StashCommit != "" || state.StashName != "") {
+		if state.StashCommit != "" {
+			result.StashErr = git.ApplyStashCommit(result.RepoPath, state.StashCommit)
+		} else {
+			result.StashErr = git.ApplyStash(result.RepoPath, state.StashName)
+		}
+		result.StashApplied = result.StashErr == nil
+	}
+
+	if state.Patch != "" {
+		if err := git.ApplyPatch(result.RepoPath, state.Patch); err != nil {
+			result.RestoreErr = fmt.Errorf("failed to apply the recorded changes from %s: %v", state.Patch, err)
+			return
+		}
+		result.PatchApplied = true
+	}
+}
+
+// restoreCommit moves the current branch of a repository back to the recorded
+// commit. It only fast-forwards, so commits made after the state was recorded
+// are never lost; a branch that has moved past the commit, e.g. by a sync
+// merge, or diverged from it is an error, unless reset is set, which resets
+// it with git reset --hard. It returns the commit the branch was reset from
+// and whether the branch was moved.
+func restoreCommit(repoPath string, commit string, reset bool) (string, bool, error) {
+	head, err := git.GetHeadCommit(repoPath)
+	if err != nil {
+		return "", false, err
+	}
+	if head == commit {
+		return "", false, nil
+	}
+
+	if reset {
+		status, err := git.GetWorkingTreeStatusWithoutCounts(repoPath)
+		if err != nil {
+			return "", false, err
+		}
+		if status.HasTrackedChanges() {
+			return "", false, fmt.Errorf("uncommitted changes, not reset to the recorded commit %s; commit or stash them first", commit)
+		}
+		if err := git.ResetHard(repoPath, commit); err != nil {
+			return "", false, err
+		}
+		return head, true, nil
+	}
+
+	if past, err := git.IsAncestor(repoPath, commit, head); err != nil {
+		return "", false, err
+	} else if past {
+		return "", false, fmt.Errorf("the branch has moved past the recorded commit %s, e.g. by a merge; revert with --reset to reset it there, or run 'git reset --hard %s'", commit, commit)
+	}
+	if err := git.FastForward(repoPath, commit); err != nil {
+		return "", false, fmt.Errorf("the branch has diverged from the recorded commit %s; revert with --reset to reset it there, or run 'git reset --hard %s': %v", commit, commit, err)
+	}
+	return "", true, nil
+}
+
+// restoreBranch moves a local branch that is not checked out back to the
+// recorded commit, by the rules of restoreCommit. It returns the commit the
+// branch was reset from and whether the branch was moved.
+func restoreBranch(repoPath string, branch string, commit string, reset bool) (string, bool, error) {
+	current, err := git.ResolveCommit(repoPath, "refs/heads/"+branch)
+	if err != nil {
+		return "", false, fmt.Errorf("branch %s no longer exists, recreate it with 'git branch %s %s'", branch, branch, commit)
+	}
+	if current == commit {
+		return "", false, nil
+	}
+
+	from := ""
+	if reset {
+		from = current
+	} else if past, err := git.IsAncestor(repoPath, commit, current); err != nil {
+		return "", false, err
+	} else if past {
+		return "", false, fmt.Errorf("branch %s has moved past the recorded commit %s, e.g. by a merge; revert with --reset to reset it there, or run 'git branch -f %s %s'", branch, commit, branch, commit)
+	} else if behind, err := git.IsAncestor(repoPath, current, commit); err != nil {
+		return "", false, err
+	} else if !behind {
+		return "", false, fmt.Errorf("branch %s has diverged from the recorded commit %s; revert with --reset to reset it there, or run 'git branch -f %s %s'", branch, commit, branch, commit)
+	}
+	if err := git.MoveBranch(repoPath, branch, commit, current); err != nil {
+		return "", false, err
+	}
+	return from, true, nil
+}
+
+// recreateBranch creates a branch that no longer exists at its recorded commit
+func recreateBranch(repoPath string, branch string, commit string) error {
+	if !git.CommitExists(repoPath, commit) {
+		return fmt.Errorf("branch %s no longer exists and its recorded commit %s is gone", branch, commit)
+	}
+	return git.MoveBranch(repoPath, branch, commit, "")
+}
+
+// restoreStashes puts the recorded stashes that are no longer in the stash list
+// back into it, in their recorded order, and returns how many it stored
+func restoreStashes(repoPath string, stashes []config.StashRecord) (int, error) {
+	if len(stashes) == 0 {
+		return 0, nil
+	}
+	current, err := git.ListStashes(repoPath)
+	if err != nil {
+		return 0, err
+	}
+	listed := make(map[string]bool)
+	for _, stash := range current {
+		listed[stash.Commit] = true
+	}
+
+	// The stashes are recorded newest first and each one is stored on top
+	stored := 0
+	for i := len(stashes) - 1; i >= 0; i-- {
+		stash := stashes[i]
+		if listed[stash.Commit] {
+			continue
+		}
+		if !git.CommitExists(repoPath, stash.Commit) {
+			return stored, fmt.Errorf("the recorded stash %s (%s) is gone", stash.Commit, stash.Subject)
+		}
+		if err := git.StoreStash(repoPath, stash.Commit, stash.Subject); err != nil {
+			return stored, err
+		}
+		stored++
+	}
+	return stored, nil
+}
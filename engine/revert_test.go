@@ -0,0 +1,364 @@
+package engine
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"git_cli_tool/config"
+	"git_cli_tool/gitexec/gitexectest"
+)
+
+func TestStateCoverage(t *testing.T) {
+	repositories := []config.Repository{
+		{Path: "repos/api"},
+		{Path: "repos/web"},
+		{Path: "repos/old", Disabled: true},
+	}
+
+	tests := []struct {
+		name             string
+		recorded         []string
+		configured       []string // configured when the state was recorded
+		wantUnrecorded   []string
+		wantUnconfigured []string
+	}{
+		{
+			name:     "state covers the configuration",
+			recorded: []string{"repos/api", "repos/web"},
+		},
+		{
+			name:           "repository added since the state was recorded",
+			recorded:       []string{"repos/api"},
+			configured:     []string{"repos/api"},
+			wantUnrecorded: []string{"repos/web"},
+		},
+		{
+			name:       "repository left out when the state was recorded",
+			recorded:   []string{"repos/api"},
+			configured: []string{"repos/api", "repos/web"},
+		},
+		{
+			name:           "state without the configured repositories",
+			recorded:       []string{"repos/api"},
+			wantUnrecorded: []string{"repos/web"},
+		},
+		{
+			name:             "repositories removed since the state was recorded",
+			recorded:         []string{"repos/web", "repos/lib", "repos/api", "repos/cli"},
+			wantUnconfigured: []string{"repos/cli", "repos/lib"},
+		},
+		{
+			name:             "disabled repositories need no entry but are still configured",
+			recorded:         []string{"repos/old", "repos/api", "repos/web"},
+			wantUnconfigured: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := config.BranchState{Repositories: make(map[string]config.RepositoryState), Configured: tt.configured}
+			for _, repoPath := range tt.recorded {
+				state.Repositories[repoPath] = config.RepositoryState{Branch: "main"}
+			}
+
+			unrecorded, unconfigured := StateCoverage(state, repositories)
+			var unrecordedPaths []string
+			for _, repo := range unrecorded {
+				unrecordedPaths = append(unrecordedPaths, repo.Path)
+			}
+			if !reflect.DeepEqual(unrecordedPaths, tt.wantUnrecorded) {
+				t.Errorf("StateCoverage() unrecorded = %v, want %v", unrecordedPaths, tt.wantUnrecorded)
+			}
+			if !reflect.DeepEqual(unconfigured, tt.wantUnconfigured) {
+				t.Errorf("StateCoverage() unconfigured = %v, want %v", unconfigured, tt.wantUnconfigured)
+			}
+		})
+	}
+}
+
+func TestRestoreCommit(t *testing.T) {
+	const (
+		recorded = "1111111111111111111111111111111111111111"
+		merged   = "2222222222222222222222222222222222222222"
+	)
+
+	tests := []struct {
+		name         string
+		head         string
+		isAncestor   int // exit code of merge-base --is-ancestor
+		ffExitCode   int
+		dirty        bool
+		reset        bool
+		wantRestored bool
+		wantFrom     string
+		wantErr      bool
+		wantRan      []string
+		wantNotRan   []string
+	}{
+		{
+			name:       "already at the recorded commit",
+			head:       recorded,
+			wantNotRan: []string{"merge", "reset"},
+		},
+		{
+			name:         "behind the recorded commit is fast-forwarded",
+			head:         merged,
+			isAncestor:   1,
+			wantRestored: true,
+			wantRan:      []string{"merge --ff-only --quiet " + recorded},
+		},
+		{
+			name:       "moved past the recorded commit by a sync",
+			head:       merged,
+			wantErr:    true,
+			wantNotRan: []string{"merge --ff-only", "reset"},
+		},
+		{
+			name:       "diverged from the recorded commit",
+			head:       merged,
+			isAncestor: 1,
+			ffExitCode: 128,
+			wantErr:    true,
+		},
+		{
+			name:         "reset to the recorded commit",
+			head:         merged,
+			reset:        true,
+			wantRestored: true,
+			wantFrom:     merged,
+			wantRan:      []string{"reset --hard --quiet " + recorded},
+		},
+		{
+			name:       "not reset with uncommitted changes",
+			head:       merged,
+			reset:      true,
+			dirty:      true,
+			wantErr:    true,
+			wantNotRan: []string{"reset"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := "# branch.oid " + tt.head + "\n# branch.head main\n"
+			if tt.dirty {
+				status += "1 .M N... 100644 100644 100644 3f2a9c1 3f2a9c1 README.md\n"
+			}
+
+			fake := gitexectest.New(t)
+			fake.On("rev-parse HEAD", gitexectest.Result{Stdout: tt.head + "\n"})
+			fake.On("merge-base --is-ancestor", gitexectest.Result{ExitCode: tt.isAncestor})
+			fake.On("merge --ff-only", gitexectest.Result{ExitCode: tt.ffExitCode})
+			fake.On("status", gitexectest.Result{Stdout: status})
+			fake.On("reset --hard", gitexectest.Result{})
+
+			from, restored, err := restoreCommit("repo", recorded, tt.reset)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("restoreCommit() error = %v, want error %v", err, tt.wantErr)
+			}
+			if restored != tt.wantRestored || from != tt.wantFrom {
+				t.Errorf("restoreCommit() = %q, %v, want %q, %v", from, restored, tt.wantFrom, tt.wantRestored)
+			}
+			for _, args := range tt.wantRan {
+				if !fake.Ran(args) {
+					t.Errorf("%q not run; calls: %q", args, fake.Calls())
+				}
+			}
+			for _, args := range tt.wantNotRan {
+				if fake.Ran(args) {
+					t.Errorf("%q run; calls: %q", args, fake.Calls())
+				}
+			}
+		})
+	}
+}
+
+func TestRestoreBranch(t *testing.T) {
+	const (
+		recorded = "1111111111111111111111111111111111111111"
+		current  = "2222222222222222222222222222222222222222"
+	)
+
+	tests := []struct {
+		name        string
+		current     string
+		pastExit    int // exit code of merge-base --is-ancestor recorded current
+		behindExit  int // exit code of merge-base --is-ancestor current recorded
+		reset       bool
+		wantMoved   bool
+		wantFrom    string
+		wantErr     bool
+		wantMoveRan bool
+	}{
+		{name: "unchanged", current: recorded},
+		{name: "moved past by a sync merge", current: current, wantErr: true},
+		{name: "behind is fast-forwarded", current: current, pastExit: 1, wantMoved: true, wantMoveRan: true},
+		{name: "diverged", current: current, pastExit: 1, behindExit: 1, wantErr: true},
+		{name: "reset", current: current, reset: true, wantMoved: true, wantFrom: current, wantMoveRan: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := gitexectest.New(t)
+			fake.On("rev-parse --verify --quiet refs/heads/feat^{commit}", gitexectest.Result{Stdout: tt.current + "\n"})
+			fake.On("merge-base --is-ancestor "+recorded+" "+current, gitexectest.Result{ExitCode: tt.pastExit})
+			fake.On("merge-base --is-ancestor "+current+" "+recorded, gitexectest.Result{ExitCode: tt.behindExit})
+			fake.On("update-ref", gitexectest.Result{})
+
+			from, moved, err := restoreBranch("repo", "feat", recorded, tt.reset)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("restoreBranch() error = %v, want error %v", err, tt.wantErr)
+			}
+			if moved != tt.wantMoved || from != tt.wantFrom {
+				t.Errorf("restoreBranch() = %q, %v, want %q, %v", from, moved, tt.wantFrom, tt.wantMoved)
+			}
+			move := "update-ref -m git_cli_tool revert refs/heads/feat " + recorded + " " + current
+			if fake.Ran(move) != tt.wantMoveRan {
+				t.Errorf("ran %q = %v, want %v; calls: %q", move, fake.Ran(move), tt.wantMoveRan, fake.Calls())
+			}
+		})
+	}
+}
+
+func TestRecreateBranch(t *testing.T) {
+	const (
+		kept    = "1111111111111111111111111111111111111111"
+		dropped = "2222222222222222222222222222222222222222"
+	)
+
+	fake := gitexectest.New(t)
+	fake.On("rev-parse --verify --quiet "+kept+"^{commit}", gitexectest.Result{Stdout: kept + "\n"})
+	fake.On("rev-parse --verify --quiet "+dropped+"^{commit}", gitexectest.Result{ExitCode: 1})
+	fake.On("update-ref", gitexectest.Result{})
+
+	if err := recreateBranch("repo", "feature/x", kept); err != nil {
+		t.Fatalf("recreateBranch() error = %v", err)
+	}
+	// An empty old value only creates the branch, it never moves an existing one
+	create := "update-ref -m git_cli_tool revert refs/heads/feature/x " + kept + " "
+	if calls := fake.Calls(); calls[len(calls)-1] != create {
+		t.Errorf("did not run %q; calls: %q", create, calls)
+	}
+	if err := recreateBranch("repo", "feature/y", dropped); err == nil {
+		t.Error("recreateBranch() succeeded for a garbage collected commit")
+	}
+}
+
+func TestRestoreStashes(t *testing.T) {
+	const (
+		listed  = "1111111111111111111111111111111111111111"
+		older   = "2222222222222222222222222222222222222222"
+		newer   = "3333333333333333333333333333333333333333"
+		dropped = "4444444444444444444444444444444444444444"
+	)
+
+	tests := []struct {
+		name       string
+		stashes    []config.StashRecord
+		wantStored []string
+		wantErr    bool
+	}{
+		{name: "nothing recorded"},
+		{
+			name:    "still in the stash list",
+			stashes: []config.StashRecord{{Commit: listed, Subject: "On feature/x: GitSwitch: main"}},
+		},
+		{
+			name: "dropped ones are stored oldest first",
+			stashes: []config.StashRecord{
+				{Commit: newer, Subject: "On feature/x: GitSwitch: main"},
+				{Commit: listed, Subject: "On main: GitSwitch: feature/x"},
+				{Commit: older, Subject: "On feature/x: GitSwitch: develop"},
+			},
+			wantStored: []string{
+				"stash store -m On feature/x: GitSwitch: develop " + older,
+				"stash store -m On feature/x: GitSwitch: main " + newer,
+			},
+		},
+		{
+			name:    "garbage collected",
+			stashes: []config.StashRecord{{Commit: dropped, Subject: "On feature/x: GitSwitch: main"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := gitexectest.New(t)
+			fake.On("stash list", gitexectest.Result{Stdout: "stash@{0}\x1f" + listed + "\x1fOn main: GitSwitch: feature/x\n"})
+			fake.On("rev-parse --verify --quiet "+dropped+"^{commit}", gitexectest.Result{ExitCode: 1})
+			fake.On("rev-parse --verify --quiet", gitexectest.Result{})
+			fake.On("stash store", gitexectest.Result{})
+
+			stored, err := restoreStashes("repo", tt.stashes)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("restoreStashes() error = %v, want error %v", err, tt.wantErr)
+			}
+			if stored != len(tt.wantStored) {
+				t.Errorf("restoreStashes() = %d, want %d", stored, len(tt.wantStored))
+			}
+			var stores []string
+			for _, call := range fake.Calls() {
+				if strings.HasPrefix(call, "stash store") {
+					stores = append(stores, call)
+				}
+			}
+			if !reflect.DeepEqual(stores, tt.wantStored) {
+				t.Errorf("stores = %q, want %q", stores, tt.wantStored)
+			}
+		})
+	}
+}
+
+func TestStateRepositories(t *testing.T) {
+	state := config.BranchState{Repositories: map[string]config.RepositoryState{
+		"repos/web": {Branch: "main"},
+		"repos/api": {Branch: "main"},
+		"repos/old": {Branch: "main"},
+	}}
+	repositories := []config.Repository{
+		{Path: "repos/api", Alias: "backend", Labels: []string{"team-x"}},
+		{Path: "repos/web", Labels: []string{"team-y"}},
+		{Path: "repos/lib", Labels: []string{"team-x"}},
+	}
+
+	tests := []struct {
+		name string
+		keep func(config.Repository) bool
+		want []string
+	}{
+		{
+			name: "all recorded repositories, sorted by path",
+			keep: func(config.Repository) bool { return true },
+			want: []string{"repos/api", "repos/old", "repos/web"},
+		},
+		{
+			name: "by label of the configured repository",
+			keep: func(r config.Repository) bool { return r.HasLabels([]string{"team-x"}) },
+			want: []string{"repos/api"},
+		},
+		{
+			name: "by alias of the configured repository",
+			keep: func(r config.Repository) bool { return r.Matches("backend") },
+			want: []string{"repos/api"},
+		},
+		{
+			name: "no longer configured repositories only match by path",
+			keep: func(r config.Repository) bool { return r.Matches("old") },
+			want: []string{"repos/old"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, repo := range StateRepositories(state, repositories, tt.keep) {
+				got = append(got, repo.Path)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("StateRepositories() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
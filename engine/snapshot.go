@@ -0,0 +1,37 @@
+package engine
+
+import (
+	"git_cli_tool/config"
+	"git_cli_tool/git"
+)
+
+// SnapshotRepository records the branch and HEAD of a repository for a named
+// snapshot. If patchDir is not empty, uncommitted changes, including untracked
+// files, are written to <patchDir>/<name>.patch so revert can re-apply them.
+func SnapshotRepository(repo config.Repository, patchDir string) (config.RepositoryState, error) {
+	var state config.RepositoryState
+
+	status, err := git.GetWorkingTreeStatus(repo.Path)
+	if err != nil {
+		return state, err
+	}
+	state.Branch = status.Branch
+	if status.Detached {
+		state.Branch = "HEAD"
+	}
+	state.Commit = status.Head
+
+	if patchDir == "" || !status.HasChanges() {
+		return state, nil
+	}
+	patch, err := git.DiffWorkingTree(repo.Path)
+	if err != nil {
+		return state, err
+	}
+	if patch != "" {
+		if state.Patch, err = config.WritePatch(patchDir, config.PatchName(repo), patch); err != nil {
+			return state, err
+		}
+	}
+	return state, nil
+}
@@ -0,0 +1,168 @@
+package engine
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"git_cli_tool/config"
+	"git_cli_tool/git"
+)
+
+// SwitchRepositories switches branches in the provided repositories in parallel, stashing
+// changes first when stashName is set, limited by stashOpts. branchesFor returns the fallback
+// order to use for each repository. One result per repository is returned in the same order.
+func SwitchRepositories(repositories []config.Repository, branchesFor func(config.Repository) []string, stashName string, stashOpts git.StashOptions, opts ParallelOptions) []git.SwitchResult {
+	return switchAll(repositories, opts, func(r config.Repository) git.SwitchResult {
+		return SwitchRepository(r, branchesFor(r), stashName, stashOpts)
+	})
+}
+
+// DetachRepositories checks out a tag or commit with a detached HEAD in the provided
+// repositories in parallel, stashing changes first when stashName is set.
+// One result per repository is returned in the same order.
+func DetachRepositories(repositories []config.Repository, ref string, stashName string, stashOpts git.StashOptions, opts ParallelOptions) []git.SwitchResult {
+	return switchAll(repositories, opts, func(r config.Repository) git.SwitchResult {
+		return DetachRepository(r, ref, stashName, stashOpts)
+	})
+}
+
+// switchAll runs switchRepo for every repository in parallel
+func switchAll(repositories []config.Repository, opts ParallelOptions, switchRepo func(config.Repository) git.SwitchResult) []git.SwitchResult {
+	results := make([]git.SwitchResult, len(repositories))
+
+	errs := ForEachRepository(repositories, opts, func(i int, r config.Repository) error {
+		result := switchRepo(r)
+		results[i] = result
+		if !result.Success {
+			return fmt.Errorf("%s", result.Message)
+		}
+		return result.HookErr
+	})
+
+	// Repositories skipped in fail-fast mode have no result yet
+	for i, err := range errs {
+		if err == ErrSkipped {
+			results[i] = git.SwitchResult{
+				RepoPath: repositories[i].Path,
+				RepoName: repositories[i].Name(),
+				Message:  "skipped",
+				Err:      err,
+			}
+		}
+	}
+
+	return results
+}
+
+// SwitchRepository stashes changes (when stashName is set, limited by stashOpts) and switches a single
+// repository to the first available branch of the fallback order, running the
+// repository's pre_switch and post_switch hooks around it
+func SwitchRepository(repo config.Repository, branches []string, stashName string, stashOpts git.StashOptions) git.SwitchResult {
+	return switchWithHooks(repo, branches, stashName, stashOpts, func() git.SwitchResult {
+		return git.SwitchBranchWithResult(repo.Path, repo.Remote, branches)
+	})
+}
+
+// DetachRepository stashes changes (when stashName is set, limited by stashOpts) and
+// checks out a tag or commit with a detached HEAD in a single repository, running the
+// repository's pre_switch and post_switch hooks around it
+func DetachRepository(repo config.Repository, ref string, stashName string, stashOpts git.StashOptions) git.SwitchResult {
+	return switchWithHooks(repo, []string{ref}, stashName, stashOpts, func() git.SwitchResult {
+		result := git.SwitchResult{
+			RepoPath:  repo.Path,
+			RepoName:  repo.Name(),
+			Attempted: []string{ref},
+		}
+		result.FromBranch, _ = git.GetCurrentBranch(repo.Path)
+
+		if result.FromBranch == "HEAD" {
+			head, err := git.GetHeadCommit(repo.Path)
+			if target, targetErr := git.ResolveCommit(repo.Path, ref); err == nil && targetErr == nil && head == target {
+				result.ToBranch = ref
+				result.Success = true
+				result.Detached = true
+				result.AlreadyOnIt = true
+				result.Message = "already at " + ref
+				return result
+			}
+		}
+
+		if result.Err = git.DetachHead(repo.Path, repo.Remote, ref); result.Err != nil {
+			result.Message = strings.SplitN(result.Err.Error(), "\n", 2)[0]
+			return result
+		}
+		result.ToBranch = ref
+		result.Success = true
+		result.Detached = true
+		result.Message = "detached at " + ref
+		return result
+	})
+}
+
+// switchWithHooks stashes changes (when stashName is set) and runs switchRepo,
+// running the repository's pre_switch and post_switch hooks around it
+func switchWithHooks(repo config.Repository, attempted []string, stashName string, stashOpts git.StashOptions, switchRepo func() git.SwitchResult) git.SwitchResult {
+	if err := RunHooks(repo, "pre_switch", repo.Hooks.PreSwitch); err != nil {
+		return git.SwitchResult{
+			RepoPath:  repo.Path,
+			RepoName:  repo.Name(),
+			Attempted: attempted,
+			Message:   err.Error(),
+			Err:       err,
+		}
+	}
+
+	stashCommit := ""
+	if stashName != "" {
+		var err error
+		stashCommit, err = git.StashChanges(repo.Path, stashName, stashOpts)
+		if err != nil {
+			return git.SwitchResult{
+				RepoPath:  repo.Path,
+				RepoName:  repo.Name(),
+				Attempted: attempted,
+				Message:   "stash failed",
+				Err:       err,
+			}
+		}
+	}
+
+	result := switchRepo()
+	result.RepoName = repo.Name()
+	result.Stashed = stashCommit != ""
+	result.StashCommit = stashCommit
+	// Nothing to do after the switch when the repository stayed on its branch
+	if result.Success && !result.AlreadyOnIt {
+		result.HookErr = RunHooks(repo, "post_switch", repo.Hooks.PostSwitch)
+	}
+	return result
+}
+
+// FindTargetBranch finds which branch of the fallback order a switch would use for a repository.
+// Returns the branch name and source ("local" or "remote"), or empty strings if none is found.
+func FindTargetBranch(repoPath string, remote string, branches []string) (string, string) {
+	absPath, err := filepath.Abs(repoPath)
+	if err != nil {
+		return "", ""
+	}
+
+	// Try each branch in order
+	for _, branch := range branches {
+		// Check if branch exists locally
+		exists, err := git.CheckBranchExists(absPath, branch)
+		if err == nil && exists {
+			return branch, "local"
+		}
+	}
+
+	// None found locally, ask the remote about all branches at once
+	onRemote := git.FindRemoteBranches(absPath, remote, branches)
+	for _, branch := range branches {
+		if onRemote[branch] {
+			return branch, "remote"
+		}
+	}
+
+	return "", ""
+}
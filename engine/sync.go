@@ -0,0 +1,182 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"git_cli_tool/config"
+	"git_cli_tool/git"
+)
+
+// Logger receives the progress messages of an operation on a repository.
+// log.RepoOutput implements it.
+type Logger interface {
+	PrintDebug(message string)
+}
+
+// SyncResult holds the result of syncing a single repository
+type SyncResult struct {
+	RepoPath       string
+	RepoName       string
+	TargetBranch   string
+	ParentBranches []string // parents merged in order, or the fallback branch when WasFallback is set
+	Success        bool
+	Message        string
+	WasFallback    bool
+	Conflict       bool        // a merge stopped on conflicts
+	Aborted        bool        // a merge stopped on conflicts and was aborted
+	Merges         []SyncMerge // merges done, in order; the failed merge is not included
+	HookErr        error       // a post_sync hook failed after a successful merge
+}
+
+// SyncMerge holds what merging one parent branch brought into the synced branch
+type SyncMerge struct {
+	Branch   string
+	UpToDate bool            // the branch already contained the parent branch
+	Commits  int             // commits brought in from the parent branch
+	Changes  git.DiffSummary // files and lines the merge changed on the branch
+}
+
+// SyncRepository switches a repository to targetBranch and merges its parent
+// branches into it in order, as set by opts, stopping at the first merge that
+// fails. Parents that do not exist are left out; fallbackBranch is merged when
+// there is no parent or none exists. The repository's pre_sync and post_sync
+// hooks run around it. Progress messages are written to out.
+func SyncRepository(out Logger, repo config.Repository, targetBranch string, parentBranches []string, fallbackBranch string, opts git.MergeOptions) SyncResult {
+	repoPath, remote := repo.Path, repo.Remote
+	absPath, err := filepath.Abs(repoPath)
+	if err != nil {
+		return SyncResult{
+			RepoPath: repoPath,
+			RepoName: repo.Name(),
+			Success:  false,
+			Message:  "failed to resolve path",
+		}
+	}
+
+	repoName := repo.Name()
+	result := SyncResult{
+		RepoPath:     absPath,
+		RepoName:     repoName,
+		TargetBranch: targetBranch,
+	}
+
+	// Check if it's a git repository
+	if err := git.ValidateRepository(absPath); err != nil {
+		result.Message = "not a git repository"
+		return result
+	}
+
+	if err := RunHooks(repo, "pre_sync", repo.Hooks.PreSync); err != nil {
+		result.Message = err.Error()
+		return result
+	}
+
+	// Fetch from remote first
+	out.PrintDebug(fmt.Sprintf("[%s] Fetching from remote...", repoName))
+	git.Fetch(absPath, remote) // Ignore fetch errors, continue anyway
+
+	// Check if target branch exists (local or remote)
+	targetExists, _ := git.CheckBranchExists(absPath, targetBranch)
+	if !targetExists {
+		// Check remote
+		remoteExists, _ := git.CheckRemoteBranchExists(absPath, remote, targetBranch)
+		if !remoteExists {
+			result.Message = fmt.Sprintf("branch '%s' not found", targetBranch)
+			return result
+		}
+	}
+
+	// Switch to target branch
+	out.PrintDebug(fmt.Sprintf("[%s] Switching to %s...", repoName, targetBranch))
+	switchResult := git.SwitchBranchWithResult(absPath, remote, []string{targetBranch})
+	if !switchResult.Success {
+		result.Message = fmt.Sprintf("failed to switch to '%s': %s", targetBranch, switchResult.Message)
+		return result
+	}
+
+	// Merge the parents that exist, in order, or the fallback branch if none does
+	for _, parent := range parentBranches {
+		if _, exists, _ := git.ResolveBranch(absPath, remote, parent); exists {
+			result.ParentBranches = append(result.ParentBranches, parent)
+		}
+	}
+	if len(result.ParentBranches) == 0 {
+		result.ParentBranches = []string{fallbackBranch}
+		result.WasFallback = true
+	}
+
+	for _, parent := range result.ParentBranches {
+		merge, message, conflict, aborted := mergeParent(out, repoName, absPath, remote, targetBranch, parent, opts)
+		if message != "" {
+			result.Conflict = conflict
+			result.Aborted = aborted
+			result.Message = message
+			if len(result.ParentBranches) > 1 {
+				result.Message = fmt.Sprintf("merging %s: %s", parent, message)
+			}
+			return result
+		}
+		result.Merges = append(result.Merges, merge)
+	}
+
+	result.Success = true
+	result.Message = "already up to date"
+	for _, merge := range result.Merges {
+		if !merge.UpToDate {
+			result.Message = "merged successfully"
+		}
+	}
+
+	result.HookErr = RunHooks(repo, "post_sync", repo.Hooks.PostSync)
+	return result
+}
+
+// mergeParent merges a parent branch into the current branch of a repository,
+// the remote-tracking branch if there is no local one. It returns what the
+// merge brought in, or a message telling why it failed, whether it stopped on
+// conflicts and whether the merge was aborted then.
+func mergeParent(out Logger, repoName, absPath, remote, targetBranch, parent string, opts git.MergeOptions) (SyncMerge, string, bool, bool) {
+	merge := SyncMerge{Branch: parent}
+
+	// Make sure the branch we're merging from exists
+	branchToMerge, exists, _ := git.ResolveBranch(absPath, remote, parent)
+	if !exists {
+		return merge, fmt.Sprintf("branch '%s' not found to merge from", parent), false, false
+	}
+
+	// Perform the merge
+	out.PrintDebug(fmt.Sprintf("[%s] Merging %s...", repoName, branchToMerge))
+	// Remember where the branch was, to tell how much the merge brings in
+	before, _ := git.GetHeadCommit(absPath)
+	incoming, _, _ := git.CountAheadBehind(absPath, "HEAD", branchToMerge)
+
+	mergeOutput, err := git.MergeBranch(absPath, branchToMerge, opts)
+	if err == git.ErrMergeConflict && opts.AbortOnConflict {
+		// The merge was aborted, the working tree is as it was before it
+		return merge, "needs manual sync (conflicts, merge aborted)", true, true
+	} else if errors.Is(err, git.ErrMergeConflict) {
+		// Leave conflicts in place for manual resolution
+		if err != git.ErrMergeConflict {
+			return merge, "CONFLICT - resolve manually (aborting the merge failed)", true, false
+		}
+		return merge, "CONFLICT - resolve manually", true, false
+	} else if err == git.ErrNotFastForward {
+		return merge, fmt.Sprintf("'%s' has diverged from '%s', not possible to fast-forward", targetBranch, branchToMerge), false, false
+	} else if err != nil {
+		return merge, err.Error(), false, false
+	}
+
+	// Check if there were actually changes merged
+	if strings.Contains(mergeOutput, "Already up to date") {
+		merge.UpToDate = true
+		return merge, "", false, false
+	}
+	merge.Commits = incoming
+	if before != "" {
+		merge.Changes, _ = git.GetDiffSummary(absPath, before, "HEAD")
+	}
+	return merge, "", false, false
+}
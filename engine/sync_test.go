@@ -0,0 +1,182 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"git_cli_tool/config"
+	"git_cli_tool/git"
+	"git_cli_tool/gitexec/gitexectest"
+)
+
+// discardLogger drops progress messages
+type discardLogger struct{}
+
+func (discardLogger) PrintDebug(message string) {}
+
+func TestSyncRepository(t *testing.T) {
+	found := gitexectest.Result{}
+	missing := gitexectest.Result{ExitCode: 1}
+
+	tests := []struct {
+		name         string
+		parents      []string
+		responses    map[string]gitexectest.Result
+		wantSuccess  bool
+		wantMessage  string
+		wantParent   string // parents merged, joined with ", "
+		wantFallback bool
+		wantMerge    string // merge command that must have been run
+		wantNoMerge  string // merge command that must not have been run
+		wantCommits  int
+		wantChanges  git.DiffSummary
+	}{
+		{
+			name:    "merges new commits from the parent",
+			parents: []string{"feature/base"},
+			responses: map[string]gitexectest.Result{
+				"rev-parse HEAD":   {Stdout: "1111111111111111111111111111111111111111\n"},
+				"rev-list":         {Stdout: "0\t3\n"},
+				"diff --shortstat": {Stdout: " 2 files changed, 5 insertions(+), 1 deletion(-)\n"},
+				"merge":            {Stdout: "Merge made by the 'ort' strategy.\n a.txt | 1 +\n"},
+			},
+			wantSuccess: true,
+			wantMessage: "merged successfully",
+			wantParent:  "feature/base",
+			wantMerge:   "merge feature/base --no-edit",
+			wantCommits: 3,
+			wantChanges: git.DiffSummary{Files: 2, Insertions: 5, Deletions: 1},
+		},
+		{
+			name:    "nothing to merge",
+			parents: []string{"feature/base"},
+			responses: map[string]gitexectest.Result{
+				"merge": {Stdout: "Already up to date.\n"},
+			},
+			wantSuccess: true,
+			wantMessage: "already up to date",
+			wantParent:  "feature/base",
+		},
+		{
+			name:    "merge conflict is left for manual resolution",
+			parents: []string{"feature/base"},
+			responses: map[string]gitexectest.Result{
+				"merge": {
+					Stdout:   "Auto-merging a.txt\nCONFLICT (content): Merge conflict in a.txt\nAutomatic merge failed; fix conflicts and then commit the result.\n",
+					ExitCode: 1,
+				},
+			},
+			wantMessage: "CONFLICT - resolve manually",
+			wantParent:  "feature/base",
+		},
+		{
+			name:    "other merge failures report git's output",
+			parents: []string{"feature/base"},
+			responses: map[string]gitexectest.Result{
+				"merge": {Stderr: "fatal: refusing to merge unrelated histories\n", ExitCode: 128},
+			},
+			wantMessage: "merge failed: fatal: refusing to merge unrelated histories",
+			wantParent:  "feature/base",
+		},
+		{
+			name:    "missing parent falls back",
+			parents: []string{"feature/gone"},
+			responses: map[string]gitexectest.Result{
+				"show-ref --verify --quiet refs/heads/feature/gone":          missing,
+				"show-ref --verify --quiet refs/remotes/origin/feature/gone": missing,
+				"merge": {Stdout: "Already up to date.\n"},
+			},
+			wantSuccess:  true,
+			wantMessage:  "already up to date",
+			wantParent:   "main",
+			wantFallback: true,
+			wantMerge:    "merge main --no-edit",
+		},
+		{
+			name:    "remote-only parent is merged from the remote",
+			parents: []string{"feature/base"},
+			responses: map[string]gitexectest.Result{
+				"show-ref --verify --quiet refs/heads/feature/base":          missing,
+				"show-ref --verify --quiet refs/remotes/origin/feature/base": found,
+				"merge": {Stdout: "Fast-forward\n"},
+			},
+			wantSuccess: true,
+			wantMessage: "merged successfully",
+			wantParent:  "feature/base",
+			wantMerge:   "merge origin/feature/base --no-edit",
+		},
+		{
+			name:    "parents are merged in order",
+			parents: []string{"feature/a", "feature/b"},
+			responses: map[string]gitexectest.Result{
+				"merge feature/a": {Stdout: "Merge made by the 'ort' strategy.\n"},
+				"merge feature/b": {Stdout: "Already up to date.\n"},
+			},
+			wantSuccess: true,
+			wantMessage: "merged successfully",
+			wantParent:  "feature/a, feature/b",
+			wantMerge:   "merge feature/b --no-edit",
+		},
+		{
+			name:    "missing parents are left out",
+			parents: []string{"feature/gone", "feature/b"},
+			responses: map[string]gitexectest.Result{
+				"show-ref --verify --quiet refs/heads/feature/gone":          missing,
+				"show-ref --verify --quiet refs/remotes/origin/feature/gone": missing,
+				"merge": {Stdout: "Already up to date.\n"},
+			},
+			wantSuccess: true,
+			wantMessage: "already up to date",
+			wantParent:  "feature/b",
+			wantMerge:   "merge feature/b --no-edit",
+		},
+		{
+			name:    "a conflict stops merging the remaining parents",
+			parents: []string{"feature/a", "feature/b"},
+			responses: map[string]gitexectest.Result{
+				"merge feature/a": {Stdout: "CONFLICT (content): Merge conflict in a.txt\n", ExitCode: 1},
+			},
+			wantMessage: "merging feature/a: CONFLICT - resolve manually",
+			wantParent:  "feature/a, feature/b",
+			wantNoMerge: "merge feature/b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.Mkdir(filepath.Join(dir, ".git"), 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			fake := gitexectest.New(t)
+			for args, result := range tt.responses {
+				fake.On(args, result)
+			}
+			fake.On("fetch", found)
+			fake.On("rev-parse --abbrev-ref HEAD", gitexectest.Result{Stdout: "feature/x\n"})
+			fake.On("show-ref", found)
+
+			repo := config.Repository{Path: dir, Remote: "origin"}
+			got := SyncRepository(discardLogger{}, repo, "feature/x", tt.parents, "main", git.MergeOptions{})
+
+			if got.Success != tt.wantSuccess || got.Message != tt.wantMessage {
+				t.Errorf("SyncRepository() = success %v, %q; want success %v, %q", got.Success, got.Message, tt.wantSuccess, tt.wantMessage)
+			}
+			if parents := strings.Join(got.ParentBranches, ", "); parents != tt.wantParent || got.WasFallback != tt.wantFallback {
+				t.Errorf("parents = %q (fallback %v), want %q (fallback %v)", parents, got.WasFallback, tt.wantParent, tt.wantFallback)
+			}
+			if tt.wantCommits > 0 && (len(got.Merges) != 1 || got.Merges[0].Commits != tt.wantCommits || got.Merges[0].Changes != tt.wantChanges) {
+				t.Errorf("merges = %+v; want %d commits, %+v", got.Merges, tt.wantCommits, tt.wantChanges)
+			}
+			if tt.wantMerge != "" && !fake.Ran(tt.wantMerge) {
+				t.Errorf("%q was not run; calls: %q", tt.wantMerge, fake.Calls())
+			}
+			if tt.wantNoMerge != "" && fake.Ran(tt.wantNoMerge) {
+				t.Errorf("%q was run; calls: %q", tt.wantNoMerge, fake.Calls())
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"git_cli_tool/config"
+	"git_cli_tool/git"
+)
+
+// VerifyHistory checks what the history states recorded for a repository refer
+// to: the branch (locally or on the remote as last fetched), the recorded commit,
+// the stash and the file with the saved uncommitted changes. It returns the ones
+// that no longer exist for each state, indexed like states; states without an
+// entry for the repository have none. Every entry of a repository that no longer
+// exists is reported as such.
+func VerifyHistory(repo config.Repository, states []config.BranchState) ([][]string, error) {
+	problems := make([][]string, len(states))
+	if err := git.ValidateRepository(repo.Path); err != nil {
+		for i, state := range states {
+			if _, ok := state.Repositories[repo.Path]; ok {
+				problems[i] = []string{"repository no longer exists"}
+			}
+		}
+		return problems, nil
+	}
+
+	remote := repo.Remote
+	if remote == "" {
+		remote = config.DefaultRemote
+	}
+	local, remoteBranches, err := git.ListBranches(repo.Path, remote)
+	if err != nil {
+		return nil, err
+	}
+	branches := make(map[string]bool)
+	for _, branch := range append(local, remoteBranches...) {
+		branches[branch] = true
+	}
+	stashes, err := git.ListStashes(repo.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Many states record the same commit, so each one is only looked up once
+	commits := make(map[string]bool)
+	commitExists := func(sha string) bool {
+		exists, ok := commits[sha]
+		if !ok {
+			exists = git.CommitExists(repo.Path, sha)
+			commits[sha] = exists
+		}
+		return exists
+	}
+
+	for i, state := range states {
+		repoState, ok := state.Repositories[repo.Path]
+		if !ok {
+			continue
+		}
+		if repoState.Branch != "" && repoState.Branch != "HEAD" && !branches[repoState.Branch] {
+			problems[i] = append(problems[i], fmt.Sprintf("branch %s no longer exists", repoState.Branch))
+		}
+		if repoState.Commit != "" && !commitExists(repoState.Commit) {
+			problems[i] = append(problems[i], fmt.Sprintf("commit %s no longer exists", repoState.Commit))
+		}
+		if stashMissing(repoState, stashes, commitExists) {
+			stash := repoState.StashName
+			if stash == "" {
+				stash = repoState.StashCommit
+			}
+			problems[i] = append(problems[i], fmt.Sprintf("stash %s no longer exists", stash))
+		}
+		for _, stash := range repoState.Stashes {
+			if !commitExists(stash.Commit) {
+				problems[i] = append(problems[i], fmt.Sprintf("stash %s no longer exists", stash.Subject))
+			}
+		}
+		if repoState.Patch != "" {
+			if _, err := os.Stat(repoState.Patch); err != nil {
+				problems[i] = append(problems[i], fmt.Sprintf("saved changes %s no longer exist", repoState.Patch))
+			}
+		}
+	}
+	return problems, nil
+}
+
+// stashMissing reports whether the stash recorded in a state can no longer be
+// restored. Revert applies a recorded stash commit even after it was dropped
+// from the stash list, so only its commit has to exist; older states, which
+// record no commit, are matched by name in the stash list.
+func stashMissing(state config.RepositoryState, stashes []git.Stash, commitExists func(string) bool) bool {
+	if state.StashCommit != "" {
+		return !commitExists(state.StashCommit)
+	}
+	if state.StashName == "" {
+		return false
+	}
+	for _, stash := range stashes {
+		if strings.Contains(stash.Subject, state.StashName) {
+			return false
+		}
+	}
+	return true
+}
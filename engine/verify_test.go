@@ -0,0 +1,124 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"git_cli_tool/config"
+	"git_cli_tool/gitexec/gitexectest"
+)
+
+func TestVerifyHistory(t *testing.T) {
+	const (
+		kept    = "1111111111111111111111111111111111111111"
+		dropped = "2222222222222222222222222222222222222222"
+		stash   = "3333333333333333333333333333333333333333"
+	)
+
+	tests := []struct {
+		name  string
+		state config.RepositoryState
+		want  []string
+	}{
+		{
+			name:  "everything still exists",
+			state: config.RepositoryState{Branch: "main", Commit: kept, StashName: "GitSwitch: feature/x", StashCommit: stash},
+		},
+		{
+			name:  "branch only on the remote",
+			state: config.RepositoryState{Branch: "feature/y"},
+		},
+		{
+			name:  "deleted branch",
+			state: config.RepositoryState{Branch: "feature/gone"},
+			want:  []string{"branch feature/gone no longer exists"},
+		},
+		{
+			name:  "detached HEAD at a garbage collected commit",
+			state: config.RepositoryState{Branch: "HEAD", Commit: dropped},
+			want:  []string{"commit " + dropped + " no longer exists"},
+		},
+		{
+			name:  "dropped stash whose commit still exists",
+			state: config.RepositoryState{Branch: "main", StashName: "GitSwitch: main", StashCommit: kept},
+		},
+		{
+			name:  "garbage collected stash",
+			state: config.RepositoryState{Branch: "main", StashName: "GitSwitch: main", StashCommit: dropped},
+			want:  []string{"stash GitSwitch: main no longer exists"},
+		},
+		{
+			name:  "older state matches the stash by name",
+			state: config.RepositoryState{Branch: "main", StashName: "GitSwitch: feature/x"},
+		},
+		{
+			name:  "older state with a dropped stash",
+			state: config.RepositoryState{Branch: "main", StashName: "GitSwitch: main"},
+			want:  []string{"stash GitSwitch: main no longer exists"},
+		},
+		{
+			name: "stashes dropped by feature done",
+			state: config.RepositoryState{Branch: "main", Stashes: []config.StashRecord{
+				{Commit: kept, Subject: "On feature/x: GitSwitch: main"},
+				{Commit: dropped, Subject: "On main: GitSwitch: feature/x"},
+			}},
+			want: []string{"stash On main: GitSwitch: feature/x no longer exists"},
+		},
+		{
+			name:  "deleted patch file",
+			state: config.RepositoryState{Branch: "main", Patch: "/nonexistent/api.patch"},
+			want:  []string{"saved changes /nonexistent/api.patch no longer exist"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.Mkdir(filepath.Join(dir, ".git"), 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			fake := gitexectest.New(t)
+			fake.On("for-each-ref", gitexectest.Result{Stdout: "refs/heads/main\nrefs/remotes/origin/HEAD\nrefs/remotes/origin/feature/y\n"})
+			fake.On("stash list", gitexectest.Result{Stdout: "stash@{0}\x1f" + stash + "\x1fOn main: GitSwitch: feature/x\n"})
+			fake.On("rev-parse --verify --quiet "+kept+"^{commit}", gitexectest.Result{Stdout: kept + "\n"})
+			fake.On("rev-parse --verify --quiet "+stash+"^{commit}", gitexectest.Result{Stdout: stash + "\n"})
+			fake.On("rev-parse --verify --quiet "+dropped+"^{commit}", gitexectest.Result{ExitCode: 1})
+
+			states := []config.BranchState{
+				{Repositories: map[string]config.RepositoryState{dir: tt.state}},
+				{Repositories: map[string]config.RepositoryState{"other": {Branch: "feature/gone"}}},
+			}
+			problems, err := VerifyHistory(config.Repository{Path: dir}, states)
+			if err != nil {
+				t.Fatalf("VerifyHistory() error = %v", err)
+			}
+			if !reflect.DeepEqual(problems[0], tt.want) {
+				t.Errorf("VerifyHistory() = %q, want %q", problems[0], tt.want)
+			}
+			if problems[1] != nil {
+				t.Errorf("VerifyHistory() reported %q for a state without the repository", problems[1])
+			}
+		})
+	}
+}
+
+func TestVerifyHistoryMissingRepository(t *testing.T) {
+	gitexectest.New(t)
+	missing := filepath.Join(t.TempDir(), "api")
+
+	states := []config.BranchState{
+		{Repositories: map[string]config.RepositoryState{missing: {Branch: "main"}}},
+		{Repositories: map[string]config.RepositoryState{}},
+	}
+	problems, err := VerifyHistory(config.Repository{Path: missing}, states)
+	if err != nil {
+		t.Fatalf("VerifyHistory() error = %v", err)
+	}
+	want := [][]string{{"repository no longer exists"}, nil}
+	if !reflect.DeepEqual(problems, want) {
+		t.Errorf("VerifyHistory() = %q, want %q", problems, want)
+	}
+}
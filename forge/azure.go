@@ -0,0 +1,255 @@
+package forge
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"git_cli_tool/config"
+)
+
+// Azure DevOps reviewer votes
+const (
+	azureVoteApproved        = 10
+	azureVoteApprovedWithTip = 5
+	azureVoteWaiting         = -5
+	azureVoteRejected        = -10
+)
+
+// azureDevOps is the pull request API of a single Azure DevOps (Services) repository
+type azureDevOps struct {
+	org     string
+	project string
+	repo    string
+	token   string
+}
+
+// azurePullRequest is a pull request as returned by the Azure DevOps API
+type azurePullRequest struct {
+	PullRequestID int    `json:"pullRequestId"`
+	Title         string `json:"title"`
+	Status        string `json:"status"`
+	MergeStatus   string `json:"mergeStatus"` // e.g. succeeded, conflicts or queued
+	IsDraft       bool   `json:"isDraft"`
+	SourceRefName string `json:"sourceRefName"`
+	TargetRefName string `json:"targetRefName"`
+	Reviewers     []struct {
+		Vote int `json:"vote"`
+	} `json:"reviewers"`
+	LastMergeSourceCommit *struct {
+		CommitID string `json:"commitId"`
+	} `json:"lastMergeSourceCommit"`
+}
+
+// azureMergeStrategies maps the merge strategies to those of Azure DevOps
+var azureMergeStrategies = map[string]string{
+	MergeStrategyMerge:  "noFastForward",
+	MergeStrategySquash: "squash",
+	MergeStrategyRebase: "rebase",
+}
+
+// isAzureHost reports whether a host belongs to Azure DevOps Services
+func isAzureHost(host string) bool {
+	host = strings.ToLower(host)
+	return host == "dev.azure.com" || host == "ssh.dev.azure.com" || strings.HasSuffix(host, ".visualstudio.com")
+}
+
+// newAzureDevOps creates the Azure DevOps provider for a remote. The organization,
+// project and repository are taken from the remote URL, which has one of the forms
+//
+//	https://dev.azure.com/{org}/{project}/_git/{repo}
+//	https://{org}.visualstudio.com/[DefaultCollection/]{project}/_git/{repo}
+//	git@ssh.dev.azure.com:v3/{org}/{project}/{repo}
+func newAzureDevOps(instance config.ForgeInstance, remote RemoteURL) (*azureDevOps, error) {
+	host := strings.ToLower(remote.Host)
+	parts := strings.Split(remote.Path, "/")
+	provider := &azureDevOps{token: token(instance, "AZURE_DEVOPS_EXT_PAT")}
+
+	switch {
+	case host == "ssh.dev.azure.com" && len(parts) == 4 && parts[0] == "v3":
+		provider.org, provider.project, provider.repo = parts[1], parts[2], parts[3]
+	case host == "dev.azure.com" && len(parts) == 4 && parts[2] == "_git":
+		provider.org, provider.project, provider.repo = parts[0], parts[1], parts[3]
+	case strings.HasSuffix(host, ".visualstudio.com"):
+		if len(parts) == 4 && strings.EqualFold(parts[0], "DefaultCollection") {
+			parts = parts[1:]
+		}
+		if len(parts) != 3 || parts[1] != "_git" {
+			return nil, fmt.Errorf("unsupported Azure DevOps remote path %s", remote.Path)
+		}
+		provider.org = strings.TrimSuffix(host, ".visualstudio.com")
+		provider.project, provider.repo = parts[0], parts[2]
+	default:
+		return nil, fmt.Errorf("unsupported Azure DevOps remote path %s", remote.Path)
+	}
+
+	return provider, nil
+}
+
+// Name returns the name of the forge
+func (a *azureDevOps) Name() string {
+	return "Azure DevOps"
+}
+
+// CreatePullRequest opens a pull request
+func (a *azureDevOps) CreatePullRequest(opts CreateOptions) (*PullRequest, error) {
+	body := map[string]interface{}{
+		"sourceRefName": "refs/heads/" + opts.SourceBranch,
+		"targetRefName": "refs/heads/" + opts.TargetBranch,
+		"title":         opts.Title,
+		"description":   opts.Description,
+		"isDraft":       opts.Draft,
+	}
+
+	var pr azurePullRequest
+	if err := doJSON("POST", a.apiURL("/pullrequests", nil), a.headers(), body, &pr); err != nil {
+		return nil, err
+	}
+	result := a.toPullRequest(pr)
+	return &result, nil
+}
+
+// FindPullRequests returns the active pull requests from a source branch
+func (a *azureDevOps) FindPullRequests(sourceBranch string) ([]PullRequest, error) {
+	query := url.Values{}
+	query.Set("searchCriteria.sourceRefName", "refs/heads/"+sourceBranch)
+	query.Set("searchCriteria.status", "active")
+
+	var response struct {
+		Value []azurePullRequest `json:"value"`
+	}
+	if err := doJSON("GET", a.apiURL("/pullrequests", query), a.headers(), nil, &response); err != nil {
+		return nil, err
+	}
+
+	prs := make([]PullRequest, len(response.Value))
+	for i, pr := range response.Value {
+		prs[i] = a.toPullRequest(pr)
+	}
+	return prs, nil
+}
+
+// LoadStatus fills in the reviewer votes and the status checks of a pull request
+func (a *azureDevOps) LoadStatus(pr *PullRequest) error {
+	var details azurePullRequest
+	if err := doJSON("GET", a.apiURL(fmt.Sprintf("/pullrequests/%d", pr.Number), nil), a.headers(), nil, &details); err != nil {
+		return err
+	}
+
+	approved := false
+	pr.Review = ReviewPending
+	for _, reviewer := range details.Reviewers {
+		switch reviewer.Vote {
+		case azureVoteRejected, azureVoteWaiting:
+			pr.Review = ReviewChangesRequested
+		case azureVoteApproved, azureVoteApprovedWithTip:
+			approved = true
+		}
+	}
+	if approved && pr.Review == ReviewPending {
+		pr.Review = ReviewApproved
+	}
+
+	var statuses struct {
+		Value []struct {
+			State string `json:"state"`
+		} `json:"value"`
+	}
+	if err := doJSON("GET", a.apiURL(fmt.Sprintf("/pullrequests/%d/statuses", pr.Number), nil), a.headers(), nil, &statuses); err != nil {
+		return err
+	}
+
+	// The worst status wins: any failure fails the pull request, anything unfinished keeps it pending
+	pr.CI = ""
+	for _, status := range statuses.Value {
+		switch status.State {
+		case "failed", "error":
+			pr.CI = "failed"
+		case "pending", "notSet":
+			if pr.CI != "failed" {
+				pr.CI = "pending"
+			}
+		case "succeeded":
+			if pr.CI == "" {
+				pr.CI = "success"
+			}
+		}
+	}
+	return nil
+}
+
+// MergePullRequest completes a pull request. Azure DevOps requires the source
+// commit the pull request was last evaluated at, so it is looked up first.
+// A completion that Azure DevOps only queued is reported as an error, since
+// the target branch does not contain the merge yet.
+func (a *azureDevOps) MergePullRequest(pr *PullRequest, opts MergeOptions) error {
+	var details azurePullRequest
+	if err := doJSON("GET", a.apiURL(fmt.Sprintf("/pullrequests/%d", pr.Number), nil), a.headers(), nil, &details); err != nil {
+		return err
+	}
+	switch {
+	case details.Status == "completed":
+		pr.State = details.Status
+		return fmt.Errorf("pull request %d %w", pr.Number, ErrAlreadyMerged)
+	case details.MergeStatus == "conflicts":
+		return fmt.Errorf("pull request %d %w", pr.Number, ErrMergeConflict)
+	case details.LastMergeSourceCommit == nil:
+		return fmt.Errorf("pull request %d has no source commit to merge", pr.Number)
+	}
+
+	body := map[string]interface{}{
+		"status":                "completed",
+		"lastMergeSourceCommit": map[string]string{"commitId": details.LastMergeSourceCommit.CommitID},
+		"completionOptions": map[string]interface{}{
+			"mergeStrategy":      azureMergeStrategies[opts.Strategy],
+			"deleteSourceBranch": opts.DeleteSourceBranch,
+		},
+	}
+	var completed azurePullRequest
+	if err := doJSON("PATCH", a.apiURL(fmt.Sprintf("/pullrequests/%d", pr.Number), nil), a.headers(), body, &completed); err != nil {
+		return err
+	}
+	pr.State = completed.Status
+	switch {
+	case completed.Status == "completed":
+		return nil
+	case completed.MergeStatus == "conflicts":
+		return fmt.Errorf("pull request %d %w", pr.Number, ErrMergeConflict)
+	}
+	return fmt.Errorf("pull request %d was not completed yet (merge status %s)", pr.Number, completed.MergeStatus)
+}
+
+// apiURL returns the API URL of a repository resource
+func (a *azureDevOps) apiURL(resource string, query url.Values) string {
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("api-version", "7.0")
+	return fmt.Sprintf("https://dev.azure.com/%s/%s/_apis/git/repositories/%s%s?%s",
+		url.PathEscape(a.org), url.PathEscape(a.project), url.PathEscape(a.repo), resource, query.Encode())
+}
+
+// headers returns the authentication headers; personal access tokens are sent
+// with basic authentication and an empty user name
+func (a *azureDevOps) headers() map[string]string {
+	if a.token == "" {
+		return nil
+	}
+	credentials := base64.StdEncoding.EncodeToString([]byte(":" + a.token))
+	return map[string]string{"Authorization": "Basic " + credentials}
+}
+
+// toPullRequest converts an Azure DevOps pull request
+func (a *azureDevOps) toPullRequest(pr azurePullRequest) PullRequest {
+	return PullRequest{
+		Number: pr.PullRequestID,
+		Title:  pr.Title,
+		URL: fmt.Sprintf("https://dev.azure.com/%s/%s/_git/%s/pullrequest/%d",
+			url.PathEscape(a.org), url.PathEscape(a.project), url.PathEscape(a.repo), pr.PullRequestID),
+		SourceBranch: strings.TrimPrefix(pr.SourceRefName, "refs/heads/"),
+		TargetBranch: strings.TrimPrefix(pr.TargetRefName, "refs/heads/"),
+		State:        pr.Status,
+		Draft:        pr.IsDraft,
+	}
+}
@@ -0,0 +1,108 @@
+package forge
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAzureDevOpsMergePullRequest(t *testing.T) {
+	const pullRequest = "/org/project/_apis/git/repositories/api/pullrequests/7"
+	const (
+		get   = "GET " + pullRequest
+		patch = "PATCH " + pullRequest
+	)
+	const active = `{"pullRequestId":7,"status":"active","mergeStatus":"succeeded","lastMergeSourceCommit":{"commitId":"1111"}}`
+
+	tests := []struct {
+		name         string
+		opts         MergeOptions
+		responses    map[string]apiResponse
+		wantRequests []string
+		wantState    string
+		wantErr      error // nil for success, errNotClassified for any other error
+	}{
+		{
+			name: "completed",
+			opts: MergeOptions{Strategy: MergeStrategySquash, DeleteSourceBranch: true},
+			responses: map[string]apiResponse{
+				get:   {Status: 200, Body: active},
+				patch: {Status: 200, Body: `{"pullRequestId":7,"status":"completed","mergeStatus":"succeeded"}`},
+			},
+			wantRequests: []string{get, patch + ` {"completionOptions":{"deleteSourceBranch":true,"mergeStrategy":"squash"},"lastMergeSourceCommit":{"commitId":"1111"},"status":"completed"}`},
+			wantState:    "completed",
+		},
+		{
+			name: "already merged",
+			opts: MergeOptions{Strategy: MergeStrategyMerge},
+			responses: map[string]apiResponse{
+				get: {Status: 200, Body: `{"pullRequestId":7,"status":"completed","mergeStatus":"succeeded","lastMergeSourceCommit":{"commitId":"1111"}}`},
+			},
+			wantRequests: []string{get},
+			wantState:    "completed",
+			wantErr:      ErrAlreadyMerged,
+		},
+		{
+			name: "conflicts found before completing",
+			opts: MergeOptions{Strategy: MergeStrategyMerge},
+			responses: map[string]apiResponse{
+				get: {Status: 200, Body: `{"pullRequestId":7,"status":"active","mergeStatus":"conflicts","lastMergeSourceCommit":{"commitId":"1111"}}`},
+			},
+			wantRequests: []string{get},
+			wantState:    "active",
+			wantErr:      ErrMergeConflict,
+		},
+		{
+			name: "conflicts found while completing",
+			opts: MergeOptions{Strategy: MergeStrategyRebase},
+			responses: map[string]apiResponse{
+				get:   {Status: 200, Body: active},
+				patch: {Status: 200, Body: `{"pullRequestId":7,"status":"active","mergeStatus":"conflicts"}`},
+			},
+			wantRequests: []string{get, patch + ` {"completionOptions":{"deleteSourceBranch":false,"mergeStrategy":"rebase"},"lastMergeSourceCommit":{"commitId":"1111"},"status":"completed"}`},
+			wantState:    "active",
+			wantErr:      ErrMergeConflict,
+		},
+		{
+			name: "completion queued",
+			opts: MergeOptions{Strategy: MergeStrategyMerge},
+			responses: map[string]apiResponse{
+				get:   {Status: 200, Body: active},
+				patch: {Status: 200, Body: `{"pullRequestId":7,"status":"active","mergeStatus":"queued"}`},
+			},
+			wantRequests: []string{get, patch + ` {"completionOptions":{"deleteSourceBranch":false,"mergeStrategy":"noFastForward"},"lastMergeSourceCommit":{"commitId":"1111"},"status":"completed"}`},
+			wantState:    "active",
+			wantErr:      errNotClassified,
+		},
+		{
+			name: "rejected by a policy",
+			opts: MergeOptions{Strategy: MergeStrategyMerge},
+			responses: map[string]apiResponse{
+				get:   {Status: 200, Body: active},
+				patch: {Status: 400, Body: `{"message":"TF401027: You need the Git 'PullRequestBypassPolicy' permission"}`},
+			},
+			wantRequests: []string{get, patch + ` {"completionOptions":{"deleteSourceBranch":false,"mergeStrategy":"noFastForward"},"lastMergeSourceCommit":{"commitId":"1111"},"status":"completed"}`},
+			wantState:    "active",
+			wantErr:      errNotClassified,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI(t)
+			for request, response := range tt.responses {
+				api.On(request, response)
+			}
+
+			provider := &azureDevOps{org: "org", project: "project", repo: "api", token: "secret"}
+			pr := &PullRequest{Number: 7, State: "active"}
+			err := provider.MergePullRequest(pr, tt.opts)
+			checkMergeError(t, err, tt.wantErr)
+			if pr.State != tt.wantState {
+				t.Errorf("State = %q, want %q", pr.State, tt.wantState)
+			}
+			if requests := api.Requests(); !reflect.DeepEqual(requests, tt.wantRequests) {
+				t.Errorf("requests = %q, want %q", requests, tt.wantRequests)
+			}
+		})
+	}
+}
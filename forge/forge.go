@@ -0,0 +1,162 @@
+// Package forge talks to code hosting servers (GitLab, Azure DevOps) to manage pull requests
+package forge
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"git_cli_tool/config"
+)
+
+// Review states of a pull request
+const (
+	ReviewApproved         = "approved"
+	ReviewPending          = "pending"
+	ReviewChangesRequested = "changes requested"
+)
+
+// PullRequest is a pull request (merge request on GitLab) on a forge
+type PullRequest struct {
+	Number        int
+	Title         string
+	URL           string
+	SourceBranch  string
+	TargetBranch  string
+	State         string // opened, closed, merged
+	Draft         bool
+	Review        string // one of the Review* states, set by LoadStatus
+	ApprovalsLeft int    // approvals still required, set by LoadStatus
+	CI            string // status of the latest pipeline or checks, e.g. "success" or "failed"; empty if none
+}
+
+// CreateOptions describes a pull request to create
+type CreateOptions struct {
+	SourceBranch string
+	TargetBranch string
+	Title        string
+	Description  string
+	Draft        bool
+}
+
+// Merge strategies of MergeOptions
+const (
+	MergeStrategyMerge  = "merge"
+	MergeStrategySquash = "squash"
+	MergeStrategyRebase = "rebase"
+)
+
+// Errors of MergePullRequest telling why a pull request was not merged
+var (
+	ErrAlreadyMerged = errors.New("is already merged")
+	ErrMergeConflict = errors.New("has conflicts with the target branch")
+)
+
+// MergeOptions describes how to merge a pull request
+type MergeOptions struct {
+	Strategy           string // one of the MergeStrategy* values
+	DeleteSourceBranch bool   // delete the source branch on the forge after merging
+}
+
+// Provider is the API of a forge for a single repository
+type Provider interface {
+	// Name returns the name of the forge, e.g. "GitLab"
+	Name() string
+	// CreatePullRequest opens a pull request
+	CreatePullRequest(opts CreateOptions) (*PullRequest, error)
+	// FindPullRequests returns the open pull requests from a source branch
+	FindPullRequests(sourceBranch string) ([]PullRequest, error)
+	// LoadStatus fills in the review and CI status of a pull request
+	LoadStatus(pr *PullRequest) error
+	// MergePullRequest merges an open pull request into its target branch
+	MergePullRequest(pr *PullRequest, opts MergeOptions) error
+}
+
+// RemoteURL is the parsed URL of a git remote
+type RemoteURL struct {
+	Host string // host name without port
+	Path string // repository path without leading slash and ".git", e.g. "group/sub/project"
+}
+
+// ParseRemoteURL parses https, ssh:// and scp-like (git@host:path) remote URLs
+func ParseRemoteURL(remoteURL string) (RemoteURL, error) {
+	var host, path string
+
+	if strings.Contains(remoteURL, "://") {
+		parsed, err := url.Parse(remoteURL)
+		if err != nil {
+			return RemoteURL{}, fmt.Errorf("invalid remote URL %s: %v", remoteURL, err)
+		}
+		host = parsed.Hostname()
+		path = parsed.Path
+	} else if at := strings.Index(remoteURL, "@"); at >= 0 || strings.Contains(remoteURL, ":") {
+		// scp-like syntax: [user@]host:path
+		hostAndPath := remoteURL[at+1:]
+		colon := strings.Index(hostAndPath, ":")
+		if colon < 0 {
+			return RemoteURL{}, fmt.Errorf("unsupported remote URL %s", remoteURL)
+		}
+		host = hostAndPath[:colon]
+		path = hostAndPath[colon+1:]
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if host == "" || path == "" {
+		return RemoteURL{}, fmt.Errorf("unsupported remote URL %s", remoteURL)
+	}
+	return RemoteURL{Host: host, Path: path}, nil
+}
+
+// ForRemote returns the provider for the repository behind a remote URL. The
+// forge is detected from the host of the URL: well-known hosts work out of the
+// box, self-hosted servers must be listed in the forge configuration.
+func ForRemote(remoteURL string, forgeConfig config.ForgeConfig) (Provider, error) {
+	remote, err := ParseRemoteURL(remoteURL)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, instance := range forgeConfig.GitLab {
+		if sameHost(instance.URL, remote.Host) {
+			return newGitLab(instance, remote.Path), nil
+		}
+	}
+	if remote.Host == "gitlab.com" {
+		return newGitLab(config.ForgeInstance{URL: "https://gitlab.com"}, remote.Path), nil
+	}
+
+	if isAzureHost(remote.Host) {
+		provider, err := newAzureDevOps(forgeConfig.AzureDevOps, remote)
+		if err != nil {
+			return nil, err
+		}
+		return provider, nil
+	}
+
+	return nil, fmt.Errorf("no forge configured for host %s", remote.Host)
+}
+
+// sameHost reports whether a configured server URL points at host
+func sameHost(serverURL string, host string) bool {
+	parsed, err := url.Parse(serverURL)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(parsed.Hostname(), host)
+}
+
+// token returns the API token of a server: the token_env variable, the token
+// setting or the given default environment variable, in that order
+func token(instance config.ForgeInstance, defaultEnv string) string {
+	if instance.TokenEnv != "" {
+		if value := os.Getenv(instance.TokenEnv); value != "" {
+			return value
+		}
+	}
+	if instance.Token != "" {
+		return instance.Token
+	}
+	return os.Getenv(defaultEnv)
+}
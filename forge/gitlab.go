@@ -0,0 +1,179 @@
+package forge
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"git_cli_tool/config"
+)
+
+// gitLab is the GitLab merge request API of a single project
+type gitLab struct {
+	baseURL string // API root, e.g. https://gitlab.example.com/api/v4
+	project string // URL-encoded project path
+	token   string
+}
+
+// gitLabMergeRequest is a merge request as returned by the GitLab API
+type gitLabMergeRequest struct {
+	IID          int    `json:"iid"`
+	Title        string `json:"title"`
+	WebURL       string `json:"web_url"`
+	SourceBranch string `json:"source_branch"`
+	TargetBranch string `json:"target_branch"`
+	State        string `json:"state"`
+	Draft        bool   `json:"draft"`
+	HasConflicts bool   `json:"has_conflicts"`
+}
+
+// newGitLab creates the GitLab provider for a project on a server
+func newGitLab(instance config.ForgeInstance, projectPath string) *gitLab {
+	return &gitLab{
+		baseURL: strings.TrimRight(instance.URL, "/") + "/api/v4",
+		project: url.PathEscape(projectPath),
+		token:   token(instance, "GITLAB_TOKEN"),
+	}
+}
+
+// Name returns the name of the forge
+func (g *gitLab) Name() string {
+	return "GitLab"
+}
+
+// CreatePullRequest opens a merge request
+func (g *gitLab) CreatePullRequest(opts CreateOptions) (*PullRequest, error) {
+	title := opts.Title
+	if opts.Draft && !strings.HasPrefix(title, "Draft:") {
+		title = "Draft: " + title
+	}
+
+	body := map[string]string{
+		"source_branch": opts.SourceBranch,
+		"target_branch": opts.TargetBranch,
+		"title":         title,
+		"description":   opts.Description,
+	}
+
+	var mr gitLabMergeRequest
+	if err := doJSON("POST", g.projectURL("/merge_requests"), g.headers(), body, &mr); err != nil {
+		return nil, err
+	}
+	pr := mr.toPullRequest()
+	return &pr, nil
+}
+
+// FindPullRequests returns the open merge requests from a source branch
+func (g *gitLab) FindPullRequests(sourceBranch string) ([]PullRequest, error) {
+	query := url.Values{}
+	query.Set("state", "opened")
+	query.Set("source_branch", sourceBranch)
+
+	var mrs []gitLabMergeRequest
+	if err := doJSON("GET", g.projectURL("/merge_requests?"+query.Encode()), g.headers(), nil, &mrs); err != nil {
+		return nil, err
+	}
+
+	prs := make([]PullRequest, len(mrs))
+	for i, mr := range mrs {
+		prs[i] = mr.toPullRequest()
+	}
+	return prs, nil
+}
+
+// LoadStatus fills in the approval state and pipeline status of a merge request
+func (g *gitLab) LoadStatus(pr *PullRequest) error {
+	var details struct {
+		DetailedMergeStatus string `json:"detailed_merge_status"`
+		HeadPipeline        *struct {
+			Status string `json:"status"`
+		} `json:"head_pipeline"`
+	}
+	if err := doJSON("GET", g.projectURL(fmt.Sprintf("/merge_requests/%d", pr.Number)), g.headers(), nil, &details); err != nil {
+		return err
+	}
+	if details.HeadPipeline != nil {
+		pr.CI = details.HeadPipeline.Status
+	}
+
+	var approvals struct {
+		Approved      bool `json:"approved"`
+		ApprovalsLeft int  `json:"approvals_left"`
+	}
+	if err := doJSON("GET", g.projectURL(fmt.Sprintf("/merge_requests/%d/approvals", pr.Number)), g.headers(), nil, &approvals); err != nil {
+		return err
+	}
+	pr.ApprovalsLeft = approvals.ApprovalsLeft
+
+	switch {
+	case details.DetailedMergeStatus == "requested_changes":
+		pr.Review = ReviewChangesRequested
+	case approvals.Approved:
+		pr.Review = ReviewApproved
+	default:
+		pr.Review = ReviewPending
+	}
+	return nil
+}
+
+// MergePullRequest merges a merge request. GitLab always uses the merge method
+// configured for the project (merge commit or fast-forward), optionally
+// squashing the commits first, so the rebase strategy is not supported.
+func (g *gitLab) MergePullRequest(pr *PullRequest, opts MergeOptions) error {
+	if opts.Strategy == MergeStrategyRebase {
+		return fmt.Errorf("GitLab merges with the merge method of the project, use the merge or squash strategy")
+	}
+
+	body := map[string]bool{
+		"squash":                      opts.Strategy == MergeStrategySquash,
+		"should_remove_source_branch": opts.DeleteSourceBranch,
+	}
+	var mr gitLabMergeRequest
+	err := doJSON("PUT", g.projectURL(fmt.Sprintf("/merge_requests/%d/merge", pr.Number)), g.headers(), body, &mr)
+	var status *statusError
+	if errors.As(err, &status) && (status.Code == 405 || status.Code == 406 || status.Code == 422) {
+		// GitLab does not say why a merge request cannot be merged; its state does
+		var current gitLabMergeRequest
+		if doJSON("GET", g.projectURL(fmt.Sprintf("/merge_requests/%d", pr.Number)), g.headers(), nil, &current) == nil {
+			switch {
+			case current.State == "merged":
+				pr.State = current.State
+				return fmt.Errorf("merge request !%d %w", pr.Number, ErrAlreadyMerged)
+			case current.HasConflicts:
+				return fmt.Errorf("merge request !%d %w", pr.Number, ErrMergeConflict)
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+	pr.State = mr.State
+	return nil
+}
+
+// projectURL returns the API URL of a project resource
+func (g *gitLab) projectURL(resource string) string {
+	return g.baseURL + "/projects/" + g.project + resource
+}
+
+// headers returns the authentication headers
+func (g *gitLab) headers() map[string]string {
+	if g.token == "" {
+		return nil
+	}
+	return map[string]string{"PRIVATE-TOKEN": g.token}
+}
+
+// toPullRequest converts a GitLab merge request
+func (mr gitLabMergeRequest) toPullRequest() PullRequest {
+	return PullRequest{
+		Number:       mr.IID,
+		Title:        mr.Title,
+		URL:          mr.WebURL,
+		SourceBranch: mr.SourceBranch,
+		TargetBranch: mr.TargetBranch,
+		State:        mr.State,
+		Draft:        mr.Draft,
+	}
+}
@@ -0,0 +1,120 @@
+package forge
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"git_cli_tool/config"
+)
+
+func TestGitLabMergePullRequest(t *testing.T) {
+	const (
+		merge = "PUT /api/v4/projects/group%2Fapi/merge_requests/7/merge"
+		get   = "GET /api/v4/projects/group%2Fapi/merge_requests/7"
+	)
+
+	tests := []struct {
+		name         string
+		opts         MergeOptions
+		responses    map[string]apiResponse
+		wantRequests []string
+		wantState    string
+		wantErr      error // nil for success, errNotClassified for any other error
+	}{
+		{
+			name:         "merged",
+			opts:         MergeOptions{Strategy: MergeStrategySquash, DeleteSourceBranch: true},
+			responses:    map[string]apiResponse{merge: {Status: 200, Body: `{"iid":7,"state":"merged"}`}},
+			wantRequests: []string{merge + ` {"should_remove_source_branch":true,"squash":true}`},
+			wantState:    "merged",
+		},
+		{
+			name: "already merged",
+			opts: MergeOptions{Strategy: MergeStrategyMerge},
+			responses: map[string]apiResponse{
+				merge: {Status: 405, Body: `{"message":"405 Method Not Allowed"}`},
+				get:   {Status: 200, Body: `{"iid":7,"state":"merged"}`},
+			},
+			wantRequests: []string{merge + ` {"should_remove_source_branch":false,"squash":false}`, get},
+			wantState:    "merged",
+			wantErr:      ErrAlreadyMerged,
+		},
+		{
+			name: "conflicts",
+			opts: MergeOptions{Strategy: MergeStrategyMerge},
+			responses: map[string]apiResponse{
+				merge: {Status: 406, Body: `{"message":"Branch cannot be merged"}`},
+				get:   {Status: 200, Body: `{"iid":7,"state":"opened","has_conflicts":true}`},
+			},
+			wantRequests: []string{merge + ` {"should_remove_source_branch":false,"squash":false}`, get},
+			wantState:    "opened",
+			wantErr:      ErrMergeConflict,
+		},
+		{
+			name: "not mergeable for another reason",
+			opts: MergeOptions{Strategy: MergeStrategyMerge},
+			responses: map[string]apiResponse{
+				merge: {Status: 405, Body: `{"message":"405 Method Not Allowed"}`},
+				get:   {Status: 200, Body: `{"iid":7,"state":"opened","draft":true}`},
+			},
+			wantRequests: []string{merge + ` {"should_remove_source_branch":false,"squash":false}`, get},
+			wantState:    "opened",
+			wantErr:      errNotClassified,
+		},
+		{
+			name:         "unauthorized",
+			opts:         MergeOptions{Strategy: MergeStrategyMerge},
+			responses:    map[string]apiResponse{merge: {Status: 401, Body: `{"message":"401 Unauthorized"}`}},
+			wantRequests: []string{merge + ` {"should_remove_source_branch":false,"squash":false}`},
+			wantState:    "opened",
+			wantErr:      errNotClassified,
+		},
+		{
+			name:      "rebase is not supported",
+			opts:      MergeOptions{Strategy: MergeStrategyRebase},
+			wantState: "opened",
+			wantErr:   errNotClassified,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI(t)
+			for request, response := range tt.responses {
+				api.On(request, response)
+			}
+
+			provider := newGitLab(config.ForgeInstance{URL: "https://gitlab.example.com", Token: "secret"}, "group/api")
+			pr := &PullRequest{Number: 7, State: "opened"}
+			err := provider.MergePullRequest(pr, tt.opts)
+			checkMergeError(t, err, tt.wantErr)
+			if pr.State != tt.wantState {
+				t.Errorf("State = %q, want %q", pr.State, tt.wantState)
+			}
+			if requests := api.Requests(); !reflect.DeepEqual(requests, tt.wantRequests) {
+				t.Errorf("requests = %q, want %q", requests, tt.wantRequests)
+			}
+		})
+	}
+}
+
+// errNotClassified stands for a merge error that is neither ErrAlreadyMerged
+// nor ErrMergeConflict
+var errNotClassified = errors.New("not classified")
+
+// checkMergeError compares the error of MergePullRequest with the wanted one
+func checkMergeError(t *testing.T, err error, want error) {
+	t.Helper()
+	switch {
+	case want == nil && err != nil:
+		t.Fatalf("MergePullRequest() error = %v", err)
+	case want == nil:
+	case err == nil:
+		t.Fatalf("MergePullRequest() succeeded, want error %v", want)
+	case want == errNotClassified && (errors.Is(err, ErrAlreadyMerged) || errors.Is(err, ErrMergeConflict)):
+		t.Fatalf("MergePullRequest() error = %v, want an unclassified error", err)
+	case want != errNotClassified && !errors.Is(err, want):
+		t.Fatalf("MergePullRequest() error = %v, want %v", err, want)
+	}
+}
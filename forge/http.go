@@ -0,0 +1,73 @@
+package forge
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// httpClient is shared by all providers
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// statusError is the error of a request answered with a non-2xx status
+type statusError struct {
+	Method string
+	Path   string
+	Status string // e.g. "405 Method Not Allowed"
+	Code   int
+	Body   string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("%s %s: %s %s", e.Method, e.Path, e.Status, e.Body)
+}
+
+// doJSON sends a request with an optional JSON body and decodes the JSON
+// response into result (if not nil). Non-2xx responses are returned as errors.
+func doJSON(method string, url string, headers map[string]string, body interface{}, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request to %s failed: %v", req.URL.Host, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %v", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &statusError{Method: method, Path: req.URL.Path, Status: resp.Status, Code: resp.StatusCode, Body: strings.TrimSpace(string(data))}
+	}
+
+	if result != nil {
+		if err := json.Unmarshal(data, result); err != nil {
+			return fmt.Errorf("failed to decode response: %v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package forge
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// apiResponse is the canned response of a fake API
+type apiResponse struct {
+	Status int
+	Body   string
+}
+
+// fakeAPI answers the requests of the providers with registered responses
+// and records every request it receives
+type fakeAPI struct {
+	mutex     sync.Mutex
+	responses map[string]apiResponse
+	requests  []string
+}
+
+// newFakeAPI sends the requests of all providers to a fake API until the end
+// of the test and returns it
+func newFakeAPI(t *testing.T) *fakeAPI {
+	api := &fakeAPI{responses: make(map[string]apiResponse)}
+	previous := httpClient.Transport
+	httpClient.Transport = api
+	t.Cleanup(func() { httpClient.Transport = previous })
+	return api
+}
+
+// On registers the response to requests with the given method and URL path,
+// e.g. On("GET /api/v4/projects/group%2Fapi/merge_requests/7", ...). Requests
+// without a registration are answered with 404 Not Found.
+func (f *fakeAPI) On(request string, response apiResponse) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.responses[request] = response
+}
+
+// Requests returns the requests received so far as "METHOD path body"
+func (f *fakeAPI) Requests() []string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return append([]string(nil), f.requests...)
+}
+
+func (f *fakeAPI) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := req.Method + " " + req.URL.EscapedPath()
+	request := key
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		request += " " + string(body)
+	}
+
+	f.mutex.Lock()
+	f.requests = append(f.requests, request)
+	response, ok := f.responses[key]
+	f.mutex.Unlock()
+	if !ok {
+		response = apiResponse{Status: http.StatusNotFound, Body: `{"message":"404 Not Found"}`}
+	}
+
+	return &http.Response{
+		StatusCode: response.Status,
+		Status:     http.StatusText(response.Status),
+		Body:       io.NopCloser(strings.NewReader(response.Body)),
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Request:    req,
+	}, nil
+}
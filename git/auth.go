@@ -0,0 +1,46 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrAuthFailed is returned when a remote command failed because the remote
+// asked for credentials. Git is run without terminal prompts, so it fails
+// instead of waiting for a username or password.
+var ErrAuthFailed = errors.New("authentication required")
+
+// authFailureMessages are parts of the messages git, ssh and common credential
+// helpers print when credentials are missing or rejected
+var authFailureMessages = []string{
+	"terminal prompts disabled",
+	"could not read username",
+	"could not read password",
+	"authentication failed",
+	"permission denied (publickey",
+	"http basic: access denied",
+	"host key verification failed",
+	"invalid username or password",
+}
+
+// IsAuthFailure reports whether the output of a failed remote command shows
+// that the remote asked for credentials or rejected them
+func IsAuthFailure(output string) bool {
+	output = strings.ToLower(output)
+	for _, message := range authFailureMessages {
+		if strings.Contains(output, message) {
+			return true
+		}
+	}
+	return false
+}
+
+// WrapAuthFailure wraps the error of a failed remote command in ErrAuthFailed if its
+// output shows an authentication failure, and returns err unchanged otherwise
+func WrapAuthFailure(err error, output string) error {
+	if err == nil || !IsAuthFailure(output) {
+		return err
+	}
+	return fmt.Errorf("%w: %v\n%s", ErrAuthFailed, err, output)
+}
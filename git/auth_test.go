@@ -0,0 +1,55 @@
+package git
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"git_cli_tool/config"
+	"git_cli_tool/gitexec/gitexectest"
+)
+
+func TestFetchRepositoryAuthFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		stderr   string
+		wantAuth bool
+	}{
+		{
+			name:     "https without credentials",
+			stderr:   "fatal: could not read Username for 'https://example.com': terminal prompts disabled\n",
+			wantAuth: true,
+		},
+		{
+			name:     "rejected credentials",
+			stderr:   "remote: HTTP Basic: Access denied\nfatal: Authentication failed for 'https://example.com/app.git/'\n",
+			wantAuth: true,
+		},
+		{
+			name:     "ssh key not accepted",
+			stderr:   "git@example.com: Permission denied (publickey).\nfatal: Could not read from remote repository.\n",
+			wantAuth: true,
+		},
+		{
+			name:   "unknown remote",
+			stderr: "fatal: 'upstream' does not appear to be a git repository\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			mustMkdir(t, filepath.Join(dir, ".git"))
+			fake := gitexectest.New(t)
+			fake.On("fetch", gitexectest.Result{Stderr: tt.stderr, ExitCode: 128})
+
+			err := FetchRepository(config.Repository{Path: dir, Remote: "origin"})
+			if err == nil {
+				t.Fatal("FetchRepository() error = nil, want an error")
+			}
+			if got := errors.Is(err, ErrAuthFailed); got != tt.wantAuth {
+				t.Errorf("errors.Is(err, ErrAuthFailed) = %v, want %v (err: %v)", got, tt.wantAuth, err)
+			}
+		})
+	}
+}
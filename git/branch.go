@@ -3,26 +3,28 @@ package git
 
 import (
 	"fmt"
-	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
-	"sync"
 
-	"git_cli_tool/config"
-	"git_cli_tool/log"
+	"git_cli_tool/gitexec"
 )
 
 // SwitchResult holds the result of switching a branch in a repository
 type SwitchResult struct {
-	RepoPath              string
-	RepoName              string
-	FromBranch            string
-	ToBranch              string
-	Success               bool
-	Message               string
-	FromRemote            bool
-	AlreadyOnIt           bool
+	RepoPath    string
+	RepoName    string
+	Attempted   []string // branches tried, in priority order
+	FromBranch  string
+	ToBranch    string // branch the repository landed on
+	Success     bool
+	Message     string
+	FromRemote  bool
+	AlreadyOnIt bool
+	Detached    bool // ToBranch is a tag or commit checked out with a detached HEAD
+	Stashed     bool
+	StashCommit string // SHA of the stash created before the switch
+	Err         error
+	HookErr     error // a post_switch hook failed after the switch succeeded
 }
 
 
@@ -34,11 +36,11 @@ func GetCurrentBranch(repoPath string) (string, error) {
 	}
 
 	// Check if repository exists
-	if _, err := os.Stat(filepath.Join(absPath, ".git")); os.IsNotExist(err) {
-		return "", fmt.Errorf("not a git repository or directory does not exist")
+	if err := ValidateRepository(absPath); err != nil {
+		return "", err
 	}
 
-	cmd := exec.Command("git", "-C", absPath, "rev-parse", "--abbrev-ref", "HEAD")
+	cmd := gitexec.Command("-C", absPath, "rev-parse", "--abbrev-ref", "HEAD")
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return "", fmt.Errorf("failed to get current branch: %v", err)
@@ -49,12 +51,12 @@ func GetCurrentBranch(repoPath string) (string, error) {
 
 // CheckBranchExists checks if a branch exists locally
 func CheckBranchExists(repoPath string, branch string) (bool, error) {
-	cmd := exec.Command("git", "-C", repoPath, "show-ref", "--verify", "--quiet", "refs/heads/"+branch)
+	cmd := gitexec.Command("-C", repoPath, "show-ref", "--verify", "--quiet", "refs/heads/"+branch)
 	err := cmd.Run()
 
 	if err != nil {
 		// Exit code 1 means branch doesn't exist, which is not an error for our purposes
-		if exitError, ok := err.(*exec.ExitError); ok && exitError.ExitCode() == 1 {
+		if gitexec.ExitCode(err) == 1 {
 			return false, nil
 		}
 		return false, err
@@ -63,14 +65,14 @@ func CheckBranchExists(repoPath string, branch string) (bool, error) {
 	return true, nil
 }
 
-// CheckRemoteBranchExists checks if a branch exists on the remote
-func CheckRemoteBranchExists(repoPath string, branch string) (bool, error) {
-	cmd := exec.Command("git", "-C", repoPath, "show-ref", "--verify", "--quiet", "refs/remotes/origin/"+branch)
+// CheckRemoteBranchExists checks if a branch exists on the given remote
+func CheckRemoteBranchExists(repoPath string, remote string, branch string) (bool, error) {
+	cmd := gitexec.Command("-C", repoPath, "show-ref", "--verify", "--quiet", "refs/remotes/"+remote+"/"+branch)
 	err := cmd.Run()
 
 	if err != nil {
 		// Exit code 1 means branch doesn't exist, which is not an error for our purposes
-		if exitError, ok := err.(*exec.ExitError); ok && exitError.ExitCode() == 1 {
+		if gitexec.ExitCode(err) == 1 {
 			return false, nil
 		}
 		return false, err
@@ -80,78 +82,76 @@ func CheckRemoteBranchExists(repoPath string, branch string) (bool, error) {
 }
 
 // SwitchBranchWithFallback tries to switch to each branch in the given order
-func SwitchBranchWithFallback(repoPath string, branches []string) error {
+func SwitchBranchWithFallback(repoPath string, remote string, branches []string) error {
 	absPath, err := filepath.Abs(repoPath)
 	if err != nil {
 		return fmt.Errorf("failed to resolve absolute path: %v", err)
 	}
 
 	// Check if repository exists
-	if _, err := os.Stat(filepath.Join(absPath, ".git")); os.IsNotExist(err) {
-		return fmt.Errorf("not a git repository or directory does not exist")
+	if err := ValidateRepository(absPath); err != nil {
+		return err
 	}
 
-	// Try each branch in order
+	// Find the local branches first. Unless the preferred branch is local, the
+	// remote is asked about all other branches in one request.
+	local := make([]bool, len(branches))
+	var candidates []string
 	var lastError error
 	for i, branch := range branches {
-		// Check if branch exists locally
-		branchExists, err := CheckBranchExists(absPath, branch)
+		exists, err := CheckBranchExists(absPath, branch)
 		if err != nil {
 			lastError = fmt.Errorf("failed to check if branch %s exists: %v", branch, err)
 			continue
 		}
+		local[i] = exists
+		if !exists {
+			candidates = append(candidates, branch)
+		}
+	}
+	if len(branches) > 0 && local[0] {
+		candidates = nil
+	}
+	onRemote := FindRemoteBranches(absPath, remote, candidates)
 
+	fetched := false
+	for i, branch := range branches {
 		// If branch exists locally, switch to it
-		if branchExists {
-			cmd := exec.Command("git", "-C", absPath, "checkout", branch)
+		if local[i] {
+			cmd := gitexec.Command("-C", absPath, "checkout", branch)
 			output, err := cmd.CombinedOutput()
 			if err != nil {
 				lastError = fmt.Errorf("git checkout failed for branch %s: %v\n%s", branch, err, output)
 				continue
 			}
-			log.PrintSuccess(fmt.Sprintf("Successfully switched to branch %s in %s", branch, repoPath))
 			return nil
 		}
-
-		// If branch doesn't exist locally, try to fetch and check remote
-		log.PrintInfo(fmt.Sprintf("Branch %s not found locally in %s, fetching from remote...", branch, repoPath))
-
-		// Fetch from remote
-		fetchCmd := exec.Command("git", "-C", absPath, "fetch")
-		output, err := fetchCmd.CombinedOutput()
-		if err != nil {
-			lastError = fmt.Errorf("git fetch failed: %v\n%s", err, output)
+		if !onRemote[branch] {
 			continue
 		}
 
-		// Check if remote branch exists
-		remoteBranchExists, err := CheckRemoteBranchExists(absPath, branch)
-		if err != nil {
-			lastError = fmt.Errorf("failed to check if remote branch %s exists: %v", branch, err)
-			continue
+		// Fetch once so the remote-tracking branch exists
+		if !fetched {
+			if err := Fetch(absPath, remote); err != nil {
+				lastError = err
+				continue
+			}
+			fetched = true
 		}
 
-		if remoteBranchExists {
-			// Create tracking branch
-			trackCmd := exec.Command("git", "-C", absPath, "checkout", "-b", branch, "--track", "origin/"+branch)
-			_, err := trackCmd.CombinedOutput()
+		// Create tracking branch
+		trackCmd := gitexec.Command("-C", absPath, "checkout", "-b", branch, "--track", remote+"/"+branch)
+		_, err := trackCmd.CombinedOutput()
+		if err != nil {
+			// If branch creation fails, try direct checkout of remote branch
+			checkoutCmd := gitexec.Command("-C", absPath, "checkout", branch)
+			checkoutOutput, err := checkoutCmd.CombinedOutput()
 			if err != nil {
-				// If branch creation fails, try direct checkout of remote branch
-				checkoutCmd := exec.Command("git", "-C", absPath, "checkout", branch)
-				checkoutOutput, err := checkoutCmd.CombinedOutput()
-				if err != nil {
-					lastError = fmt.Errorf("failed to checkout remote branch %s: %v\n%s", branch, err, checkoutOutput)
-					continue
-				}
+				lastError = fmt.Errorf("failed to checkout remote branch %s: %v\n%s", branch, err, checkoutOutput)
+				continue
 			}
-			log.PrintSuccess(fmt.Sprintf("Successfully switched to branch %s in %s", branch, repoPath))
-			return nil
-		}
-
-		// If we're on the last branch and none have worked, log that we're trying the next branch
-		if i < len(branches)-1 {
-			log.PrintInfo(fmt.Sprintf("Branch %s not found locally or remotely in %s, trying next branch...", branch, repoPath))
 		}
+		return nil
 	}
 
 	// If we get here, none of the branches worked
@@ -162,23 +162,26 @@ func SwitchBranchWithFallback(repoPath string, branches []string) error {
 }
 
 // SwitchBranchWithResult switches to a branch and returns the result (no logging)
-func SwitchBranchWithResult(repoPath string, branches []string) SwitchResult {
+func SwitchBranchWithResult(repoPath string, remote string, branches []string) SwitchResult {
 	absPath, err := filepath.Abs(repoPath)
 	repoName := filepath.Base(repoPath)
 	
 	result := SwitchResult{
-		RepoPath: repoPath,
-		RepoName: repoName,
+		RepoPath:  repoPath,
+		RepoName:  repoName,
+		Attempted: branches,
 	}
 
 	if err != nil {
 		result.Message = "failed to resolve path"
+		result.Err = err
 		return result
 	}
 
 	// Check if repository exists
-	if _, err := os.Stat(filepath.Join(absPath, ".git")); os.IsNotExist(err) {
+	if err := ValidateRepository(absPath); err != nil {
 		result.Message = "not a git repository"
+		result.Err = err
 		return result
 	}
 
@@ -197,10 +200,6 @@ func SwitchBranchWithResult(repoPath string, branches []string) SwitchResult {
 	branchesInfo := make([]branchInfo, len(branches))
 	currentBranchPriority := -1 // -1 means current branch is not in the list
 	
-	// Fetch remotes once upfront (for efficiency)
-	fetchCmd := exec.Command("git", "-C", absPath, "fetch")
-	fetchCmd.CombinedOutput() // Ignore errors
-	
 	for i, branch := range branches {
 		info := branchInfo{name: branch, priority: i}
 		
@@ -214,15 +213,23 @@ func SwitchBranchWithResult(repoPath string, branches []string) SwitchResult {
 			info.existsLocal = exists
 		}
 		
-		// Check if branch exists on remote
-		if !info.existsLocal {
-			exists, _ := CheckRemoteBranchExists(absPath, branch)
-			info.existsRemote = exists
-		}
-		
 		branchesInfo[i] = info
 	}
 	
+	// Ask the remote about the branches preferred over the first local one,
+	// all in one request; the others cannot change the outcome
+	var candidates []string
+	for _, info := range branchesInfo {
+		if info.existsLocal {
+			break
+		}
+		candidates = append(candidates, info.name)
+	}
+	onRemote := FindRemoteBranches(absPath, remote, candidates)
+	for i := range branchesInfo {
+		branchesInfo[i].existsRemote = onRemote[branchesInfo[i].name]
+	}
+	
 	// Find the best available branch (highest priority that exists)
 	bestBranchIdx := -1
 	for i, info := range branchesInfo {
@@ -235,6 +242,7 @@ func SwitchBranchWithResult(repoPath string, branches []string) SwitchResult {
 	// No branches found at all
 	if bestBranchIdx == -1 {
 		result.Message = "no matching branch found"
+		result.Err = fmt.Errorf("none of the branches %v exist locally or on %s", branches, remote)
 		return result
 	}
 	
@@ -252,7 +260,7 @@ func SwitchBranchWithResult(repoPath string, branches []string) SwitchResult {
 	// We're not on the best branch - need to switch
 	// Try to switch to the best available branch
 	if bestBranch.existsLocal {
-		cmd := exec.Command("git", "-C", absPath, "checkout", bestBranch.name)
+		cmd := gitexec.Command("-C", absPath, "checkout", bestBranch.name)
 		output, err := cmd.CombinedOutput()
 		if err != nil {
 			// Check if the error is due to uncommitted changes
@@ -265,6 +273,7 @@ func SwitchBranchWithResult(repoPath string, branches []string) SwitchResult {
 			} else {
 				result.Message = fmt.Sprintf("checkout failed: %v", err)
 			}
+			result.Err = fmt.Errorf("%v\n%s", err, output)
 			return result
 		}
 		result.ToBranch = bestBranch.name
@@ -273,8 +282,9 @@ func SwitchBranchWithResult(repoPath string, branches []string) SwitchResult {
 		return result
 	}
 	
-	// Best branch is only on remote - create tracking branch
-	trackCmd := exec.Command("git", "-C", absPath, "checkout", "-b", bestBranch.name, "--track", "origin/"+bestBranch.name)
+	// Best branch is only on remote - fetch it and create tracking branch
+	Fetch(absPath, remote) // Ignore errors, the checkout reports them
+	trackCmd := gitexec.Command("-C", absPath, "checkout", "-b", bestBranch.name, "--track", remote+"/"+bestBranch.name)
 	output, err := trackCmd.CombinedOutput()
 	if err != nil {
 		// Check if the error is due to uncommitted changes
@@ -284,11 +294,12 @@ func SwitchBranchWithResult(repoPath string, branches []string) SwitchResult {
 			strings.Contains(outputStr, "would be overwritten") ||
 			strings.Contains(outputStr, "local changes") {
 			result.Message = "uncommitted changes"
+			result.Err = fmt.Errorf("%v\n%s", err, output)
 			return result
 		}
 		
 		// Try direct checkout as fallback
-		checkoutCmd := exec.Command("git", "-C", absPath, "checkout", bestBranch.name)
+		checkoutCmd := gitexec.Command("-C", absPath, "checkout", bestBranch.name)
 		output, err = checkoutCmd.CombinedOutput()
 		if err != nil {
 			outputStr = string(output)
@@ -300,6 +311,7 @@ func SwitchBranchWithResult(repoPath string, branches []string) SwitchResult {
 			} else {
 				result.Message = fmt.Sprintf("checkout failed: %v", err)
 			}
+			result.Err = fmt.Errorf("%v\n%s", err, output)
 			return result
 		}
 	}
@@ -311,67 +323,16 @@ func SwitchBranchWithResult(repoPath string, branches []string) SwitchResult {
 	return result
 }
 
-// SwitchBranchWithFallbackAndStash tries to switch to each branch in the given order, stashing changes if requested
-func SwitchBranchWithFallbackAndStash(repoPath string, branches []string, stashName string) (bool, error) {
-	wasStashed := false
-	// If stashName is not empty, stash changes first
-	if stashName != "" {
-		var err error
-		wasStashed, err = StashChanges(repoPath, stashName)
-		if err != nil {
-			return false, fmt.Errorf("failed to stash changes: %v", err)
-		}
-	}
-
-	// Proceed with normal branch switching
-	return wasStashed, SwitchBranchWithFallback(repoPath, branches)
-}
-
-// SwitchBranchesWithStash switches branches in the provided repositories in parallel, with optional stashing
-func SwitchBranchesWithStash(repositories []config.Repository, branches []string, stashName string) map[string]bool {
-	var wg sync.WaitGroup
-	var mutex sync.Mutex // Mutex to protect the stashedRepos map from concurrent writes
-	stashedRepos := make(map[string]bool)
-
-	wg.Add(len(repositories))
-
-	for _, repo := range repositories {
-		go func(r config.Repository) {
-			defer wg.Done()
-
-			var err error
-			if stashName != "" {
-				var wasStashed bool
-				wasStashed, err = SwitchBranchWithFallbackAndStash(r.Path, branches, stashName)
-				if err == nil && wasStashed {
-					mutex.Lock()
-					stashedRepos[r.Path] = true
-					mutex.Unlock()
-				}
-			} else {
-				err = SwitchBranchWithFallback(r.Path, branches)
-			}
-
-			if err != nil {
-				log.PrintErrorNoExit(log.ErrGitCheckoutFailed, fmt.Sprintf("Error switching branch in %s", r.Path), err)
-			}
-		}(repo)
-	}
-
-	wg.Wait()
-	return stashedRepos
-}
-
 // SwitchToBranch switches to a specific branch in a repository
-func SwitchToBranch(repoPath string, branch string) error {
+func SwitchToBranch(repoPath string, remote string, branch string) error {
 	absPath, err := filepath.Abs(repoPath)
 	if err != nil {
 		return fmt.Errorf("failed to resolve absolute path: %v", err)
 	}
 
 	// Check if repository exists
-	if _, err := os.Stat(filepath.Join(absPath, ".git")); os.IsNotExist(err) {
-		return fmt.Errorf("not a git repository or directory does not exist")
+	if err := ValidateRepository(absPath); err != nil {
+		return err
 	}
 
 	// Check if branch exists locally
@@ -382,44 +343,39 @@ func SwitchToBranch(repoPath string, branch string) error {
 
 	// If branch exists locally, switch to it
 	if branchExists {
-		cmd := exec.Command("git", "-C", absPath, "checkout", branch)
+		cmd := gitexec.Command("-C", absPath, "checkout", branch)
 		output, err := cmd.CombinedOutput()
 		if err != nil {
 			return fmt.Errorf("git checkout failed for branch %s: %v\n%s", branch, err, output)
 		}
-		log.PrintSuccess(fmt.Sprintf("Successfully switched to branch %s in %s", branch, repoPath))
 		return nil
 	}
 
 	// If branch doesn't exist locally, try to find and check it out from remote
-	log.PrintInfo(fmt.Sprintf("Branch %s not found locally in %s, checking remote...", branch, repoPath))
 
 	// Fetch from remote
-	fetchCmd := exec.Command("git", "-C", absPath, "fetch")
-	fetchOutput, err := fetchCmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("git fetch failed: %v\n%s", err, fetchOutput)
+	if err := Fetch(absPath, remote); err != nil {
+		return err
 	}
 
 	// Check if remote branch exists
-	remoteBranchExists, err := CheckRemoteBranchExists(absPath, branch)
+	remoteBranchExists, err := CheckRemoteBranchExists(absPath, remote, branch)
 	if err != nil {
 		return fmt.Errorf("failed to check if remote branch %s exists: %v", branch, err)
 	}
 
 	if remoteBranchExists {
 		// Create tracking branch
-		trackCmd := exec.Command("git", "-C", absPath, "checkout", "-b", branch, "--track", "origin/"+branch)
+		trackCmd := gitexec.Command("-C", absPath, "checkout", "-b", branch, "--track", remote+"/"+branch)
 		_, err := trackCmd.CombinedOutput()
 		if err != nil {
 			// If branch creation fails, try direct checkout of remote branch
-			checkoutCmd := exec.Command("git", "-C", absPath, "checkout", branch)
+			checkoutCmd := gitexec.Command("-C", absPath, "checkout", branch)
 			checkoutOutput, err := checkoutCmd.CombinedOutput()
 			if err != nil {
 				return fmt.Errorf("failed to checkout remote branch %s: %v\n%s", branch, err, checkoutOutput)
 			}
 		}
-		log.PrintSuccess(fmt.Sprintf("Successfully switched to branch %s in %s", branch, repoPath))
 		return nil
 	}
 
@@ -427,54 +383,285 @@ func SwitchToBranch(repoPath string, branch string) error {
 }
 
 // SwitchBranch attempts to switch to the given branch in the specified repository
-func SwitchBranch(repoPath string, branch string, stashChanges bool) error {
+func SwitchBranch(repoPath string, remote string, branch string, stashChanges bool) error {
 	// Check if we need to stash changes
 	if stashChanges {
-		if _, err := StashChanges(repoPath, branch); err != nil {
+		if _, err := StashChanges(repoPath, branch, StashOptions{}); err != nil {
 			return fmt.Errorf("failed to stash changes: %v", err)
 		}
 	}
 
 	// Try to check out the branch directly first
-	cmd := exec.Command("git", "-C", repoPath, "checkout", branch)
+	cmd := gitexec.Command("-C", repoPath, "checkout", branch)
 	if _, err := cmd.CombinedOutput(); err == nil {
-		log.PrintSuccess(fmt.Sprintf("Successfully switched to branch %s in %s", branch, repoPath))
 		return nil
 	} else {
 		// Branch doesn't exist locally, check if it exists remotely
-		log.PrintInfo(fmt.Sprintf("Branch %s not found locally in %s, checking remote...", branch, repoPath))
 
 		// Fetch from remote to get latest branches
-		fetchCmd := exec.Command("git", "-C", repoPath, "fetch")
-		if _, err := fetchCmd.CombinedOutput(); err != nil {
+		if err := Fetch(repoPath, remote); err != nil {
 			return fmt.Errorf("failed to fetch from remote: %v", err)
 		}
 
 		// Check if the branch exists as a remote branch
-		lsRemoteCmd := exec.Command("git", "-C", repoPath, "ls-remote", "--heads", "origin", branch)
+		lsRemoteCmd := gitexec.Command("-C", repoPath, "ls-remote", "--heads", remote, branch)
 		output, _ := lsRemoteCmd.CombinedOutput()
 
 		if len(output) > 0 {
 			// Remote branch exists, check it out
-			checkoutCmd := exec.Command("git", "-C", repoPath, "checkout", "-b", branch, "--track", "origin/"+branch)
+			checkoutCmd := gitexec.Command("-C", repoPath, "checkout", "-b", branch, "--track", remote+"/"+branch)
 			_, err := checkoutCmd.CombinedOutput()
 
 			if err != nil {
 				// If that failed, maybe the branch already exists locally but is tracking a different remote
 				// Try a simple checkout with tracking
-				checkoutTrackCmd := exec.Command("git", "-C", repoPath, "checkout", "--track", "origin/"+branch)
+				checkoutTrackCmd := gitexec.Command("-C", repoPath, "checkout", "--track", remote+"/"+branch)
 				output, err = checkoutTrackCmd.CombinedOutput()
 				if err != nil {
 					return fmt.Errorf("failed to checkout branch %s: %v\n%s", branch, err, string(output))
 				}
 			}
 
-			log.PrintSuccess(fmt.Sprintf("Successfully switched to branch %s in %s", branch, repoPath))
 			return nil
 		} else {
 			// Branch doesn't exist remotely either
-			log.PrintWarning(fmt.Sprintf("Branch %s not found locally or remotely in %s", branch, repoPath))
 			return fmt.Errorf("branch %s not found locally or remotely", branch)
 		}
 	}
 }
+
+// CreateBranch creates a branch at startPoint without checking it out
+func CreateBranch(repoPath string, branch string, startPoint string) error {
+	cmd := gitexec.Command("-C", repoPath, "branch", "--no-track", branch, startPoint)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to create branch %s: %v\n%s", branch, err, output)
+	}
+	return nil
+}
+
+// PushBranch pushes a branch to the remote and sets it as the branch's upstream
+func PushBranch(repoPath string, remote string, branch string) error {
+	cmd := gitexec.Command("-C", repoPath, "push", "-u", remote, branch)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return WrapAuthFailure(fmt.Errorf("failed to push branch %s: %v\n%s", branch, err, output), string(output))
+	}
+	return nil
+}
+
+// ListBranches returns the local branches of a repository and the branches of
+// the given remote (without the remote prefix), both sorted by name
+func ListBranches(repoPath string, remote string) ([]string, []string, error) {
+	cmd := gitexec.Command("-C", repoPath, "for-each-ref", "--format=%(refname)", "refs/heads", "refs/remotes/"+remote)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list branches: %v\n%s", err, output)
+	}
+	local, remoteBranches := splitBranchRefs(string(output), remote)
+	return local, remoteBranches, nil
+}
+
+// ListMergedBranches returns the local branches and the branches of the given
+// remote (without the remote prefix) whose tip is reachable from ref, i.e. that
+// were merged into it or never diverged from it
+func ListMergedBranches(repoPath string, ref string, remote string) ([]string, []string, error) {
+	cmd := gitexec.Command("-C", repoPath, "for-each-ref", "--merged", ref, "--format=%(refname)", "refs/heads", "refs/remotes/"+remote)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list branches merged into %s: %v\n%s", ref, err, output)
+	}
+	local, remoteBranches := splitBranchRefs(string(output), remote)
+	return local, remoteBranches, nil
+}
+
+// ListBranchesAt returns the local branches and the branches of the given
+// remote (without the remote prefix) whose tip is the commit ref points to,
+// e.g. branches that were just created from it and have no commits of their own
+func ListBranchesAt(repoPath string, ref string, remote string) ([]string, []string, error) {
+	cmd := gitexec.Command("-C", repoPath, "for-each-ref", "--points-at", ref, "--format=%(refname)", "refs/heads", "refs/remotes/"+remote)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list branches at %s: %v\n%s", ref, err, output)
+	}
+	local, remoteBranches := splitBranchRefs(string(output), remote)
+	return local, remoteBranches, nil
+}
+
+// splitBranchRefs splits for-each-ref output into the local branches and the
+// branches of the given remote, without their prefixes
+func splitBranchRefs(output string, remote string) ([]string, []string) {
+	var local, remoteBranches []string
+	remotePrefix := "refs/remotes/" + remote + "/"
+	for _, ref := range strings.Split(strings.TrimSpace(output), "\n") {
+		switch {
+		case strings.HasPrefix(ref, "refs/heads/"):
+			local = append(local, strings.TrimPrefix(ref, "refs/heads/"))
+		case strings.HasPrefix(ref, remotePrefix) && ref != remotePrefix+"HEAD":
+			remoteBranches = append(remoteBranches, strings.TrimPrefix(ref, remotePrefix))
+		}
+	}
+	return local, remoteBranches
+}
+
+// DeleteBranch deletes a local branch. Without force, git refuses to delete a
+// branch that is not merged into its upstream or HEAD.
+func DeleteBranch(repoPath string, branch string, force bool) error {
+	flag := "-d"
+	if force {
+		flag = "-D"
+	}
+	cmd := gitexec.Command("-C", repoPath, "branch", flag, branch)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to delete branch %s: %v\n%s", branch, err, output)
+	}
+	return nil
+}
+
+// DeleteRemoteBranch deletes a branch on the remote, which also removes its
+// remote-tracking branch
+func DeleteRemoteBranch(repoPath string, remote string, branch string) error {
+	cmd := gitexec.Command("-C", repoPath, "push", remote, "--delete", branch)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to delete %s/%s: %v\n%s", remote, branch, err, output)
+	}
+	return nil
+}
+
+// RenameBranch renames a local branch, failing if the new name already exists
+func RenameBranch(repoPath string, oldName string, newName string) error {
+	cmd := gitexec.Command("-C", repoPath, "branch", "-m", oldName, newName)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to rename branch %s to %s: %v\n%s", oldName, newName, err, output)
+	}
+	return nil
+}
+
+// GetBranchUpstream returns the upstream of a local branch (e.g. origin/main),
+// or an empty string if it has none
+func GetBranchUpstream(repoPath string, branch string) string {
+	cmd := gitexec.Command("-C", repoPath, "rev-parse", "--abbrev-ref", "--symbolic-full-name", branch+"@{upstream}")
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// GetDefaultBranch returns the default branch of a remote as recorded by
+// refs/remotes/<remote>/HEAD when the repository was cloned (e.g. main,
+// master or develop), or an empty string if it was never recorded
+func GetDefaultBranch(repoPath string, remote string) (string, error) {
+	cmd := gitexec.Command("-C", repoPath, "symbolic-ref", "--quiet", "--short", "refs/remotes/"+remote+"/HEAD")
+	output, err := cmd.Output()
+	if err != nil {
+		// Exit code 1 means the ref does not exist or is not symbolic
+		if gitexec.ExitCode(err) == 1 {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read the default branch of %s: %v", remote, err)
+	}
+	return strings.TrimPrefix(strings.TrimSpace(string(output)), remote+"/"), nil
+}
+
+// SetBranchUpstream sets the upstream of a local branch, e.g. to origin/main
+func SetBranchUpstream(repoPath string, branch string, upstream string) error {
+	cmd := gitexec.Command("-C", repoPath, "branch", "--set-upstream-to="+upstream, branch)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to set the upstream of %s to %s: %v\n%s", branch, upstream, err, output)
+	}
+	return nil
+}
+
+// RemoteHasBranch asks the remote whether it has a branch, unlike
+// CheckRemoteBranchExists, which looks at the possibly stale remote-tracking branch
+func RemoteHasBranch(repoPath string, remote string, branch string) (bool, error) {
+	cmd := gitexec.Command("-C", repoPath, "ls-remote", "--heads", "--exit-code", remote, "refs/heads/"+branch)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		// Exit code 2 means no matching ref was found
+		if gitexec.ExitCode(err) == 2 {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to query %s: %v\n%s", remote, err, output)
+	}
+	return true, nil
+}
+
+// RemoteHeads asks the remote which of the branches it has, with a single
+// ls-remote for all of them, and returns the names of those it has
+func RemoteHeads(repoPath string, remote string, branches []string) (map[string]bool, error) {
+	args := []string{"-C", repoPath, "ls-remote", "--heads", remote}
+	for _, branch := range branches {
+		args = append(args, "refs/heads/"+branch)
+	}
+	output, err := gitexec.Command(args...).CombinedOutput()
+	if err != nil {
+		return nil, WrapAuthFailure(fmt.Errorf("failed to query %s: %v\n%s", remote, err, output), string(output))
+	}
+
+	heads := make(map[string]bool)
+	for _, line := range strings.Split(string(output), "\n") {
+		_, ref, found := strings.Cut(strings.TrimSpace(line), "\t")
+		if name, ok := strings.CutPrefix(ref, "refs/heads/"); found && ok {
+			heads[name] = true
+		}
+	}
+	return heads, nil
+}
+
+// FindRemoteBranches returns which of the branches the remote has, asking the
+// remote with RemoteHeads. If the remote cannot be reached, the remote-tracking
+// branches of the last fetch are used instead.
+func FindRemoteBranches(repoPath string, remote string, branches []string) map[string]bool {
+	if len(branches) == 0 {
+		return nil
+	}
+	heads, err := RemoteHeads(repoPath, remote, branches)
+	if err == nil {
+		return heads
+	}
+
+	heads = make(map[string]bool)
+	for _, branch := range branches {
+		if exists, _ := CheckRemoteBranchExists(repoPath, remote, branch); exists {
+			heads[branch] = true
+		}
+	}
+	return heads
+}
+
+// DeleteRemoteTrackingBranch deletes the local remote-tracking branch of a
+// branch that no longer exists on the remote
+func DeleteRemoteTrackingBranch(repoPath string, remote string, branch string) error {
+	cmd := gitexec.Command("-C", repoPath, "branch", "--delete", "--remotes", remote+"/"+branch)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to delete %s/%s: %v\n%s", remote, branch, err, output)
+	}
+	return nil
+}
+
+// DetachHead checks out a tag or commit with a detached HEAD. If the ref is not
+// known locally, the remote is fetched first, including its tags.
+func DetachHead(repoPath string, remote string, ref string) error {
+	if !CommitExists(repoPath, ref) {
+		fetchCmd := gitexec.Command("-C", repoPath, "fetch", remote, "--tags")
+		if output, err := fetchCmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("git fetch failed: %v\n%s", err, output)
+		}
+		if !CommitExists(repoPath, ref) {
+			return fmt.Errorf("%s not found", ref)
+		}
+	}
+
+	cmd := gitexec.Command("-C", repoPath, "checkout", "--quiet", "--detach", ref)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git checkout --detach %s failed: %v\n%s", ref, err, output)
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"git_cli_tool/gitexec/gitexectest"
+)
+
+// newRepoDir returns a temporary directory that looks like a repository
+func newRepoDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestSwitchBranchWithResult(t *testing.T) {
+	found := gitexectest.Result{}
+	missing := gitexectest.Result{ExitCode: 1}
+
+	tests := []struct {
+		name      string
+		branches  []string
+		current   string
+		responses map[string]gitexectest.Result
+		wantRan   string // command that must have been run
+		notRan    string // command that must not have been run
+		want      SwitchResult
+	}{
+		{
+			name:     "already on the preferred branch",
+			branches: []string{"feature/x", "main"},
+			current:  "feature/x",
+			responses: map[string]gitexectest.Result{
+				"show-ref --verify --quiet refs/remotes/origin/feature/x": found,
+			},
+			want: SwitchResult{FromBranch: "feature/x", ToBranch: "feature/x", Success: true, AlreadyOnIt: true, Message: "already on target"},
+		},
+		{
+			name:     "falls back to the first local branch",
+			branches: []string{"feature/x", "develop", "main"},
+			current:  "main",
+			responses: map[string]gitexectest.Result{
+				"show-ref --verify --quiet refs/heads/feature/x": missing,
+				"ls-remote --heads origin refs/heads/feature/x":  found,
+				"show-ref --verify --quiet refs/heads/develop":   found,
+				"checkout develop": found,
+			},
+			wantRan: "checkout develop",
+			notRan:  "fetch",
+			want:    SwitchResult{FromBranch: "main", ToBranch: "develop", Success: true, Message: "switched"},
+		},
+		{
+			name:     "creates a tracking branch for a remote-only branch",
+			branches: []string{"feature/x", "main"},
+			current:  "main",
+			responses: map[string]gitexectest.Result{
+				"show-ref --verify --quiet refs/heads/feature/x": missing,
+				"ls-remote --heads origin refs/heads/feature/x":  {Stdout: "3f2a9c1\trefs/heads/feature/x\n"},
+				"checkout -b feature/x --track origin/feature/x": found,
+			},
+			wantRan: "checkout -b feature/x --track origin/feature/x",
+			want:    SwitchResult{FromBranch: "main", ToBranch: "feature/x", Success: true, FromRemote: true, Message: "switched (from remote)"},
+		},
+		{
+			name:     "asks the remote about all candidates at once",
+			branches: []string{"feature/x", "feature/y", "main"},
+			current:  "develop",
+			responses: map[string]gitexectest.Result{
+				"show-ref --verify --quiet refs/heads/": missing,
+				"ls-remote --heads origin refs/heads/feature/x refs/heads/feature/y refs/heads/main": {
+					Stdout: "3f2a9c1\trefs/heads/feature/y\n8b1e0d4\trefs/heads/main\n",
+				},
+				"checkout -b feature/y --track origin/feature/y": found,
+			},
+			wantRan: "checkout -b feature/y --track origin/feature/y",
+			want:    SwitchResult{FromBranch: "develop", ToBranch: "feature/y", Success: true, FromRemote: true, Message: "switched (from remote)"},
+		},
+		{
+			name:     "unreachable remote falls back to remote-tracking branches",
+			branches: []string{"feature/x", "main"},
+			current:  "main",
+			responses: map[string]gitexectest.Result{
+				"show-ref --verify --quiet refs/heads/feature/x": missing,
+				"ls-remote": {Stderr: "fatal: unable to access 'https://example.com/app.git/'\n", ExitCode: 128},
+				"show-ref --verify --quiet refs/remotes/origin/feature/x": found,
+				"checkout -b feature/x --track origin/feature/x":          found,
+			},
+			wantRan: "checkout -b feature/x --track origin/feature/x",
+			want:    SwitchResult{FromBranch: "main", ToBranch: "feature/x", Success: true, FromRemote: true, Message: "switched (from remote)"},
+		},
+		{
+			name:     "current branch wins over lower priority branches",
+			branches: []string{"feature/x", "develop", "main"},
+			current:  "develop",
+			responses: map[string]gitexectest.Result{
+				"show-ref --verify --quiet refs/heads/feature/x": missing,
+				"ls-remote --heads origin refs/heads/feature/x":  found,
+				"show-ref --verify --quiet refs/heads/main":      found,
+			},
+			want: SwitchResult{FromBranch: "develop", ToBranch: "develop", Success: true, AlreadyOnIt: true, Message: "already on target"},
+		},
+		{
+			name:     "no branch exists",
+			branches: []string{"feature/x"},
+			current:  "main",
+			responses: map[string]gitexectest.Result{
+				"show-ref": missing,
+			},
+			want: SwitchResult{FromBranch: "main", Message: "no matching branch found"},
+		},
+		{
+			name:     "uncommitted changes block the checkout",
+			branches: []string{"develop"},
+			current:  "main",
+			responses: map[string]gitexectest.Result{
+				"show-ref --verify --quiet refs/heads/develop": found,
+				"checkout develop": {
+					Stderr:   "error: Your local changes to the following files would be overwritten by checkout:\n\tREADME.md\n",
+					ExitCode: 1,
+				},
+			},
+			want: SwitchResult{FromBranch: "main", Message: "uncommitted changes"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := gitexectest.New(t)
+			fake.On("rev-parse --abbrev-ref HEAD", gitexectest.Result{Stdout: tt.current + "\n"})
+			fake.On("fetch", found)
+			for args, result := range tt.responses {
+				fake.On(args, result)
+			}
+
+			got := SwitchBranchWithResult(newRepoDir(t), "origin", tt.branches)
+
+			if got.FromBranch != tt.want.FromBranch || got.ToBranch != tt.want.ToBranch ||
+				got.Success != tt.want.Success || got.AlreadyOnIt != tt.want.AlreadyOnIt ||
+				got.FromRemote != tt.want.FromRemote || got.Message != tt.want.Message {
+				t.Errorf("got %+v\nwant %+v", got, tt.want)
+			}
+			if tt.want.Success != (got.Err == nil) {
+				t.Errorf("Err = %v, want success %v", got.Err, tt.want.Success)
+			}
+			if tt.wantRan != "" && !fake.Ran(tt.wantRan) {
+				t.Errorf("%q was not run; calls: %q", tt.wantRan, fake.Calls())
+			}
+			if tt.notRan != "" && fake.Ran(tt.notRan) {
+				t.Errorf("%q was run; calls: %q", tt.notRan, fake.Calls())
+			}
+			lsRemotes := 0
+			for _, call := range fake.Calls() {
+				if strings.HasPrefix(call, "ls-remote") {
+					lsRemotes++
+				}
+			}
+			if lsRemotes > 1 {
+				t.Errorf("ls-remote ran %d times, want at most once; calls: %q", lsRemotes, fake.Calls())
+			}
+		})
+	}
+}
+
+func TestCheckBranchExists(t *testing.T) {
+	tests := []struct {
+		name    string
+		result  gitexectest.Result
+		want    bool
+		wantErr bool
+	}{
+		{name: "exists", result: gitexectest.Result{}, want: true},
+		{name: "missing", result: gitexectest.Result{ExitCode: 1}, want: false},
+		{name: "git error", result: gitexectest.Result{Stderr: "fatal: not a git repository", ExitCode: 128}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := gitexectest.New(t)
+			fake.On("show-ref --verify --quiet refs/heads/main", tt.result)
+
+			got, err := CheckBranchExists("repo", "main")
+			if got != tt.want || (err != nil) != tt.wantErr {
+				t.Errorf("CheckBranchExists() = %v, %v; want %v, error %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetDefaultBranch(t *testing.T) {
+	tests := []struct {
+		name    string
+		result  gitexectest.Result
+		want    string
+		wantErr bool
+	}{
+		{name: "main", result: gitexectest.Result{Stdout: "origin/main\n"}, want: "main"},
+		{name: "branch with a slash", result: gitexectest.Result{Stdout: "origin/release/2.x\n"}, want: "release/2.x"},
+		{name: "not recorded", result: gitexectest.Result{ExitCode: 1}, want: ""},
+		{name: "git error", result: gitexectest.Result{Stderr: "fatal: not a git repository", ExitCode: 128}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := gitexectest.New(t)
+			fake.On("symbolic-ref --quiet --short refs/remotes/origin/HEAD", tt.result)
+
+			got, err := GetDefaultBranch("repo", "origin")
+			if got != tt.want || (err != nil) != tt.wantErr {
+				t.Errorf("GetDefaultBranch() = %q, %v; want %q, error %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
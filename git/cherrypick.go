@@ -0,0 +1,102 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"git_cli_tool/gitexec"
+)
+
+// ErrCherryPickConflict is returned when a cherry-pick stopped on conflicts.
+// The repository is left in the middle of the cherry-pick for manual resolution.
+var ErrCherryPickConflict = errors.New("cherry-pick stopped on conflicts")
+
+// ResolveBranch returns the ref to use for a branch: the local branch if it
+// exists, otherwise its remote-tracking branch. ok is false if neither exists.
+func ResolveBranch(repoPath string, remote string, branch string) (ref string, ok bool, err error) {
+	exists, err := CheckBranchExists(repoPath, branch)
+	if err != nil {
+		return "", false, err
+	}
+	if exists {
+		return branch, true, nil
+	}
+
+	exists, err = CheckRemoteBranchExists(repoPath, remote, branch)
+	if err != nil {
+		return "", false, err
+	}
+	if exists {
+		return remote + "/" + branch, true, nil
+	}
+	return "", false, nil
+}
+
+// FindCommitsToPick returns the non-merge commits of source that are not in onto,
+// oldest first. Commits whose change is already in onto (e.g. picked earlier) are
+// left out. With a pattern, only commits whose message matches it are returned.
+func FindCommitsToPick(repoPath string, onto string, source string, pattern string) ([]string, error) {
+	args := []string{"-C", repoPath, "log", "--reverse", "--format=%H", "--no-merges", "--cherry-pick", "--right-only"}
+	if pattern != "" {
+		args = append(args, "--extended-regexp", "--grep="+pattern)
+	}
+	args = append(args, onto+"..."+source, "--")
+
+	cmd := gitexec.Command(args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits of %s: %v\n%s", source, err, output)
+	}
+	return strings.Fields(string(output)), nil
+}
+
+// CherryPick applies commits onto the current branch of a repository, in order.
+// With recordOrigin, "(cherry picked from commit ...)" is added to the messages.
+func CherryPick(repoPath string, commits []string, recordOrigin bool) error {
+	if err := ValidateRepository(repoPath); err != nil {
+		return err
+	}
+
+	args := []string{"-C", repoPath, "cherry-pick"}
+	if recordOrigin {
+		args = append(args, "-x")
+	}
+	args = append(args, commits...)
+
+	cmd := gitexec.Command(args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if operation, _ := GetOperationInProgress(repoPath); operation == OperationCherryPick {
+			return fmt.Errorf("%w: resolve manually, then run git cherry-pick --continue", ErrCherryPickConflict)
+		}
+		return fmt.Errorf("git cherry-pick failed: %v\n%s", err, output)
+	}
+	return nil
+}
+
+// FindUnpickedCommits returns the commits of source that are not in base and whose
+// change is not in onto yet, oldest first (as listed by git cherry)
+func FindUnpickedCommits(repoPath string, onto string, source string, base string) ([]string, error) {
+	cmd := gitexec.Command("-C", repoPath, "cherry", onto, source, base)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare %s with %s: %v\n%s", source, onto, err, output)
+	}
+
+	// Lines are "+ <sha>" for missing commits and "- <sha>" for ones already applied
+	var commits []string
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "+" {
+			commits = append(commits, fields[1])
+		}
+	}
+	return commits, nil
+}
+
+// CommitExists reports whether ref names a commit in the repository
+func CommitExists(repoPath string, ref string) bool {
+	cmd := gitexec.Command("-C", repoPath, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	return cmd.Run() == nil
+}
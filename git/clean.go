@@ -0,0 +1,36 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+
+	"git_cli_tool/gitexec"
+)
+
+// CleanPreview returns the paths `git clean` would remove with the given flags
+// (for example -d and -x), without removing anything
+func CleanPreview(repoPath string, flags []string) ([]string, error) {
+	args := append([]string{"-C", repoPath, "clean", "--dry-run"}, flags...)
+	output, err := gitexec.Command(args...).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("git clean --dry-run failed: %v\n%s", err, output)
+	}
+
+	var paths []string
+	for _, line := range strings.Split(string(output), "\n") {
+		// "Would remove build/" or, for nested repositories, "Would skip repository lib/"
+		if path := strings.TrimPrefix(line, "Would remove "); path != line {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+// Clean removes untracked files with `git clean --force` and the given flags
+func Clean(repoPath string, flags []string) error {
+	args := append([]string{"-C", repoPath, "clean", "--force", "--quiet"}, flags...)
+	if output, err := gitexec.Command(args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("git clean failed: %v\n%s", err, output)
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package git
+
+import (
+	"fmt"
+
+	"git_cli_tool/gitexec"
+)
+
+// CommitChanges records the staged changes of a repository with a message. With all,
+// changes to tracked files are staged first, like `git commit -a`. With sign,
+// the commit is signed with the repository's signing configuration.
+func CommitChanges(repoPath string, message string, all bool, sign bool) error {
+	args := []string{"-C", repoPath, "commit", "-m", message}
+	if all {
+		args = append(args, "--all")
+	}
+	if sign {
+		args = append(args, "--gpg-sign")
+	}
+	output, err := gitexec.Command(args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to commit: %v\n%s", err, output)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+
+	"git_cli_tool/gitexec"
+)
+
+// DiffMode selects what GetDiff returns
+type DiffMode int
+
+const (
+	DiffStat     DiffMode = iota // diffstat summary
+	DiffNameOnly                 // names of changed files
+	DiffPatch                    // full patch
+)
+
+// GetDiff returns the diff of the working tree of a repository against ref
+// (HEAD when empty, i.e. all uncommitted changes). The result is empty when
+// there are no differences.
+func GetDiff(repoPath string, ref string, mode DiffMode) (string, error) {
+	if err := ValidateRepository(repoPath); err != nil {
+		return "", err
+	}
+
+	if ref == "" {
+		ref = "HEAD"
+	}
+
+	args := []string{"-C", repoPath, "diff", "--no-color"}
+	switch mode {
+	case DiffStat:
+		args = append(args, "--stat")
+	case DiffNameOnly:
+		args = append(args, "--name-only")
+	}
+	// The trailing "--" makes git treat ref as a revision, never as a path
+	args = append(args, ref, "--")
+
+	cmd := gitexec.Command(args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git diff %s failed: %v\n%s", ref, err, output)
+	}
+	return strings.TrimRight(string(output), "\n"), nil
+}
+
+// DiffSummary counts the changes between two commits
+type DiffSummary struct {
+	Files      int
+	Insertions int
+	Deletions  int
+}
+
+// GetDiffSummary returns how many files, and lines in them, differ between two commits
+func GetDiffSummary(repoPath string, from string, to string) (DiffSummary, error) {
+	cmd := gitexec.Command("-C", repoPath, "diff", "--shortstat", from, to, "--")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return DiffSummary{}, fmt.Errorf("git diff %s %s failed: %v\n%s", from, to, err, output)
+	}
+	return parseShortStat(string(output)), nil
+}
+
+// GetChangedFiles returns the paths of the files that differ between two commits,
+// relative to the top of the repository
+func GetChangedFiles(repoPath string, from string, to string) ([]string, error) {
+	cmd := gitexec.Command("-C", repoPath, "diff", "--name-only", from, to, "--")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("git diff %s %s failed: %v\n%s", from, to, err, output)
+	}
+
+	var files []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimRight(line, "\r"); line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+// parseShortStat parses the output of git diff --shortstat, e.g.
+// " 3 files changed, 10 insertions(+), 2 deletions(-)"; parts without changes are left out by git
+func parseShortStat(output string) DiffSummary {
+	var summary DiffSummary
+	for _, part := range strings.Split(strings.TrimSpace(output), ",") {
+		var count int
+		var what string
+		if _, err := fmt.Sscanf(strings.TrimSpace(part), "%d %s", &count, &what); err != nil {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(what, "file"):
+			summary.Files = count
+		case strings.HasPrefix(what, "insertion"):
+			summary.Insertions = count
+		case strings.HasPrefix(what, "deletion"):
+			summary.Deletions = count
+		}
+	}
+	return summary
+}
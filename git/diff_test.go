@@ -0,0 +1,54 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+
+	"git_cli_tool/gitexec/gitexectest"
+)
+
+func TestParseShortStat(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   DiffSummary
+	}{
+		{name: "all parts", output: " 3 files changed, 10 insertions(+), 2 deletions(-)\n", want: DiffSummary{Files: 3, Insertions: 10, Deletions: 2}},
+		{name: "singular", output: " 1 file changed, 1 insertion(+)\n", want: DiffSummary{Files: 1, Insertions: 1}},
+		{name: "only deletions", output: " 2 files changed, 7 deletions(-)\n", want: DiffSummary{Files: 2, Deletions: 7}},
+		{name: "no changes", output: "", want: DiffSummary{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseShortStat(tt.output); got != tt.want {
+				t.Errorf("parseShortStat() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetChangedFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		result  gitexectest.Result
+		want    []string
+		wantErr bool
+	}{
+		{name: "changed files", result: gitexectest.Result{Stdout: "go.sum\nweb/package-lock.json\n"}, want: []string{"go.sum", "web/package-lock.json"}},
+		{name: "no changes", result: gitexectest.Result{}, want: nil},
+		{name: "unknown commit", result: gitexectest.Result{Stderr: "fatal: bad revision 'abc'", ExitCode: 128}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := gitexectest.New(t)
+			fake.On("diff --name-only abc def --", tt.result)
+
+			got, err := GetChangedFiles("repo", "abc", "def")
+			if !reflect.DeepEqual(got, tt.want) || (err != nil) != tt.wantErr {
+				t.Errorf("GetChangedFiles() = %q, %v; want %q, error %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package git
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"git_cli_tool/config"
+	"git_cli_tool/gitexec"
+)
+
+// FetchOptions are the workspace-wide settings of the fetches the tool runs
+type FetchOptions struct {
+	Prune      bool // delete remote-tracking branches that are gone on the remote
+	AllRemotes bool // fetch all remotes instead of only the repository's remote
+	Tags       bool // fetch all tags, not only those pointing into fetched history
+}
+
+// fetchOptions applies to every fetch run by Fetch
+var fetchOptions = FetchOptions{Prune: true}
+
+// SetFetchOptions sets the options of every fetch run by Fetch
+func SetFetchOptions(options FetchOptions) {
+	fetchOptions = options
+}
+
+// fetches records when Fetch last fetched each repository and remote, so a
+// command that looks at the remote several times fetches only once
+var fetches = struct {
+	sync.Mutex
+	at map[string]time.Time
+}{at: make(map[string]time.Time)}
+
+// refetch makes Fetch fetch again even if it already fetched in this run
+var refetch bool
+
+// SetRefetch makes every call of Fetch fetch, even if the repository was
+// already fetched in this run
+func SetRefetch(enabled bool) {
+	refetch = enabled
+}
+
+// ForgetFetches forgets which repositories were fetched, so the next Fetch of
+// each repository fetches again, e.g. on every refresh of a long-running command
+func ForgetFetches() {
+	fetches.Lock()
+	defer fetches.Unlock()
+	fetches.at = make(map[string]time.Time)
+}
+
+// Fetch fetches the remote of a repository with the configured fetch options.
+// A repository that was already fetched in this run is not fetched again
+// unless SetRefetch is enabled.
+func Fetch(repoPath string, remote string) error {
+	key := repoPath
+	if absPath, err := filepath.Abs(repoPath); err == nil {
+		key = absPath
+	}
+	if !fetchOptions.AllRemotes {
+		key += "\x00" + remote
+	}
+	fetches.Lock()
+	_, fetched := fetches.at[key]
+	fetches.Unlock()
+	if fetched && !refetch {
+		return nil
+	}
+
+	args := []string{"-C", repoPath, "fetch"}
+	if fetchOptions.Prune {
+		args = append(args, "--prune")
+	}
+	if fetchOptions.Tags {
+		args = append(args, "--tags")
+	}
+	if fetchOptions.AllRemotes {
+		args = append(args, "--all")
+	} else {
+		args = append(args, remote)
+	}
+
+	output, err := gitexec.Command(args...).CombinedOutput()
+	if err != nil {
+		if IsAuthFailure(string(output)) {
+			return WrapAuthFailure(err, string(output))
+		}
+		return fmt.Errorf("git fetch failed: %v\n%s", err, output)
+	}
+
+	fetches.Lock()
+	fetches.at[key] = time.Now()
+	fetches.Unlock()
+	return nil
+}
+
+// FetchRepository fetches the configured remote of a repository
+func FetchRepository(repo config.Repository) error {
+	if err := ValidateRepository(repo.Path); err != nil {
+		return err
+	}
+	return Fetch(repo.Path, repo.Remote)
+}
+
+// GetRemoteURL returns the URL of a remote of a repository
+func GetRemoteURL(repoPath string, remote string) (string, error) {
+	cmd := gitexec.Command("-C", repoPath, "remote", "get-url", remote)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("failed to get URL of remote %s: %v\n%s", remote, err, output)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,86 @@
+package git
+
+import (
+	"testing"
+
+	"git_cli_tool/gitexec/gitexectest"
+)
+
+func TestFetchOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options FetchOptions
+		want    string
+	}{
+		{
+			name:    "prune only",
+			options: FetchOptions{Prune: true},
+			want:    "fetch --prune origin",
+		},
+		{
+			name:    "no options",
+			options: FetchOptions{},
+			want:    "fetch origin",
+		},
+		{
+			name:    "all remotes with tags",
+			options: FetchOptions{Prune: true, AllRemotes: true, Tags: true},
+			want:    "fetch --prune --tags --all",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous := fetchOptions
+			SetFetchOptions(tt.options)
+			t.Cleanup(func() { SetFetchOptions(previous) })
+
+			fake := gitexectest.New(t)
+			fake.On("fetch", gitexectest.Result{})
+
+			if err := Fetch(t.TempDir(), "origin"); err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+			if calls := fake.Calls(); len(calls) != 1 || calls[0] != tt.want {
+				t.Errorf("Fetch() ran %q, want %q", calls, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchOncePerRun(t *testing.T) {
+	tests := []struct {
+		name    string
+		refetch bool
+		forget  bool // ForgetFetches is called between the fetches
+		want    int  // number of git fetch commands run
+	}{
+		{name: "second fetch is skipped", want: 1},
+		{name: "refetch fetches every time", refetch: true, want: 2},
+		{name: "forgotten fetches fetch again", forget: true, want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetRefetch(tt.refetch)
+			t.Cleanup(func() { SetRefetch(false) })
+
+			fake := gitexectest.New(t)
+			fake.On("fetch", gitexectest.Result{})
+
+			dir := t.TempDir()
+			if err := Fetch(dir, "origin"); err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+			if tt.forget {
+				ForgetFetches()
+			}
+			if err := Fetch(dir, "origin"); err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+			if got := len(fake.Calls()); got != tt.want {
+				t.Errorf("ran %d fetches, want %d; calls: %q", got, tt.want, fake.Calls())
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"git_cli_tool/gitexec"
+)
+
+// ErrFsckTimeout is returned by Fsck when the check did not finish in time
+var ErrFsckTimeout = errors.New("fsck timed out")
+
+// FsckReport holds the problems git fsck found in a repository
+type FsckReport struct {
+	Corruption   []string // objects that cannot be read, e.g. "error: ... object corrupt or missing"
+	Connectivity []string // objects that are referenced but missing, e.g. "missing blob <sha>"
+}
+
+// Healthy reports whether fsck found no problems
+func (r FsckReport) Healthy() bool {
+	return len(r.Corruption) == 0 && len(r.Connectivity) == 0
+}
+
+// fsckObjectErrors are parts of the error messages fsck prints about objects
+// that cannot be read or are invalid
+var fsckObjectErrors = []string{
+	"object",
+	"corrupt",
+	"inflate",
+	"mismatch",
+	"pack",
+	"error in ",
+	"invalid sha1 pointer",
+	"bad sha1",
+}
+
+// Fsck checks the integrity and connectivity of a repository's objects with
+// "git fsck --no-dangling", killing git if it runs longer than timeout (zero
+// means no limit). Problems are only reported when fsck fails and names broken
+// objects; warnings printed by a successful fsck are ignored. An error means
+// the check itself could not be completed, e.g. in a folder that is not a
+// git repository.
+func Fsck(repoPath string, timeout time.Duration) (FsckReport, error) {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	cmd := gitexec.CommandContext(ctx, "-C", repoPath, "fsck", "--no-dangling", "--no-progress")
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return FsckReport{}, fmt.Errorf("%w after %s", ErrFsckTimeout, timeout)
+	}
+
+	if err == nil {
+		return FsckReport{}, nil
+	}
+	report := parseFsckOutput(string(output))
+	if report.Healthy() {
+		return report, fmt.Errorf("git fsck failed: %v\n%s", err, output)
+	}
+	return report, nil
+}
+
+// parseFsckOutput sorts the problems in the output of a failed git fsck into
+// corruption and connectivity problems. Indented lines continue the previous
+// problem, e.g. the "to blob <sha>" line of a broken link. Other lines, such
+// as warnings or errors that are not about objects, are left out.
+func parseFsckOutput(output string) FsckReport {
+	var report FsckReport
+	var last *[]string
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			continue
+		case line != trimmed && last != nil && len(*last) > 0:
+			(*last)[len(*last)-1] += " " + trimmed
+			continue
+		case strings.HasPrefix(trimmed, "missing ") || strings.HasPrefix(trimmed, "broken link"):
+			last = &report.Connectivity
+		case isFsckObjectError(trimmed):
+			last = &report.Corruption
+		default:
+			last = nil
+			continue
+		}
+		*last = append(*last, trimmed)
+	}
+	return report
+}
+
+// isFsckObjectError reports whether a line of fsck output is an error about
+// an object, as opposed to e.g. "fatal: not a git repository"
+func isFsckObjectError(line string) bool {
+	if !strings.HasPrefix(line, "error") && !strings.HasPrefix(line, "fatal:") {
+		return false
+	}
+	for _, message := range fsckObjectErrors {
+		if strings.Contains(line, message) {
+			return true
+		}
+	}
+	return false
+}
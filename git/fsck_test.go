@@ -0,0 +1,79 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+
+	"git_cli_tool/gitexec/gitexectest"
+)
+
+func TestFsck(t *testing.T) {
+	tests := []struct {
+		name             string
+		result           gitexectest.Result
+		wantCorruption   []string
+		wantConnectivity []string
+		wantErr          bool
+	}{
+		{
+			name:   "healthy",
+			result: gitexectest.Result{Stderr: "notice: HEAD points to an unborn branch (main)\n"},
+		},
+		{
+			name: "missing and broken link",
+			result: gitexectest.Result{
+				Stdout:   "broken link from    tree 1111\n              to    blob 2222\nmissing blob 2222\n",
+				ExitCode: 2,
+			},
+			wantConnectivity: []string{"broken link from    tree 1111 to    blob 2222", "missing blob 2222"},
+		},
+		{
+			name: "corrupt loose object",
+			result: gitexectest.Result{
+				Stderr:   "error: inflate: data stream error (incorrect header check)\nfatal: loose object 3333 is corrupt\n",
+				ExitCode: 128,
+			},
+			wantCorruption: []string{"error: inflate: data stream error (incorrect header check)", "fatal: loose object 3333 is corrupt"},
+		},
+		{
+			name:   "warnings of a successful check",
+			result: gitexectest.Result{Stderr: "warning in tree 4444: badFilemode: contains bad file modes\n"},
+		},
+		{
+			name: "errors about objects next to warnings",
+			result: gitexectest.Result{
+				Stderr:   "warning in tree 4444: badFilemode: contains bad file modes\nerror: sha1 mismatch for .git/objects/55/55 (expected 5555)\nerror: 5555: object corrupt or missing: .git/objects/55/55\n",
+				ExitCode: 4,
+			},
+			wantCorruption: []string{"error: sha1 mismatch for .git/objects/55/55 (expected 5555)", "error: 5555: object corrupt or missing: .git/objects/55/55"},
+		},
+		{
+			name:    "not a git repository",
+			result:  gitexectest.Result{Stderr: "fatal: not a git repository (or any of the parent directories): .git\n", ExitCode: 128},
+			wantErr: true,
+		},
+		{
+			name:    "failed without a report",
+			result:  gitexectest.Result{ExitCode: 128},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := gitexectest.New(t)
+			fake.On("fsck --no-dangling --no-progress", tt.result)
+
+			report, err := Fsck("repo", 0)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Fsck() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(report.Corruption, tt.wantCorruption) {
+				t.Errorf("Corruption = %q, want %q", report.Corruption, tt.wantCorruption)
+			}
+			if !reflect.DeepEqual(report.Connectivity, tt.wantConnectivity) {
+				t.Errorf("Connectivity = %q, want %q", report.Connectivity, tt.wantConnectivity)
+			}
+		})
+	}
+}
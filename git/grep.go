@@ -0,0 +1,72 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"git_cli_tool/gitexec"
+)
+
+// GrepOptions controls how GrepRepository searches a repository
+type GrepOptions struct {
+	IgnoreCase   bool
+	WordRegexp   bool
+	FixedStrings bool
+	FilesOnly    bool     // only list the names of matching files
+	Pathspecs    []string // limit the search to these paths
+}
+
+// GrepRepository runs `git grep` for a pattern in the tracked files of a repository
+// and returns the matching lines as "file:line:text" (or file names with FilesOnly).
+// Finding no match is not an error.
+func GrepRepository(repoPath string, pattern string, opts GrepOptions) ([]string, error) {
+	if err := ValidateRepository(repoPath); err != nil {
+		return nil, err
+	}
+
+	args := []string{"-C", repoPath, "grep", "--no-color", "-I"}
+	if opts.FilesOnly {
+		args = append(args, "-l")
+	} else {
+		args = append(args, "-n")
+	}
+	if opts.IgnoreCase {
+		args = append(args, "-i")
+	}
+	if opts.WordRegexp {
+		args = append(args, "-w")
+	}
+	if opts.FixedStrings {
+		args = append(args, "-F")
+	}
+	args = append(args, "-e", pattern)
+	if len(opts.Pathspecs) > 0 {
+		args = append(args, "--")
+		args = append(args, opts.Pathspecs...)
+	}
+
+	cmd := gitexec.Command(args...)
+	output, err := cmd.Output()
+	if err != nil {
+		// git grep exits with 1 when nothing matched
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 && len(exitErr.Stderr) == 0 {
+			return nil, nil
+		}
+		stderr := ""
+		if exitErr != nil {
+			stderr = strings.TrimSpace(string(exitErr.Stderr))
+		}
+		return nil, fmt.Errorf("git grep failed: %v\n%s", err, stderr)
+	}
+
+	var matches []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if line != "" {
+			matches = append(matches, line)
+		}
+	}
+	return matches, nil
+}
@@ -0,0 +1,126 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"git_cli_tool/gitexec"
+)
+
+// States of an installed hook compared to its shared source
+const (
+	HookInstalled = "ok"
+	HookMissing   = "missing"
+	HookDiffers   = "differs"
+)
+
+// GetHooksDir returns the directory git runs the hooks of a repository from,
+// taking core.hooksPath and worktrees into account
+func GetHooksDir(repoPath string) (string, error) {
+	cmd := gitexec.Command("-C", repoPath, "rev-parse", "--git-path", "hooks")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate hooks directory: %v\n%s", err, output)
+	}
+
+	dir := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(repoPath, dir)
+	}
+	return dir, nil
+}
+
+// ListHookScripts returns the names of the hook scripts in a shared hooks
+// directory, sorted. Subdirectories, hidden files and *.sample files are ignored.
+func ListHookScripts(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hooks directory: %v", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || strings.HasSuffix(name, ".sample") {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// HookState compares the hook installed in hooksDir with its source script.
+// A symbolic link to the source counts as installed.
+func HookState(hooksDir string, source string) (string, error) {
+	target := filepath.Join(hooksDir, filepath.Base(source))
+
+	info, err := os.Lstat(target)
+	if os.IsNotExist(err) {
+		return HookMissing, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		if link, err := os.Readlink(target); err == nil && filepath.Clean(link) == filepath.Clean(source) {
+			return HookInstalled, nil
+		}
+	}
+
+	installed, err := os.ReadFile(target)
+	if err != nil {
+		return "", err
+	}
+	shared, err := os.ReadFile(source)
+	if err != nil {
+		return "", err
+	}
+	if !bytes.Equal(installed, shared) {
+		return HookDiffers, nil
+	}
+	return HookInstalled, nil
+}
+
+// InstallHook installs a hook script into hooksDir, replacing an existing hook.
+// With link, a symbolic link to the (absolute) source is created instead of a copy.
+func InstallHook(hooksDir string, source string, link bool) error {
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		return fmt.Errorf("failed to create hooks directory: %v", err)
+	}
+
+	target := filepath.Join(hooksDir, filepath.Base(source))
+	if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to replace %s: %v", target, err)
+	}
+
+	if link {
+		if err := os.Symlink(source, target); err != nil {
+			return fmt.Errorf("failed to link %s: %v", target, err)
+		}
+		return nil
+	}
+
+	in, err := os.Open(source)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", source, err)
+	}
+	defer in.Close()
+
+	// Hooks must be executable to be run by git
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %v", target, err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to write %s: %v", target, err)
+	}
+	return out.Close()
+}
@@ -0,0 +1,147 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"git_cli_tool/gitexec"
+)
+
+// Commit is a single commit as returned by GetCommits
+type Commit struct {
+	SHA     string
+	Time    time.Time
+	Author  string
+	Email   string
+	Subject string
+}
+
+// LogOptions controls which commits GetCommits returns
+type LogOptions struct {
+	Since       string // any date git understands, e.g. "2 days ago"
+	Author      string // author pattern; "me" is the repository's user.email
+	AllBranches bool   // include all local and remote branches instead of HEAD only
+	Limit       int    // maximum number of commits per repository (0 = no limit)
+}
+
+// GetCommits returns the commits of a repository matching the options, newest first
+func GetCommits(repoPath string, opts LogOptions) ([]Commit, error) {
+	if err := ValidateRepository(repoPath); err != nil {
+		return nil, err
+	}
+
+	// Fields are separated by the unit separator so subjects can contain anything
+	args := []string{"-C", repoPath, "log", "--no-color", "--format=%H%x1f%ct%x1f%an%x1f%ae%x1f%s"}
+	if opts.Since != "" {
+		args = append(args, "--since="+opts.Since)
+	}
+	if opts.Author != "" {
+		author := opts.Author
+		if author == "me" {
+			email, err := GetUserEmail(repoPath)
+			if err != nil {
+				return nil, err
+			}
+			author = email
+		}
+		args = append(args, "--author="+author)
+	}
+	if opts.Limit > 0 {
+		args = append(args, fmt.Sprintf("--max-count=%d", opts.Limit))
+	}
+	if opts.AllBranches {
+		args = append(args, "--all")
+	}
+
+	cmd := gitexec.Command(args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		// A repository without commits has nothing to show
+		if strings.Contains(string(output), "does not have any commits") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("git log failed: %v\n%s", err, output)
+	}
+
+	return parseCommits(string(output)), nil
+}
+
+// GetUserEmail returns the user.email configured for a repository
+func GetUserEmail(repoPath string) (string, error) {
+	cmd := gitexec.Command("-C", repoPath, "config", "user.email")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("user.email is not configured: %v", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// parseCommits parses the output of git log with the format used by GetCommits
+func parseCommits(output string) []Commit {
+	var commits []Commit
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(line, "\x1f")
+		if len(fields) != 5 {
+			continue
+		}
+		timestamp, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		commits = append(commits, Commit{
+			SHA:     fields[0],
+			Time:    time.Unix(timestamp, 0),
+			Author:  fields[2],
+			Email:   fields[3],
+			Subject: fields[4],
+		})
+	}
+	return commits
+}
+
+// CountCommitsSince returns the number of commits on HEAD that are not reachable from ref
+func CountCommitsSince(repoPath string, ref string) (int, error) {
+	cmd := gitexec.Command("-C", repoPath, "rev-list", "--count", ref+"..HEAD", "--")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count commits since %s: %v\n%s", ref, err, output)
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(string(output)))
+	if err != nil {
+		return 0, fmt.Errorf("unexpected rev-list output: %s", output)
+	}
+	return count, nil
+}
+
+// CountAheadBehind returns the number of commits on ref that are not reachable
+// from base, and the number of commits on base that are not reachable from ref
+func CountAheadBehind(repoPath string, base string, ref string) (int, int, error) {
+	cmd := gitexec.Command("-C", repoPath, "rev-list", "--left-right", "--count", base+"..."+ref, "--")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to compare %s with %s: %v\n%s", ref, base, err, output)
+	}
+	var behind, ahead int
+	if _, err := fmt.Sscanf(string(output), "%d %d", &behind, &ahead); err != nil {
+		return 0, 0, fmt.Errorf("unexpected rev-list output: %s", output)
+	}
+	return ahead, behind, nil
+}
+
+// GetLastCommit returns the abbreviated SHA, author and relative age (e.g. "2 days ago")
+// of the commit checked out in a repository
+func GetLastCommit(repoPath string) (string, string, string, error) {
+	cmd := gitexec.Command("-C", repoPath, "log", "-1", "--format=%h%x00%an%x00%cr")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to read last commit: %v\n%s", err, output)
+	}
+
+	fields := strings.Split(strings.TrimSpace(string(output)), "\x00")
+	if len(fields) != 3 {
+		return "", "", "", fmt.Errorf("unexpected git log output: %q", output)
+	}
+	return fields[0], fields[1], fields[2], nil
+}
@@ -0,0 +1,77 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"git_cli_tool/gitexec"
+)
+
+// RunMaintenance compacts the object store of a repository. By default it runs
+// the tasks of "git maintenance run" (gc unless maintenance.* is configured),
+// falling back to "git gc" for git versions without maintenance. With
+// aggressive, it runs "git gc --aggressive", which repacks everything from
+// scratch. Unreachable objects are kept for git's usual grace period
+// (gc.pruneExpire), since history entries still refer to dropped autostashes
+// and other unreachable commits by SHA.
+func RunMaintenance(repoPath string, aggressive bool) error {
+	if aggressive {
+		return runGC(repoPath, "--aggressive")
+	}
+
+	cmd := gitexec.Command("-C", repoPath, "maintenance", "run")
+	output, err := cmd.CombinedOutput()
+	if err != nil && strings.Contains(string(output), "is not a git command") {
+		return runGC(repoPath)
+	}
+	if err != nil {
+		return fmt.Errorf("git maintenance run failed: %v\n%s", err, output)
+	}
+	return nil
+}
+
+// runGC runs git gc with the given options
+func runGC(repoPath string, options ...string) error {
+	args := append([]string{"-C", repoPath, "gc", "--quiet"}, options...)
+	output, err := gitexec.Command(args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git gc failed: %v\n%s", err, output)
+	}
+	return nil
+}
+
+// PruneRemote deletes the remote-tracking branches of remote whose branch no
+// longer exists on the remote
+func PruneRemote(repoPath string, remote string) error {
+	cmd := gitexec.Command("-C", repoPath, "remote", "prune", remote)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return WrapAuthFailure(fmt.Errorf("git remote prune %s failed: %v\n%s", remote, err, output), string(output))
+	}
+	return nil
+}
+
+// ObjectStoreSize returns the disk space in bytes used by the objects of a
+// repository: loose objects, packs and garbage, as counted by git count-objects
+func ObjectStoreSize(repoPath string) (int64, error) {
+	cmd := gitexec.Command("-C", repoPath, "count-objects", "-v")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count objects: %v\n%s", err, output)
+	}
+
+	var kib int64
+	for _, line := range strings.Split(string(output), "\n") {
+		key, value, found := strings.Cut(line, ": ")
+		if !found || (key != "size" && key != "size-pack" && key != "size-garbage") {
+			continue
+		}
+		size, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("unexpected count-objects output %q", line)
+		}
+		kib += size
+	}
+	return kib * 1024, nil
+}
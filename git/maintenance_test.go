@@ -0,0 +1,69 @@
+package git
+
+import (
+	"testing"
+
+	"git_cli_tool/gitexec/gitexectest"
+)
+
+func TestObjectStoreSize(t *testing.T) {
+	fake := gitexectest.New(t)
+	fake.On("count-objects", gitexectest.Result{Stdout: "count: 12\nsize: 48\nin-pack: 300\npacks: 2\nsize-pack: 1000\nprune-packable: 0\ngarbage: 1\nsize-garbage: 4\n"})
+
+	got, err := ObjectStoreSize("repo")
+	if err != nil {
+		t.Fatalf("ObjectStoreSize() error = %v", err)
+	}
+	if want := int64((48 + 1000 + 4) * 1024); got != want {
+		t.Errorf("ObjectStoreSize() = %d, want %d", got, want)
+	}
+}
+
+func TestRunMaintenance(t *testing.T) {
+	tests := []struct {
+		name       string
+		aggressive bool
+		noCommand  bool // git is too old to know "git maintenance"
+		want       []string
+	}{
+		{
+			name: "maintenance run",
+			want: []string{"maintenance run"},
+		},
+		{
+			name:      "falls back to gc",
+			noCommand: true,
+			want:      []string{"maintenance run", "gc --quiet"},
+		},
+		{
+			name:       "aggressive gc",
+			aggressive: true,
+			want:       []string{"gc --quiet --aggressive"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := gitexectest.New(t)
+			if tt.noCommand {
+				fake.On("maintenance", gitexectest.Result{Stderr: "git: 'maintenance' is not a git command. See 'git --help'.\n", ExitCode: 1})
+			} else {
+				fake.On("maintenance", gitexectest.Result{})
+			}
+			fake.On("gc", gitexectest.Result{})
+
+			if err := RunMaintenance("repo", tt.aggressive); err != nil {
+				t.Fatalf("RunMaintenance() error = %v", err)
+			}
+			calls := fake.Calls()
+			if len(calls) != len(tt.want) {
+				t.Fatalf("RunMaintenance() ran %q, want %q", calls, tt.want)
+			}
+			for i := range calls {
+				if calls[i] != tt.want[i] {
+					t.Errorf("call %d = %q, want %q", i, calls[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"git_cli_tool/gitexec"
+)
+
+// ErrMergeConflict is returned when a merge stopped on conflicts. The
+// repository is left in the middle of the merge for manual resolution, unless
+// the merge was aborted with MergeOptions.AbortOnConflict.
+var ErrMergeConflict = errors.New("merge stopped on conflicts")
+
+// ErrNotFastForward is returned by MergeBranch with FFOnly when the current
+// branch has diverged from the merged ref
+var ErrNotFastForward = errors.New("not possible to fast-forward")
+
+// MergeOptions controls how MergeBranch merges a ref
+type MergeOptions struct {
+	FFOnly          bool   // refuse to merge unless the current branch can be fast-forwarded
+	NoFF            bool   // create a merge commit even if the current branch could be fast-forwarded
+	Squash          bool   // commit the changes of the ref as a single commit instead of a merge
+	StrategyOption  string // passed to git merge -X, e.g. "ours" or "theirs"
+	AbortOnConflict bool   // abort a merge that stopped on conflicts, leaving the working tree as before
+}
+
+// MergeBranch merges ref into the current branch of a repository and returns
+// the output of git merge. Nothing is merged when the branch is already up to
+// date, which the output tells ("Already up to date").
+func MergeBranch(repoPath string, ref string, opts MergeOptions) (string, error) {
+	args := []string{"-C", repoPath, "merge", ref, "--no-edit"}
+	switch {
+	case opts.FFOnly:
+		args = append(args, "--ff-only")
+	case opts.NoFF:
+		args = append(args, "--no-ff")
+	}
+	if opts.Squash {
+		args = append(args, "--squash")
+	}
+	if opts.StrategyOption != "" {
+		args = append(args, "-X", opts.StrategyOption)
+	}
+
+	cmd := gitexec.Command(args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if strings.Contains(string(output), "CONFLICT") || strings.Contains(string(output), "Automatic merge failed") {
+			if opts.AbortOnConflict {
+				return string(output), abortMerge(repoPath, opts.Squash)
+			}
+			return string(output), ErrMergeConflict
+		}
+		if strings.Contains(string(output), "Not possible to fast-forward") {
+			return string(output), ErrNotFastForward
+		}
+		return string(output), fmt.Errorf("merge failed: %s", strings.TrimSpace(string(output)))
+	}
+
+	// A squash merge only stages the changes; commit them unless there are none
+	if opts.Squash {
+		diffCmd := gitexec.Command("-C", repoPath, "diff", "--cached", "--quiet")
+		if diffCmd.Run() == nil {
+			return string(output), nil
+		}
+		commitCmd := gitexec.Command("-C", repoPath, "commit", "--no-edit")
+		if commitOutput, err := commitCmd.CombinedOutput(); err != nil {
+			return string(output), fmt.Errorf("failed to commit the squashed changes: %v\n%s", err, commitOutput)
+		}
+	}
+	return string(output), nil
+}
+
+// abortMerge aborts a merge that stopped on conflicts and returns
+// ErrMergeConflict, noting if the working tree could not be restored. A squash
+// merge leaves no merge to abort, so its changes are reset instead.
+func abortMerge(repoPath string, squash bool) error {
+	args := []string{"-C", repoPath, "merge", "--abort"}
+	if squash {
+		args = []string{"-C", repoPath, "reset", "--merge"}
+	}
+	cmd := gitexec.Command(args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w, and aborting the merge failed: %v\n%s", ErrMergeConflict, err, output)
+	}
+	return ErrMergeConflict
+}
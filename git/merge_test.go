@@ -0,0 +1,105 @@
+package git
+
+import (
+	"errors"
+	"testing"
+
+	"git_cli_tool/gitexec/gitexectest"
+)
+
+func TestMergeBranch(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       MergeOptions
+		merge      gitexectest.Result
+		staged     bool
+		wantMerge  string
+		wantCommit bool
+		wantAbort  string
+		wantErr    error
+	}{
+		{
+			name:      "merge",
+			merge:     gitexectest.Result{Stdout: "Merge made by the 'ort' strategy.\n"},
+			wantMerge: "merge origin/main --no-edit",
+		},
+		{
+			name:      "conflict",
+			merge:     gitexectest.Result{Stdout: "CONFLICT (content): Merge conflict in a.txt\nAutomatic merge failed; fix conflicts and then commit the result.\n", ExitCode: 1},
+			wantMerge: "merge origin/main --no-edit",
+			wantErr:   ErrMergeConflict,
+		},
+		{
+			name:      "conflict aborted",
+			opts:      MergeOptions{AbortOnConflict: true},
+			merge:     gitexectest.Result{Stdout: "CONFLICT (content): Merge conflict in a.txt\n", ExitCode: 1},
+			wantMerge: "merge origin/main --no-edit",
+			wantAbort: "merge --abort",
+			wantErr:   ErrMergeConflict,
+		},
+		{
+			name:      "squash conflict aborted",
+			opts:      MergeOptions{Squash: true, AbortOnConflict: true},
+			merge:     gitexectest.Result{Stdout: "CONFLICT (content): Merge conflict in a.txt\n", ExitCode: 1},
+			wantMerge: "merge origin/main --no-edit --squash",
+			wantAbort: "reset --merge",
+			wantErr:   ErrMergeConflict,
+		},
+		{
+			name:      "fast-forward only refused",
+			opts:      MergeOptions{FFOnly: true},
+			merge:     gitexectest.Result{Stderr: "fatal: Not possible to fast-forward, aborting.\n", ExitCode: 128},
+			wantMerge: "merge origin/main --no-edit --ff-only",
+			wantErr:   ErrNotFastForward,
+		},
+		{
+			name:      "strategy option",
+			opts:      MergeOptions{NoFF: true, StrategyOption: "theirs"},
+			merge:     gitexectest.Result{Stdout: "Merge made by the 'ort' strategy.\n"},
+			wantMerge: "merge origin/main --no-edit --no-ff -X theirs",
+		},
+		{
+			name:       "squash commits the staged changes",
+			opts:       MergeOptions{Squash: true},
+			merge:      gitexectest.Result{Stdout: "Squash commit -- not updating HEAD\n"},
+			staged:     true,
+			wantMerge:  "merge origin/main --no-edit --squash",
+			wantCommit: true,
+		},
+		{
+			name:      "squash without changes",
+			opts:      MergeOptions{Squash: true},
+			merge:     gitexectest.Result{Stdout: "Already up to date.\n"},
+			wantMerge: "merge origin/main --no-edit --squash",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := gitexectest.New(t)
+			fake.On("merge --abort", gitexectest.Result{})
+			fake.On("reset --merge", gitexectest.Result{})
+			fake.On("merge", tt.merge)
+			if tt.staged {
+				fake.On("diff --cached --quiet", gitexectest.Result{ExitCode: 1})
+			} else {
+				fake.On("diff --cached --quiet", gitexectest.Result{})
+			}
+			fake.On("commit --no-edit", gitexectest.Result{})
+
+			_, err := MergeBranch("repo", "origin/main", tt.opts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("MergeBranch() error = %v, want %v", err, tt.wantErr)
+			}
+			if !fake.Ran(tt.wantMerge) {
+				t.Errorf("%q was not run; calls: %q", tt.wantMerge, fake.Calls())
+			}
+			if tt.wantAbort != "" && !fake.Ran(tt.wantAbort) {
+				t.Errorf("%q was not run; calls: %q", tt.wantAbort, fake.Calls())
+			}
+			if committed := fake.Ran("commit --no-edit"); committed != tt.wantCommit {
+				t.Errorf("committed = %v, want %v; calls: %q", committed, tt.wantCommit, fake.Calls())
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package git
+
+import (
+	"fmt"
+
+	"git_cli_tool/gitexec"
+)
+
+// CloneMirror creates a bare mirror clone of source (a repository path or URL) at dest
+func CloneMirror(source string, dest string) error {
+	cmd := gitexec.Command("clone", "--mirror", "--quiet", source, dest)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git clone --mirror failed: %v\n%s", err, output)
+	}
+	return nil
+}
+
+// UpdateMirror updates all refs of a mirror clone from its origin, removing
+// refs that no longer exist there
+func UpdateMirror(mirrorPath string) error {
+	cmd := gitexec.Command("-C", mirrorPath, "remote", "update", "--prune")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git remote update failed: %v\n%s", err, output)
+	}
+	return nil
+}
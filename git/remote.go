@@ -0,0 +1,25 @@
+package git
+
+import (
+	"fmt"
+
+	"git_cli_tool/gitexec"
+)
+
+// SetRemoteURL changes the URL of an existing remote of a repository
+func SetRemoteURL(repoPath string, remote string, url string) error {
+	cmd := gitexec.Command("-C", repoPath, "remote", "set-url", remote, url)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to set URL of remote %s: %v\n%s", remote, err, output)
+	}
+	return nil
+}
+
+// AddRemote adds a remote to a repository
+func AddRemote(repoPath string, remote string, url string) error {
+	cmd := gitexec.Command("-C", repoPath, "remote", "add", remote, url)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to add remote %s: %v\n%s", remote, err, output)
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"git_cli_tool/gitexec"
+)
+
+// GetHeadCommit returns the full SHA of HEAD
+func GetHeadCommit(repoPath string) (string, error) {
+	cmd := gitexec.Command("-C", repoPath, "rev-parse", "HEAD")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD: %v\n%s", err, output)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// ResolveCommit returns the full SHA of the commit a ref (branch, tag or SHA) points to
+func ResolveCommit(repoPath string, ref string) (string, error) {
+	cmd := gitexec.Command("-C", repoPath, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%s is not a commit", ref)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// DiffHead returns a binary patch of all uncommitted changes to tracked files,
+// staged or not. Untracked files are not included.
+func DiffHead(repoPath string) (string, error) {
+	cmd := gitexec.Command("-C", repoPath, "diff", "--binary", "HEAD")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to diff uncommitted changes: %v", err)
+	}
+	return string(output), nil
+}
+
+// DiffWorkingTree returns a binary patch of all uncommitted changes, including
+// untracked files that are not ignored. The changes are staged in a temporary
+// copy of the index, so the repository's own index is left untouched.
+func DiffWorkingTree(repoPath string) (string, error) {
+	output, err := gitexec.Command("-C", repoPath, "rev-parse", "--git-path", "index").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the index: %v", err)
+	}
+	indexPath := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(indexPath) {
+		indexPath = filepath.Join(repoPath, indexPath)
+	}
+
+	tmpIndex, err := os.CreateTemp("", "git_cli_tool-index-")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmpIndex.Name())
+	// Starting from a copy keeps git's stat cache, so unchanged files are not rehashed
+	if index, err := os.Open(indexPath); err == nil {
+		_, err = io.Copy(tmpIndex, index)
+		index.Close()
+		if err != nil {
+			tmpIndex.Close()
+			return "", fmt.Errorf("failed to copy the index: %v", err)
+		}
+	}
+	tmpIndex.Close()
+	env := append(os.Environ(), "GIT_INDEX_FILE="+tmpIndex.Name())
+
+	add := gitexec.Command("-C", repoPath, "add", "--all")
+	add.Env = env
+	if output, err := add.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to stage changes in a temporary index: %v\n%s", err, output)
+	}
+
+	diff := gitexec.Command("-C", repoPath, "diff", "--binary", "--cached", "HEAD")
+	diff.Env = env
+	patch, err := diff.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to diff uncommitted changes: %v", err)
+	}
+	return string(patch), nil
+}
+
+// DiffUntracked returns a binary patch creating the untracked files of a
+// repository that are not ignored, e.g. to restore them after git clean. The
+// files are staged in a temporary index, so the repository's own index is left
+// untouched.
+func DiffUntracked(repoPath string) (string, error) {
+	files, err := gitexec.Command("-C", repoPath, "ls-files", "--others", "--exclude-standard", "-z").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to list untracked files: %v", err)
+	}
+	if len(files) == 0 {
+		return "", nil
+	}
+
+	// git creates the temporary index itself; an empty file is not a valid index
+	tmpIndex, err := os.CreateTemp("", "git_cli_tool-index-")
+	if err != nil {
+		return "", err
+	}
+	tmpIndex.Close()
+	os.Remove(tmpIndex.Name())
+	defer os.Remove(tmpIndex.Name())
+	env := append(os.Environ(), "GIT_INDEX_FILE="+tmpIndex.Name(), "GIT_LITERAL_PATHSPECS=1")
+
+	// Starting from HEAD, the untracked files are the only difference
+	readTree := gitexec.Command("-C", repoPath, "read-tree", "HEAD")
+	readTree.Env = env
+	if output, err := readTree.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to prepare a temporary index: %v\n%s", err, output)
+	}
+
+	add := gitexec.Command("-C", repoPath, "add", "--pathspec-from-file=-", "--pathspec-file-nul")
+	add.Env = env
+	add.Stdin = bytes.NewReader(files)
+	if output, err := add.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to stage untracked files in a temporary index: %v\n%s", err, output)
+	}
+
+	diff := gitexec.Command("-C", repoPath, "diff", "--binary", "--cached", "HEAD")
+	diff.Env = env
+	patch, err := diff.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to diff untracked files: %v", err)
+	}
+	return string(patch), nil
+}
+
+// ResetHard resets the current branch, index and working tree to ref
+func ResetHard(repoPath string, ref string) error {
+	cmd := gitexec.Command("-C", repoPath, "reset", "--hard", "--quiet", ref)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git reset --hard %s failed: %v\n%s", ref, err, output)
+	}
+	return nil
+}
+
+// FastForward moves the current branch forward to commit, failing if that is
+// not possible without a merge
+func FastForward(repoPath string, commit string) error {
+	cmd := gitexec.Command("-C", repoPath, "merge", "--ff-only", "--quiet", commit)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git merge --ff-only failed: %v\n%s", err, output)
+	}
+	return nil
+}
+
+// MoveBranch points a local branch that is not checked out at commit. It fails
+// if the branch no longer points at old, e.g. because it changed meanwhile.
+func MoveBranch(repoPath string, branch string, commit string, old string) error {
+	cmd := gitexec.Command("-C", repoPath, "update-ref", "-m", "git_cli_tool revert", "refs/heads/"+branch, commit, old)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to move branch %s: %v\n%s", branch, err, output)
+	}
+	return nil
+}
+
+// IsAncestor reports whether ancestor is reachable from commit, i.e. commit
+// contains it. A commit is its own ancestor.
+func IsAncestor(repoPath string, ancestor string, commit string) (bool, error) {
+	cmd := gitexec.Command("-C", repoPath, "merge-base", "--is-ancestor", ancestor, commit)
+	output, err := cmd.CombinedOutput()
+	switch {
+	case err == nil:
+		return true, nil
+	case gitexec.ExitCode(err) == 1:
+		return false, nil
+	default:
+		return false, fmt.Errorf("git merge-base --is-ancestor failed: %v\n%s", err, output)
+	}
+}
+
+// ApplyPatch applies a patch file to the working tree. The path must be absolute.
+func ApplyPatch(repoPath string, patchFile string) error {
+	cmd := gitexec.Command("-C", repoPath, "apply", "--whitespace=nowarn", patchFile)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git apply failed: %v\n%s", err, output)
+	}
+	return nil
+}
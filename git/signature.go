@@ -0,0 +1,66 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+
+	"git_cli_tool/gitexec"
+)
+
+// CommitSignature is the signature check of a single commit
+type CommitSignature struct {
+	SHA    string
+	Status string // %G? of git log: G, U, X, Y, R, E, B or N
+	Signer string // signer of the signature, empty for unsigned commits
+}
+
+// Valid reports whether the commit has a good signature. Signatures with
+// unknown trust (U) count as valid, since trust is a property of the keyring.
+func (s CommitSignature) Valid() bool {
+	return s.Status == "G" || s.Status == "U"
+}
+
+// Describe explains the signature status in words
+func (s CommitSignature) Describe() string {
+	switch s.Status {
+	case "G", "U":
+		return "signed by " + s.Signer
+	case "X":
+		return "expired signature"
+	case "Y":
+		return "signed with an expired key"
+	case "R":
+		return "signed with a revoked key"
+	case "E":
+		return "signature cannot be checked (missing key?)"
+	case "B":
+		return "bad signature"
+	}
+	return "unsigned"
+}
+
+// GetSignatures checks the GPG or SSH signatures of commits, using the signing
+// configuration of the repository (gpg.format, gpg.ssh.allowedSignersFile). A
+// range such as origin/main..HEAD checks every commit in it, any other ref
+// only the commit it points to.
+func GetSignatures(repoPath string, revision string) ([]CommitSignature, error) {
+	args := []string{"-C", repoPath, "log", "--format=%H%x00%G?%x00%GS"}
+	if !strings.Contains(revision, "..") {
+		args = append(args, "-1")
+	}
+	args = append(args, revision, "--")
+	output, err := gitexec.Command(args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check signatures of %s: %v", revision, err)
+	}
+
+	var signatures []CommitSignature
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.SplitN(line, "\x00", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		signatures = append(signatures, CommitSignature{SHA: fields[0], Status: fields[1], Signer: fields[2]})
+	}
+	return signatures, nil
+}
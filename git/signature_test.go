@@ -0,0 +1,63 @@
+package git
+
+import (
+	"testing"
+
+	"git_cli_tool/gitexec/gitexectest"
+)
+
+func TestGetSignatures(t *testing.T) {
+	const good = "1111111111111111111111111111111111111111"
+	const unsigned = "2222222222222222222222222222222222222222"
+
+	tests := []struct {
+		name      string
+		revision  string
+		stdout    string
+		wantCall  string
+		wantValid []bool
+	}{
+		{
+			name:      "single commit",
+			revision:  "HEAD",
+			stdout:    good + "\x00G\x00dev@example.com\n",
+			wantCall:  "log --format=%H%x00%G?%x00%GS -1 HEAD --",
+			wantValid: []bool{true},
+		},
+		{
+			name:      "range with an unsigned commit",
+			revision:  "origin/main..HEAD",
+			stdout:    good + "\x00U\x00dev@example.com\n" + unsigned + "\x00N\x00\n",
+			wantCall:  "log --format=%H%x00%G?%x00%GS origin/main..HEAD --",
+			wantValid: []bool{true, false},
+		},
+		{
+			name:     "empty range",
+			revision: "HEAD..HEAD",
+			wantCall: "log --format=%H%x00%G?%x00%GS HEAD..HEAD --",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := gitexectest.New(t)
+			fake.On("log", gitexectest.Result{Stdout: tt.stdout})
+
+			got, err := GetSignatures("repo", tt.revision)
+			if err != nil {
+				t.Fatalf("GetSignatures() error = %v", err)
+			}
+			if len(got) != len(tt.wantValid) {
+				t.Fatalf("GetSignatures() returned %d commits, want %d", len(got), len(tt.wantValid))
+			}
+			for i, signature := range got {
+				if signature.Valid() != tt.wantValid[i] {
+					t.Errorf("commit %d Valid() = %v, want %v", i, signature.Valid(), tt.wantValid[i])
+				}
+			}
+			if calls := fake.Calls(); len(calls) != 1 || calls[0] != tt.wantCall {
+				t.Errorf("calls = %q, want %q", calls, tt.wantCall)
+			}
+		})
+	}
+}
@@ -3,57 +3,102 @@ package git
 
 import (
 	"fmt"
-	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
-	"git_cli_tool/log"
+	"git_cli_tool/gitexec"
 )
 
+// StashOptions limits what StashChanges stashes. The zero value stashes all
+// changes, including untracked files.
+type StashOptions struct {
+	TrackedOnly bool     // leave untracked files in the working tree
+	KeepIndex   bool     // also keep staged changes in the index and working tree
+	Pathspec    []string // only stash changes to these paths
+}
+
 // StashChanges stashes changes in a repository with the given name.
-// Returns (true, nil) if changes were stashed, (false, nil) if no changes to stash,
-// or (false, error) if an error occurred.
-func StashChanges(repoPath string, stashName string) (bool, error) {
+// Returns the commit SHA of the new stash, which identifies it even after other
+// stashes are pushed, or an empty string if there were no changes to stash.
+func StashChanges(repoPath string, stashName string, opts StashOptions) (string, error) {
 	absPath, err := filepath.Abs(repoPath)
 	if err != nil {
-		return false, fmt.Errorf("failed to resolve absolute path: %v", err)
+		return "", fmt.Errorf("failed to resolve absolute path: %v", err)
 	}
 
 	// Check if repository exists
-	if _, err := os.Stat(filepath.Join(absPath, ".git")); os.IsNotExist(err) {
-		return false, fmt.Errorf("not a git repository or directory does not exist")
+	if err := ValidateRepository(absPath); err != nil {
+		return "", err
 	}
 
-	// Check if there are changes to stash
-	statusCmd := exec.Command("git", "-C", absPath, "status", "--porcelain")
+	// Check if there are changes to stash, looking only at what would be stashed
+	statusArgs := []string{"-C", absPath, "status", "--porcelain"}
+	if opts.TrackedOnly {
+		statusArgs = append(statusArgs, "--untracked-files=no")
+	}
+	if len(opts.Pathspec) > 0 {
+		statusArgs = append(append(statusArgs, "--"), opts.Pathspec...)
+	}
+	statusCmd := gitexec.Command(statusArgs...)
 	statusOutput, err := statusCmd.CombinedOutput()
 	if err != nil {
-		return false, fmt.Errorf("failed to get git status: %v", err)
+		return "", fmt.Errorf("failed to get git status: %v", err)
 	}
 
 	// If there are no changes, skip stashing
 	if len(strings.TrimSpace(string(statusOutput))) == 0 {
-		log.PrintInfo(fmt.Sprintf("No changes to stash in %s", repoPath))
-		return false, nil
+		return "", nil
 	}
 
 	// Create a detailed message with the stash name
 	message := fmt.Sprintf("GitSwitch: %s", stashName)
 
-	// Stash changes with the provided name, include untracked files
-	// Use --include-untracked to ensure all files are included, even new ones
-	stashCmd := exec.Command("git", "-C", absPath, "stash", "push", "--include-untracked", "-m", message)
+	// Stash changes with the provided name, by default including untracked
+	// files to ensure all files are included, even new ones
+	stashArgs := []string{"-C", absPath, "stash", "push"}
+	if !opts.TrackedOnly {
+		stashArgs = append(stashArgs, "--include-untracked")
+	}
+	if opts.KeepIndex {
+		stashArgs = append(stashArgs, "--keep-index")
+	}
+	stashArgs = append(stashArgs, "-m", message)
+	if len(opts.Pathspec) > 0 {
+		stashArgs = append(append(stashArgs, "--"), opts.Pathspec...)
+	}
+	// git stash push succeeds without creating a stash when, e.g. with a pathspec
+	// or --keep-index, nothing is left to stash, so stash@{0} is compared before
+	// and after to tell a new stash from one that was already there
+	before, err := latestStash(absPath)
+	if err != nil {
+		return "", err
+	}
+	stashCmd := gitexec.Command(stashArgs...)
 	stashOutput, err := stashCmd.CombinedOutput()
 	if err != nil {
-		return false, fmt.Errorf("failed to stash changes: %v\n%s", err, stashOutput)
+		return "", fmt.Errorf("failed to stash changes: %v\n%s", err, stashOutput)
 	}
 
-	log.PrintSuccess(fmt.Sprintf("Successfully stashed changes in %s with message '%s'", repoPath, message))
-	log.PrintInfo(fmt.Sprintf("To view stashed changes: git -C \"%s\" stash list", absPath))
-	log.PrintInfo(fmt.Sprintf("To apply the stash: git -C \"%s\" stash apply", absPath))
+	// The new stash is stash@{0}; its SHA stays valid when it is renumbered
+	after, err := latestStash(absPath)
+	if err != nil || after == before {
+		return "", err
+	}
+	return after, nil
+}
 
-	return true, nil
+// latestStash returns the commit SHA of stash@{0}, or an empty string if the
+// stash list is empty
+func latestStash(repoPath string) (string, error) {
+	shaCmd := gitexec.Command("-C", repoPath, "rev-parse", "--verify", "--quiet", "stash@{0}")
+	shaOutput, err := shaCmd.Output()
+	if gitexec.ExitCode(err) == 1 {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve the latest stash: %v", err)
+	}
+	return strings.TrimSpace(string(shaOutput)), nil
 }
 
 // ApplyStash applies a specific stash in a repository
@@ -64,43 +109,196 @@ func ApplyStash(repoPath string, stashName string) error {
 	}
 
 	// Check if repository exists
-	if _, err := os.Stat(filepath.Join(absPath, ".git")); os.IsNotExist(err) {
-		return fmt.Errorf("not a git repository or directory does not exist")
+	if err := ValidateRepository(absPath); err != nil {
+		return err
 	}
 
-	// Find stash with matching name
-	listCmd := exec.Command("git", "-C", absPath, "stash", "list")
+	stashIndex, err := FindStash(absPath, stashName)
+	if err != nil {
+		return err
+	}
+	if stashIndex == "" {
+		return fmt.Errorf("no stash found with name '%s'", stashName)
+	}
+
+	// Apply the stash
+	applyCmd := gitexec.Command("-C", absPath, "stash", "apply", stashIndex)
+	applyOutput, err := applyCmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to apply stash %s: %v\n%s", stashIndex, err, applyOutput)
+	}
+
+	return nil
+}
+
+// ApplyStashCommit applies the stash with the given commit SHA, as recorded by StashChanges
+func ApplyStashCommit(repoPath string, sha string) error {
+	applyCmd := gitexec.Command("-C", repoPath, "stash", "apply", sha)
+	if applyOutput, err := applyCmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to apply stash %s: %v\n%s", sha, err, applyOutput)
+	}
+	return nil
+}
+
+// Stash is an entry of the stash list of a repository
+type Stash struct {
+	Ref     string // e.g. stash@{0}
+	Commit  string
+	Subject string // e.g. "On main: GitSwitch: feature/x"
+}
+
+// ListStashes returns the stashes of a repository, newest first
+func ListStashes(repoPath string) ([]Stash, error) {
+	listCmd := gitexec.Command("-C", repoPath, "stash", "list", "--format=%gd%x1f%H%x1f%gs")
 	listOutput, err := listCmd.CombinedOutput()
 	if err != nil {
-		return fmt.Errorf("failed to list stashes: %v", err)
+		return nil, fmt.Errorf("failed to list stashes: %v", err)
+	}
+
+	var stashes []Stash
+	for _, line := range strings.Split(string(listOutput), "\n") {
+		parts := strings.SplitN(line, "\x1f", 3)
+		if len(parts) == 3 {
+			stashes = append(stashes, Stash{Ref: parts[0], Commit: parts[1], Subject: parts[2]})
+		}
 	}
+	return stashes, nil
+}
 
-	stashLines := strings.Split(string(listOutput), "\n")
-	stashIndex := ""
+// FindStashCommit returns the stash with the given commit SHA (e.g. stash@{2}),
+// or an empty string if it is no longer in the stash list
+func FindStashCommit(repoPath string, sha string) (string, error) {
+	listCmd := gitexec.Command("-C", repoPath, "stash", "list", "--format=%gd %H")
+	listOutput, err := listCmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("failed to list stashes: %v", err)
+	}
+
+	for _, line := range strings.Split(string(listOutput), "\n") {
+		parts := strings.Fields(line)
+		if len(parts) == 2 && parts[1] == sha {
+			return parts[0], nil
+		}
+	}
+
+	return "", nil
+}
+
+// FindStash returns the stash whose message contains stashName (e.g. stash@{0}),
+// or an empty string if there is none
+func FindStash(repoPath string, stashName string) (string, error) {
+	listCmd := gitexec.Command("-C", repoPath, "stash", "list")
+	listOutput, err := listCmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("failed to list stashes: %v", err)
+	}
 
 	// Format of stash line: stash@{0}: On branch: message
-	for _, line := range stashLines {
+	for _, line := range strings.Split(string(listOutput), "\n") {
 		if strings.Contains(line, stashName) {
 			// Extract the stash index (e.g., stash@{0})
 			parts := strings.SplitN(line, ":", 2)
-			if len(parts) > 0 {
-				stashIndex = strings.TrimSpace(parts[0])
-				break
-			}
+			return strings.TrimSpace(parts[0]), nil
 		}
 	}
 
-	if stashIndex == "" {
-		return fmt.Errorf("no stash found with name '%s'", stashName)
+	return "", nil
+}
+
+// DropStashCommit drops the stash with the given commit SHA from the stash
+// list. Does nothing if it is no longer in the list.
+func DropStashCommit(repoPath string, sha string) error {
+	stashIndex, err := FindStashCommit(repoPath, sha)
+	if err != nil || stashIndex == "" {
+		return err
 	}
 
-	// Apply the stash
-	applyCmd := exec.Command("git", "-C", absPath, "stash", "apply", stashIndex)
-	applyOutput, err := applyCmd.CombinedOutput()
+	dropCmd := gitexec.Command("-C", repoPath, "stash", "drop", stashIndex)
+	if dropOutput, err := dropCmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to drop stash %s: %v\n%s", stashIndex, err, dropOutput)
+	}
+	return nil
+}
+
+// CountStashes returns the total number of stashes in a repository and how
+// many of them were created by GitSwitch
+func CountStashes(repoPath string) (int, int, error) {
+	listCmd := gitexec.Command("-C", repoPath, "stash", "list", "--format=%gs")
+	listOutput, err := listCmd.CombinedOutput()
 	if err != nil {
-		return fmt.Errorf("failed to apply stash %s: %v\n%s", stashIndex, err, applyOutput)
+		return 0, 0, fmt.Errorf("failed to list stashes: %v", err)
+	}
+
+	total := 0
+	gitSwitch := 0
+	for _, line := range strings.Split(strings.TrimSpace(string(listOutput)), "\n") {
+		if line == "" {
+			continue
+		}
+		total++
+		if strings.Contains(line, ": GitSwitch: ") {
+			gitSwitch++
+		}
 	}
 
-	log.PrintSuccess(fmt.Sprintf("Successfully applied stash %s in %s", stashIndex, repoPath))
+	return total, gitSwitch, nil
+}
+
+// DropBranchStashes drops every GitSwitch stash that was created on the given
+// branch or named after it, and returns how many were dropped
+func DropBranchStashes(repoPath string, branch string) (int, error) {
+	listCmd := gitexec.Command("-C", repoPath, "stash", "list", "--format=%gd %gs")
+	listOutput, err := listCmd.CombinedOutput()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list stashes: %v", err)
+	}
+
+	var matching []string
+	for _, line := range strings.Split(string(listOutput), "\n") {
+		parts := strings.SplitN(strings.TrimSpace(line), " ", 2)
+		if len(parts) == 2 && isBranchStash(parts[1], branch) {
+			matching = append(matching, parts[0])
+		}
+	}
+
+	// Drop the oldest first, so the indexes of the remaining ones stay valid
+	for i := len(matching) - 1; i >= 0; i-- {
+		dropCmd := gitexec.Command("-C", repoPath, "stash", "drop", matching[i])
+		if dropOutput, err := dropCmd.CombinedOutput(); err != nil {
+			return len(matching) - 1 - i, fmt.Errorf("failed to drop stash %s: %v\n%s", matching[i], err, dropOutput)
+		}
+	}
+	return len(matching), nil
+}
+
+// BranchStashes returns the GitSwitch stashes that DropBranchStashes would drop
+// for the given branch, newest first
+func BranchStashes(repoPath string, branch string) ([]Stash, error) {
+	stashes, err := ListStashes(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	var matching []Stash
+	for _, stash := range stashes {
+		if isBranchStash(stash.Subject, branch) {
+			matching = append(matching, stash)
+		}
+	}
+	return matching, nil
+}
+
+// isBranchStash reports whether a stash subject belongs to a GitSwitch stash
+// that was created on the given branch or named after it
+func isBranchStash(subject string, branch string) bool {
+	return strings.HasPrefix(subject, fmt.Sprintf("On %s: GitSwitch: ", branch)) || strings.HasSuffix(subject, ": GitSwitch: "+branch)
+}
+
+// StoreStash adds a stash commit back to the top of the stash list, e.g. one
+// that was dropped, with the subject it had there
+func StoreStash(repoPath string, commit string, subject string) error {
+	storeCmd := gitexec.Command("-C", repoPath, "stash", "store", "-m", subject, commit)
+	if storeOutput, err := storeCmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to store stash %s: %v\n%s", commit, err, storeOutput)
+	}
 	return nil
 }
@@ -0,0 +1,217 @@
+package git
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"git_cli_tool/gitexec/gitexectest"
+)
+
+const stashList = `stash@{0} On feature/x: GitSwitch: main
+stash@{1} On main: WIP before lunch
+stash@{2} On main: GitSwitch: feature/x
+stash@{3} On feature/x-2: GitSwitch: feature/x
+stash@{4} On main: GitSwitch: release
+`
+
+func TestStashChangesWithoutNewStash(t *testing.T) {
+	tests := []struct {
+		name   string
+		latest gitexectest.Result // rev-parse stash@{0}, before and after the push
+	}{
+		{name: "an older stash is not taken for the new one", latest: gitexectest.Result{Stdout: "1111111111111111111111111111111111111111\n"}},
+		{name: "empty stash list", latest: gitexectest.Result{ExitCode: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := gitexectest.New(t)
+			fake.On("status --porcelain", gitexectest.Result{Stdout: " M api/handler.go\n"})
+			fake.On("rev-parse --verify --quiet stash@{0}", tt.latest)
+			fake.On("stash push", gitexectest.Result{Stdout: "No local changes to save\n"})
+
+			got, err := StashChanges(newRepoDir(t), "wip", StashOptions{Pathspec: []string{"web/"}})
+			if err != nil {
+				t.Fatalf("StashChanges() error = %v", err)
+			}
+			if got != "" {
+				t.Errorf("StashChanges() = %q, want no stash", got)
+			}
+			if !fake.Ran("stash push") {
+				t.Errorf("StashChanges() did not run git stash push")
+			}
+		})
+	}
+}
+
+func TestApplyStash(t *testing.T) {
+	list := `stash@{0}: On main: GitSwitch: feature/y
+stash@{1}: On main: GitSwitch: feature/x
+`
+	tests := []struct {
+		name      string
+		stashName string
+		wantApply string
+		wantErr   bool
+	}{
+		{name: "applies the first matching stash", stashName: "feature/x", wantApply: "stash apply stash@{1}"},
+		{name: "unknown stash name", stashName: "hotfix", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := gitexectest.New(t)
+			fake.On("stash list", gitexectest.Result{Stdout: list})
+			fake.On("stash apply", gitexectest.Result{})
+
+			err := ApplyStash(newRepoDir(t), tt.stashName)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ApplyStash() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantApply != "" && !fake.Ran(tt.wantApply) {
+				t.Errorf("%q was not run; calls: %q", tt.wantApply, fake.Calls())
+			}
+		})
+	}
+}
+
+func TestCountStashes(t *testing.T) {
+	tests := []struct {
+		name          string
+		output        string
+		wantTotal     int
+		wantGitSwitch int
+	}{
+		{name: "no stashes", output: "", wantTotal: 0, wantGitSwitch: 0},
+		{name: "mixed stashes", output: "On main: GitSwitch: x\nOn main: WIP\nOn dev: GitSwitch: y\n", wantTotal: 3, wantGitSwitch: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := gitexectest.New(t)
+			fake.On("stash list --format=%gs", gitexectest.Result{Stdout: tt.output})
+
+			total, gitSwitch, err := CountStashes("repo")
+			if err != nil {
+				t.Fatalf("CountStashes() error = %v", err)
+			}
+			if total != tt.wantTotal || gitSwitch != tt.wantGitSwitch {
+				t.Errorf("CountStashes() = %d, %d; want %d, %d", total, gitSwitch, tt.wantTotal, tt.wantGitSwitch)
+			}
+		})
+	}
+}
+
+func TestFindStashCommit(t *testing.T) {
+	list := `stash@{0} 1111111111111111111111111111111111111111
+stash@{1} 2222222222222222222222222222222222222222
+`
+	tests := []struct {
+		name string
+		sha  string
+		want string
+	}{
+		{name: "renumbered stash", sha: "2222222222222222222222222222222222222222", want: "stash@{1}"},
+		{name: "dropped stash", sha: "3333333333333333333333333333333333333333", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := gitexectest.New(t)
+			fake.On("stash list --format=%gd %H", gitexectest.Result{Stdout: list})
+
+			got, err := FindStashCommit("repo", tt.sha)
+			if err != nil {
+				t.Fatalf("FindStashCommit() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("FindStashCommit(%q) = %q, want %q", tt.sha, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListStashes(t *testing.T) {
+	fake := gitexectest.New(t)
+	fake.On("stash list --format=%gd%x1f%H%x1f%gs", gitexectest.Result{
+		Stdout: "stash@{0}\x1f1111111111111111111111111111111111111111\x1fOn main: GitSwitch: feature/x\n" +
+			"stash@{1}\x1f2222222222222222222222222222222222222222\x1fWIP on main: 77d344a add api\n",
+	})
+
+	got, err := ListStashes("repo")
+	if err != nil {
+		t.Fatalf("ListStashes() error = %v", err)
+	}
+	want := []Stash{
+		{Ref: "stash@{0}", Commit: "1111111111111111111111111111111111111111", Subject: "On main: GitSwitch: feature/x"},
+		{Ref: "stash@{1}", Commit: "2222222222222222222222222222222222222222", Subject: "WIP on main: 77d344a add api"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ListStashes() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDropBranchStashes(t *testing.T) {
+	tests := []struct {
+		name      string
+		branch    string
+		wantDrops []string
+	}{
+		{
+			name:      "created on and named after the branch, oldest first",
+			branch:    "feature/x",
+			wantDrops: []string{"stash drop stash@{3}", "stash drop stash@{2}", "stash drop stash@{0}"},
+		},
+		{name: "only the stash named after the branch", branch: "release", wantDrops: []string{"stash drop stash@{4}"}},
+		{name: "no stashes", branch: "develop"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := gitexectest.New(t)
+			fake.On("stash list --format=%gd %gs", gitexectest.Result{Stdout: stashList})
+			fake.On("stash drop", gitexectest.Result{})
+
+			dropped, err := DropBranchStashes("repo", tt.branch)
+			if err != nil {
+				t.Fatalf("DropBranchStashes() error = %v", err)
+			}
+			if dropped != len(tt.wantDrops) {
+				t.Errorf("DropBranchStashes() = %d, want %d", dropped, len(tt.wantDrops))
+			}
+
+			var drops []string
+			for _, call := range fake.Calls() {
+				if strings.HasPrefix(call, "stash drop") {
+					drops = append(drops, call)
+				}
+			}
+			if !reflect.DeepEqual(drops, tt.wantDrops) {
+				t.Errorf("drops = %q, want %q", drops, tt.wantDrops)
+			}
+		})
+	}
+}
+
+func TestBranchStashes(t *testing.T) {
+	fake := gitexectest.New(t)
+	fake.On("stash list --format=%gd%x1f%H%x1f%gs", gitexectest.Result{
+		Stdout: "stash@{0}\x1f1111111111111111111111111111111111111111\x1fOn feature/x: GitSwitch: main\n" +
+			"stash@{1}\x1f2222222222222222222222222222222222222222\x1fOn main: WIP before lunch\n" +
+			"stash@{2}\x1f3333333333333333333333333333333333333333\x1fOn main: GitSwitch: feature/x\n" +
+			"stash@{3}\x1f4444444444444444444444444444444444444444\x1fOn feature/x-2: GitSwitch: main\n",
+	})
+
+	got, err := BranchStashes("repo", "feature/x")
+	if err != nil {
+		t.Fatalf("BranchStashes() error = %v", err)
+	}
+	want := []Stash{
+		{Ref: "stash@{0}", Commit: "1111111111111111111111111111111111111111", Subject: "On feature/x: GitSwitch: main"},
+		{Ref: "stash@{2}", Commit: "3333333333333333333333333333333333333333", Subject: "On main: GitSwitch: feature/x"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("BranchStashes() = %+v, want %+v", got, want)
+	}
+}
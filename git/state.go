@@ -0,0 +1,100 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"git_cli_tool/gitexec"
+)
+
+// Operations that can be left in progress in a repository
+const (
+	OperationMerge      = "merge"
+	OperationRebase     = "rebase"
+	OperationCherryPick = "cherry-pick"
+	OperationRevert     = "revert"
+	OperationAm         = "am"
+)
+
+// GetGitDir returns the absolute path of the repository's git directory
+func GetGitDir(repoPath string) (string, error) {
+	cmd := gitexec.Command("-C", repoPath, "rev-parse", "--absolute-git-dir")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate git directory: %v\n%s", err, output)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// GetOperationInProgress returns the merge, rebase, cherry-pick, revert or am
+// operation a repository is in the middle of, or an empty string if there is none
+func GetOperationInProgress(repoPath string) (string, error) {
+	gitDir, err := GetGitDir(repoPath)
+	if err != nil {
+		return "", err
+	}
+
+	// Marker files and directories git leaves behind while an operation is stopped
+	markers := []struct {
+		name      string
+		operation string
+	}{
+		{"rebase-merge", OperationRebase},
+		{"rebase-apply/applying", OperationAm},
+		{"rebase-apply", OperationRebase},
+		{"MERGE_HEAD", OperationMerge},
+		{"CHERRY_PICK_HEAD", OperationCherryPick},
+		{"REVERT_HEAD", OperationRevert},
+	}
+
+	for _, marker := range markers {
+		if _, err := os.Stat(filepath.Join(gitDir, marker.name)); err == nil {
+			return marker.operation, nil
+		}
+	}
+
+	return "", nil
+}
+
+// ConflictedFile is a path with unresolved conflicts
+type ConflictedFile struct {
+	Path  string
+	State string // how the sides conflict, e.g. "both modified" or "deleted by them"
+}
+
+// conflictStates describes the XY codes of unmerged entries in git status
+var conflictStates = map[string]string{
+	"DD": "both deleted",
+	"AU": "added by us",
+	"UD": "deleted by them",
+	"UA": "added by them",
+	"DU": "deleted by us",
+	"AA": "both added",
+	"UU": "both modified",
+}
+
+// ListConflictedFiles returns the paths of a repository with unresolved conflicts
+func ListConflictedFiles(repoPath string) ([]ConflictedFile, error) {
+	cmd := gitexec.Command("-C", repoPath, "status", "--porcelain=v2", "--untracked-files=no")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status: %v\n%s", err, output)
+	}
+
+	var files []ConflictedFile
+	for _, line := range strings.Split(string(output), "\n") {
+		// Unmerged entries: "u <XY> <sub> <m1> <m2> <m3> <mW> <h1> <h2> <h3> <path>"
+		fields := strings.SplitN(strings.TrimRight(line, "\r"), " ", 11)
+		if len(fields) < 11 || fields[0] != "u" {
+			continue
+		}
+		state, ok := conflictStates[fields[1]]
+		if !ok {
+			state = fields[1]
+		}
+		files = append(files, ConflictedFile{Path: fields[10], State: state})
+	}
+	return files, nil
+}
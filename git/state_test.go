@@ -0,0 +1,46 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+
+	"git_cli_tool/gitexec/gitexectest"
+)
+
+func TestListConflictedFiles(t *testing.T) {
+	tests := []struct {
+		name   string
+		status string
+		want   []ConflictedFile
+	}{
+		{
+			name: "unmerged entries",
+			status: "1 .M N... 100644 100644 100644 3f2a9c1 3f2a9c1 README.md\n" +
+				"u UU N... 100644 100644 100644 100644 1111111 2222222 3333333 src/main.go\n" +
+				"u UD N... 100644 100644 000000 100644 1111111 2222222 0000000 docs/old guide.md\n",
+			want: []ConflictedFile{
+				{Path: "src/main.go", State: "both modified"},
+				{Path: "docs/old guide.md", State: "deleted by them"},
+			},
+		},
+		{
+			name:   "no conflicts",
+			status: "1 M. N... 100644 100644 100644 3f2a9c1 4b5c6d7 README.md\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := gitexectest.New(t)
+			fake.On("status --porcelain=v2", gitexectest.Result{Stdout: tt.status})
+
+			got, err := ListConflictedFiles("repo")
+			if err != nil {
+				t.Fatalf("ListConflictedFiles() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ListConflictedFiles() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+
+	"git_cli_tool/gitexec"
+)
+
+// WorkingTreeStatus is the parsed output of `git status --porcelain=v2 --branch`
+type WorkingTreeStatus struct {
+	Branch          string // empty when HEAD is detached
+	Detached        bool
+	Head            string // commit SHA of HEAD, empty in a repository without commits
+	Upstream        string // empty when no upstream is set
+	Ahead           int
+	Behind          int
+	CountsUnknown   bool // HEAD differs from the upstream but Ahead and Behind were not counted
+	StagedChanges   int
+	UnstagedChanges int
+	UntrackedFiles  int
+	Conflicts       int
+}
+
+// HasChanges reports whether the working tree or index has any changes
+func (s WorkingTreeStatus) HasChanges() bool {
+	return s.StagedChanges+s.UnstagedChanges+s.UntrackedFiles+s.Conflicts > 0
+}
+
+// HasTrackedChanges reports whether the index or tracked files have changes,
+// ignoring untracked files, which git carries across a switch or pull
+func (s WorkingTreeStatus) HasTrackedChanges() bool {
+	return s.StagedChanges+s.UnstagedChanges+s.Conflicts > 0
+}
+
+// GetWorkingTreeStatus returns branch, upstream and change information of a
+// repository using a single git invocation
+func GetWorkingTreeStatus(repoPath string) (WorkingTreeStatus, error) {
+	cmd := gitexec.Command("-C", repoPath, "status", "--porcelain=v2", "--branch")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return WorkingTreeStatus{}, fmt.Errorf("failed to get status: %v\n%s", err, output)
+	}
+	return ParseStatusPorcelainV2(string(output)), nil
+}
+
+// GetWorkingTreeStatusWithoutCounts is GetWorkingTreeStatus without counting
+// the commits ahead of and behind the upstream, which can be slow in large
+// repositories. CountsUnknown is set when HEAD and the upstream differ.
+func GetWorkingTreeStatusWithoutCounts(repoPath string) (WorkingTreeStatus, error) {
+	cmd := gitexec.Command("-C", repoPath, "status", "--porcelain=v2", "--branch", "--no-ahead-behind")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return WorkingTreeStatus{}, fmt.Errorf("failed to get status: %v\n%s", err, output)
+	}
+	return ParseStatusPorcelainV2(string(output)), nil
+}
+
+// ParseStatusPorcelainV2 parses the output of `git status --porcelain=v2 --branch`
+func ParseStatusPorcelainV2(output string) WorkingTreeStatus {
+	var status WorkingTreeStatus
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" {
+			continue
+		}
+
+		switch line[0] {
+		case '#':
+			parseBranchHeader(line, &status)
+		case '1', '2':
+			// Ordinary and renamed/copied entries: "1 XY ..." / "2 XY ..."
+			if len(line) < 4 {
+				continue
+			}
+			if line[2] != '.' {
+				status.StagedChanges++
+			}
+			if line[3] != '.' {
+				status.UnstagedChanges++
+			}
+		case 'u':
+			status.Conflicts++
+		case '?':
+			status.UntrackedFiles++
+		}
+	}
+
+	return status
+}
+
+// parseBranchHeader parses a "# branch.*" header line
+func parseBranchHeader(line string, status *WorkingTreeStatus) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 {
+		return
+	}
+
+	switch fields[1] {
+	case "branch.oid":
+		if fields[2] != "(initial)" {
+			status.Head = fields[2]
+		}
+	case "branch.head":
+		if fields[2] == "(detached)" {
+			status.Detached = true
+		} else {
+			status.Branch = fields[2]
+		}
+	case "branch.upstream":
+		status.Upstream = fields[2]
+	case "branch.ab":
+		// "+? -?" with --no-ahead-behind when HEAD and upstream differ
+		if len(fields) == 4 && fields[2] == "+?" {
+			status.CountsUnknown = true
+		} else if len(fields) == 4 {
+			fmt.Sscanf(fields[2], "+%d", &status.Ahead)
+			fmt.Sscanf(fields[3], "-%d", &status.Behind)
+		}
+	}
+}
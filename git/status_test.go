@@ -0,0 +1,63 @@
+package git
+
+import "testing"
+
+func TestParseStatusPorcelainV2(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   WorkingTreeStatus
+	}{
+		{
+			name: "ahead and behind",
+			output: "# branch.oid 77d344a9b9f38d640a97836f130059e2d2563a54\n# branch.head main\n" +
+				"# branch.upstream origin/main\n# branch.ab +2 -1\n1 .M N... 100644 100644 100644 3f2a9c1 3f2a9c1 README.md\n? build/\n",
+			want: WorkingTreeStatus{Branch: "main", Head: "77d344a9b9f38d640a97836f130059e2d2563a54", Upstream: "origin/main", Ahead: 2, Behind: 1, UnstagedChanges: 1, UntrackedFiles: 1},
+		},
+		{
+			name: "counts not computed",
+			output: "# branch.oid 77d344a9b9f38d640a97836f130059e2d2563a54\n# branch.head main\n" +
+				"# branch.upstream origin/main\n# branch.ab +? -?\n",
+			want: WorkingTreeStatus{Branch: "main", Head: "77d344a9b9f38d640a97836f130059e2d2563a54", Upstream: "origin/main", CountsUnknown: true},
+		},
+		{
+			name:   "detached HEAD",
+			output: "# branch.oid 77d344a9b9f38d640a97836f130059e2d2563a54\n# branch.head (detached)\n",
+			want:   WorkingTreeStatus{Detached: true, Head: "77d344a9b9f38d640a97836f130059e2d2563a54"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseStatusPorcelainV2(tt.output); got != tt.want {
+				t.Errorf("ParseStatusPorcelainV2() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasTrackedChanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  WorkingTreeStatus
+		changes bool
+		tracked bool
+	}{
+		{name: "clean", status: WorkingTreeStatus{}},
+		{name: "untracked only", status: WorkingTreeStatus{UntrackedFiles: 2}, changes: true},
+		{name: "staged", status: WorkingTreeStatus{StagedChanges: 1, UntrackedFiles: 1}, changes: true, tracked: true},
+		{name: "unstaged", status: WorkingTreeStatus{UnstagedChanges: 1}, changes: true, tracked: true},
+		{name: "conflicted", status: WorkingTreeStatus{Conflicts: 1}, changes: true, tracked: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.status.HasChanges(); got != tt.changes {
+				t.Errorf("HasChanges() = %v, want %v", got, tt.changes)
+			}
+			if got := tt.status.HasTrackedChanges(); got != tt.tracked {
+				t.Errorf("HasTrackedChanges() = %v, want %v", got, tt.tracked)
+			}
+		})
+	}
+}
@@ -3,13 +3,12 @@ package git
 
 import (
 	"fmt"
-	"os"
-	"os/exec"
 	"path/filepath"
-	"sync"
+	"strconv"
+	"strings"
+	"unicode"
 
-	"git_cli_tool/config"
-	"git_cli_tool/log"
+	"git_cli_tool/gitexec"
 )
 
 // SyncTags synchronizes local tags with remote in a single optimized operation.
@@ -17,15 +16,15 @@ import (
 // - Updates tags that point to different commits locally vs remote (--force)
 // - Removes local tags that no longer exist on remote (--prune --prune-tags)
 // - Fetches new tags from remote (--tags)
-func SyncTags(repoPath string) error {
+func SyncTags(repoPath string, remote string) error {
 	absPath, err := filepath.Abs(repoPath)
 	if err != nil {
 		return fmt.Errorf("failed to resolve absolute path: %v", err)
 	}
 
 	// Check if repository exists
-	if _, err := os.Stat(filepath.Join(absPath, ".git")); os.IsNotExist(err) {
-		return fmt.Errorf("not a git repository or directory does not exist")
+	if err := ValidateRepository(absPath); err != nil {
+		return err
 	}
 
 	// Single command to sync all tags:
@@ -33,33 +32,180 @@ func SyncTags(repoPath string) error {
 	// --force: overwrite local tags that differ from remote
 	// --prune: remove remote-tracking refs that no longer exist
 	// --prune-tags: remove local tags that no longer exist on remote
-	fetchCmd := exec.Command("git", "-C", absPath, "fetch", "--tags", "--force", "--prune", "--prune-tags")
+	fetchCmd := gitexec.Command("-C", absPath, "fetch", remote, "--tags", "--force", "--prune", "--prune-tags")
 	fetchOutput, err := fetchCmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("failed to sync tags: %v\n%s", err, fetchOutput)
 	}
 
-	log.PrintSuccess(fmt.Sprintf("Successfully synced tags in %s", repoPath))
 	return nil
 }
 
-// ProcessTags syncs tags for all repositories in parallel
-func ProcessTags(repositories []config.Repository) {
-	var wg sync.WaitGroup
-	wg.Add(len(repositories))
+// ListTags returns the local tags of a repository, mapped to the object they
+// point to (the tag object for annotated tags)
+func ListTags(repoPath string) (map[string]string, error) {
+	cmd := gitexec.Command("-C", repoPath, "for-each-ref", "--format=%(objectname) %(refname:strip=2)", "refs/tags")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %v\n%s", err, output)
+	}
+	return parseTagRefs(string(output), ""), nil
+}
+
+// ListRemoteTags returns the tags of the remote, mapped to the object they
+// point to, in the same form as ListTags
+func ListRemoteTags(repoPath string, remote string) (map[string]string, error) {
+	cmd := gitexec.Command("-C", repoPath, "ls-remote", "--tags", "--refs", remote)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, WrapAuthFailure(fmt.Errorf("failed to list remote tags: %v\n%s", err, output), string(output))
+	}
+	return parseTagRefs(string(output), "refs/tags/"), nil
+}
+
+// parseTagRefs parses "<object> <ref>" lines, removing prefix from the refs
+func parseTagRefs(output string, prefix string) map[string]string {
+	tags := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		tags[strings.TrimPrefix(fields[1], prefix)] = fields[0]
+	}
+	return tags
+}
+
+// RestoreTag points a local tag at object again, creating or overwriting it.
+// It fails if the object no longer exists, e.g. after git gc.
+func RestoreTag(repoPath string, tag string, object string) error {
+	cmd := gitexec.Command("-C", repoPath, "update-ref", "refs/tags/"+tag, object)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to restore tag %s: %v\n%s", tag, err, output)
+	}
+	return nil
+}
 
-	for _, repo := range repositories {
-		go func(r config.Repository) {
-			defer wg.Done()
+// CreateTag creates an annotated tag at ref. With sign, the tag is signed with
+// the repository's signing configuration (user.signingkey, gpg.format).
+func CreateTag(repoPath string, tag string, ref string, message string, sign bool) error {
+	mode := "-a"
+	if sign {
+		mode = "-s"
+	}
+	cmd := gitexec.Command("-C", repoPath, "tag", mode, tag, "-m", message, ref)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to create tag %s: %v\n%s", tag, err, output)
+	}
+	return nil
+}
 
-			log.PrintOperation(fmt.Sprintf("Syncing tags for %s", r.Path))
+// PushTag pushes a single tag to the remote
+func PushTag(repoPath string, remote string, tag string) error {
+	cmd := gitexec.Command("-C", repoPath, "push", remote, "refs/tags/"+tag)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to push tag %s: %v\n%s", tag, err, output)
+	}
+	return nil
+}
 
-			err := SyncTags(r.Path)
-			if err != nil {
-				log.PrintErrorNoExit(log.ErrGitTagOperationFailed, fmt.Sprintf("Error syncing tags in %s", r.Path), err)
+// GetExactTag returns a tag pointing exactly at HEAD, or an empty string if there is none
+func GetExactTag(repoPath string) string {
+	cmd := gitexec.Command("-C", repoPath, "describe", "--tags", "--exact-match", "HEAD")
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// TagExists reports whether a tag exists locally
+func TagExists(repoPath string, tag string) bool {
+	cmd := gitexec.Command("-C", repoPath, "show-ref", "--verify", "--quiet", "refs/tags/"+tag)
+	return cmd.Run() == nil
+}
+
+// FetchTags fetches all tags of the remote, without pruning or overwriting local tags
+func FetchTags(repoPath string, remote string) error {
+	cmd := gitexec.Command("-C", repoPath, "fetch", remote, "--tags")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to fetch tags: %v\n%s", err, output)
+	}
+	return nil
+}
+
+// FindNearestEarlierTag returns the highest local tag that sorts before tag in
+// version order and has the same prefix (e.g. "v" in "v2.3.0"), or an empty
+// string if there is none
+func FindNearestEarlierTag(repoPath string, tag string) (string, error) {
+	cmd := gitexec.Command("-C", repoPath, "tag", "--list")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("failed to list tags: %v\n%s", err, output)
+	}
+
+	prefix := versionPrefix(tag)
+	nearest := ""
+	for _, candidate := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if candidate == "" || versionPrefix(candidate) != prefix {
+			continue
+		}
+		if CompareVersions(candidate, tag) < 0 && (nearest == "" || CompareVersions(candidate, nearest) > 0) {
+			nearest = candidate
+		}
+	}
+	return nearest, nil
+}
+
+// CompareVersions compares two version strings such as "v2.10.0" and "v2.9.1",
+// comparing runs of digits numerically and everything else as text.
+// Returns -1, 0 or 1.
+func CompareVersions(a string, b string) int {
+	aParts, bParts := versionParts(a), versionParts(b)
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		x, y := aParts[i], bParts[i]
+		xNum, xErr := strconv.Atoi(x)
+		yNum, yErr := strconv.Atoi(y)
+		switch {
+		case xErr == nil && yErr == nil && xNum != yNum:
+			if xNum < yNum {
+				return -1
 			}
-		}(repo)
+			return 1
+		case (xErr != nil || yErr != nil) && x != y:
+			return strings.Compare(x, y)
+		}
+	}
+	switch {
+	case len(aParts) < len(bParts):
+		return -1
+	case len(aParts) > len(bParts):
+		return 1
 	}
+	return 0
+}
 
-	wg.Wait()
+// versionParts splits a version into runs of digits and runs of other characters
+func versionParts(version string) []string {
+	var parts []string
+	start := 0
+	for i := 1; i <= len(version); i++ {
+		if i == len(version) || unicode.IsDigit(rune(version[i])) != unicode.IsDigit(rune(version[i-1])) {
+			parts = append(parts, version[start:i])
+			start = i
+		}
+	}
+	return parts
+}
+
+// versionPrefix returns the part of a version before its first digit, e.g. "v" or "release-"
+func versionPrefix(version string) string {
+	if i := strings.IndexFunc(version, unicode.IsDigit); i >= 0 {
+		return version[:i]
+	}
+	return version
 }
@@ -0,0 +1,76 @@
+package git
+
+import (
+	"testing"
+
+	"git_cli_tool/gitexec/gitexectest"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "v2.3.0", b: "v2.3.0", want: 0},
+		{a: "v2.9.1", b: "v2.10.0", want: -1},
+		{a: "v2.10.0", b: "v2.9.1", want: 1},
+		{a: "v2.3", b: "v2.3.1", want: -1},
+		{a: "v2.3.0-rc1", b: "v2.3.0-rc2", want: -1},
+		{a: "1.0", b: "v1.0", want: -1},
+	}
+
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestFindNearestEarlierTag(t *testing.T) {
+	tags := "api-3.0.0\nv1.9.0\nv2.2.0\nv2.2.10\nv2.4.0\nv2.10.0\n"
+
+	tests := []struct {
+		name string
+		tag  string
+		want string
+	}{
+		{name: "highest earlier version", tag: "v2.3.0", want: "v2.2.10"},
+		{name: "numeric not lexical order", tag: "v2.11.0", want: "v2.10.0"},
+		{name: "tags with another prefix are ignored", tag: "v1.0.0", want: ""},
+		{name: "other prefix", tag: "api-3.1.0", want: "api-3.0.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := gitexectest.New(t)
+			fake.On("tag --list", gitexectest.Result{Stdout: tags})
+
+			got, err := FindNearestEarlierTag("repo", tt.tag)
+			if err != nil {
+				t.Fatalf("FindNearestEarlierTag() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("FindNearestEarlierTag(%q) = %q, want %q", tt.tag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListRemoteTags(t *testing.T) {
+	fake := gitexectest.New(t)
+	fake.On("ls-remote", gitexectest.Result{Stdout: "1111111 refs/tags/v1.0.0\n2222222 refs/tags/release/2024\n"})
+
+	got, err := ListRemoteTags("repo", "origin")
+	if err != nil {
+		t.Fatalf("ListRemoteTags() error = %v", err)
+	}
+	want := map[string]string{"v1.0.0": "1111111", "release/2024": "2222222"}
+	if len(got) != len(want) {
+		t.Fatalf("ListRemoteTags() = %v, want %v", got, want)
+	}
+	for tag, object := range want {
+		if got[tag] != object {
+			t.Errorf("ListRemoteTags()[%q] = %q, want %q", tag, got[tag], object)
+		}
+	}
+}
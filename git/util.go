@@ -4,14 +4,15 @@ package git
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"strings"
 
-	"git_cli_tool/config"
-	"git_cli_tool/log"
+	"git_cli_tool/gitexec"
 )
 
-// ValidateRepository checks if a path is a valid git repository
+// ValidateRepository checks if a path is a valid git repository. Besides a .git
+// directory, the .git file of linked worktrees and submodules is accepted when
+// the git directory it points to exists.
 func ValidateRepository(repoPath string) error {
 	absPath, err := filepath.Abs(repoPath)
 	if err != nil {
@@ -19,13 +20,62 @@ func ValidateRepository(repoPath string) error {
 	}
 
 	// Check if repository exists
-	if _, err := os.Stat(filepath.Join(absPath, ".git")); os.IsNotExist(err) {
+	dotGit := filepath.Join(absPath, ".git")
+	info, err := os.Stat(dotGit)
+	if err != nil {
 		return fmt.Errorf("not a git repository or directory does not exist")
 	}
+	if info.IsDir() {
+		return nil
+	}
+
+	gitDir, err := ReadGitFile(dotGit)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(gitDir); err != nil {
+		return fmt.Errorf("git directory %s referenced by %s does not exist", gitDir, dotGit)
+	}
 
 	return nil
 }
 
+// ReadGitFile returns the git directory a .git file points to. Linked worktrees
+// and submodules have such a file ("gitdir: <path>") instead of a .git directory.
+// Relative paths are resolved against the directory containing the file.
+func ReadGitFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %v", path, err)
+	}
+
+	content := strings.TrimSpace(string(data))
+	if !strings.HasPrefix(content, "gitdir:") {
+		return "", fmt.Errorf("%s is not a valid .git file", path)
+	}
+
+	gitDir := strings.TrimSpace(strings.TrimPrefix(content, "gitdir:"))
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(filepath.Dir(path), gitDir)
+	}
+	return filepath.Clean(gitDir), nil
+}
+
+// FindRepositoryRoot returns the top-level directory of the git repository
+// containing dir, or an error if dir is not inside a git repository
+func FindRepositoryRoot(dir string) (string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve absolute path: %v", err)
+	}
+
+	output, err := gitexec.Command("-C", absDir, "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return "", fmt.Errorf("%s is not inside a git repository", absDir)
+	}
+	return filepath.FromSlash(strings.TrimSpace(string(output))), nil
+}
+
 // RunGitCommand runs a git command in the specified repository path
 func RunGitCommand(repoPath string, args ...string) (string, error) {
 	if err := ValidateRepository(repoPath); err != nil {
@@ -34,43 +84,8 @@ func RunGitCommand(repoPath string, args ...string) (string, error) {
 
 	// Add the -C flag and repository path to the beginning of the arguments
 	cmdArgs := append([]string{"-C", repoPath}, args...)
-	cmd := exec.Command("git", cmdArgs...)
+	cmd := gitexec.Command(cmdArgs...)
 	output, err := cmd.CombinedOutput()
 
 	return string(output), err
 }
-
-// RevertToState reverts all repositories to the state described in the history
-func RevertToState(state config.BranchState, applyStashes bool) error {
-	log.PrintOperation(fmt.Sprintf("Reverting to branch state from %s", state.Timestamp))
-
-	if state.Description != "" {
-		log.PrintInfo(fmt.Sprintf("Description: %s", state.Description))
-	}
-
-	// Process each repository in state
-	for repoPath, branchInfo := range state.Repositories {
-		// Skip if there's no branch info (shouldn't happen, but just in case)
-		if branchInfo.Branch == "" {
-			log.PrintWarning(fmt.Sprintf("Skipping %s: no branch recorded in history", repoPath))
-			continue
-		}
-
-		// Switch to the recorded branch
-		err := SwitchToBranch(repoPath, branchInfo.Branch)
-		if err != nil {
-			log.PrintErrorNoExit(log.ErrGitCheckoutFailed, fmt.Sprintf("Error switching branch in %s", repoPath), err)
-			continue
-		}
-
-		// If there was a stash recorded and applyStashes is true, try to apply it
-		if branchInfo.StashName != "" && applyStashes {
-			err = ApplyStash(repoPath, branchInfo.StashName)
-			if err != nil {
-				log.PrintErrorNoExit(log.ErrGitApplyStashFailed, fmt.Sprintf("Error applying stash in %s", repoPath), err)
-			}
-		}
-	}
-
-	return nil
-}
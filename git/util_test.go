@@ -0,0 +1,126 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"git_cli_tool/gitexec/gitexectest"
+)
+
+func TestValidateRepository(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(t *testing.T, dir string) // prepares the repository directory
+		wantErr bool
+	}{
+		{
+			name: ".git directory",
+			setup: func(t *testing.T, dir string) {
+				mustMkdir(t, filepath.Join(dir, ".git"))
+			},
+		},
+		{
+			name: "worktree with absolute gitdir",
+			setup: func(t *testing.T, dir string) {
+				gitDir := filepath.Join(t.TempDir(), "main", ".git", "worktrees", "feature")
+				mustMkdir(t, gitDir)
+				mustWrite(t, filepath.Join(dir, ".git"), "gitdir: "+gitDir+"\n")
+			},
+		},
+		{
+			name: "submodule with relative gitdir",
+			setup: func(t *testing.T, dir string) {
+				mustMkdir(t, filepath.Join(dir, "modules", "lib"))
+				mustMkdir(t, filepath.Join(dir, "lib"))
+				mustWrite(t, filepath.Join(dir, "lib", ".git"), "gitdir: ../modules/lib\n")
+			},
+		},
+		{
+			name:    "no .git",
+			setup:   func(t *testing.T, dir string) {},
+			wantErr: true,
+		},
+		{
+			name: "gitdir does not exist",
+			setup: func(t *testing.T, dir string) {
+				mustWrite(t, filepath.Join(dir, ".git"), "gitdir: /nonexistent/worktrees/x\n")
+			},
+			wantErr: true,
+		},
+		{
+			name: "malformed .git file",
+			setup: func(t *testing.T, dir string) {
+				mustWrite(t, filepath.Join(dir, ".git"), "not a pointer\n")
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			tt.setup(t, dir)
+
+			// The submodule case checks out the module below the temporary directory
+			repoPath := dir
+			if _, err := os.Stat(filepath.Join(dir, "lib")); err == nil {
+				repoPath = filepath.Join(dir, "lib")
+			}
+
+			err := ValidateRepository(repoPath)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateRepository() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFindRepositoryRoot(t *testing.T) {
+	tests := []struct {
+		name    string
+		result  gitexectest.Result
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "inside a repository",
+			result: gitexectest.Result{Stdout: "/home/me/code/tool\n"},
+			want:   filepath.FromSlash("/home/me/code/tool"),
+		},
+		{
+			name:    "outside a repository",
+			result:  gitexectest.Result{Stderr: "fatal: not a git repository (or any of the parent directories): .git\n", ExitCode: 128},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := gitexectest.New(t)
+			fake.On("rev-parse --show-toplevel", tt.result)
+
+			got, err := FindRepositoryRoot(t.TempDir())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FindRepositoryRoot() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("FindRepositoryRoot() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func mustMkdir(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(path, 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func mustWrite(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
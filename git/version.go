@@ -0,0 +1,166 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"git_cli_tool/config"
+	"git_cli_tool/gitexec"
+)
+
+// Version patterns for well-known version files, keyed by file name.
+// The first group of each pattern is the version.
+var defaultVersionPatterns = map[string]string{
+	"package.json": `"version"\s*:\s*"([^"]+)"`,
+	"Chart.yaml":   `(?m)^version:\s*["']?([^"'\s]+)`,
+	"VERSION":      `^\s*(\S+)`,
+	"VERSION.txt":  `^\s*(\S+)`,
+}
+
+// versionPattern returns the compiled pattern of a version file
+func versionPattern(file config.VersionFile) (*regexp.Regexp, error) {
+	pattern := file.Pattern
+	if pattern == "" {
+		pattern = defaultVersionPatterns[filepath.Base(file.Path)]
+	}
+	if pattern == "" {
+		return nil, fmt.Errorf("no pattern configured for %s and its file type is not known", file.Path)
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid version pattern for %s: %v", file.Path, err)
+	}
+	if re.NumSubexp() < 1 {
+		return nil, fmt.Errorf("version pattern for %s has no capture group", file.Path)
+	}
+	return re, nil
+}
+
+// ReadVersion returns the version stored in a version file of a repository
+func ReadVersion(repoPath string, file config.VersionFile) (string, error) {
+	re, err := versionPattern(file)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(filepath.Join(repoPath, file.Path))
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %v", file.Path, err)
+	}
+
+	match := re.FindSubmatch(data)
+	if match == nil {
+		return "", fmt.Errorf("no version found in %s", file.Path)
+	}
+	return string(match[1]), nil
+}
+
+// WriteVersion replaces the version stored in a version file of a repository
+func WriteVersion(repoPath string, file config.VersionFile, version string) error {
+	re, err := versionPattern(file)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(repoPath, file.Path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", file.Path, err)
+	}
+
+	// Only the first group of the first match is replaced, the rest of the file is kept as is
+	loc := re.FindSubmatchIndex(data)
+	if loc == nil {
+		return fmt.Errorf("no version found in %s", file.Path)
+	}
+	updated := append(append(append([]byte{}, data[:loc[2]]...), version...), data[loc[3]:]...)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", file.Path, err)
+	}
+	if err := os.WriteFile(path, updated, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write %s: %v", file.Path, err)
+	}
+	return nil
+}
+
+// semverPattern matches a semantic version (https://semver.org) with an optional "v" prefix
+var semverPattern = regexp.MustCompile(`^v?(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+	`(-(0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(\.(0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*)?` +
+	`(\+[0-9a-zA-Z-]+(\.[0-9a-zA-Z-]+)*)?$`)
+
+// IsSemanticVersion reports whether version is a semantic version such as
+// 1.4.0, v2.0.0-rc.1 or 1.0.0+build.5
+func IsSemanticVersion(version string) bool {
+	return semverPattern.MatchString(version)
+}
+
+// BumpVersion returns the version following current for a bump of "major", "minor"
+// or "patch". Any other bump is taken as the new version itself and must be a
+// semantic version. A "v" prefix is kept and pre-release or build suffixes are dropped.
+func BumpVersion(current string, bump string) (string, error) {
+	if bump != "major" && bump != "minor" && bump != "patch" {
+		if !IsSemanticVersion(bump) {
+			return "", fmt.Errorf("'%s' is not a semantic version such as 1.4.0 or v2.0.0-rc.1", bump)
+		}
+		return bump, nil
+	}
+
+	prefix := ""
+	version := current
+	if strings.HasPrefix(version, "v") {
+		prefix = "v"
+		version = version[1:]
+	}
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+
+	parts := strings.Split(version, ".")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("version '%s' is not of the form MAJOR.MINOR.PATCH", current)
+	}
+	numbers := make([]int, 3)
+	for i, part := range parts {
+		number, err := strconv.Atoi(part)
+		if err != nil {
+			return "", fmt.Errorf("version '%s' is not of the form MAJOR.MINOR.PATCH", current)
+		}
+		numbers[i] = number
+	}
+
+	switch bump {
+	case "major":
+		numbers = []int{numbers[0] + 1, 0, 0}
+	case "minor":
+		numbers = []int{numbers[0], numbers[1] + 1, 0}
+	case "patch":
+		numbers[2]++
+	}
+	return fmt.Sprintf("%s%d.%d.%d", prefix, numbers[0], numbers[1], numbers[2]), nil
+}
+
+// CommitFiles commits the given files, and only those, with a message.
+// With sign, the commit is signed.
+func CommitFiles(repoPath string, message string, files []string, sign bool) error {
+	addArgs := append([]string{"-C", repoPath, "add", "--"}, files...)
+	if output, err := gitexec.Command(addArgs...).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to stage files: %v\n%s", err, output)
+	}
+
+	commitArgs := []string{"-C", repoPath, "commit", "-m", message}
+	if sign {
+		commitArgs = append(commitArgs, "--gpg-sign")
+	}
+	commitArgs = append(append(commitArgs, "--"), files...)
+	if output, err := gitexec.Command(commitArgs...).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to commit: %v\n%s", err, output)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package git
+
+import "testing"
+
+func TestBumpVersion(t *testing.T) {
+	tests := []struct {
+		current string
+		bump    string
+		want    string
+		wantErr bool
+	}{
+		{current: "1.4.2", bump: "patch", want: "1.4.3"},
+		{current: "1.4.2", bump: "minor", want: "1.5.0"},
+		{current: "v1.4.2", bump: "major", want: "v2.0.0"},
+		{current: "1.4.2-rc.1+build.7", bump: "patch", want: "1.4.3"},
+		{current: "1.4", bump: "patch", wantErr: true},
+		{current: "1.4.2", bump: "2.0.0", want: "2.0.0"},
+		{current: "1.4.2", bump: "v2.1.0-rc.1", want: "v2.1.0-rc.1"},
+		{current: "1.4.2", bump: "1.0.0+build.5", want: "1.0.0+build.5"},
+		{current: "1.4.2", bump: "2.0", wantErr: true},
+		{current: "1.4.2", bump: "01.0.0", wantErr: true},
+		{current: "1.4.2", bump: "mayor", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := BumpVersion(tt.current, tt.bump)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("BumpVersion(%q, %q) error = %v, wantErr %v", tt.current, tt.bump, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("BumpVersion(%q, %q) = %q, want %q", tt.current, tt.bump, got, tt.want)
+		}
+	}
+}
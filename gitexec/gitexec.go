@@ -0,0 +1,384 @@
+// Package gitexec runs git commands. Every git invocation of the tool goes
+// through Command, so it can be traced, recorded in a transcript and replaced
+// by a fake Runner in tests.
+package gitexec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"git_cli_tool/log"
+)
+
+// transcript receives a record of every git command that is run, if set
+var (
+	transcriptMutex sync.Mutex
+	transcript      io.Writer
+)
+
+// trace echoes every git command before it runs
+var trace bool
+
+// SetTrace enables printing every git command before it runs, like "set -x"
+func SetTrace(enabled bool) {
+	trace = enabled
+}
+
+// SetTranscript records every git command, its working directory, output and
+// exit code to w. Pass nil to stop recording.
+func SetTranscript(w io.Writer) {
+	transcriptMutex.Lock()
+	defer transcriptMutex.Unlock()
+	transcript = w
+}
+
+// binary is the git executable that is run
+var binary = "git"
+
+// SetBinary runs the git executable at path (or found in the PATH by name)
+// for every git command that is created afterwards. An empty path restores "git".
+func SetBinary(path string) {
+	if path == "" {
+		path = "git"
+	}
+	binary = path
+}
+
+// globalArgs are added to every git command, e.g. "-c core.longpaths=true"
+var globalArgs []string
+
+// SetGlobalArgs adds args to every git command that is created afterwards,
+// before the git subcommand (after a leading "-C <path>")
+func SetGlobalArgs(args []string) {
+	globalArgs = args
+}
+
+// ConfigEntry is a git configuration variable and its value
+type ConfigEntry struct {
+	Key   string
+	Value string
+}
+
+// remoteConfig is passed to the git commands that talk to a remote
+var remoteConfig []ConfigEntry
+
+// remoteSubcommands are the git subcommands that may contact a remote
+var remoteSubcommands = map[string]bool{
+	"clone":       true,
+	"fetch":       true,
+	"ls-remote":   true,
+	"maintenance": true, // the prefetch task fetches
+	"pull":        true,
+	"push":        true,
+	"remote":      true,
+	"submodule":   true,
+}
+
+// SetRemoteConfig passes git configuration to every command created afterwards
+// that may talk to a remote, e.g. http.proxy. It is passed in the environment
+// (GIT_CONFIG_COUNT, GIT_CONFIG_KEY_<n> and GIT_CONFIG_VALUE_<n>, read by git
+// 2.31 and later) instead of as -c options, so values with credentials show up
+// neither in the process list nor in the trace or the transcript.
+func SetRemoteConfig(entries []ConfigEntry) {
+	remoteConfig = entries
+}
+
+// terminalPrompts allows git to ask for credentials on the terminal
+var terminalPrompts bool
+
+// SetTerminalPrompts allows git to ask for usernames, passwords and
+// passphrases on the terminal. They are disabled by default, so a command
+// that needs credentials fails instead of waiting for input that a parallel
+// run never gives it; ssh then runs in batch mode, so it does not ask for a
+// key passphrase or to accept an unknown host key either.
+func SetTerminalPrompts(enabled bool) {
+	terminalPrompts = enabled
+}
+
+// Runner executes git commands. The command's Stdout and Stderr are always set
+// when Run is called; a fake runner writes its canned output to them.
+type Runner interface {
+	Run(cmd *exec.Cmd) error
+}
+
+// execRunner runs commands as processes
+type execRunner struct{}
+
+func (execRunner) Run(cmd *exec.Cmd) error {
+	return cmd.Run()
+}
+
+// runner executes all git commands
+var runner Runner = execRunner{}
+
+// SetRunner replaces the runner that executes git commands and returns the
+// previous one, so tests can restore it. It must not be called while commands run.
+func SetRunner(r Runner) Runner {
+	previous := runner
+	runner = r
+	return previous
+}
+
+// ExitCode returns the exit code of a git command that ran and failed, or -1
+// if err is nil or the command could not be run
+func ExitCode(err error) int {
+	var exitErr interface{ ExitCode() int }
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+// Cmd is a git command. It embeds exec.Cmd, so Dir, Env, Stdin, Stdout and
+// Stderr can be set as usual before running it.
+type Cmd struct {
+	*exec.Cmd
+}
+
+// Command returns a git command with the given arguments, e.g. Command("-C", path, "status")
+func Command(args ...string) *Cmd {
+	return &Cmd{Cmd: exec.Command(binary, withGlobalArgs(args)...)}
+}
+
+// CommandContext is like Command, but git is killed when ctx is done, e.g.
+// when a timeout expires
+func CommandContext(ctx context.Context, args ...string) *Cmd {
+	cmd := exec.CommandContext(ctx, binary, withGlobalArgs(args)...)
+	// Do not wait for processes git started that still hold its output open
+	cmd.WaitDelay = time.Second
+	return &Cmd{Cmd: cmd}
+}
+
+// withGlobalArgs inserts the global arguments before the git subcommand
+func withGlobalArgs(args []string) []string {
+	if len(globalArgs) == 0 {
+		return args
+	}
+	prefix := 0
+	if len(args) >= 2 && args[0] == "-C" {
+		prefix = 2
+	}
+	return append(append(append([]string{}, args[:prefix]...), globalArgs...), args[prefix:]...)
+}
+
+// Run runs the command and waits for it to finish
+func (c *Cmd) Run() error {
+	var stdout, stderr bytes.Buffer
+	c.Stdout = teeWriter(c.Stdout, &stdout)
+	c.Stderr = teeWriter(c.Stderr, &stderr)
+
+	start := c.start()
+	err := runner.Run(c.Cmd)
+	c.record(start, stdout.Bytes(), stderr.Bytes(), err)
+	return err
+}
+
+// Output runs the command and returns its standard output
+func (c *Cmd) Output() ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+
+	start := c.start()
+	err := runner.Run(c.Cmd)
+	c.record(start, stdout.Bytes(), stderr.Bytes(), err)
+
+	// Keep the behaviour of exec.Cmd.Output, which returns stderr with the exit error
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		exitErr.Stderr = stderr.Bytes()
+	}
+	return stdout.Bytes(), err
+}
+
+// CombinedOutput runs the command and returns its standard output and error combined
+func (c *Cmd) CombinedOutput() ([]byte, error) {
+	var combined lockedBuffer
+	var stdout, stderr bytes.Buffer
+	c.Stdout = io.MultiWriter(&combined, &stdout)
+	c.Stderr = io.MultiWriter(&combined, &stderr)
+
+	start := c.start()
+	err := runner.Run(c.Cmd)
+	c.record(start, stdout.Bytes(), stderr.Bytes(), err)
+	return combined.Bytes(), err
+}
+
+// start sets up the environment of the command, traces it if enabled and
+// returns the time it started
+func (c *Cmd) start() time.Time {
+	if c.Env == nil {
+		c.Env = os.Environ()
+	}
+	// Like --no-optional-locks: background commands such as status must not
+	// take locks that make the user's own git commands fail
+	c.Env = append(c.Env, "GIT_OPTIONAL_LOCKS=0")
+	if !terminalPrompts {
+		c.Env = append(c.Env, "GIT_TERMINAL_PROMPT=0", "GCM_INTERACTIVE=never")
+		c.Env = withSSHBatchMode(c.Env)
+	}
+	if len(remoteConfig) > 0 && remoteSubcommands[subcommand(c.Args)] {
+		c.Env = withConfigEnv(c.Env, remoteConfig)
+	}
+
+	if trace {
+		log.PrintTrace(commandLine(c.Args))
+	}
+	return time.Now()
+}
+
+// withSSHBatchMode makes the ssh git runs fail instead of prompting. An ssh
+// command set with GIT_SSH_COMMAND gets the option added, a program set with
+// GIT_SSH cannot take it and is left alone.
+func withSSHBatchMode(env []string) []string {
+	var sshCommand, sshProgram string
+	for _, variable := range env {
+		if value, ok := strings.CutPrefix(variable, "GIT_SSH_COMMAND="); ok {
+			sshCommand = value
+		} else if value, ok := strings.CutPrefix(variable, "GIT_SSH="); ok {
+			sshProgram = value
+		}
+	}
+	if sshCommand == "" && sshProgram != "" {
+		return env
+	}
+	if sshCommand == "" {
+		sshCommand = "ssh"
+	}
+	return append(env, "GIT_SSH_COMMAND="+sshCommand+" -o BatchMode=yes")
+}
+
+// subcommand returns the git subcommand of command arguments, skipping the
+// executable and the options before the subcommand
+func subcommand(args []string) string {
+	for i := 1; i < len(args); i++ {
+		switch {
+		case args[i] == "-C" || args[i] == "-c":
+			i++
+		case !strings.HasPrefix(args[i], "-"):
+			return args[i]
+		}
+	}
+	return ""
+}
+
+// withConfigEnv adds configuration entries to an environment, after the ones
+// that are already passed in it with GIT_CONFIG_COUNT
+func withConfigEnv(env []string, entries []ConfigEntry) []string {
+	count := 0
+	for _, variable := range env {
+		if value, ok := strings.CutPrefix(variable, "GIT_CONFIG_COUNT="); ok {
+			count, _ = strconv.Atoi(value)
+		}
+	}
+	for _, entry := range entries {
+		env = append(env, fmt.Sprintf("GIT_CONFIG_KEY_%d=%s", count, entry.Key), fmt.Sprintf("GIT_CONFIG_VALUE_%d=%s", count, entry.Value))
+		count++
+	}
+	// When a variable is set more than once, the last value is used
+	return append(env, fmt.Sprintf("GIT_CONFIG_COUNT=%d", count))
+}
+
+// commandLine formats command arguments so they can be copied into a shell
+func commandLine(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\n'\"$`\\*?|&;<>()") {
+			arg = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+		quoted[i] = arg
+	}
+	return strings.Join(quoted, " ")
+}
+
+// record writes the transcript entry of a finished command
+func (c *Cmd) record(start time.Time, stdout []byte, stderr []byte, err error) {
+	transcriptMutex.Lock()
+	defer transcriptMutex.Unlock()
+	if transcript == nil {
+		return
+	}
+
+	var entry strings.Builder
+	fmt.Fprintf(&entry, "[%s] %s\n", start.Format("2006-01-02T15:04:05.000Z07:00"), commandLine(c.Args))
+	fmt.Fprintf(&entry, "dir:    %s\n", c.workingDir())
+	fmt.Fprintf(&entry, "exit:   %s (%s)\n", exitStatus(err), time.Since(start).Round(time.Millisecond))
+	writeStream(&entry, "stdout", stdout)
+	writeStream(&entry, "stderr", stderr)
+	entry.WriteString("\n")
+
+	io.WriteString(transcript, entry.String())
+}
+
+// workingDir returns the directory git operates in: the -C path if given,
+// otherwise the directory the command is run from
+func (c *Cmd) workingDir() string {
+	for i := 1; i+1 < len(c.Args); i++ {
+		if c.Args[i] == "-C" {
+			return c.Args[i+1]
+		}
+	}
+	if c.Dir != "" {
+		return c.Dir
+	}
+	dir, _ := os.Getwd()
+	return dir
+}
+
+// exitStatus describes how a command ended
+func exitStatus(err error) string {
+	if err == nil {
+		return "0"
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return fmt.Sprintf("%d", exitErr.ExitCode())
+	}
+	return err.Error()
+}
+
+// writeStream adds the output of a stream to a transcript entry, indented
+func writeStream(entry *strings.Builder, name string, output []byte) {
+	text := strings.TrimRight(string(output), "\n")
+	if text == "" {
+		return
+	}
+	fmt.Fprintf(entry, "%s:\n", name)
+	for _, line := range strings.Split(text, "\n") {
+		fmt.Fprintf(entry, "    %s\n", line)
+	}
+}
+
+// teeWriter also copies output to a transcript buffer; a nil writer discards output
+func teeWriter(w io.Writer, buffer *bytes.Buffer) io.Writer {
+	if w == nil {
+		return buffer
+	}
+	return io.MultiWriter(w, buffer)
+}
+
+// lockedBuffer is a buffer that stdout and stderr can be written to concurrently
+type lockedBuffer struct {
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buffer.Write(p)
+}
+
+// Bytes returns the buffered output
+func (b *lockedBuffer) Bytes() []byte {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buffer.Bytes()
+}
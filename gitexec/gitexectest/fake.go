@@ -0,0 +1,123 @@
+// Package gitexectest provides a fake gitexec.Runner, so code that runs git
+// can be tested without repositories or a git binary.
+package gitexectest
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+
+	"git_cli_tool/gitexec"
+)
+
+// Result is the canned outcome of a git command
+type Result struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+}
+
+// ExitError is returned for results with a non-zero exit code. Like
+// exec.ExitError it has an ExitCode method, so gitexec.ExitCode works with it.
+type ExitError struct {
+	Code   int
+	Stderr string
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// ExitCode returns the exit code of the fake command
+func (e *ExitError) ExitCode() int {
+	return e.Code
+}
+
+// Fake is a gitexec.Runner that answers git commands with registered results
+// and records every command it receives
+type Fake struct {
+	mutex     sync.Mutex
+	responses []response
+	calls     []string
+}
+
+// response is a result registered for commands starting with args
+type response struct {
+	args   string
+	result Result
+}
+
+// New installs a fake runner until the end of the test and returns it
+func New(t testing.TB) *Fake {
+	fake := &Fake{}
+	previous := gitexec.SetRunner(fake)
+	t.Cleanup(func() { gitexec.SetRunner(previous) })
+	return fake
+}
+
+// On registers the result of git commands whose space-joined arguments start
+// with args, e.g. On("rev-parse --abbrev-ref HEAD", Result{Stdout: "main\n"}).
+// A leading "-C <path>" of the command is ignored. When several registrations
+// match, the first one wins. Commands without a registration fail with exit code 128.
+func (f *Fake) On(args string, result Result) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.responses = append(f.responses, response{args: args, result: result})
+}
+
+// Calls returns the commands run so far, without the leading "-C <path>"
+func (f *Fake) Calls() []string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return append([]string(nil), f.calls...)
+}
+
+// Ran reports whether a command starting with args was run
+func (f *Fake) Ran(args string) bool {
+	for _, call := range f.Calls() {
+		if hasPrefix(call, args) {
+			return true
+		}
+	}
+	return false
+}
+
+// Run implements gitexec.Runner
+func (f *Fake) Run(cmd *exec.Cmd) error {
+	args := cmd.Args[1:]
+	if len(args) >= 2 && args[0] == "-C" {
+		args = args[2:]
+	}
+
+	call := strings.Join(args, " ")
+
+	f.mutex.Lock()
+	f.calls = append(f.calls, call)
+	result := Result{Stderr: fmt.Sprintf("fatal: unexpected git command: %s\n", call), ExitCode: 128}
+	for _, r := range f.responses {
+		if hasPrefix(call, r.args) {
+			result = r.result
+			break
+		}
+	}
+	f.mutex.Unlock()
+
+	if cmd.Stdout != nil {
+		io.WriteString(cmd.Stdout, result.Stdout)
+	}
+	if cmd.Stderr != nil {
+		io.WriteString(cmd.Stderr, result.Stderr)
+	}
+	if result.ExitCode != 0 {
+		return &ExitError{Code: result.ExitCode, Stderr: result.Stderr}
+	}
+	return nil
+}
+
+// hasPrefix reports whether a command line starts with the whole words of prefix
+func hasPrefix(call string, prefix string) bool {
+	return call == prefix || strings.HasPrefix(call, prefix+" ")
+}
@@ -0,0 +1,136 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Collector groups the output of a parallel operation per repository.
+// Output is buffered and printed grouped per repository, in the order the
+// repositories were registered, when Flush is called. In streaming mode every
+// line is printed as soon as it is written instead.
+type Collector struct {
+	mutex   sync.Mutex
+	stream  bool
+	order   []string
+	outputs map[string]*RepoOutput
+}
+
+// RepoOutput is the output of a single repository within a Collector
+type RepoOutput struct {
+	collector *Collector
+	lines     []outputLine
+}
+
+// outputLine is a buffered line and the stream it belongs to
+type outputLine struct {
+	text   string
+	stderr bool
+}
+
+// NewCollector creates a collector that prints repositories in the order of keys
+func NewCollector(keys []string, stream bool) *Collector {
+	collector := &Collector{
+		stream:  stream,
+		outputs: make(map[string]*RepoOutput),
+	}
+	for _, key := range keys {
+		collector.Repo(key)
+	}
+	return collector
+}
+
+// Repo returns the output of a repository, registering it if it is not known yet
+func (c *Collector) Repo(key string) *RepoOutput {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	output, ok := c.outputs[key]
+	if !ok {
+		output = &RepoOutput{collector: c}
+		c.outputs[key] = output
+		c.order = append(c.order, key)
+	}
+	return output
+}
+
+// Flush prints all buffered output grouped per repository
+func (c *Collector) Flush() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for _, key := range c.order {
+		output := c.outputs[key]
+		for _, line := range output.lines {
+			writeLine(line)
+		}
+		output.lines = nil
+	}
+}
+
+// write buffers a line, or prints it straight away in streaming mode.
+// Lines below the current log level are dropped.
+func (o *RepoOutput) write(l Level, text string, stderr bool) {
+	if !Enabled(l) {
+		return
+	}
+
+	o.collector.mutex.Lock()
+	defer o.collector.mutex.Unlock()
+
+	line := outputLine{text: text, stderr: stderr}
+	if o.collector.stream {
+		writeLine(line)
+		return
+	}
+	o.lines = append(o.lines, line)
+}
+
+// PrintErrorNoExit records an error message with the appropriate error code
+func (o *RepoOutput) PrintErrorNoExit(code string, description string, err error) {
+	o.write(LevelError, FormatError(code, description, err), true)
+}
+
+// PrintWarning records a warning message
+func (o *RepoOutput) PrintWarning(message string) {
+	o.write(LevelWarn, FormatWarning(message), true)
+}
+
+// PrintSuccess records a success message
+func (o *RepoOutput) PrintSuccess(message string) {
+	o.write(LevelInfo, FormatSuccess(message), false)
+}
+
+// PrintInfo records an info message
+func (o *RepoOutput) PrintInfo(message string) {
+	o.write(LevelInfo, message, false)
+}
+
+// PrintOperation records a message about an operation being performed
+func (o *RepoOutput) PrintOperation(operation string) {
+	o.write(LevelInfo, operation, false)
+}
+
+// PrintDebug records a debug message
+func (o *RepoOutput) PrintDebug(message string) {
+	o.write(LevelDebug, FormatDebug(message), true)
+}
+
+// writeLine prints a line to the stream it belongs to. An active progress
+// line is cleared first and redrawn below the printed line.
+func writeLine(line outputLine) {
+	terminalMutex.Lock()
+	defer terminalMutex.Unlock()
+
+	if activeProgress != nil {
+		activeProgress.clear()
+		defer activeProgress.draw()
+	}
+
+	if line.stderr {
+		fmt.Fprintln(os.Stderr, line.text)
+	} else {
+		fmt.Println(line.text)
+	}
+}
@@ -0,0 +1,66 @@
+package log
+
+import (
+	"fmt"
+	"os"
+)
+
+// ANSI escape sequences used for colored output
+const (
+	colorReset  = "\033[0m"
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+	colorGray   = "\033[90m"
+)
+
+// Whether colors are used on each stream; off until ConfigureColor is called
+var (
+	colorStdout bool
+	colorStderr bool
+)
+
+// ConfigureColor enables colored output on the streams that are terminals,
+// unless disabled (--no-color) or the NO_COLOR environment variable is set
+func ConfigureColor(disabled bool) {
+	if disabled || os.Getenv("NO_COLOR") != "" {
+		colorStdout = false
+		colorStderr = false
+		return
+	}
+	colorStdout = IsTerminal(os.Stdout) && enableVirtualTerminal(os.Stdout)
+	colorStderr = IsTerminal(os.Stderr) && enableVirtualTerminal(os.Stderr)
+}
+
+// IsTerminal reports whether a file is an interactive terminal
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// ClearScreen clears the terminal so a view can be redrawn in place.
+// Returns false, without printing anything, when stdout is not a terminal.
+func ClearScreen() bool {
+	if !IsTerminal(os.Stdout) || !enableVirtualTerminal(os.Stdout) {
+		return false
+	}
+	terminalMutex.Lock()
+	defer terminalMutex.Unlock()
+	fmt.Print("\033[H\033[2J")
+	return true
+}
+
+// colorize wraps text in a color if colors are enabled for the stream it is printed to
+func colorize(text string, color string, stderr bool) string {
+	enabled := colorStdout
+	if stderr {
+		enabled = colorStderr
+	}
+	if !enabled {
+		return text
+	}
+	return color + text + colorReset
+}
@@ -0,0 +1,10 @@
+//go:build !windows
+
+package log
+
+import "os"
+
+// enableVirtualTerminal is a no-op: terminals outside Windows understand ANSI escape sequences
+func enableVirtualTerminal(f *os.File) bool {
+	return true
+}
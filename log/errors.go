@@ -21,6 +21,10 @@ const (
 	ErrGitFetchFailed        = "E205" // Failed to fetch from remote
 	ErrGitPullFailed         = "E206" // Failed to pull from remote
 	ErrGitTagOperationFailed = "E207" // Failed to perform tag operation
+	ErrGitBranchesDiverged   = "E208" // Repositories ended up on different branches
+	ErrGitAuthFailed         = "E209" // The remote asked for credentials or rejected them
+	ErrGitConflict           = "E210" // A merge or cherry-pick stopped on conflicts
+	ErrGitUncommittedChanges = "E211" // Repositories have uncommitted changes
 
 	// Repository errors (3xx)
 	ErrRepoNotFound    = "E301" // Repository not found
@@ -28,22 +32,27 @@ const (
 	ErrRepoNotGit      = "E303" // Not a git repository
 
 	// History operation errors (4xx)
-	ErrHistoryReadFailed   = "E401" // Failed to read history file
-	ErrHistoryWriteFailed  = "E402" // Failed to write history file
-	ErrHistoryStateFailed  = "E403" // Failed to save state to history
-	ErrHistoryIndexInvalid = "E404" // Invalid history index
+	ErrHistoryReadFailed      = "E401" // Failed to read history file
+	ErrHistoryWriteFailed     = "E402" // Failed to write history file
+	ErrHistoryStateFailed     = "E403" // Failed to save state to history
+	ErrHistoryIndexInvalid    = "E404" // Invalid history index
+	ErrHistoryStateIncomplete = "E405" // History state does not match the configured repositories
 
 	// General errors (9xx)
 	ErrInvalidArgument = "E901" // Invalid argument passed
+	ErrLogFileFailed   = "E902" // Failed to open the log file
+	ErrHookFailed      = "E903" // A configured hook command failed
+	ErrServeFailed     = "E904" // The HTTP server could not be started
+	ErrPromptRequired  = "E905" // A confirmation or choice was needed but prompts are disabled
 	ErrOperationFailed = "E999" // Generic operation failed
 )
 
 // FormatError formats an error with a consistent structure including the error code
 func FormatError(code string, description string, err error) string {
 	if err != nil {
-		return fmt.Sprintf("[%s] %s: %v", code, description, err)
+		return colorize(fmt.Sprintf("[%s] %s: %v", code, description, err), colorRed, true)
 	}
-	return fmt.Sprintf("[%s] %s", code, description)
+	return colorize(fmt.Sprintf("[%s] %s", code, description), colorRed, true)
 }
 
 // GetErrorCode extracts the error code from a formatted error message
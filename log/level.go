@@ -0,0 +1,25 @@
+package log
+
+// Level is the minimum severity of messages that are printed
+type Level int
+
+// Log levels, from most to least verbose
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// level is the current log level, set from the --verbose and --quiet flags
+var level = LevelInfo
+
+// SetLevel sets the minimum severity of printed messages
+func SetLevel(l Level) {
+	level = l
+}
+
+// Enabled reports whether messages of the given level are printed
+func Enabled(l Level) bool {
+	return l >= level
+}
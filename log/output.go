@@ -7,53 +7,74 @@ import (
 
 // FormatWarning formats a warning message with a consistent structure
 func FormatWarning(message string) string {
-	return fmt.Sprintf("[WARN]    %s", message)
+	return colorize(fmt.Sprintf("[WARN]    %s", message), colorYellow, true)
 }
 
 // FormatSuccess formats a success message with a consistent structure
 func FormatSuccess(message string) string {
-	return fmt.Sprintf("[SUCCESS] %s", message)
+	return colorize(fmt.Sprintf("[SUCCESS] %s", message), colorGreen, false)
 }
 
 // FormatDebug formats a debug message with a consistent structure
 func FormatDebug(message string) string {
-	return fmt.Sprintf("[DEBUG]   %s", message)
+	return colorize(fmt.Sprintf("[DEBUG]   %s", message), colorGray, true)
 }
 
 // PrintError prints an error message with the appropriate error code and exits with code 1
 func PrintError(code string, description string, err error) {
-	fmt.Fprintln(os.Stderr, FormatError(code, description, err))
+	writeLine(outputLine{text: FormatError(code, description, err), stderr: true})
 	os.Exit(1)
 }
 
 // PrintErrorNoExit prints an error message with the appropriate error code without exiting
 func PrintErrorNoExit(code string, description string, err error) {
-	fmt.Fprintln(os.Stderr, FormatError(code, description, err))
+	writeLine(outputLine{text: FormatError(code, description, err), stderr: true})
 }
 
 // PrintWarning prints a warning message
 func PrintWarning(message string) {
-	fmt.Fprintln(os.Stderr, FormatWarning(message))
+	if Enabled(LevelWarn) {
+		writeLine(outputLine{text: FormatWarning(message), stderr: true})
+	}
 }
 
 // PrintSuccess prints a success message
 func PrintSuccess(message string) {
-	fmt.Println(FormatSuccess(message))
+	if Enabled(LevelInfo) {
+		writeLine(outputLine{text: FormatSuccess(message)})
+	}
 }
 
 // PrintInfo prints an info message
 func PrintInfo(message string) {
-	fmt.Println((message))
+	if Enabled(LevelInfo) {
+		writeLine(outputLine{text: message})
+	}
+}
+
+// PrintOutput prints the primary output of a command, such as search results.
+// It is printed at every log level so --quiet only hides the surrounding messages.
+func PrintOutput(message string) {
+	writeLine(outputLine{text: message})
 }
 
 // PrintOperation prints a message about an operation being performed
 func PrintOperation(operation string) {
-	fmt.Println((operation))
+	if Enabled(LevelInfo) {
+		writeLine(outputLine{text: operation})
+	}
 }
 
-// PrintDebug prints a debug message
+// PrintDebug prints a debug message, only shown with --verbose
 func PrintDebug(message string) {
-	fmt.Fprintln(os.Stderr, FormatDebug(message))
+	if Enabled(LevelDebug) {
+		writeLine(outputLine{text: FormatDebug(message), stderr: true})
+	}
+}
+
+// PrintTrace prints a command before it runs, only called with --trace
+func PrintTrace(command string) {
+	writeLine(outputLine{text: colorize("+ "+command, colorGray, true), stderr: true})
 }
 
 // PrintOperationResult prints the result of an operation
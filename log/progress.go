@@ -0,0 +1,147 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// spinnerFrames are the frames of the spinner shown for each running repository
+var spinnerFrames = []rune("⠋⠙⠹⠸⠼⠴⠦⠧⠇⠏")
+
+// maxProgressRepos is the number of running repositories listed on the progress line
+const maxProgressRepos = 4
+
+// maxProgressWidth keeps the progress line short enough not to wrap, so it can be redrawn in place
+const maxProgressWidth = 79
+
+// terminalMutex serializes writes to the terminal while a progress line is shown
+var terminalMutex sync.Mutex
+
+// activeProgress is the progress line currently shown on stderr, if any
+var activeProgress *Progress
+
+// Progress shows how many repositories of a parallel operation are complete,
+// with a spinner for every repository still running. It is drawn on a single
+// line on stderr that is cleared before any other output is printed and redrawn
+// after it, so it can be used together with a Collector in streaming mode.
+// It is only shown when stderr is a terminal and info messages are enabled.
+type Progress struct {
+	label   string
+	total   int
+	done    int
+	frame   int
+	running []progressRepo
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// progressRepo is a running repository and the frame its spinner started at
+type progressRepo struct {
+	name  string
+	start int
+}
+
+// StartProgress shows a progress line for an operation on total repositories.
+// The returned progress must be stopped before the results are printed.
+func StartProgress(label string, total int) *Progress {
+	progress := &Progress{label: label, total: total}
+	if !Enabled(LevelInfo) || !interactive || !IsTerminal(os.Stderr) || !enableVirtualTerminal(os.Stderr) {
+		return progress
+	}
+
+	progress.stop = make(chan struct{})
+	progress.stopped = make(chan struct{})
+
+	terminalMutex.Lock()
+	activeProgress = progress
+	progress.draw()
+	terminalMutex.Unlock()
+
+	go progress.animate()
+	return progress
+}
+
+// Start marks a repository as running
+func (p *Progress) Start(name string) {
+	terminalMutex.Lock()
+	defer terminalMutex.Unlock()
+	p.running = append(p.running, progressRepo{name: name, start: p.frame})
+}
+
+// Done marks a repository as complete
+func (p *Progress) Done(name string) {
+	terminalMutex.Lock()
+	defer terminalMutex.Unlock()
+
+	p.done++
+	for i, repo := range p.running {
+		if repo.name == name {
+			p.running = append(p.running[:i], p.running[i+1:]...)
+			break
+		}
+	}
+}
+
+// Stop removes the progress line from the terminal
+func (p *Progress) Stop() {
+	if p.stop == nil {
+		return
+	}
+	close(p.stop)
+	<-p.stopped
+	p.stop = nil
+
+	terminalMutex.Lock()
+	defer terminalMutex.Unlock()
+	p.clear()
+	activeProgress = nil
+}
+
+// animate redraws the progress line until it is stopped
+func (p *Progress) animate() {
+	defer close(p.stopped)
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			terminalMutex.Lock()
+			p.frame++
+			p.draw()
+			terminalMutex.Unlock()
+		}
+	}
+}
+
+// draw writes the progress line over the current terminal line.
+// Must be called with terminalMutex held.
+func (p *Progress) draw() {
+	var line strings.Builder
+	fmt.Fprintf(&line, "%s %d/%d", p.label, p.done, p.total)
+	for i, repo := range p.running {
+		if i == maxProgressRepos {
+			fmt.Fprintf(&line, "  +%d more", len(p.running)-maxProgressRepos)
+			break
+		}
+		frame := spinnerFrames[(p.frame-repo.start)%len(spinnerFrames)]
+		fmt.Fprintf(&line, "  %c %s", frame, repo.name)
+	}
+
+	text := []rune(line.String())
+	if len(text) > maxProgressWidth {
+		text = text[:maxProgressWidth]
+	}
+	fmt.Fprintf(os.Stderr, "\r\033[K%s", string(text))
+}
+
+// clear removes the progress line. Must be called with terminalMutex held.
+func (p *Progress) clear() {
+	fmt.Fprint(os.Stderr, "\r\033[K")
+}
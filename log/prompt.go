@@ -0,0 +1,127 @@
+package log
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ErrNotInteractive is returned by Confirm, Choose and SelectMany when prompts are disabled
+// or there is no terminal to ask on
+var ErrNotInteractive = errors.New("cannot ask: prompts are disabled (--non-interactive) or standard input is not a terminal")
+
+// interactive is false in non-interactive mode, where nothing is asked
+var interactive = true
+
+// SetInteractive enables or disables prompts. With prompts disabled, the prompts
+// fail with ErrNotInteractive and no progress line is drawn.
+func SetInteractive(enabled bool) {
+	interactive = enabled
+}
+
+// CanPrompt reports whether questions can be asked: prompts are enabled and
+// standard input is a terminal
+func CanPrompt() bool {
+	return interactive && IsTerminal(os.Stdin)
+}
+
+// Confirm asks a yes/no question on the terminal and reports whether it was
+// answered with yes. The default answer is no.
+func Confirm(question string) (bool, error) {
+	if !CanPrompt() {
+		return false, ErrNotInteractive
+	}
+
+	terminalMutex.Lock()
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	terminalMutex.Unlock()
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		return false, err
+	}
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
+// Choose lists numbered options on the terminal and asks for one of them,
+// returning its index. An empty or invalid answer returns an error.
+func Choose(question string, options []string) (int, error) {
+	if !CanPrompt() {
+		return -1, ErrNotInteractive
+	}
+
+	terminalMutex.Lock()
+	fmt.Fprintln(os.Stderr, question)
+	for i, option := range options {
+		fmt.Fprintf(os.Stderr, "  %d) %s\n", i+1, option)
+	}
+	fmt.Fprintf(os.Stderr, "Choose [1-%d]: ", len(options))
+	terminalMutex.Unlock()
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		return -1, err
+	}
+
+	choice, err := strconv.Atoi(strings.TrimSpace(answer))
+	if err != nil || choice < 1 || choice > len(options) {
+		return -1, fmt.Errorf("invalid choice %q", strings.TrimSpace(answer))
+	}
+	return choice - 1, nil
+}
+
+// SelectMany lists numbered options on the terminal, all of them checked, and
+// lets the user toggle options by entering their numbers until an empty answer
+// accepts the list. It returns which options are checked.
+func SelectMany(question string, options []string) ([]bool, error) {
+	if !CanPrompt() {
+		return nil, ErrNotInteractive
+	}
+
+	checked := make([]bool, len(options))
+	for i := range checked {
+		checked[i] = true
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		terminalMutex.Lock()
+		fmt.Fprintln(os.Stderr, question)
+		for i, option := range options {
+			mark := " "
+			if checked[i] {
+				mark = "x"
+			}
+			fmt.Fprintf(os.Stderr, "  [%s] %d) %s\n", mark, i+1, option)
+		}
+		fmt.Fprint(os.Stderr, "Toggle with numbers (e.g. 2 4), Enter to continue: ")
+		terminalMutex.Unlock()
+
+		answer, err := reader.ReadString('\n')
+		if err != nil && answer == "" {
+			return nil, err
+		}
+
+		fields := strings.FieldsFunc(answer, func(r rune) bool {
+			return r == ' ' || r == ',' || r == '\t' || r == '\r' || r == '\n'
+		})
+		if len(fields) == 0 {
+			return checked, nil
+		}
+		for _, field := range fields {
+			choice, err := strconv.Atoi(field)
+			if err != nil || choice < 1 || choice > len(options) {
+				terminalMutex.Lock()
+				fmt.Fprintf(os.Stderr, "Invalid choice %q\n", field)
+				terminalMutex.Unlock()
+				continue
+			}
+			checked[choice-1] = !checked[choice-1]
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"git_cli_tool/config"
+)
+
+// CommentOnTicket adds a comment to a Jira issue. Jira Cloud is authenticated
+// with the account email and an API token, Jira Server with a personal access
+// token (read from token_env, token or JIRA_API_TOKEN).
+func CommentOnTicket(jira config.JiraConfig, ticket string, text string) error {
+	if jira.URL == "" {
+		return fmt.Errorf("no Jira URL configured")
+	}
+
+	data, err := json.Marshal(map[string]string{"body": text})
+	if err != nil {
+		return fmt.Errorf("failed to encode comment: %v", err)
+	}
+	endpoint := strings.TrimRight(jira.URL, "/") + "/rest/api/2/issue/" + url.PathEscape(ticket) + "/comment"
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	token := jiraToken(jira)
+	if jira.Email != "" {
+		req.SetBasicAuth(jira.Email, token)
+	} else if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// jiraToken returns the API token from the token_env variable, the token
+// setting or JIRA_API_TOKEN, in that order
+func jiraToken(jira config.JiraConfig) string {
+	if jira.TokenEnv != "" {
+		if value := os.Getenv(jira.TokenEnv); value != "" {
+			return value
+		}
+	}
+	if jira.Token != "" {
+		return jira.Token
+	}
+	return os.Getenv("JIRA_API_TOKEN")
+}
@@ -0,0 +1,107 @@
+// Package notify posts summaries of finished commands to Slack or other webhooks
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"git_cli_tool/config"
+)
+
+// httpClient is used for all notifications
+var httpClient = &http.Client{Timeout: 15 * time.Second}
+
+// Summary describes the outcome of a command run across repositories.
+// It is the JSON payload of generic webhooks.
+type Summary struct {
+	Command   string   `json:"command"`
+	Succeeded []string `json:"succeeded"`
+	Failed    []string `json:"failed"`
+	Duration  float64  `json:"duration_seconds"`
+	Text      string   `json:"text"` // human readable summary, also used for Slack
+
+	elapsed time.Duration
+}
+
+// NewSummary builds the summary of a command from the repository names and
+// their errors (nil for repositories that succeeded)
+func NewSummary(command string, names []string, errs []error, duration time.Duration) Summary {
+	summary := Summary{
+		Command:   command,
+		Succeeded: []string{},
+		Failed:    []string{},
+		Duration:  duration.Round(time.Second).Seconds(),
+		elapsed:   duration,
+	}
+	for i, name := range names {
+		if errs[i] != nil {
+			summary.Failed = append(summary.Failed, name)
+		} else {
+			summary.Succeeded = append(summary.Succeeded, name)
+		}
+	}
+
+	summary.Text = fmt.Sprintf("git_cli_tool %s finished in %s: %d succeeded, %d failed",
+		command, duration.Round(time.Second), len(summary.Succeeded), len(summary.Failed))
+	if len(summary.Failed) > 0 {
+		summary.Text += fmt.Sprintf(" (%s)", strings.Join(summary.Failed, ", "))
+	}
+	return summary
+}
+
+// ShouldSend reports whether a summary passes the only_on_failure and min_duration settings
+func ShouldSend(notifications config.NotificationsConfig, summary Summary) bool {
+	if notifications.SlackWebhook == "" && notifications.Webhook == "" {
+		return false
+	}
+	if notifications.OnlyOnFailure && len(summary.Failed) == 0 {
+		return false
+	}
+	return summary.elapsed >= notifications.MinDuration
+}
+
+// Send posts a summary to every configured target
+func Send(notifications config.NotificationsConfig, summary Summary) error {
+	var errs []string
+
+	if notifications.SlackWebhook != "" {
+		if err := post(notifications.SlackWebhook, map[string]string{"text": summary.Text}); err != nil {
+			errs = append(errs, fmt.Sprintf("slack: %v", err))
+		}
+	}
+	if notifications.Webhook != "" {
+		if err := post(notifications.Webhook, summary); err != nil {
+			errs = append(errs, fmt.Sprintf("webhook: %v", err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// post sends a JSON payload to a URL
+func post(url string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %v", err)
+	}
+
+	resp, err := httpClient.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}